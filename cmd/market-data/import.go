@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
+)
+
+// runImport loads CSV or JSON price files through the file provider and stores them via the market service.
+//
+//	market-data import [-symbol AAPL] [-name "Apple Inc."] [-exchange NASDAQ] [-format csv] [-delimiter ";"] [-interval 1d]
+//	FILE...
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	symbol := flags.String("symbol", "", "symbol of the imported prices, defaults to the file name without extension")
+	name := flags.String("name", "", "full name of the symbol")
+	exchange := flags.String("exchange", "", "exchange of the symbol, defaults to file_provider.exchange")
+	format := flags.String("format", "", "file format (csv, json), defaults to the file extension")
+	delimiter := flags.String("delimiter", "", "CSV delimiter, defaults to file_provider.delimiter")
+	interval := flags.String("interval", string(yahoo.Interval1d), "bar interval of the imported prices")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: market-data import [flags] FILE...")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if !slices.Contains(yahoo.Intervals, yahoo.IntervalAPI(*interval)) {
+		log.Fatal().Str("interval", *interval).Msg("Unknown bar interval")
+	}
+	if *symbol != "" && flags.NArg() > 1 {
+		log.Fatal().Msg("-symbol can only be used with a single file")
+	}
+
+	cfg := initConfig()
+	initLogger(&cfg.Logging)
+
+	fileCfg := cfg.FileProvider
+	if *format != "" {
+		fileCfg.Format = *format
+	}
+	if *delimiter != "" {
+		fileCfg.Delimiter = *delimiter
+	}
+	if *exchange != "" {
+		fileCfg.Exchange = *exchange
+	}
+	fileProvider := createFileProvider(&fileCfg)
+
+	db := initDatabase(&cfg.Database)
+	defer db.Close()
+	runMigrations(cfg.Migrations.Enabled, cfg.Database.GetSchemaConnectionString())
+
+	marketSvc := market.NewMarketService(market.NewMarketRepository(db), fileProvider)
+	if validator := newValidator(&cfg.Validation); validator != nil {
+		marketSvc.SetValidator(validator)
+	}
+	if cfg.Anomalies.OnIngest {
+		marketSvc.SetAnomalyDetector(newAnomalyService(&cfg.Anomalies, db))
+	}
+
+	ctx := context.Background()
+	failed := false
+	fmt.Printf("%-30s %-10s %9s %9s %9s %11s\n", "FILE", "SYMBOL", "INSERTED", "UPDATED", "REJECTED", "QUARANTINED")
+	for _, path := range flags.Args() {
+		fileSymbol := *symbol
+		if fileSymbol == "" {
+			fileSymbol = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+
+		data, rejections, err := fileProvider.LoadFile(fileSymbol, path)
+		if err != nil {
+			log.Error().Err(err).Str("path", path).Msg("Failed to load price file")
+			failed = true
+			continue
+		}
+		for _, rejection := range rejections {
+			log.Warn().Str("path", path).Int("row", rejection.Row).Str("reason", rejection.Reason).Msg("Row rejected")
+		}
+		if *name != "" {
+			data.Name = *name
+		}
+		data.Interval = yahoo.IntervalAPI(*interval)
+
+		result := &market.SaveResult{}
+		if len(data.Prices) > 0 {
+			result, err = marketSvc.ImportMarketData(ctx, data)
+			if err != nil {
+				log.Error().Err(err).Str("path", path).Msg("Failed to import price file")
+				failed = true
+				continue
+			}
+		}
+
+		fmt.Printf("%-30s %-10s %9d %9d %9d %11d\n", filepath.Base(path), data.Symbol, result.Inserted, result.Updated,
+			len(rejections), result.Quarantined)
+	}
+
+	if failed {
+		db.Close()
+		os.Exit(1)
+	}
+}
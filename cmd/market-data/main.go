@@ -7,12 +7,29 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	data "github.com/market-data/db"
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/domain/anomaly"
+	"github.com/market-data/internal/domain/archive"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/market-data/internal/domain/leader"
 	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/domain/series"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/market-data/internal/providers/binance"
+	"github.com/market-data/internal/providers/file"
+	"github.com/market-data/internal/providers/fred"
+	"github.com/market-data/internal/providers/plugin"
+	"github.com/market-data/internal/providers/synthetic"
 	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/scheduler"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -26,6 +43,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
 	cfg := initConfig()
 	initLogger(&cfg.Logging)
 
@@ -35,10 +57,18 @@ func main() {
 	runMigrations(cfg.Migrations.Enabled, cfg.Database.GetSchemaConnectionString())
 
 	router := initRouter()
+	meter := initUsageMeter(&cfg.Usage, db)
+	if meter != nil {
+		defer flushUsage(meter)
+	}
+
 	// Create market repository and service
 	marketRepo := market.NewMarketRepository(db)
-	yahooClient := createYahooProvider(&cfg.YahooFinance)
-	marketSvc := market.NewMarketService(marketRepo, yahooClient)
+	provider := createProvider(cfg, meter)
+	if p, ok := provider.(*plugin.Client); ok {
+		defer p.Stop()
+	}
+	marketSvc := newMarketService(cfg, db, marketRepo, providerName(cfg), provider)
 
 	// Configure auto-update settings
 	marketSvc.SetAutoUpdateSettings(
@@ -46,16 +76,103 @@ func main() {
 		cfg.YahooFinance.EnableAutoUpdate,
 	)
 
+	if cfg.FetchThrough.Enabled {
+		marketSvc.SetFetchThrough(cfg.FetchThrough.GetTimeout(), cfg.FetchThrough.GetUnknownTTL(),
+			cfg.FetchThrough.MaxUnknown)
+		if meter != nil {
+			marketSvc.SetQuotaChecker(meter)
+		}
+	}
+
 	// Start auto-update if enabled
 	//if cfg.YahooFinance.EnableAutoUpdate {
 	//	marketSvc.StartAutoUpdate()
 	//}
 
-	registerControllers(router, marketSvc)
+	fredClient := fred.NewClient(&cfg.Fred)
+	if meter != nil {
+		fredClient.SetTransport(usage.NewTransport(meter, strings.ToLower(fred.Source), nil))
+	}
+	seriesSvc := series.NewSeriesService(series.NewSeriesRepository(db), fredClient)
+	optionsSvc := options.NewOptionsService(options.NewOptionsRepository(db), createYahooProvider(&cfg.YahooFinance, meter))
+	optionsSvc.SetAnalyticsSettings(marketSvc, cfg.Options.RiskFreeRate, cfg.Options.PersistAnalytics)
+
+	var elector *leader.Elector
+	if cfg.Leader.Enabled {
+		elector = leader.NewElector(leader.NewLeaseRepository(db), leader.RoleScheduler, leader.Identity(),
+			cfg.Leader.GetLeaseTTL())
+		elector.Start(context.Background())
+		defer elector.Stop()
+	}
+
+	// with several replicas the demo data is seeded by the leader only, the others find it stored
+	if cfg.Demo.Enabled {
+		if elector == nil || elector.IsLeader() {
+			seedDemoData(marketSvc, cfg.Demo.Symbols)
+		} else {
+			log.Info().Str("leader", elector.Status().Leader).Msg("Demo data is seeded by the leader")
+		}
+	}
+
+	trackingSvc := initTracking(cfg, db, meter, marketRepo, marketSvc)
+
+	jobQueue := initJobQueue(&cfg.Jobs, db, marketSvc)
+
+	var monitor *freshness.Monitor
+	if cfg.Freshness.Enabled {
+		monitor = initFreshness(cfg, db, provider, trackingSvc)
+	}
+
+	sched := initScheduler(cfg, meter, marketSvc, optionsSvc, trackingSvc, jobQueue, monitor)
+	if elector != nil {
+		sched.SetLeadership(elector)
+	}
+	sched.Start(context.Background())
+	defer sched.Stop()
+
+	jobQueue.Start(context.Background())
+	defer jobQueue.Stop()
+
+	registerControllers(router, &services{
+		market:    marketSvc,
+		series:    seriesSvc,
+		options:   optionsSvc,
+		tracking:  trackingSvc,
+		jobs:      jobQueue,
+		anomalies: newAnomalyService(&cfg.Anomalies, db),
+		usage:     meter,
+		elector:   elector,
+		monitor:   monitor,
+	})
 
 	startServer(router, cfg.Server.Host, cfg.Server.Port)
 }
 
+// services groups the domain services exposed through the API
+type services struct {
+	market    *market.MarketService
+	series    *series.SeriesService
+	options   *options.OptionsService
+	tracking  *tracking.TrackingService
+	jobs      *jobs.Queue
+	anomalies *anomaly.Service
+	usage     *usage.Meter       // nil when usage accounting is disabled
+	elector   *leader.Elector    // nil when leader election is disabled
+	monitor   *freshness.Monitor // nil when freshness monitoring is disabled
+}
+
+// runCommand executes a one-off command instead of starting the server
+func runCommand(name string, args []string) {
+	switch name {
+	case "import":
+		runImport(args)
+	case "reprocess":
+		runReprocess(args)
+	default:
+		log.Fatal().Str("command", name).Msg("Unknown command, available commands: import, reprocess")
+	}
+}
+
 func initConfig() *config.Config {
 	cfg, err := config.Load()
 	if err != nil {
@@ -110,17 +227,366 @@ func requestLoggingMiddleware(c *gin.Context) {
 		Msg("Request processed")
 }
 
-func createYahooProvider(cfg *config.YahooFinanceConfig) *yahoo.Client {
+// initUsageMeter creates the provider usage meter with the requests already made today, nil when disabled
+func initUsageMeter(cfg *config.UsageConfig, db *database.DB) *usage.Meter {
+	if !cfg.Enabled {
+		return nil
+	}
+	meter := usage.NewMeter(usage.NewUsageRepository(db), cfg.DailyCaps)
+	if err := meter.Load(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to load today's provider usage, daily caps start from zero")
+	}
+	return meter
+}
+
+func flushUsage(meter *usage.Meter) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := meter.Flush(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush provider usage")
+	}
+}
+
+// providerName returns the name of the configured market data provider, as used for usage accounting
+func providerName(cfg *config.Config) string {
+	switch {
+	case cfg.Demo.Enabled:
+		return "synthetic"
+	case cfg.DataProvider == "":
+		return yahoo.Provider
+	default:
+		return cfg.DataProvider
+	}
+}
+
+// newMarketService creates the market service of a provider, archiving its raw responses when enabled
+func newMarketService(cfg *config.Config, db *database.DB, repo *market.MarketRepository, name string,
+	provider market.DataProvider) *market.MarketService {
+	svc := market.NewMarketService(repo, provider)
+	svc.SetProviderName(name)
+	svc.SetFetchLocker(repo)
+	if validator := newValidator(&cfg.Validation); validator != nil {
+		svc.SetValidator(validator)
+	}
+	if cfg.Anomalies.OnIngest {
+		svc.SetAnomalyDetector(newAnomalyService(&cfg.Anomalies, db))
+	}
+	if cfg.Archive.Enabled {
+		svc.SetPayloadArchive(archive.NewArchiveRepository(db))
+	}
+	return svc
+}
+
+// newValidator creates the validator of incoming bars, nil when validation is disabled
+func newValidator(cfg *config.ValidationConfig) *market.Validator {
+	if !cfg.Enabled {
+		return nil
+	}
+	validator, err := market.NewValidator(cfg.Rules, cfg.MaxChangePercent)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid validation configuration")
+	}
+	return validator
+}
+
+// newAnomalyService creates the anomaly detection of stored bars
+func newAnomalyService(cfg *config.AnomalyConfig, db *database.DB) *anomaly.Service {
+	detector, err := anomaly.NewDetector(anomaly.Settings{
+		Method:    cfg.Method,
+		Window:    cfg.Window,
+		Threshold: cfg.Threshold,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid anomaly detection configuration")
+	}
+	return anomaly.NewService(anomaly.NewAnomalyRepository(db), detector)
+}
+
+func createProvider(cfg *config.Config, meter *usage.Meter) market.DataProvider {
+	if cfg.Demo.Enabled {
+		log.Info().Msg("Demo mode enabled, serving synthetic market data")
+		return createSyntheticProvider(&cfg.Synthetic)
+	}
+
+	switch cfg.DataProvider {
+	case "binance":
+		log.Info().Msg("Using Binance klines provider")
+		client := binance.NewClient(&cfg.Binance)
+		// records the responses in the fetch log, and their usage when metered
+		client.SetTransport(usage.NewTransport(meter, binance.Provider, nil))
+		return client
+	case "file":
+		log.Info().Str("directory", cfg.FileProvider.Directory).Msg("Using file provider")
+		return createFileProvider(&cfg.FileProvider)
+	case "synthetic":
+		log.Info().Int64("seed", cfg.Synthetic.Seed).Msg("Using synthetic provider")
+		return createSyntheticProvider(&cfg.Synthetic)
+	case "", "yahoo":
+		return createYahooProvider(&cfg.YahooFinance, meter)
+	default:
+		for i := range cfg.Plugins {
+			if cfg.Plugins[i].Name == cfg.DataProvider {
+				log.Info().Str("plugin", cfg.DataProvider).Msg("Using plugin provider")
+				return createPluginProvider(&cfg.Plugins[i])
+			}
+		}
+		log.Fatal().Str("provider", cfg.DataProvider).Msg("Unknown data provider")
+		return nil
+	}
+}
+
+func createPluginProvider(cfg *config.PluginConfig) *plugin.Client {
+	client := plugin.NewClient(cfg)
+	if err := client.Start(context.Background()); err != nil {
+		log.Fatal().Err(err).Str("plugin", cfg.Name).Msg("Failed to start plugin provider")
+	}
+	return client
+}
+
+func createFileProvider(cfg *config.FileProviderConfig) *file.Client {
+	client, err := file.NewClient(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid file provider configuration")
+	}
+	return client
+}
+
+func createSyntheticProvider(cfg *config.SyntheticConfig) *synthetic.Client {
+	client, err := synthetic.NewClient(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid synthetic provider configuration")
+	}
+	return client
+}
+
+// seedDemoData stores the history of the demo symbols so the API has data to serve right after startup
+func seedDemoData(marketSvc *market.MarketService, symbols []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for _, symbol := range symbols {
+		if err := marketSvc.FetchAndStoreMarketData(ctx, symbol); err != nil {
+			log.Error().Err(err).Str("symbol", symbol).Msg("Failed to seed demo symbol")
+			continue
+		}
+		log.Info().Str("symbol", symbol).Msg("Demo symbol seeded")
+	}
+}
+
+func createYahooProvider(cfg *config.YahooFinanceConfig, meter *usage.Meter) *yahoo.Client {
 	// Create and configure Yahoo Finance client
-	return yahoo.NewClient(cfg)
+	client := yahoo.NewClient(cfg)
+	// records the responses in the fetch log, and their usage when metered
+	client.SetTransport(usage.NewTransport(meter, yahoo.Provider, nil))
+	return client
 }
 
-func registerControllers(router *gin.Engine, marketSvc *market.MarketService) {
+// initTracking creates the tracked symbols service and seeds the configured symbols on the first start.
+// Symbols can be tracked with the configured provider and the built-in HTTP and synthetic providers, the
+// file and plugin providers are only available when configured as the data provider.
+func initTracking(
+	cfg *config.Config,
+	db *database.DB,
+	meter *usage.Meter,
+	marketRepo *market.MarketRepository,
+	marketSvc *market.MarketService,
+) *tracking.TrackingService {
+	defaultProvider := providerName(cfg)
+	fetchers := map[string]tracking.Fetcher{defaultProvider: marketSvc}
+	if _, ok := fetchers[yahoo.Provider]; !ok {
+		fetchers[yahoo.Provider] = newMarketService(cfg, db, marketRepo, yahoo.Provider,
+			createYahooProvider(&cfg.YahooFinance, meter))
+	}
+	if _, ok := fetchers[binance.Provider]; !ok {
+		client := binance.NewClient(&cfg.Binance)
+		client.SetTransport(usage.NewTransport(meter, binance.Provider, nil))
+		fetchers[binance.Provider] = newMarketService(cfg, db, marketRepo, binance.Provider, client)
+	}
+	if _, ok := fetchers["synthetic"]; !ok {
+		fetchers["synthetic"] = newMarketService(cfg, db, marketRepo, "synthetic",
+			createSyntheticProvider(&cfg.Synthetic))
+	}
+
+	trackingSvc := tracking.NewTrackingService(tracking.NewTrackingRepository(db), defaultProvider, fetchers,
+		tracking.Policy{
+			Intervals:      cfg.Tracking.DefaultIntervals,
+			HistoryDays:    cfg.Tracking.DefaultHistoryDays,
+			RefreshMinutes: cfg.Tracking.DefaultRefreshInterval,
+		})
+	if meter != nil {
+		trackingSvc.SetQuotaChecker(meter)
+	}
+
+	symbols := cfg.YahooFinance.DefaultSymbols
+	if cfg.Demo.Enabled {
+		symbols = cfg.Demo.Symbols
+	}
+	if err := trackingSvc.Seed(context.Background(), symbols); err != nil {
+		log.Error().Err(err).Msg("Failed to seed tracked symbols")
+	}
+	return trackingSvc
+}
+
+// initJobQueue creates the background job queue with the handlers of every job type
+func initJobQueue(cfg *config.JobsConfig, db *database.DB, marketSvc *market.MarketService) *jobs.Queue {
+	queue := jobs.NewQueue(jobs.NewJobRepository(db), jobs.Settings{
+		Workers:      cfg.Workers,
+		PollInterval: cfg.GetPollInterval(),
+		Timeout:      cfg.GetTimeout(),
+		MaxAttempts:  cfg.MaxAttempts,
+		BackoffBase:  cfg.GetBackoffBase(),
+		BackoffMax:   cfg.GetBackoffMax(),
+	})
+	fetch := func(ctx context.Context, job *jobs.Job) error {
+		return marketSvc.FetchAndStoreMarketData(ctx, job.Symbol)
+	}
+	queue.RegisterHandler(jobs.TypeBackfill, fetch)
+	queue.RegisterHandler(jobs.TypeRefresh, fetch)
+	return queue
+}
+
+// initFreshness creates the monitor of the tracked symbols. Symbols of providers with a trading calendar follow
+// it, the others the NYSE sessions.
+func initFreshness(cfg *config.Config, db *database.DB, provider market.DataProvider,
+	trackingSvc *tracking.TrackingService) *freshness.Monitor {
+	monitor := freshness.NewMonitor(freshness.NewFreshnessRepository(db), trackingSvc, freshness.Settings{
+		DefaultProvider: providerName(cfg),
+		Grace:           cfg.Freshness.GetGrace(),
+		MaxAge:          cfg.Freshness.GetCheckInterval(),
+	})
+	monitor.SetCalendar(binance.Provider, calendar.AlwaysOpen{})
+	if p, ok := provider.(interface{ Calendar() calendar.Calendar }); ok {
+		monitor.SetCalendar(providerName(cfg), p.Calendar())
+	}
+	return monitor
+}
+
+func initScheduler(
+	cfg *config.Config,
+	meter *usage.Meter,
+	marketSvc *market.MarketService,
+	optionsSvc *options.OptionsService,
+	trackingSvc *tracking.TrackingService,
+	jobQueue *jobs.Queue,
+	monitor *freshness.Monitor,
+) *scheduler.Scheduler {
+	sched := scheduler.New()
+	retention := cfg.Jobs.GetRetention()
+	sched.Add(scheduler.Job{
+		Name:     "job-purge",
+		Interval: time.Hour,
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			return jobQueue.Purge(ctx, retention)
+		},
+	})
+	if meter != nil {
+		sched.Add(scheduler.Job{
+			Name:     "usage-flush",
+			Interval: cfg.Usage.GetFlushInterval(),
+			Timeout:  cfg.Usage.GetFlushInterval(),
+			Run:      meter.Flush,
+		})
+	}
+	if cfg.Tracking.Enabled {
+		concurrency := cfg.Tracking.Concurrency
+		// no timeout, a refresh may backfill years of history and the provider requests have their own
+		sched.Add(scheduler.Job{
+			Name:     "tracked-symbols-refresh",
+			Interval: cfg.Tracking.GetCheckInterval(),
+			Run: func(ctx context.Context) error {
+				return trackingSvc.RefreshDue(ctx, concurrency)
+			},
+		})
+	}
+	if monitor != nil {
+		sched.Add(scheduler.Job{
+			Name:     "freshness-check",
+			Interval: cfg.Freshness.GetCheckInterval(),
+			Timeout:  time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := monitor.Check(ctx)
+				return err
+			},
+		})
+	}
+	if cfg.Quotes.EnablePolling {
+		concurrency := cfg.Quotes.Concurrency
+		sched.Add(scheduler.Job{
+			Name:     "quote-poll",
+			Interval: cfg.Quotes.GetPollInterval(),
+			Timeout:  cfg.Quotes.GetPollInterval(),
+			Run: quotaGuard(meter, providerName(cfg), func(ctx context.Context) error {
+				// without configured symbols the active tracked symbols are polled
+				symbols := cfg.Quotes.Symbols
+				if len(symbols) == 0 {
+					var err error
+					if symbols, err = trackingSvc.ActiveSymbols(ctx); err != nil {
+						return err
+					}
+				}
+				return marketSvc.RefreshQuotes(ctx, symbols, concurrency)
+			}),
+		})
+	}
+	if cfg.Options.EnableSnapshots {
+		symbols := cfg.Options.Symbols
+		sched.Add(scheduler.Job{
+			Name:     "option-chain-snapshot",
+			Interval: cfg.Options.GetSnapshotInterval(),
+			Timeout:  cfg.Options.GetSnapshotInterval(),
+			Run: quotaGuard(meter, yahoo.Provider, func(ctx context.Context) error {
+				return optionsSvc.SnapshotChains(ctx, symbols)
+			}),
+		})
+	}
+	return sched
+}
+
+// quotaGuard pauses a scheduled job while the daily request cap of its provider is reached
+func quotaGuard(meter *usage.Meter, provider string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	if meter == nil {
+		return run
+	}
+	return func(ctx context.Context) error {
+		if err := meter.CheckQuota(provider); err != nil {
+			log.Warn().Err(err).Str("provider", provider).Msg("Scheduled fetch paused")
+			return nil
+		}
+		return run(ctx)
+	}
+}
+
+func registerControllers(router *gin.Engine, svcs *services) {
 	// Register controllers
 	healthController := api.NewHealthController()
-	marketController := api.NewMarketController(marketSvc)
+	if svcs.elector != nil {
+		healthController.SetElector(svcs.elector)
+	}
+	if svcs.monitor != nil {
+		healthController.SetFreshnessMonitor(svcs.monitor)
+		api.NewFreshnessController(svcs.monitor).RegisterRoutes(router)
+	}
+	marketController := api.NewMarketController(svcs.market)
+	quoteController := api.NewQuoteController(svcs.market)
+	seriesController := api.NewSeriesController(svcs.series, svcs.market)
+	optionsController := api.NewOptionsController(svcs.options)
+	analyticsController := api.NewAnalyticsController(svcs.options)
 	healthController.RegisterRoutes(router)
 	marketController.RegisterRoutes(router)
+	quoteController.RegisterRoutes(router)
+	seriesController.RegisterRoutes(router)
+	optionsController.RegisterRoutes(router)
+	analyticsController.RegisterRoutes(router)
+	api.NewTrackingController(svcs.tracking).RegisterRoutes(router)
+	symbolController := api.NewSymbolController(svcs.market, svcs.jobs)
+	symbolController.SetTracking(svcs.tracking)
+	symbolController.RegisterRoutes(router)
+	api.NewJobController(svcs.jobs).RegisterRoutes(router)
+	api.NewQuarantineController(svcs.market).RegisterRoutes(router)
+	api.NewAnomalyController(svcs.anomalies).RegisterRoutes(router)
+	if svcs.usage != nil {
+		api.NewUsageController(svcs.usage).RegisterRoutes(router)
+	}
 }
 
 func startServer(router *gin.Engine, host, port string) {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/market-data/internal/domain/archive"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/binance"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
+)
+
+// runReprocess parses archived provider responses of a symbol again and stores the result, without calling
+// the provider. The range selects fetches by the day they were made, both dates are inclusive.
+//
+//	market-data reprocess -symbol AAPL [-from 2025-06-01] [-to 2025-06-30]
+func runReprocess(args []string) {
+	flags := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	symbol := flags.String("symbol", "", "symbol whose archived fetches are reprocessed")
+	fromFlag := flags.String("from", "", "first fetch day (YYYY-MM-DD), defaults to the oldest archived fetch")
+	toFlag := flags.String("to", "", "last fetch day (YYYY-MM-DD), defaults to today")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: market-data reprocess -symbol SYMBOL [-from DATE] [-to DATE]")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if *symbol == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	from := time.Time{}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	var err error
+	if *fromFlag != "" {
+		if from, err = time.Parse(time.DateOnly, *fromFlag); err != nil {
+			log.Fatal().Err(err).Msg("-from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.Parse(time.DateOnly, *toFlag); err != nil {
+			log.Fatal().Err(err).Msg("-to must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	cfg := initConfig()
+	initLogger(&cfg.Logging)
+
+	db := initDatabase(&cfg.Database)
+	defer db.Close()
+	runMigrations(cfg.Migrations.Enabled, cfg.Database.GetSchemaConnectionString())
+
+	marketSvc := market.NewMarketService(market.NewMarketRepository(db), nil)
+	marketSvc.SetPayloadArchive(archive.NewArchiveRepository(db))
+	if validator := newValidator(&cfg.Validation); validator != nil {
+		marketSvc.SetValidator(validator)
+	}
+	if cfg.Anomalies.OnIngest {
+		marketSvc.SetAnomalyDetector(newAnomalyService(&cfg.Anomalies, db))
+	}
+
+	parsers := map[string]market.PayloadParser{
+		yahoo.Provider:   yahoo.NewClient(&cfg.YahooFinance),
+		binance.Provider: binance.NewClient(&cfg.Binance),
+	}
+
+	results, err := marketSvc.Reprocess(context.Background(), *symbol, from, to.AddDate(0, 0, 1), parsers)
+	if err != nil {
+		db.Close()
+		log.Fatal().Err(err).Msg("Failed to load archived fetches")
+	}
+
+	failed := false
+	fmt.Printf("%-25s %-10s %9s %9s %9s %11s\n", "FETCHED_AT", "PROVIDER", "PRICES", "INSERTED", "UPDATED",
+		"QUARANTINED")
+	for _, result := range results {
+		if result.Err != nil {
+			failed = true
+			fmt.Printf("%-25s %-10s %9d %s\n", result.FetchedAt.Format(time.RFC3339), result.Provider, result.Prices,
+				result.Err)
+			continue
+		}
+		fmt.Printf("%-25s %-10s %9d %9d %9d %11d\n", result.FetchedAt.Format(time.RFC3339), result.Provider,
+			result.Prices, result.Saved.Inserted, result.Saved.Updated, result.Saved.Quarantined)
+	}
+	if len(results) == 0 {
+		log.Warn().Str("symbol", *symbol).Msg("No archived fetches found")
+	}
+
+	if failed {
+		db.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,8 @@
+-- Drop the index on series_observations
+DROP INDEX IF EXISTS idx_series_observations_series_time;
+
+-- Drop the series_observations table
+DROP TABLE IF EXISTS series_observations;
+
+-- Drop the series table
+DROP TABLE IF EXISTS series;
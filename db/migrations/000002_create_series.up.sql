@@ -0,0 +1,33 @@
+-- 1. Create the series table to store metadata of scalar time series (rates, CPI, unemployment, ...)
+CREATE TABLE IF NOT EXISTS series
+(
+    id         SERIAL PRIMARY KEY,                -- Unique identifier for each series
+    code       TEXT        NOT NULL UNIQUE,       -- Series code at the source (e.g., CPIAUCSL)
+    title      TEXT        NOT NULL,              -- Human readable title
+    frequency  TEXT        NOT NULL DEFAULT '',   -- Observation frequency (e.g., D, W, M, Q, A)
+    units      TEXT        NOT NULL DEFAULT '',   -- Units of the observed values
+    source     TEXT        NOT NULL,              -- Provider the series is fetched from (e.g., FRED)
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now() -- Timestamp when the record was created
+);
+
+-- 2. Create the series_observations table to store single value observations
+CREATE TABLE IF NOT EXISTS series_observations
+(
+    series_id INTEGER     NOT NULL REFERENCES series (id), -- Foreign key to series table
+    time      TIMESTAMPTZ NOT NULL,                        -- Observation date
+    value     NUMERIC(20, 6),                              -- Observed value, NULL when the source reports it missing
+    PRIMARY KEY (series_id, time)                          -- Composite primary key on series and time
+);
+
+-- Convert the series_observations table into a TimescaleDB hypertable
+--   observations are daily at most, so chunks span a year
+SELECT create_hypertable(
+               'series_observations',
+               'time',
+               chunk_time_interval => INTERVAL '365 days',
+               if_not_exists => TRUE
+       );
+
+-- Create an index to speed up queries filtering by series and time in descending order
+CREATE INDEX IF NOT EXISTS idx_series_observations_series_time
+    ON series_observations (series_id, time DESC);
@@ -0,0 +1,8 @@
+-- Drop the option_snapshots table
+DROP TABLE IF EXISTS option_snapshots;
+
+-- Drop the index on option_contracts
+DROP INDEX IF EXISTS idx_option_contracts_symbol_expiry;
+
+-- Drop the option_contracts table
+DROP TABLE IF EXISTS option_contracts;
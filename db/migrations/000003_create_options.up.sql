@@ -0,0 +1,37 @@
+-- 1. Create the option_contracts table to store option contracts of an underlying symbol
+CREATE TABLE IF NOT EXISTS option_contracts
+(
+    id              SERIAL PRIMARY KEY,                                          -- Unique identifier for each contract
+    symbol_id       INTEGER        NOT NULL REFERENCES symbols (id),             -- Foreign key to the underlying symbol
+    contract_symbol TEXT           NOT NULL UNIQUE,                              -- OCC contract symbol (e.g., AAPL250815C00230000)
+    option_type     TEXT           NOT NULL CHECK (option_type IN ('call', 'put')), -- Call or put
+    expiry          DATE           NOT NULL,                                     -- Expiration date
+    strike          NUMERIC(18, 6) NOT NULL,                                     -- Strike price
+    created_at      TIMESTAMPTZ    NOT NULL DEFAULT now()                        -- Timestamp when the record was created
+);
+
+-- Create an index to speed up chain queries by underlying and expiry
+CREATE INDEX IF NOT EXISTS idx_option_contracts_symbol_expiry
+    ON option_contracts (symbol_id, expiry);
+
+-- 2. Create the option_snapshots table to store daily quotes of option contracts
+CREATE TABLE IF NOT EXISTS option_snapshots
+(
+    contract_id        INTEGER     NOT NULL REFERENCES option_contracts (id), -- Foreign key to option_contracts table
+    time               TIMESTAMPTZ NOT NULL,                                  -- Snapshot day (midnight UTC)
+    bid                NUMERIC(18, 6),                                        -- Bid price
+    ask                NUMERIC(18, 6),                                        -- Ask price
+    last_price         NUMERIC(18, 6),                                        -- Last traded price
+    volume             BIGINT,                                                -- Contracts traded during the day
+    open_interest      BIGINT,                                                -- Open interest
+    implied_volatility NUMERIC(12, 6),                                        -- Implied volatility reported by the provider
+    PRIMARY KEY (contract_id, time)                                           -- Composite primary key on contract and time
+);
+
+-- Convert the option_snapshots table into a TimescaleDB hypertable
+SELECT create_hypertable(
+               'option_snapshots',
+               'time',
+               chunk_time_interval => INTERVAL '7 days',
+               if_not_exists => TRUE
+       );
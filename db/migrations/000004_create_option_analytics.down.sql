@@ -0,0 +1,2 @@
+-- Drop the option_analytics table
+DROP TABLE IF EXISTS option_analytics;
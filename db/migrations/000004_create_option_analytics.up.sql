@@ -0,0 +1,23 @@
+-- Create the option_analytics table to store Black-Scholes analytics computed for option snapshots
+CREATE TABLE IF NOT EXISTS option_analytics
+(
+    contract_id        INTEGER     NOT NULL REFERENCES option_contracts (id), -- Foreign key to option_contracts table
+    time               TIMESTAMPTZ NOT NULL,                                  -- Snapshot day the analytics belong to
+    underlying_price   NUMERIC(18, 6) NOT NULL,                               -- Stored close of the underlying used for pricing
+    theoretical_price  NUMERIC(18, 6),                                        -- Black-Scholes price at the pricing volatility
+    implied_volatility NUMERIC(12, 6),                                        -- Volatility implied by the mid price
+    delta              NUMERIC(12, 6),                                        -- Sensitivity to the underlying price
+    gamma              NUMERIC(12, 6),                                        -- Sensitivity of delta to the underlying price
+    vega               NUMERIC(12, 6),                                        -- Price change per volatility point
+    theta              NUMERIC(12, 6),                                        -- Price change per calendar day
+    rho                NUMERIC(12, 6),                                        -- Price change per rate point
+    PRIMARY KEY (contract_id, time)                                           -- Composite primary key on contract and time
+);
+
+-- Convert the option_analytics table into a TimescaleDB hypertable
+SELECT create_hypertable(
+               'option_analytics',
+               'time',
+               chunk_time_interval => INTERVAL '7 days',
+               if_not_exists => TRUE
+       );
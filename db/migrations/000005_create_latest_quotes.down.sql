@@ -0,0 +1,2 @@
+-- Drop the latest_quotes table
+DROP TABLE IF EXISTS latest_quotes;
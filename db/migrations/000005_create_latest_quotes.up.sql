@@ -0,0 +1,12 @@
+-- Create the latest_quotes table to store the most recent market quote of each symbol
+CREATE TABLE IF NOT EXISTS latest_quotes
+(
+    symbol_id      INTEGER PRIMARY KEY REFERENCES symbols (id), -- Foreign key to symbols table, one quote per symbol
+    price          NUMERIC(18, 6) NOT NULL,                     -- Regular market price
+    previous_close NUMERIC(18, 6),                              -- Close of the previous session
+    change         NUMERIC(18, 6),                              -- Price change against the previous close
+    change_percent NUMERIC(12, 6),                              -- Price change in percent of the previous close
+    currency       TEXT           NOT NULL DEFAULT '',          -- Quote currency (e.g., USD)
+    market_time    TIMESTAMPTZ    NOT NULL,                     -- Time of the quote reported by the provider
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT now()        -- Timestamp when the quote was stored
+);
@@ -0,0 +1,9 @@
+-- Drop the index on price_fetch_logs
+DROP INDEX IF EXISTS idx_price_fetch_logs_symbol_fetched_at;
+
+-- Drop the payload link of fetch logs
+ALTER TABLE price_fetch_logs
+    DROP COLUMN IF EXISTS payload_hashes;
+
+-- Drop the raw_payloads table
+DROP TABLE IF EXISTS raw_payloads;
@@ -0,0 +1,18 @@
+-- 1. Create the raw_payloads table to archive provider responses, stored once per distinct content
+CREATE TABLE IF NOT EXISTS raw_payloads
+(
+    hash       TEXT PRIMARY KEY,                   -- Hex encoded SHA-256 of the uncompressed response body
+    provider   TEXT        NOT NULL,               -- Provider which returned the response (e.g., yahoo)
+    encoding   TEXT        NOT NULL,               -- Compression of the stored payload (gzip)
+    size       INTEGER     NOT NULL,               -- Size of the uncompressed response body in bytes
+    payload    BYTEA       NOT NULL,               -- Compressed response body
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()  -- Timestamp when the payload was first archived
+);
+
+-- 2. Link fetch logs to the archived responses of the fetch, in request order
+ALTER TABLE price_fetch_logs
+    ADD COLUMN IF NOT EXISTS payload_hashes TEXT[];
+
+-- Create an index to speed up looking up archived fetches of a symbol
+CREATE INDEX IF NOT EXISTS idx_price_fetch_logs_symbol_fetched_at
+    ON price_fetch_logs (symbol, fetched_at);
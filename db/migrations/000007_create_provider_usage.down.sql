@@ -0,0 +1,5 @@
+-- Drop the index on provider_usage
+DROP INDEX IF EXISTS idx_provider_usage_day;
+
+-- Drop the provider_usage table
+DROP TABLE IF EXISTS provider_usage;
@@ -0,0 +1,16 @@
+-- 1. Create the provider_usage table counting the calls made to every provider per UTC day and symbol
+CREATE TABLE IF NOT EXISTS provider_usage
+(
+    provider   TEXT        NOT NULL,               -- Provider which was called (e.g., yahoo)
+    day        DATE        NOT NULL,               -- UTC day of the calls
+    symbol     TEXT        NOT NULL DEFAULT '',    -- Symbol the calls were made for, empty for batch requests
+    requests   BIGINT      NOT NULL DEFAULT 0,     -- HTTP requests sent, including retries
+    retries    BIGINT      NOT NULL DEFAULT 0,     -- Requests which were retries of a failed request
+    failures   BIGINT      NOT NULL DEFAULT 0,     -- Requests which failed or were answered with an error status
+    bytes      BIGINT      NOT NULL DEFAULT 0,     -- Response bytes received
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- Timestamp of the last update of the counters
+    PRIMARY KEY (provider, day, symbol)
+);
+
+-- Create an index to speed up reading the usage of a day range
+CREATE INDEX IF NOT EXISTS idx_provider_usage_day ON provider_usage (day);
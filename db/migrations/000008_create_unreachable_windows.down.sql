@@ -0,0 +1,5 @@
+-- Drop the index on unreachable_windows
+DROP INDEX IF EXISTS idx_unreachable_windows_symbol;
+
+-- Drop the unreachable_windows table
+DROP TABLE IF EXISTS unreachable_windows;
@@ -0,0 +1,14 @@
+-- 1. Create the unreachable_windows table recording parts of fetch windows the provider cannot serve
+CREATE TABLE IF NOT EXISTS unreachable_windows
+(
+    id           SERIAL PRIMARY KEY,
+    symbol       TEXT        NOT NULL,               -- Symbol of the fetch
+    bar_interval TEXT        NOT NULL,               -- Interval of the bars (e.g., 1m, 1d)
+    start_time   TIMESTAMPTZ NOT NULL,               -- Start of the unreachable window
+    end_time     TIMESTAMPTZ NOT NULL,               -- End of the unreachable window, exclusive
+    reason       TEXT        NOT NULL,               -- Why the provider cannot serve the window
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT now()  -- Timestamp when the window was recorded
+);
+
+-- Create an index to speed up looking up the unreachable windows of a symbol
+CREATE INDEX IF NOT EXISTS idx_unreachable_windows_symbol ON unreachable_windows (symbol, start_time);
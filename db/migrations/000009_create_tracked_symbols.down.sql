@@ -0,0 +1,2 @@
+-- Drop the tracked_symbols table
+DROP TABLE IF EXISTS tracked_symbols;
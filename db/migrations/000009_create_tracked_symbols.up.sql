@@ -0,0 +1,16 @@
+-- 1. Create the tracked_symbols table holding the symbols refreshed by the scheduler and their fetch policy
+CREATE TABLE IF NOT EXISTS tracked_symbols
+(
+    id               SERIAL PRIMARY KEY,
+    symbol           TEXT        NOT NULL UNIQUE,              -- Ticker symbol (e.g., AAPL)
+    intervals        TEXT[]      NOT NULL DEFAULT '{1d}',      -- Bar intervals collected (e.g., {1d,5m})
+    history_days     INTEGER     NOT NULL,                     -- Days of history fetched when an interval starts
+    refresh_minutes  INTEGER     NOT NULL,                     -- Minutes between two refreshes
+    provider         TEXT        NOT NULL DEFAULT '',          -- Data provider, empty for the configured one
+    active           BOOLEAN     NOT NULL DEFAULT true,        -- Whether the scheduler refreshes the symbol
+    last_attempt_at  TIMESTAMPTZ,                              -- Start of the last refresh
+    last_success_at  TIMESTAMPTZ,                              -- Start of the last refresh of all intervals
+    last_error       TEXT,                                     -- Error of the last refresh, null when it succeeded
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),       -- Timestamp when the record was created
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()        -- Timestamp when the policy was last changed
+);
@@ -0,0 +1,6 @@
+-- Drop the indexes on fetch_jobs
+DROP INDEX IF EXISTS idx_fetch_jobs_status;
+DROP INDEX IF EXISTS idx_fetch_jobs_runnable;
+
+-- Drop the fetch_jobs table
+DROP TABLE IF EXISTS fetch_jobs;
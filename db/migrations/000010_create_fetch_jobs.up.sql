@@ -0,0 +1,25 @@
+-- 1. Create the fetch_jobs table, the queue of background fetch work (e.g. backfills) shared by all replicas
+CREATE TABLE IF NOT EXISTS fetch_jobs
+(
+    id           BIGSERIAL PRIMARY KEY,
+    job_type     TEXT        NOT NULL,                  -- Kind of work (e.g., backfill)
+    symbol       TEXT        NOT NULL,                  -- Symbol the job works on
+    priority     INTEGER     NOT NULL DEFAULT 0,        -- Jobs with a higher priority run first
+    status       TEXT        NOT NULL DEFAULT 'queued', -- queued, running, succeeded or dead
+    attempts     INTEGER     NOT NULL DEFAULT 0,        -- Number of started runs
+    max_attempts INTEGER     NOT NULL,                  -- Runs before the job is dead-lettered
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),    -- Earliest start, pushed back after failures
+    locked_by    TEXT,                                  -- Worker running the job
+    locked_at    TIMESTAMPTZ,                           -- Start of the current run, expired leases are taken over
+    last_error   TEXT,                                  -- Error of the last failed run
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),    -- Timestamp when the job was enqueued
+    started_at   TIMESTAMPTZ,                           -- Start of the first run
+    finished_at  TIMESTAMPTZ                            -- Timestamp when the job succeeded or was dead-lettered
+);
+
+-- Create a partial index to speed up claiming the next runnable job
+CREATE INDEX IF NOT EXISTS idx_fetch_jobs_runnable
+    ON fetch_jobs (priority DESC, run_at, id) WHERE status IN ('queued', 'running');
+
+-- Create an index to speed up listing the jobs of a status, e.g. the dead-lettered ones
+CREATE INDEX IF NOT EXISTS idx_fetch_jobs_status ON fetch_jobs (status, created_at DESC);
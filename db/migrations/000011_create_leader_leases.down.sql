@@ -0,0 +1,2 @@
+-- Drop the leader_leases table
+DROP TABLE IF EXISTS leader_leases;
@@ -0,0 +1,9 @@
+-- 1. Create the leader_leases table electing the replica running singleton work such as the scheduler
+CREATE TABLE IF NOT EXISTS leader_leases
+(
+    name         TEXT PRIMARY KEY,           -- Elected role (e.g., scheduler)
+    holder       TEXT        NOT NULL,       -- Replica holding the lease (hostname-pid)
+    acquired_at  TIMESTAMPTZ NOT NULL,       -- Timestamp when the holder took the lease
+    renewed_at   TIMESTAMPTZ NOT NULL,       -- Timestamp of the last renewal
+    expires_at   TIMESTAMPTZ NOT NULL        -- Timestamp after which other replicas may take the lease over
+);
@@ -0,0 +1,15 @@
+-- Drop the index on the symbol link of price_fetch_logs
+DROP INDEX IF EXISTS idx_price_fetch_logs_symbol_id_fetched_at;
+
+-- Drop the request details of fetch logs
+ALTER TABLE price_fetch_logs
+    DROP COLUMN IF EXISTS rows_updated,
+    DROP COLUMN IF EXISTS rows_inserted,
+    DROP COLUMN IF EXISTS retries,
+    DROP COLUMN IF EXISTS http_status,
+    DROP COLUMN IF EXISTS duration_ms,
+    DROP COLUMN IF EXISTS range_end,
+    DROP COLUMN IF EXISTS range_start,
+    DROP COLUMN IF EXISTS bar_interval,
+    DROP COLUMN IF EXISTS provider,
+    DROP COLUMN IF EXISTS symbol_id;
@@ -0,0 +1,23 @@
+-- 1. Link fetch logs to the fetched symbol and record what was requested and how the request went
+ALTER TABLE price_fetch_logs
+    ADD COLUMN IF NOT EXISTS symbol_id     INTEGER REFERENCES symbols (id), -- Fetched symbol, unset until it is stored
+    ADD COLUMN IF NOT EXISTS provider      TEXT,                            -- Provider which was asked (e.g., yahoo)
+    ADD COLUMN IF NOT EXISTS bar_interval  TEXT,                            -- Requested bar interval (e.g., 1d)
+    ADD COLUMN IF NOT EXISTS range_start   TIMESTAMPTZ,                     -- Start of the requested range
+    ADD COLUMN IF NOT EXISTS range_end     TIMESTAMPTZ,                     -- End of the requested range
+    ADD COLUMN IF NOT EXISTS duration_ms   INTEGER,                         -- Time taken by the fetch and storing its data
+    ADD COLUMN IF NOT EXISTS http_status   INTEGER,                         -- Status of the last provider response
+    ADD COLUMN IF NOT EXISTS retries       INTEGER,                         -- Provider requests repeated after a failure
+    ADD COLUMN IF NOT EXISTS rows_inserted INTEGER,                         -- Prices stored for the first time
+    ADD COLUMN IF NOT EXISTS rows_updated  INTEGER;                         -- Stored prices overwritten
+
+-- 2. Link the existing fetch logs to their symbols
+UPDATE price_fetch_logs l
+SET symbol_id = s.id
+FROM symbols s
+WHERE s.symbol = l.symbol
+  AND l.symbol_id IS NULL;
+
+-- Create an index to speed up listing the latest fetches of a symbol
+CREATE INDEX IF NOT EXISTS idx_price_fetch_logs_symbol_id_fetched_at
+    ON price_fetch_logs (symbol_id, fetched_at DESC);
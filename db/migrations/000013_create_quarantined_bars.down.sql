@@ -0,0 +1,10 @@
+-- Drop the quarantine counter of fetch logs
+ALTER TABLE price_fetch_logs
+    DROP COLUMN IF EXISTS rows_quarantined;
+
+-- Drop the indexes on quarantined_bars
+DROP INDEX IF EXISTS idx_quarantined_bars_status_symbol;
+DROP INDEX IF EXISTS idx_quarantined_bars_pending;
+
+-- Drop the quarantined_bars table
+DROP TABLE IF EXISTS quarantined_bars;
@@ -0,0 +1,31 @@
+-- 1. Create the quarantined_bars table to hold incoming bars which failed validation until they are reviewed
+CREATE TABLE IF NOT EXISTS quarantined_bars
+(
+    id           BIGSERIAL PRIMARY KEY,
+    symbol       TEXT        NOT NULL,                            -- Symbol of the bar
+    time         TIMESTAMPTZ NOT NULL,                            -- Timestamp of the bar
+    open_price   NUMERIC(18, 6),                                  -- Bar as received from the provider
+    high_price   NUMERIC(18, 6),
+    low_price    NUMERIC(18, 6),
+    close_price  NUMERIC(18, 6),
+    adj_close    NUMERIC(18, 6),
+    volume       BIGINT,
+    rule         TEXT        NOT NULL,                            -- First validation rule the bar violated
+    reason       TEXT        NOT NULL,                            -- Description of the violation
+    fetch_log_id INTEGER REFERENCES price_fetch_logs (id),         -- Fetch which received the bar, unset when reprocessed
+    status       TEXT        NOT NULL DEFAULT 'pending',          -- pending, released or discarded
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),              -- Timestamp when the bar was quarantined
+    reviewed_at  TIMESTAMPTZ                                      -- Timestamp when the bar was released or discarded
+);
+
+-- Create a unique index so a bar fetched again while awaiting review is quarantined once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_bars_pending
+    ON quarantined_bars (symbol, time) WHERE status = 'pending';
+
+-- Create an index to speed up listing the bars awaiting review
+CREATE INDEX IF NOT EXISTS idx_quarantined_bars_status_symbol
+    ON quarantined_bars (status, symbol, created_at DESC);
+
+-- 2. Count the quarantined bars of a fetch in the fetch log
+ALTER TABLE price_fetch_logs
+    ADD COLUMN IF NOT EXISTS rows_quarantined INTEGER;
@@ -0,0 +1,5 @@
+-- Drop the index on price_anomalies
+DROP INDEX IF EXISTS idx_price_anomalies_status_symbol;
+
+-- Drop the price_anomalies table
+DROP TABLE IF EXISTS price_anomalies;
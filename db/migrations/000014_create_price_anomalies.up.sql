@@ -0,0 +1,22 @@
+-- 1. Create the price_anomalies table to store stored bars flagged by the anomaly detector
+CREATE TABLE IF NOT EXISTS price_anomalies
+(
+    id          BIGSERIAL PRIMARY KEY,
+    symbol      TEXT             NOT NULL,                       -- Symbol of the bar
+    time        TIMESTAMPTZ      NOT NULL,                       -- Timestamp of the bar
+    metric      TEXT             NOT NULL,                       -- return or volume
+    method      TEXT             NOT NULL,                       -- zscore or mad
+    value       DOUBLE PRECISION NOT NULL,                       -- Log return or log volume of the bar
+    baseline    DOUBLE PRECISION NOT NULL,                       -- Mean or median of the preceding window
+    score       DOUBLE PRECISION NOT NULL,                       -- Deviation from the baseline in robust standard deviations
+    close_price NUMERIC(18, 6),                                  -- Bar as stored when it was flagged
+    volume      BIGINT,
+    status      TEXT             NOT NULL DEFAULT 'open',        -- open, kept or corrected
+    detected_at TIMESTAMPTZ      NOT NULL DEFAULT now(),         -- Timestamp when the bar was last flagged
+    reviewed_at TIMESTAMPTZ,                                     -- Timestamp when the anomaly was kept or corrected
+    CONSTRAINT uq_price_anomalies_symbol_time_metric UNIQUE (symbol, time, metric)
+);
+
+-- Create an index to speed up listing the anomalies awaiting review
+CREATE INDEX IF NOT EXISTS idx_price_anomalies_status_symbol
+    ON price_anomalies (status, symbol, time DESC);
@@ -0,0 +1,28 @@
+-- Restore the anomalies per bar time, keeping the daily ones
+DELETE FROM price_anomalies WHERE bar_interval <> '1d';
+ALTER TABLE price_anomalies
+    DROP CONSTRAINT IF EXISTS uq_price_anomalies_symbol_interval_time_metric;
+ALTER TABLE price_anomalies
+    ADD CONSTRAINT uq_price_anomalies_symbol_time_metric UNIQUE (symbol, time, metric);
+ALTER TABLE price_anomalies
+    DROP COLUMN IF EXISTS bar_interval;
+
+-- Restore the quarantine per bar time, keeping the daily bars
+DELETE FROM quarantined_bars WHERE bar_interval <> '1d';
+DROP INDEX IF EXISTS idx_quarantined_bars_pending;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_bars_pending
+    ON quarantined_bars (symbol, time) WHERE status = 'pending';
+ALTER TABLE quarantined_bars
+    DROP COLUMN IF EXISTS bar_interval;
+
+-- Restore the prices per symbol and time, only the daily bars fit the former primary key
+DELETE FROM stock_prices WHERE bar_interval <> '1d';
+DROP INDEX IF EXISTS idx_stock_prices_symbol_interval_time;
+CREATE INDEX IF NOT EXISTS idx_stock_prices_symbol_time
+    ON stock_prices (symbol_id, time DESC);
+ALTER TABLE stock_prices
+    DROP CONSTRAINT IF EXISTS stock_prices_pkey;
+ALTER TABLE stock_prices
+    ADD PRIMARY KEY (symbol_id, time);
+ALTER TABLE stock_prices
+    DROP COLUMN IF EXISTS bar_interval;
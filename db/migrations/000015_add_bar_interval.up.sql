@@ -0,0 +1,32 @@
+-- 1. Store the bar interval with each price. Bars of different intervals starting at the same time, e.g. a
+--    daily bar and the first 5m bar of the session, no longer overwrite each other. Prices stored before
+--    were mostly daily and are kept as daily bars.
+ALTER TABLE stock_prices
+    ADD COLUMN IF NOT EXISTS bar_interval TEXT NOT NULL DEFAULT '1d';
+
+ALTER TABLE stock_prices
+    DROP CONSTRAINT IF EXISTS stock_prices_pkey;
+ALTER TABLE stock_prices
+    ADD PRIMARY KEY (symbol_id, bar_interval, time);
+
+-- Replace the index on symbol and time by one on symbol, interval and time
+DROP INDEX IF EXISTS idx_stock_prices_symbol_time;
+CREATE INDEX IF NOT EXISTS idx_stock_prices_symbol_interval_time
+    ON stock_prices (symbol_id, bar_interval, time DESC);
+
+-- 2. Quarantine bars per interval
+ALTER TABLE quarantined_bars
+    ADD COLUMN IF NOT EXISTS bar_interval TEXT NOT NULL DEFAULT '1d';
+
+DROP INDEX IF EXISTS idx_quarantined_bars_pending;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_bars_pending
+    ON quarantined_bars (symbol, bar_interval, time) WHERE status = 'pending';
+
+-- 3. Flag anomalies per interval
+ALTER TABLE price_anomalies
+    ADD COLUMN IF NOT EXISTS bar_interval TEXT NOT NULL DEFAULT '1d';
+
+ALTER TABLE price_anomalies
+    DROP CONSTRAINT IF EXISTS uq_price_anomalies_symbol_time_metric;
+ALTER TABLE price_anomalies
+    ADD CONSTRAINT uq_price_anomalies_symbol_interval_time_metric UNIQUE (symbol, bar_interval, time, metric);
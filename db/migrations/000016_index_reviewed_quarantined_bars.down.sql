@@ -0,0 +1,2 @@
+-- Drop the index on reviewed quarantined bars
+DROP INDEX IF EXISTS idx_quarantined_bars_reviewed;
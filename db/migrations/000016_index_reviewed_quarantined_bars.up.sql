@@ -0,0 +1,3 @@
+-- Create an index to look up reviewed bars, so a bar released or discarded is not quarantined again
+CREATE INDEX IF NOT EXISTS idx_quarantined_bars_reviewed
+    ON quarantined_bars (symbol, bar_interval, time) WHERE status <> 'pending';
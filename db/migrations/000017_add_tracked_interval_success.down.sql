@@ -0,0 +1,3 @@
+-- Drop the last successful refresh per interval
+ALTER TABLE tracked_symbols
+    DROP COLUMN IF EXISTS interval_success_at;
@@ -0,0 +1,8 @@
+-- Record the last successful refresh of every interval of a tracked symbol, so an interval failing to refresh
+-- does not hold back the others. Symbols refreshed before continue every interval from their last success.
+ALTER TABLE tracked_symbols
+    ADD COLUMN IF NOT EXISTS interval_success_at JSONB NOT NULL DEFAULT '{}'; -- Start of the last successful refresh per interval
+
+UPDATE tracked_symbols
+SET interval_success_at = (SELECT COALESCE(jsonb_object_agg(i, last_success_at), '{}') FROM unnest(intervals) AS i)
+WHERE last_success_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Round volumes back to whole units
+ALTER TABLE price_anomalies
+    ALTER COLUMN volume TYPE BIGINT USING round(volume);
+
+ALTER TABLE quarantined_bars
+    ALTER COLUMN volume TYPE BIGINT USING round(volume);
+
+ALTER TABLE stock_prices
+    ALTER COLUMN volume TYPE BIGINT USING round(volume);
@@ -0,0 +1,9 @@
+-- Store volumes as decimals, so volumes traded in fractions of the base asset (0.8 BTC) are kept exactly
+ALTER TABLE stock_prices
+    ALTER COLUMN volume TYPE NUMERIC(28, 8); -- Trading volume during the interval in the base asset
+
+ALTER TABLE quarantined_bars
+    ALTER COLUMN volume TYPE NUMERIC(28, 8);
+
+ALTER TABLE price_anomalies
+    ALTER COLUMN volume TYPE NUMERIC(28, 8);
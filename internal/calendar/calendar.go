@@ -0,0 +1,107 @@
+package calendar
+
+import "time"
+
+// Calendar describes when a market trades. Providers use it to translate
+// relative periods into concrete time ranges and to reason about when the
+// next or previous bar is expected.
+type Calendar interface {
+	// IsOpen reports whether the market is trading at the given instant.
+	IsOpen(t time.Time) bool
+	// LastOpen returns the latest instant at or before t during which the market was open.
+	LastOpen(t time.Time) time.Time
+	// AddSessions moves t by n trading sessions (days). Negative n moves backwards.
+	AddSessions(t time.Time, n int) time.Time
+}
+
+// AlwaysOpen is a calendar for assets that trade around the clock, every day of the year (e.g. crypto).
+type AlwaysOpen struct{}
+
+// IsOpen always returns true.
+func (AlwaysOpen) IsOpen(time.Time) bool {
+	return true
+}
+
+// LastOpen returns t unchanged, the market never closes.
+func (AlwaysOpen) LastOpen(t time.Time) time.Time {
+	return t
+}
+
+// AddSessions moves t by n calendar days.
+func (AlwaysOpen) AddSessions(t time.Time, n int) time.Time {
+	return t.AddDate(0, 0, n)
+}
+
+// Exchange is a calendar for a venue with a single regular session on weekdays.
+// Exchange holidays are not modelled.
+type Exchange struct {
+	Location *time.Location
+	Open     time.Duration // session open as an offset from local midnight
+	Close    time.Duration // session close as an offset from local midnight
+}
+
+// NYSE returns the regular trading session calendar of the New York Stock Exchange.
+func NYSE() *Exchange {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		loc = time.FixedZone("EST", -5*60*60)
+	}
+	return &Exchange{
+		Location: loc,
+		Open:     9*time.Hour + 30*time.Minute,
+		Close:    16 * time.Hour,
+	}
+}
+
+// IsOpen reports whether t falls into a regular weekday session.
+func (e *Exchange) IsOpen(t time.Time) bool {
+	local := t.In(e.Location)
+	if !isWeekday(local) {
+		return false
+	}
+	offset := local.Sub(midnight(local))
+	return offset >= e.Open && offset < e.Close
+}
+
+// LastOpen returns t if the session is open, otherwise the close of the previous session.
+func (e *Exchange) LastOpen(t time.Time) time.Time {
+	if e.IsOpen(t) {
+		return t
+	}
+	local := t.In(e.Location)
+	day := midnight(local)
+	if isWeekday(local) && local.Sub(day) >= e.Close {
+		return day.Add(e.Close)
+	}
+	for {
+		day = day.AddDate(0, 0, -1)
+		if isWeekday(day) {
+			return day.Add(e.Close)
+		}
+	}
+}
+
+// AddSessions moves t by n weekday sessions, skipping weekends.
+func (e *Exchange) AddSessions(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step = -1
+		n = -n
+	}
+	local := t.In(e.Location)
+	for n > 0 {
+		local = local.AddDate(0, 0, step)
+		if isWeekday(local) {
+			n--
+		}
+	}
+	return local
+}
+
+func isWeekday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
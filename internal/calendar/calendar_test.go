@@ -0,0 +1,38 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchange(t *testing.T) {
+	nyse := calendar.NYSE()
+	ny := nyse.Location
+
+	friday := time.Date(2025, time.August, 8, 10, 0, 0, 0, ny)
+	saturday := time.Date(2025, time.August, 9, 10, 0, 0, 0, ny)
+	monday := time.Date(2025, time.August, 11, 8, 0, 0, 0, ny)
+
+	assert.True(t, nyse.IsOpen(friday))
+	assert.False(t, nyse.IsOpen(saturday))
+	assert.False(t, nyse.IsOpen(monday))
+
+	fridayClose := time.Date(2025, time.August, 8, 16, 0, 0, 0, ny)
+	assert.True(t, nyse.LastOpen(saturday).Equal(fridayClose))
+	assert.True(t, nyse.LastOpen(monday).Equal(fridayClose))
+	assert.True(t, nyse.LastOpen(friday).Equal(friday))
+
+	assert.Equal(t, time.Date(2025, time.August, 4, 10, 0, 0, 0, ny), nyse.AddSessions(monday.Add(2*time.Hour), -5))
+}
+
+func TestAlwaysOpen(t *testing.T) {
+	cal := calendar.AlwaysOpen{}
+	saturday := time.Date(2025, time.August, 9, 3, 0, 0, 0, time.UTC)
+
+	assert.True(t, cal.IsOpen(saturday))
+	assert.Equal(t, saturday, cal.LastOpen(saturday))
+	assert.Equal(t, saturday.AddDate(0, 0, -5), cal.AddSessions(saturday, -5))
+}
@@ -17,6 +17,24 @@ type Config struct {
 	Logging      LoggingConfig      `mapstructure:"logging"`
 	Migrations   MigrationsConfig   `mapstructure:"migrations"`
 	YahooFinance YahooFinanceConfig `mapstructure:"yahoo_finance"`
+	Binance      BinanceConfig      `mapstructure:"binance"`
+	FileProvider FileProviderConfig `mapstructure:"file_provider"`
+	Fred         FredConfig         `mapstructure:"fred"`
+	Options      OptionsConfig      `mapstructure:"options"`
+	Quotes       QuotesConfig       `mapstructure:"quotes"`
+	Archive      ArchiveConfig      `mapstructure:"archive"`
+	FetchThrough FetchThroughConfig `mapstructure:"fetch_through"`
+	Synthetic    SyntheticConfig    `mapstructure:"synthetic"`
+	Demo         DemoConfig         `mapstructure:"demo"`
+	Plugins      []PluginConfig     `mapstructure:"plugins"`
+	Usage        UsageConfig        `mapstructure:"usage"`
+	Tracking     TrackingConfig     `mapstructure:"tracking"`
+	Jobs         JobsConfig         `mapstructure:"jobs"`
+	Leader       LeaderConfig       `mapstructure:"leader_election"`
+	Freshness    FreshnessConfig    `mapstructure:"freshness"`
+	Validation   ValidationConfig   `mapstructure:"validation"`
+	Anomalies    AnomalyConfig      `mapstructure:"anomalies"`
+	DataProvider string             `mapstructure:"data_provider"`
 }
 
 // ServerConfig represents the server configuration
@@ -53,6 +71,9 @@ type MigrationsConfig struct {
 // YahooFinanceConfig represents the Yahoo Finance API configuration
 type YahooFinanceConfig struct {
 	BaseURL          string   `mapstructure:"base_url"`
+	OptionsBaseURL   string   `mapstructure:"options_base_url"`
+	SparkBaseURL     string   `mapstructure:"spark_base_url"`
+	BatchSize        int      `mapstructure:"batch_size"`
 	RequestTimeout   int      `mapstructure:"request_timeout"`
 	RetryCount       int      `mapstructure:"retry_count"`
 	RetryWaitTime    int      `mapstructure:"retry_wait_time"`
@@ -61,6 +82,286 @@ type YahooFinanceConfig struct {
 	EnableAutoUpdate bool     `mapstructure:"enable_auto_update"`
 }
 
+// BinanceConfig represents the Binance klines API configuration
+type BinanceConfig struct {
+	BaseURL        string `mapstructure:"base_url"`
+	RequestTimeout int    `mapstructure:"request_timeout"`
+	RetryCount     int    `mapstructure:"retry_count"`
+	RetryWaitTime  int    `mapstructure:"retry_wait_time"`
+	PageLimit      int    `mapstructure:"page_limit"`
+}
+
+// FileProviderConfig represents the configuration of the CSV/JSON file provider
+type FileProviderConfig struct {
+	Directory        string        `mapstructure:"directory"`
+	Format           string        `mapstructure:"format"`
+	Delimiter        string        `mapstructure:"delimiter"`
+	DecimalSeparator string        `mapstructure:"decimal_separator"`
+	DateFormats      []string      `mapstructure:"date_formats"`
+	Timezone         string        `mapstructure:"timezone"`
+	Exchange         string        `mapstructure:"exchange"`
+	Columns          ColumnMapping `mapstructure:"columns"`
+}
+
+// ColumnMapping maps price fields to column names (CSV header or JSON keys) of the imported files
+type ColumnMapping struct {
+	Time     string `mapstructure:"time"`
+	Open     string `mapstructure:"open"`
+	High     string `mapstructure:"high"`
+	Low      string `mapstructure:"low"`
+	Close    string `mapstructure:"close"`
+	AdjClose string `mapstructure:"adj_close"`
+	Volume   string `mapstructure:"volume"`
+}
+
+// FredConfig represents the FRED economic data API configuration
+type FredConfig struct {
+	BaseURL        string `mapstructure:"base_url"`
+	APIKey         string `mapstructure:"api_key"`
+	RequestTimeout int    `mapstructure:"request_timeout"`
+	RetryCount     int    `mapstructure:"retry_count"`
+	RetryWaitTime  int    `mapstructure:"retry_wait_time"`
+	PageLimit      int    `mapstructure:"page_limit"`
+}
+
+// GetRequestTimeout returns the request timeout as a time.Duration
+func (fc *FredConfig) GetRequestTimeout() time.Duration {
+	return time.Duration(fc.RequestTimeout) * time.Second
+}
+
+// GetRetryWaitTime returns the retry wait time as a time.Duration
+func (fc *FredConfig) GetRetryWaitTime() time.Duration {
+	return time.Duration(fc.RetryWaitTime) * time.Millisecond
+}
+
+// OptionsConfig represents the option chain snapshot configuration
+type OptionsConfig struct {
+	EnableSnapshots  bool     `mapstructure:"enable_snapshots"`
+	Symbols          []string `mapstructure:"symbols"`
+	SnapshotInterval int      `mapstructure:"snapshot_interval"`
+	RiskFreeRate     float64  `mapstructure:"risk_free_rate"`
+	PersistAnalytics bool     `mapstructure:"persist_analytics"`
+}
+
+// GetSnapshotInterval returns the snapshot interval as a time.Duration
+func (oc *OptionsConfig) GetSnapshotInterval() time.Duration {
+	return time.Duration(oc.SnapshotInterval) * time.Minute
+}
+
+// QuotesConfig represents the latest-quote poller configuration
+type QuotesConfig struct {
+	EnablePolling bool     `mapstructure:"enable_polling"`
+	Symbols       []string `mapstructure:"symbols"`
+	PollInterval  int      `mapstructure:"poll_interval"`
+	Concurrency   int      `mapstructure:"concurrency"`
+}
+
+// GetPollInterval returns the poll interval as a time.Duration
+func (qc *QuotesConfig) GetPollInterval() time.Duration {
+	return time.Duration(qc.PollInterval) * time.Second
+}
+
+// ArchiveConfig represents the raw provider response archive configuration
+type ArchiveConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// FetchThroughConfig represents fetching symbols missing from the database when they are requested
+type FetchThroughConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	Timeout    int  `mapstructure:"timeout"`     // seconds a request waits for the provider
+	UnknownTTL int  `mapstructure:"unknown_ttl"` // minutes symbols unknown to the provider are not fetched again
+	MaxUnknown int  `mapstructure:"max_unknown"` // symbols unknown to the provider remembered at most
+}
+
+// GetTimeout returns the maximum duration of a fetch-through as a time.Duration
+func (fc *FetchThroughConfig) GetTimeout() time.Duration {
+	return time.Duration(fc.Timeout) * time.Second
+}
+
+// GetUnknownTTL returns how long unknown symbols are remembered as a time.Duration
+func (fc *FetchThroughConfig) GetUnknownTTL() time.Duration {
+	return time.Duration(fc.UnknownTTL) * time.Minute
+}
+
+// SyntheticConfig represents the configuration of the synthetic (geometric Brownian motion) provider
+type SyntheticConfig struct {
+	Seed           int64         `mapstructure:"seed"`
+	StartPrice     float64       `mapstructure:"start_price"`
+	Drift          float64       `mapstructure:"drift"`
+	Volatility     float64       `mapstructure:"volatility"`
+	GapProbability float64       `mapstructure:"gap_probability"`
+	StartDate      string        `mapstructure:"start_date"`
+	EndDate        string        `mapstructure:"end_date"`
+	Splits         []SplitConfig `mapstructure:"splits"`
+}
+
+// SplitConfig represents a stock split of a synthetic symbol
+type SplitConfig struct {
+	Symbol string  `mapstructure:"symbol"`
+	Date   string  `mapstructure:"date"`
+	Ratio  float64 `mapstructure:"ratio"`
+}
+
+// DemoConfig represents the demo mode, which serves synthetic data and seeds symbols on startup
+type DemoConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Symbols []string `mapstructure:"symbols"`
+}
+
+// UsageConfig represents the provider usage accounting configuration
+type UsageConfig struct {
+	Enabled       bool             `mapstructure:"enabled"`
+	FlushInterval int              `mapstructure:"flush_interval"` // seconds
+	DailyCaps     map[string]int64 `mapstructure:"daily_caps"`     // requests per provider and UTC day
+}
+
+// GetFlushInterval returns the interval of persisting the usage counters as a time.Duration
+func (uc *UsageConfig) GetFlushInterval() time.Duration {
+	return time.Duration(uc.FlushInterval) * time.Second
+}
+
+// TrackingConfig represents the refresh of the tracked symbols and the default fetch policy of new ones
+type TrackingConfig struct {
+	Enabled                bool     `mapstructure:"enabled"`
+	CheckInterval          int      `mapstructure:"check_interval"` // seconds between looking for due symbols
+	Concurrency            int      `mapstructure:"concurrency"`    // symbols refreshed in parallel
+	DefaultIntervals       []string `mapstructure:"default_intervals"`
+	DefaultHistoryDays     int      `mapstructure:"default_history_days"`
+	DefaultRefreshInterval int      `mapstructure:"default_refresh_interval"` // minutes
+}
+
+// GetCheckInterval returns the interval of looking for due tracked symbols as a time.Duration
+func (tc *TrackingConfig) GetCheckInterval() time.Duration {
+	return time.Duration(tc.CheckInterval) * time.Second
+}
+
+// JobsConfig represents the background job queue, e.g. the backfills of registered symbols
+type JobsConfig struct {
+	Workers      int `mapstructure:"workers"`       // jobs run in parallel by each replica
+	PollInterval int `mapstructure:"poll_interval"` // seconds between looking for runnable jobs
+	Timeout      int `mapstructure:"timeout"`       // seconds per run
+	MaxAttempts  int `mapstructure:"max_attempts"`  // runs before a job is dead-lettered
+	BackoffBase  int `mapstructure:"backoff_base"`  // seconds before the first retry, doubled for every further one
+	BackoffMax   int `mapstructure:"backoff_max"`   // seconds
+	Retention    int `mapstructure:"retention"`     // minutes succeeded jobs are kept
+}
+
+// GetPollInterval returns the interval of looking for runnable jobs as a time.Duration
+func (jc *JobsConfig) GetPollInterval() time.Duration {
+	return time.Duration(jc.PollInterval) * time.Second
+}
+
+// GetTimeout returns the maximum duration of a job as a time.Duration
+func (jc *JobsConfig) GetTimeout() time.Duration {
+	return time.Duration(jc.Timeout) * time.Second
+}
+
+// GetBackoffBase returns the wait before the first retry of a failed job as a time.Duration
+func (jc *JobsConfig) GetBackoffBase() time.Duration {
+	return time.Duration(jc.BackoffBase) * time.Second
+}
+
+// GetBackoffMax returns the longest wait between two runs of a job as a time.Duration
+func (jc *JobsConfig) GetBackoffMax() time.Duration {
+	return time.Duration(jc.BackoffMax) * time.Second
+}
+
+// GetRetention returns how long succeeded jobs are kept as a time.Duration
+func (jc *JobsConfig) GetRetention() time.Duration {
+	return time.Duration(jc.Retention) * time.Minute
+}
+
+// LeaderConfig represents the election of the replica running the scheduler
+type LeaderConfig struct {
+	Enabled  bool `mapstructure:"enabled"`   // without election every replica runs the scheduled jobs
+	LeaseTTL int  `mapstructure:"lease_ttl"` // seconds until a dead leader is replaced
+}
+
+// GetLeaseTTL returns the validity of a leader lease as a time.Duration
+func (lc *LeaderConfig) GetLeaseTTL() time.Duration {
+	return time.Duration(lc.LeaseTTL) * time.Second
+}
+
+// FreshnessConfig represents the monitoring of tracked symbols for data which stopped updating
+type FreshnessConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	Grace         int  `mapstructure:"grace"`          // minutes a symbol may lag on top of its refresh interval
+	CheckInterval int  `mapstructure:"check_interval"` // minutes between checks, reports are reused meanwhile
+}
+
+// GetGrace returns the tolerated lag on top of the refresh interval as a time.Duration
+func (fc *FreshnessConfig) GetGrace() time.Duration {
+	return time.Duration(fc.Grace) * time.Minute
+}
+
+// GetCheckInterval returns the time between two freshness checks as a time.Duration
+func (fc *FreshnessConfig) GetCheckInterval() time.Duration {
+	return time.Duration(fc.CheckInterval) * time.Minute
+}
+
+// ValidationConfig represents the checks of incoming bars, bars failing them are quarantined instead of stored
+type ValidationConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	Rules            []string `mapstructure:"rules"`              // rules applied in order
+	MaxChangePercent float64  `mapstructure:"max_change_percent"` // largest close move between bars of max_change
+}
+
+// AnomalyConfig represents the detection of bad ticks among stored bars by rolling scores of returns and volumes
+type AnomalyConfig struct {
+	OnIngest  bool    `mapstructure:"on_ingest"` // score the bars stored by every fetch and import
+	Method    string  `mapstructure:"method"`    // zscore or mad
+	Window    int     `mapstructure:"window"`    // preceding bars forming the baseline of a bar
+	Threshold float64 `mapstructure:"threshold"` // score from which a bar is flagged
+}
+
+// PluginConfig represents an external data provider process speaking the stdio JSON protocol.
+// Zero durations fall back to the defaults of the getters, as list entries get no viper defaults.
+type PluginConfig struct {
+	Name            string   `mapstructure:"name"`
+	Command         string   `mapstructure:"command"`
+	Args            []string `mapstructure:"args"`
+	Env             []string `mapstructure:"env"` // KEY=VALUE entries added to the service environment
+	Dir             string   `mapstructure:"dir"`
+	RequestTimeout  int      `mapstructure:"request_timeout"`   // seconds
+	HealthInterval  int      `mapstructure:"health_interval"`   // seconds
+	RestartWaitTime int      `mapstructure:"restart_wait_time"` // milliseconds, doubled after every failed restart
+}
+
+// GetRequestTimeout returns the request timeout as a time.Duration, 30 seconds by default
+func (pc *PluginConfig) GetRequestTimeout() time.Duration {
+	if pc.RequestTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(pc.RequestTimeout) * time.Second
+}
+
+// GetHealthInterval returns the health check interval as a time.Duration, 10 seconds by default
+func (pc *PluginConfig) GetHealthInterval() time.Duration {
+	if pc.HealthInterval <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(pc.HealthInterval) * time.Second
+}
+
+// GetRestartWaitTime returns the initial wait before restarting the process as a time.Duration, 1 second by default
+func (pc *PluginConfig) GetRestartWaitTime() time.Duration {
+	if pc.RestartWaitTime <= 0 {
+		return time.Second
+	}
+	return time.Duration(pc.RestartWaitTime) * time.Millisecond
+}
+
+// GetRequestTimeout returns the request timeout as a time.Duration
+func (bc *BinanceConfig) GetRequestTimeout() time.Duration {
+	return time.Duration(bc.RequestTimeout) * time.Second
+}
+
+// GetRetryWaitTime returns the retry wait time as a time.Duration
+func (bc *BinanceConfig) GetRetryWaitTime() time.Duration {
+	return time.Duration(bc.RetryWaitTime) * time.Millisecond
+}
+
 // GetRequestTimeout returns the request timeout as a time.Duration
 func (yfc *YahooFinanceConfig) GetRequestTimeout() time.Duration {
 	return time.Duration(yfc.RequestTimeout) * time.Second
@@ -136,6 +437,9 @@ func Load() (*Config, error) {
 
 	// Yahoo Finance defaults
 	viper.SetDefault("yahoo_finance.base_url", "https://query1.finance.yahoo.com/v8/finance/chart/")
+	viper.SetDefault("yahoo_finance.options_base_url", "https://query2.finance.yahoo.com/v7/finance/options/")
+	viper.SetDefault("yahoo_finance.spark_base_url", "https://query1.finance.yahoo.com/v8/finance/spark")
+	viper.SetDefault("yahoo_finance.batch_size", 20)
 	viper.SetDefault("yahoo_finance.request_timeout", 10)
 	viper.SetDefault("yahoo_finance.retry_count", 3)
 	viper.SetDefault("yahoo_finance.retry_wait_time", 500)
@@ -143,6 +447,110 @@ func Load() (*Config, error) {
 	viper.SetDefault("yahoo_finance.update_interval", 15)
 	viper.SetDefault("yahoo_finance.enable_auto_update", true)
 
+	// Binance defaults
+	viper.SetDefault("binance.base_url", "https://api.binance.com/api/v3/klines")
+	viper.SetDefault("binance.request_timeout", 10)
+	viper.SetDefault("binance.retry_count", 3)
+	viper.SetDefault("binance.retry_wait_time", 500)
+	viper.SetDefault("binance.page_limit", 1000)
+
+	// File provider defaults
+	viper.SetDefault("file_provider.directory", "data")
+	viper.SetDefault("file_provider.format", "csv")
+	viper.SetDefault("file_provider.delimiter", ",")
+	viper.SetDefault("file_provider.decimal_separator", ".")
+	viper.SetDefault("file_provider.date_formats", []string{"2006-01-02", time.RFC3339})
+	viper.SetDefault("file_provider.timezone", "UTC")
+	viper.SetDefault("file_provider.exchange", "FILE")
+	viper.SetDefault("file_provider.columns.time", "Date")
+	viper.SetDefault("file_provider.columns.open", "Open")
+	viper.SetDefault("file_provider.columns.high", "High")
+	viper.SetDefault("file_provider.columns.low", "Low")
+	viper.SetDefault("file_provider.columns.close", "Close")
+	viper.SetDefault("file_provider.columns.adj_close", "Adj Close")
+	viper.SetDefault("file_provider.columns.volume", "Volume")
+
+	// FRED defaults
+	viper.SetDefault("fred.base_url", "https://api.stlouisfed.org/fred")
+	viper.SetDefault("fred.request_timeout", 10)
+	viper.SetDefault("fred.retry_count", 3)
+	viper.SetDefault("fred.retry_wait_time", 500)
+	viper.SetDefault("fred.page_limit", 100000)
+
+	// Options defaults
+	viper.SetDefault("options.enable_snapshots", false)
+	viper.SetDefault("options.symbols", []string{"AAPL", "MSFT", "GOOG"})
+	viper.SetDefault("options.snapshot_interval", 1440)
+	viper.SetDefault("options.risk_free_rate", 0.04)
+	viper.SetDefault("options.persist_analytics", false)
+
+	// Quotes defaults
+	viper.SetDefault("quotes.enable_polling", false)
+	viper.SetDefault("quotes.poll_interval", 60)
+	viper.SetDefault("quotes.concurrency", 4)
+
+	viper.SetDefault("archive.enabled", false)
+
+	// Fetch-through defaults
+	viper.SetDefault("fetch_through.enabled", false)
+	viper.SetDefault("fetch_through.timeout", 10)
+	viper.SetDefault("fetch_through.unknown_ttl", 1440)
+	viper.SetDefault("fetch_through.max_unknown", 10000)
+
+	// Usage defaults
+	viper.SetDefault("usage.enabled", true)
+	viper.SetDefault("usage.flush_interval", 60)
+	viper.SetDefault("usage.daily_caps", map[string]int64{})
+
+	// Tracked symbols defaults
+	viper.SetDefault("tracking.enabled", true)
+	viper.SetDefault("tracking.check_interval", 60)
+	viper.SetDefault("tracking.concurrency", 4)
+	viper.SetDefault("tracking.default_intervals", []string{"1d"})
+	viper.SetDefault("tracking.default_history_days", 1825)
+	viper.SetDefault("tracking.default_refresh_interval", 15)
+
+	// Job queue defaults
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.poll_interval", 5)
+	viper.SetDefault("jobs.timeout", 600)
+	viper.SetDefault("jobs.max_attempts", 5)
+	viper.SetDefault("jobs.backoff_base", 30)
+	viper.SetDefault("jobs.backoff_max", 3600)
+	viper.SetDefault("jobs.retention", 1440)
+
+	// Leader election defaults
+	viper.SetDefault("leader_election.enabled", true)
+	viper.SetDefault("leader_election.lease_ttl", 30)
+
+	// Freshness monitor defaults
+	viper.SetDefault("freshness.enabled", true)
+	viper.SetDefault("freshness.grace", 30)
+	viper.SetDefault("freshness.check_interval", 5)
+
+	// Validation defaults
+	viper.SetDefault("validation.enabled", true)
+	viper.SetDefault("validation.rules", []string{"positive_prices", "high_low", "open_close_range", "non_negative_volume"})
+	viper.SetDefault("validation.max_change_percent", 50)
+
+	// Anomaly detection defaults
+	viper.SetDefault("anomalies.on_ingest", true)
+	viper.SetDefault("anomalies.method", "mad")
+	viper.SetDefault("anomalies.window", 20)
+	viper.SetDefault("anomalies.threshold", 6)
+
+	// Synthetic provider and demo defaults
+	viper.SetDefault("synthetic.seed", 42)
+	viper.SetDefault("synthetic.start_price", 100)
+	viper.SetDefault("synthetic.drift", 0.07)
+	viper.SetDefault("synthetic.volatility", 0.25)
+	viper.SetDefault("synthetic.gap_probability", 0)
+	viper.SetDefault("synthetic.start_date", "2015-01-02")
+	viper.SetDefault("demo.enabled", false)
+	viper.SetDefault("demo.symbols", []string{"DEMO", "ACME", "GLOBEX", "INITECH"})
+
+	viper.SetDefault("data_provider", "yahoo")
+
 	// Read environment variables
 	viper.AutomaticEnv()
 
@@ -150,6 +558,9 @@ func Load() (*Config, error) {
 	if port := os.Getenv("PORT"); port != "" {
 		viper.Set("server.port", port)
 	}
+	if apiKey := os.Getenv("FRED_API_KEY"); apiKey != "" {
+		viper.Set("fred.api_key", apiKey)
+	}
 
 	// Read configuration file
 	if err := viper.ReadInConfig(); err != nil {
@@ -13,9 +13,16 @@ import (
 	"github.com/market-data/internal/config"
 )
 
+// advisoryLockPoll is the wait between two attempts to take an advisory lock held by another session
+const advisoryLockPoll = 200 * time.Millisecond
+
 // DB represents a database connection pool
 type DB struct {
 	Pool *pgxpool.Pool
+
+	// lockSlots caps the connections pinned by advisory locks at half the pool, so holders of locks can
+	// still run queries
+	lockSlots chan struct{}
 }
 
 // NewWithConfig creates a new database connection pool using the provided configuration
@@ -60,7 +67,7 @@ func NewWithConfig(dbConfig *config.DatabaseConfig) (*DB, error) {
 		Str("dbname", dbConfig.DBName).
 		Msg("Connected to database")
 
-	return &DB{Pool: pool}, nil
+	return &DB{Pool: pool, lockSlots: make(chan struct{}, max(config.MaxConns/2, 1))}, nil
 }
 
 // Close closes the database connection pool
@@ -123,3 +130,66 @@ func (db *DB) InTransaction(ctx context.Context, fn func(tx pgx.Tx) error) error
 	}
 	return tx.Commit(ctx)
 }
+
+// AdvisoryLock takes the session-level advisory lock of a key on a dedicated connection and reports whether it
+// had to wait for another session holding it. The lock is held until unlock is called; keys are hashed, so
+// distinct keys may rarely share a lock.
+//
+// A held lock pins its connection, so at most half the pool holds locks at a time. While the lock is held
+// elsewhere, the connection is given back between attempts instead of blocking in pg_advisory_lock.
+func (db *DB) AdvisoryLock(ctx context.Context, key string) (unlock func(), waited bool, err error) {
+	for {
+		unlock, err := db.tryAdvisoryLock(ctx, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if unlock != nil {
+			return unlock, waited, nil
+		}
+
+		waited = true
+		select {
+		case <-ctx.Done():
+			return nil, false, eris.Wrapf(ctx.Err(), "failed to take advisory lock: %s", key)
+		case <-time.After(advisoryLockPoll):
+		}
+	}
+}
+
+// tryAdvisoryLock takes the advisory lock of a key if no other session holds it, unlock is nil otherwise
+func (db *DB) tryAdvisoryLock(ctx context.Context, key string) (unlock func(), err error) {
+	select {
+	case db.lockSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, eris.Wrapf(ctx.Err(), "failed to take advisory lock: %s", key)
+	}
+	releaseSlot := func() { <-db.lockSlots }
+
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		releaseSlot()
+		return nil, eris.Wrap(err, "failed to acquire connection")
+	}
+
+	var locked bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key).Scan(&locked)
+	if err != nil || !locked {
+		conn.Release()
+		releaseSlot()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to take advisory lock: %s", key)
+		}
+		return nil, nil
+	}
+
+	return func() {
+		_, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key)
+		if err != nil {
+			// closing the session releases its locks
+			log.Error().Err(err).Str("key", key).Msg("Failed to release advisory lock")
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+		releaseSlot()
+	}, nil
+}
@@ -0,0 +1,61 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/database"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_AdvisoryLock(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+
+	cfg := timescaleDB.DatabaseConfigForTimescale()
+	cfg.MaxConnections = 2
+	db, err := database.NewWithConfig(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// a lock held elsewhere is waited for
+	unlock, waited, err := db.AdvisoryLock(ctx, "fetch:AAPL:1d")
+	require.NoError(t, err)
+	assert.False(t, waited)
+	time.AfterFunc(300*time.Millisecond, unlock)
+	unlock, waited, err = db.AdvisoryLock(ctx, "fetch:AAPL:1d")
+	require.NoError(t, err)
+	assert.True(t, waited)
+	unlock()
+
+	// more lockers than connections, holders still get connections for their queries
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, _, err := db.AdvisoryLock(ctx, fmt.Sprintf("fetch:SYM%d:1d", i%3))
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer unlock()
+			_, err = db.ExecContext(ctx, `SELECT pg_sleep(0.05)`)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
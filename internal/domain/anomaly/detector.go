@@ -0,0 +1,181 @@
+package anomaly
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// minWindow is the smallest baseline a score is meaningful for
+const minWindow = 5
+
+// Scale factors turning absolute deviations into estimates of the standard deviation of normal data
+const (
+	madScale              = 1.4826
+	meanAbsDeviationScale = 1.2533
+)
+
+// Settings configures a Detector
+type Settings struct {
+	Method    string  // zscore or mad
+	Window    int     // preceding bars forming the baseline of a bar
+	Threshold float64 // absolute score from which a bar is flagged
+}
+
+// Detector flags bars whose return or volume deviates from a rolling baseline of the preceding bars. Returns
+// are flagged in both directions, volumes only when they spike. Flagged values do not enter the baselines.
+type Detector struct {
+	settings Settings
+}
+
+// NewDetector creates a detector, returns an error wrapping ErrInvalidSettings for unknown methods, windows
+// shorter than five bars and non-positive thresholds.
+func NewDetector(settings Settings) (*Detector, error) {
+	if settings.Method != MethodZScore && settings.Method != MethodMAD {
+		return nil, eris.Wrapf(ErrInvalidSettings, "unknown method %q, supported are %s and %s", settings.Method,
+			MethodZScore, MethodMAD)
+	}
+	if settings.Window < minWindow {
+		return nil, eris.Wrapf(ErrInvalidSettings, "window must be at least %d bars", minWindow)
+	}
+	if settings.Threshold <= 0 {
+		return nil, eris.Wrap(ErrInvalidSettings, "threshold must be positive")
+	}
+	return &Detector{settings: settings}, nil
+}
+
+// Lookback returns the number of bars preceding a range needed to score its first bar
+func (d *Detector) Lookback() int {
+	return d.settings.Window + 1
+}
+
+// Detect scores the bars of an interval of a symbol, in time order, and returns the anomalies among those
+// from a time on.
+// Earlier bars only form the baselines.
+//
+// A bad tick moves the close away and back, so the return of the bar following a flagged one is taken from
+// the last unflagged close. When the close stays at the new level, the move from the flagged bar is normal
+// and the bar is not flagged either, the level shift is then only reported once.
+func (d *Detector) Detect(symbol string, interval yahoo.IntervalAPI, bars []Bar, from time.Time) []Anomaly {
+	var anomalies []Anomaly
+	flag := func(bar Bar, metric string, value, baseline, score float64) {
+		if bar.Time.Before(from) {
+			return
+		}
+		anomalies = append(anomalies, Anomaly{
+			Symbol:     symbol,
+			Interval:   interval,
+			Time:       bar.Time,
+			Metric:     metric,
+			Method:     d.settings.Method,
+			Value:      value,
+			Baseline:   baseline,
+			Score:      score,
+			ClosePrice: bar.ClosePrice,
+			Volume:     bar.Volume,
+			Status:     StatusOpen,
+		})
+	}
+
+	returns := make([]float64, 0, d.settings.Window)
+	volumes := make([]float64, 0, d.settings.Window)
+	var prevClose, refClose float64
+	for _, bar := range bars {
+		if bar.ClosePrice != nil && *bar.ClosePrice > 0 {
+			closePrice := *bar.ClosePrice
+			if refClose > 0 {
+				value := math.Log(closePrice / refClose)
+				score, baseline, ok := d.score(value, returns)
+				jumped := ok && math.Abs(score) >= d.settings.Threshold
+				normal := value
+				if jumped && prevClose != refClose {
+					normal = math.Log(closePrice / prevClose)
+					fromPrev, _, _ := d.score(normal, returns)
+					jumped = math.Abs(fromPrev) >= d.settings.Threshold
+				}
+				if jumped {
+					flag(bar, MetricReturn, value, baseline, score)
+				} else {
+					returns = push(returns, normal, d.settings.Window)
+					refClose = closePrice
+				}
+			} else {
+				refClose = closePrice
+			}
+			prevClose = closePrice
+		}
+
+		if bar.Volume != nil && *bar.Volume >= 0 {
+			value := math.Log1p(*bar.Volume)
+			score, baseline, ok := d.score(value, volumes)
+			if ok && score >= d.settings.Threshold {
+				flag(bar, MetricVolume, value, baseline, score)
+			} else {
+				volumes = push(volumes, value, d.settings.Window)
+			}
+		}
+	}
+	return anomalies
+}
+
+// score returns the deviation of a value from the baseline of a full window, ok is false while the window
+// is filling up or when its values do not vary
+func (d *Detector) score(value float64, window []float64) (score, baseline float64, ok bool) {
+	if len(window) < d.settings.Window {
+		return 0, 0, false
+	}
+	var spread float64
+	switch d.settings.Method {
+	case MethodZScore:
+		baseline, spread = meanStdDev(window)
+	default:
+		baseline = median(window)
+		deviations := make([]float64, len(window))
+		var sum float64
+		for i, v := range window {
+			deviations[i] = math.Abs(v - baseline)
+			sum += deviations[i]
+		}
+		spread = madScale * median(deviations)
+		if spread == 0 {
+			// more than half the values are equal, e.g. unchanged closes of an illiquid symbol
+			spread = meanAbsDeviationScale * sum / float64(len(window))
+		}
+	}
+	if spread == 0 {
+		return 0, baseline, false
+	}
+	return (value - baseline) / spread, baseline, true
+}
+
+// push appends a value to a rolling window, dropping the oldest one when it is full
+func push(window []float64, value float64, size int) []float64 {
+	if len(window) == size {
+		window = append(window[:0], window[1:]...)
+	}
+	return append(window, value)
+}
+
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stdDev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stdDev / float64(len(values)-1))
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,107 @@
+package anomaly_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/anomaly"
+	"github.com/market-data/internal/providers/yahoo"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dailyBars returns daily bars of the closes with a steady volume
+func dailyBars(start time.Time, closes []float64) []anomaly.Bar {
+	bars := make([]anomaly.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = anomaly.Bar{
+			Time:       start.AddDate(0, 0, i),
+			ClosePrice: testsTools.Ptr(c),
+			Volume:     testsTools.Ptr(float64(1_000_000 + 10_000*(i%7))),
+		}
+	}
+	return bars
+}
+
+// wiggle returns n closes around a price, moving up to 1% from bar to bar
+func wiggle(n int, price float64) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = price * (1 + 0.01*math.Sin(float64(i)))
+	}
+	return closes
+}
+
+func TestNewDetector(t *testing.T) {
+	_, err := anomaly.NewDetector(anomaly.Settings{Method: "iqr", Window: 20, Threshold: 6})
+	assert.ErrorIs(t, err, anomaly.ErrInvalidSettings)
+
+	_, err = anomaly.NewDetector(anomaly.Settings{Method: anomaly.MethodMAD, Window: 2, Threshold: 6})
+	assert.ErrorIs(t, err, anomaly.ErrInvalidSettings)
+
+	_, err = anomaly.NewDetector(anomaly.Settings{Method: anomaly.MethodZScore, Window: 20})
+	assert.ErrorIs(t, err, anomaly.ErrInvalidSettings)
+}
+
+func TestDetector_Detect(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, method := range []string{anomaly.MethodMAD, anomaly.MethodZScore} {
+		t.Run(method, func(t *testing.T) {
+			detector, err := anomaly.NewDetector(anomaly.Settings{Method: method, Window: 20, Threshold: 6})
+			require.NoError(t, err)
+
+			t.Run("Steady prices", func(t *testing.T) {
+				assert.Empty(t, detector.Detect("AAPL", yahoo.Interval1d, dailyBars(start, wiggle(60, 100)), time.Time{}))
+			})
+
+			t.Run("Bad tick", func(t *testing.T) {
+				closes := wiggle(60, 100)
+				closes[40] *= 10
+				anomalies := detector.Detect("AAPL", yahoo.Interval1d, dailyBars(start, closes), time.Time{})
+				require.Len(t, anomalies, 1, "the return to the normal level is not flagged")
+				assert.Equal(t, anomaly.MetricReturn, anomalies[0].Metric)
+				assert.Equal(t, method, anomalies[0].Method)
+				assert.True(t, start.AddDate(0, 0, 40).Equal(anomalies[0].Time))
+				assert.Greater(t, anomalies[0].Score, 6.0)
+				assert.InDelta(t, closes[40], *anomalies[0].ClosePrice, 1e-9)
+				assert.Equal(t, anomaly.StatusOpen, anomalies[0].Status)
+			})
+
+			t.Run("Level shift", func(t *testing.T) {
+				closes := append(wiggle(40, 100), wiggle(20, 50)...)
+				anomalies := detector.Detect("AAPL", yahoo.Interval1d, dailyBars(start, closes), time.Time{})
+				require.Len(t, anomalies, 1, "bars at the new level are not flagged")
+				assert.True(t, start.AddDate(0, 0, 40).Equal(anomalies[0].Time))
+				assert.Less(t, anomalies[0].Score, -6.0)
+			})
+
+			t.Run("Volume spike", func(t *testing.T) {
+				bars := dailyBars(start, wiggle(60, 100))
+				bars[45].Volume = testsTools.Ptr(500_000_000.0)
+				bars[50].Volume = testsTools.Ptr(0.0)
+				anomalies := detector.Detect("AAPL", yahoo.Interval1d, bars, time.Time{})
+				require.Len(t, anomalies, 1, "volume drops are not flagged")
+				assert.Equal(t, anomaly.MetricVolume, anomalies[0].Metric)
+				assert.True(t, start.AddDate(0, 0, 45).Equal(anomalies[0].Time))
+			})
+
+			t.Run("Bars before the range only form baselines", func(t *testing.T) {
+				closes := wiggle(60, 100)
+				closes[30] *= 10
+				closes[50] *= 10
+				anomalies := detector.Detect("AAPL", yahoo.Interval1d, dailyBars(start, closes), start.AddDate(0, 0, 40))
+				require.Len(t, anomalies, 1)
+				assert.True(t, start.AddDate(0, 0, 50).Equal(anomalies[0].Time))
+			})
+
+			t.Run("Too few bars", func(t *testing.T) {
+				closes := wiggle(15, 100)
+				closes[10] *= 10
+				assert.Empty(t, detector.Detect("AAPL", yahoo.Interval1d, dailyBars(start, closes), time.Time{}))
+			})
+		})
+	}
+}
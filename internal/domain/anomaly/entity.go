@@ -0,0 +1,80 @@
+package anomaly
+
+import (
+	"errors"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// Domain errors
+var (
+	ErrAnomalyNotFound = errors.New("anomaly not found")
+	ErrAnomalyReviewed = errors.New("anomaly already reviewed")
+	ErrInvalidSettings = errors.New("invalid anomaly detection settings")
+)
+
+// Metrics scored by the detector
+const (
+	MetricReturn = "return" // log return of the close from the previous bar
+	MetricVolume = "volume" // log of one plus the volume
+)
+
+// Scoring methods
+const (
+	MethodZScore = "zscore" // deviation from the mean in standard deviations
+	MethodMAD    = "mad"    // deviation from the median in scaled median absolute deviations, robust to outliers
+)
+
+// Review states of an anomaly
+const (
+	StatusOpen      = "open"      // awaiting review
+	StatusKept      = "kept"      // the bar is genuine and stays as stored
+	StatusCorrected = "corrected" // the stored bar was overwritten with corrected values
+)
+
+// Anomaly is a stored bar whose return or volume deviates from the preceding bars by more than the threshold
+type Anomaly struct {
+	ID         int64             `db:"id"`           // BIGSERIAL PRIMARY KEY
+	Symbol     string            `db:"symbol"`       // TEXT NOT NULL
+	Interval   yahoo.IntervalAPI `db:"bar_interval"` // TEXT NOT NULL
+	Time       time.Time         `db:"time"`         // TIMESTAMPTZ NOT NULL
+	Metric     string            `db:"metric"`       // TEXT NOT NULL
+	Method     string            `db:"method"`       // TEXT NOT NULL
+	Value      float64           `db:"value"`        // DOUBLE PRECISION NOT NULL
+	Baseline   float64           `db:"baseline"`     // DOUBLE PRECISION NOT NULL
+	Score      float64           `db:"score"`        // DOUBLE PRECISION NOT NULL
+	ClosePrice *float64          `db:"close_price"`  // NUMERIC(18, 6) nullable
+	Volume     *float64          `db:"volume"`       // NUMERIC(28, 8) nullable
+	Status     string            `db:"status"`       // TEXT NOT NULL DEFAULT 'open'
+	DetectedAt time.Time         `db:"detected_at"`  // TIMESTAMPTZ NOT NULL DEFAULT now()
+	ReviewedAt *time.Time        `db:"reviewed_at"`  // TIMESTAMPTZ (nullable)
+}
+
+// Bar is the part of a stored bar the detector scores
+type Bar struct {
+	Time       time.Time `db:"time"`
+	ClosePrice *float64  `db:"close_price"`
+	Volume     *float64  `db:"volume"`
+}
+
+// Correction holds the values overwriting a stored bar, nil fields keep their stored value
+type Correction struct {
+	OpenPrice  *float64
+	HighPrice  *float64
+	LowPrice   *float64
+	ClosePrice *float64
+	AdjClose   *float64
+	Volume     *float64
+}
+
+// Filter selects anomalies, zero fields do not filter
+type Filter struct {
+	Symbol   string
+	Interval yahoo.IntervalAPI
+	Metric   string
+	Status   string
+	From     time.Time // bars from, inclusive
+	To       time.Time // bars until, inclusive
+	Limit    int
+}
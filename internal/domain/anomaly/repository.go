@@ -0,0 +1,254 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the persistence of detected anomalies
+type Repository interface {
+	// GetBars returns the bars of an interval of a symbol from a time on, until to unless zero, preceded by up
+	// to lookback earlier bars, in time order
+	GetBars(ctx context.Context, symbol string, interval yahoo.IntervalAPI, from, to time.Time,
+		lookback int) ([]Bar, error)
+	// SaveAnomalies stores detected anomalies, updating the scores of open ones and leaving reviewed ones
+	SaveAnomalies(ctx context.Context, anomalies []Anomaly) error
+	ListAnomalies(ctx context.Context, filter Filter) ([]Anomaly, error)
+	// KeepAnomaly marks an open anomaly kept
+	KeepAnomaly(ctx context.Context, id int64) (*Anomaly, error)
+	// CorrectAnomaly overwrites the stored bar of an open anomaly and marks the open anomalies of the bar
+	// corrected
+	CorrectAnomaly(ctx context.Context, id int64, correction *Correction) (*Anomaly, error)
+}
+
+// AnomalyRepository implements Repository with PostgreSQL
+type AnomalyRepository struct {
+	db *database.DB
+}
+
+// NewAnomalyRepository creates a new anomaly repository
+func NewAnomalyRepository(db *database.DB) *AnomalyRepository {
+	return &AnomalyRepository{
+		db: db,
+	}
+}
+
+// anomalyColumns are the columns of Anomaly
+const anomalyColumns = `
+	id, symbol, bar_interval, time, metric, method, value, baseline, score, close_price, volume,
+	status, detected_at, reviewed_at`
+
+// GetBars returns the bars of an interval of a symbol from a time on, until to unless zero, preceded by up to
+// lookback earlier bars, in time order
+func (r *AnomalyRepository) GetBars(ctx context.Context, symbol string, interval yahoo.IntervalAPI, from,
+	to time.Time, lookback int) ([]Bar, error) {
+	query := `
+		SELECT time, close_price, volume
+		FROM (
+			(SELECT sp.time, sp.close_price, sp.volume
+			 FROM stock_prices sp
+			 JOIN symbols s ON sp.symbol_id = s.id
+			 WHERE s.symbol = $1 AND sp.bar_interval = $5 AND sp.time < $2
+			 ORDER BY sp.time DESC
+			 LIMIT $4)
+			UNION ALL
+			(SELECT sp.time, sp.close_price, sp.volume
+			 FROM stock_prices sp
+			 JOIN symbols s ON sp.symbol_id = s.id
+			 WHERE s.symbol = $1 AND sp.bar_interval = $5 AND sp.time >= $2
+			   AND ($3::timestamptz IS NULL OR sp.time <= $3))
+		) bars
+		ORDER BY time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, nullableTime(to), lookback, interval)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query bars of symbol: %s", symbol)
+	}
+
+	bars, err := pgx.CollectRows(rows, pgx.RowToStructByName[Bar])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect bar rows")
+	}
+	return bars, nil
+}
+
+// SaveAnomalies stores detected anomalies. Anomalies detected again update the scores of open ones, reviewed
+// ones are left as they are.
+func (r *AnomalyRepository) SaveAnomalies(ctx context.Context, anomalies []Anomaly) error {
+	query := `
+		INSERT INTO price_anomalies (
+			symbol, bar_interval, time, metric, method, value, baseline, score, close_price, volume
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)
+		ON CONFLICT (symbol, bar_interval, time, metric) DO UPDATE SET
+			method = EXCLUDED.method,
+			value = EXCLUDED.value,
+			baseline = EXCLUDED.baseline,
+			score = EXCLUDED.score,
+			close_price = EXCLUDED.close_price,
+			volume = EXCLUDED.volume,
+			detected_at = now()
+		WHERE price_anomalies.status = 'open'
+	`
+
+	return r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, a := range anomalies {
+			batch.Queue(query, a.Symbol, a.Interval, a.Time, a.Metric, a.Method, a.Value, a.Baseline, a.Score,
+				a.ClosePrice, a.Volume)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return eris.Wrap(err, "failed to upsert anomalies")
+		}
+		return nil
+	})
+}
+
+// ListAnomalies retrieves the anomalies matching the filter, latest bar first
+func (r *AnomalyRepository) ListAnomalies(ctx context.Context, filter Filter) ([]Anomaly, error) {
+	query := `
+		SELECT ` + anomalyColumns + `
+		FROM price_anomalies
+		WHERE ($1 = '' OR symbol = $1)
+		  AND ($2 = '' OR metric = $2)
+		  AND ($3 = '' OR status = $3)
+		  AND ($4::timestamptz IS NULL OR time >= $4)
+		  AND ($5::timestamptz IS NULL OR time <= $5)
+		  AND ($7 = '' OR bar_interval = $7)
+		ORDER BY time DESC, id DESC
+		LIMIT NULLIF($6, 0)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Symbol, filter.Metric, filter.Status,
+		nullableTime(filter.From), nullableTime(filter.To), filter.Limit, filter.Interval)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query anomalies")
+	}
+
+	anomalies, err := pgx.CollectRows(rows, pgx.RowToStructByName[Anomaly])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect anomaly rows")
+	}
+	return anomalies, nil
+}
+
+// KeepAnomaly marks an open anomaly kept, its bar stays as stored
+func (r *AnomalyRepository) KeepAnomaly(ctx context.Context, id int64) (*Anomaly, error) {
+	var kept *Anomaly
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := lockOpenAnomaly(ctx, tx, id); err != nil {
+			return err
+		}
+		var err error
+		kept, err = setAnomalyStatus(ctx, tx, id, StatusKept)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// CorrectAnomaly overwrites the stored bar of an open anomaly with the corrected values and marks all open
+// anomalies of the bar corrected
+func (r *AnomalyRepository) CorrectAnomaly(ctx context.Context, id int64,
+	correction *Correction) (*Anomaly, error) {
+	queryStockPrice := `
+		UPDATE stock_prices sp SET
+			open_price = COALESCE($3, sp.open_price),
+			high_price = COALESCE($4, sp.high_price),
+			low_price = COALESCE($5, sp.low_price),
+			close_price = COALESCE($6, sp.close_price),
+			adj_close = COALESCE($7, sp.adj_close),
+			volume = COALESCE($8, sp.volume)
+		FROM symbols s
+		WHERE sp.symbol_id = s.id AND s.symbol = $1 AND sp.time = $2 AND sp.bar_interval = $9
+	`
+	queryBarAnomalies := `
+		UPDATE price_anomalies
+		SET status = 'corrected', reviewed_at = now()
+		WHERE symbol = $1 AND time = $2 AND bar_interval = $4 AND status = 'open' AND id <> $3
+	`
+
+	var corrected *Anomaly
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		anomaly, err := lockOpenAnomaly(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, queryStockPrice, anomaly.Symbol, anomaly.Time, correction.OpenPrice,
+			correction.HighPrice, correction.LowPrice, correction.ClosePrice, correction.AdjClose, correction.Volume,
+			anomaly.Interval)
+		if err != nil {
+			return eris.Wrapf(err, "failed to correct bar of anomaly: %d", id)
+		}
+		if tag.RowsAffected() == 0 {
+			return eris.Wrapf(ErrAnomalyNotFound, "bar of anomaly %d is no longer stored", id)
+		}
+		if _, err := tx.Exec(ctx, queryBarAnomalies, anomaly.Symbol, anomaly.Time, id, anomaly.Interval); err != nil {
+			return eris.Wrapf(err, "failed to update anomalies of bar of anomaly: %d", id)
+		}
+		corrected, err = setAnomalyStatus(ctx, tx, id, StatusCorrected)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return corrected, nil
+}
+
+// lockOpenAnomaly locks an anomaly for review, returns ErrAnomalyNotFound or ErrAnomalyReviewed when it
+// cannot be reviewed
+func lockOpenAnomaly(ctx context.Context, tx pgx.Tx, id int64) (*Anomaly, error) {
+	query := `SELECT ` + anomalyColumns + ` FROM price_anomalies WHERE id = $1 FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query anomaly: %d", id)
+	}
+	anomaly, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[Anomaly])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAnomalyNotFound
+		}
+		return nil, eris.Wrapf(err, "cannot collect exactly one row for anomaly: %d", id)
+	}
+	if anomaly.Status != StatusOpen {
+		return nil, eris.Wrapf(ErrAnomalyReviewed, "anomaly %d is %s", id, anomaly.Status)
+	}
+	return anomaly, nil
+}
+
+func setAnomalyStatus(ctx context.Context, tx pgx.Tx, id int64, status string) (*Anomaly, error) {
+	query := `
+		UPDATE price_anomalies
+		SET status = $2, reviewed_at = now()
+		WHERE id = $1
+		RETURNING ` + anomalyColumns
+
+	rows, err := tx.Query(ctx, query, id, status)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to update anomaly: %d", id)
+	}
+	anomaly, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[Anomaly])
+	if err != nil {
+		return nil, eris.Wrapf(err, "cannot collect exactly one row for anomaly: %d", id)
+	}
+	return anomaly, nil
+}
+
+// nullableTime maps the zero time to NULL, which disables the optional time filters of the queries
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,96 @@
+package anomaly_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/anomaly"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyService_ScanAndReview(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -60)
+	prices := make([]yahoo.StockPrice, 0, 60)
+	for i, c := range wiggle(60, 100) {
+		prices = append(prices, yahoo.StockPrice{
+			Time: start.AddDate(0, 0, i), Open: c, High: c, Low: c, Close: c, AdjClose: c,
+			Volume: float64(1_000_000 + 10_000*(i%7)),
+		})
+	}
+	prices[40].Close *= 10
+	prices[40].Volume = 500_000_000
+	marketRepo := market.NewMarketRepository(db)
+	_, err = marketRepo.SaveMarketData(ctx, &yahoo.MarketData{Symbol: "AAPL", Prices: prices})
+	require.NoError(t, err)
+
+	detector, err := anomaly.NewDetector(anomaly.Settings{Method: anomaly.MethodMAD, Window: 20, Threshold: 6})
+	require.NoError(t, err)
+	repo := anomaly.NewAnomalyRepository(db)
+	service := anomaly.NewService(repo, detector)
+
+	bars, err := repo.GetBars(ctx, "AAPL", yahoo.Interval1d, start.AddDate(0, 0, 50), time.Time{}, detector.Lookback())
+	require.NoError(t, err)
+	require.Len(t, bars, 10+detector.Lookback(), "bars of the range are preceded by the lookback")
+	assert.True(t, start.AddDate(0, 0, 50-detector.Lookback()).Equal(bars[0].Time))
+
+	anomalies, err := service.Scan(ctx, "AAPL", yahoo.Interval1d, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2, "the bad tick has a return and a volume anomaly")
+	assert.True(t, start.AddDate(0, 0, 40).Equal(anomalies[0].Time))
+
+	// detecting again on ingest keeps a single anomaly per bar and metric
+	require.NoError(t, service.DetectAnomalies(ctx, "AAPL", yahoo.Interval1d, start.AddDate(0, 0, 30)))
+	open, err := service.ListAnomalies(ctx, anomaly.Filter{Symbol: "AAPL", Status: anomaly.StatusOpen})
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+
+	corrected, err := service.Correct(ctx, open[0].ID, &anomaly.Correction{
+		ClosePrice: testsTools.Ptr(prices[39].Close),
+		Volume:     testsTools.Ptr(1_000_000.0),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, anomaly.StatusCorrected, corrected.Status)
+	require.NotNil(t, corrected.ReviewedAt)
+
+	open, err = service.ListAnomalies(ctx, anomaly.Filter{Symbol: "AAPL", Status: anomaly.StatusOpen})
+	require.NoError(t, err)
+	assert.Empty(t, open, "correcting a bar resolves all its anomalies")
+
+	stored, err := marketRepo.GetStockPrice(ctx, "AAPL", yahoo.Interval1d)
+	require.NoError(t, err)
+	for _, p := range *stored {
+		if p.Time.Equal(start.AddDate(0, 0, 40)) {
+			assert.InDelta(t, prices[39].Close, *p.ClosePrice, 1e-6)
+			assert.Equal(t, 1_000_000.0, *p.Volume)
+		}
+	}
+
+	_, err = service.Keep(ctx, corrected.ID)
+	assert.ErrorIs(t, err, anomaly.ErrAnomalyReviewed)
+	_, err = service.Keep(ctx, 12345)
+	assert.ErrorIs(t, err, anomaly.ErrAnomalyNotFound)
+
+	// reviewed anomalies are not reopened, the corrected bar is no longer flagged
+	anomalies, err = service.Scan(ctx, "AAPL", yahoo.Interval1d, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+	for _, a := range anomalies {
+		assert.Equal(t, anomaly.StatusCorrected, a.Status)
+	}
+}
@@ -0,0 +1,82 @@
+package anomaly
+
+import (
+	"context"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Service detects anomalies in stored prices and tracks their review
+type Service struct {
+	repo     Repository
+	detector *Detector
+}
+
+// NewService creates a new anomaly service
+func NewService(repo Repository, detector *Detector) *Service {
+	return &Service{
+		repo:     repo,
+		detector: detector,
+	}
+}
+
+// DetectAnomalies scores the bars of an interval of a symbol stored from a time on against the bars
+// preceding them, as done after storing fetched data
+func (s *Service) DetectAnomalies(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	from time.Time) error {
+	_, err := s.detect(ctx, symbol, interval, from, time.Time{})
+	return err
+}
+
+// Scan scores the stored bars of an interval of a symbol from a time on, until to unless zero, and returns
+// the anomalies of the range latest first, including those reviewed before
+func (s *Service) Scan(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	from, to time.Time) ([]Anomaly, error) {
+	if _, err := s.detect(ctx, symbol, interval, from, to); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAnomalies(ctx, Filter{Symbol: symbol, Interval: interval, From: from, To: to})
+}
+
+func (s *Service) detect(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	from, to time.Time) (int, error) {
+	bars, err := s.repo.GetBars(ctx, symbol, interval, from, to, s.detector.Lookback())
+	if err != nil {
+		return 0, err
+	}
+	anomalies := s.detector.Detect(symbol, interval, bars, from)
+	if len(anomalies) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.SaveAnomalies(ctx, anomalies); err != nil {
+		return 0, eris.Wrapf(err, "failed to save anomalies of symbol: %s", symbol)
+	}
+	log.Warn().
+		Str("symbol", symbol).
+		Str("interval", string(interval)).
+		Int("anomalies", len(anomalies)).
+		Time("first", anomalies[0].Time).
+		Msg("Price anomalies detected")
+	return len(anomalies), nil
+}
+
+// ListAnomalies retrieves the anomalies matching the filter, latest bar first
+func (s *Service) ListAnomalies(ctx context.Context, filter Filter) ([]Anomaly, error) {
+	return s.repo.ListAnomalies(ctx, filter)
+}
+
+// Keep marks an open anomaly kept, the bar is genuine. Returns ErrAnomalyNotFound for unknown anomalies and
+// ErrAnomalyReviewed for anomalies no longer open.
+func (s *Service) Keep(ctx context.Context, id int64) (*Anomaly, error) {
+	return s.repo.KeepAnomaly(ctx, id)
+}
+
+// Correct overwrites the stored bar of an open anomaly, nil values of the correction keep the stored ones,
+// and marks the open anomalies of the bar corrected. Returns ErrAnomalyNotFound for unknown anomalies and
+// bars no longer stored, ErrAnomalyReviewed for anomalies no longer open.
+func (s *Service) Correct(ctx context.Context, id int64, correction *Correction) (*Anomaly, error) {
+	return s.repo.CorrectAnomaly(ctx, id, correction)
+}
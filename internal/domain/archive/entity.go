@@ -0,0 +1,59 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// EncodingGzip is the compression of archived payloads.
+const EncodingGzip = "gzip"
+
+// Fetch groups the archived responses of a logged fetch in request order.
+type Fetch struct {
+	LogID     int
+	Symbol    string
+	FetchedAt time.Time
+	Provider  string
+	Interval  yahoo.IntervalAPI // bar interval of the fetch, daily for fetches logged without one
+	Hashes    []string
+	Payloads  [][]byte // uncompressed response bodies
+}
+
+// Hash returns the content address of a response body, the hex encoded SHA-256 of it.
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, eris.Wrap(err, "failed to compress payload")
+	}
+	if err := w.Close(); err != nil {
+		return nil, eris.Wrap(err, "failed to compress payload")
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(payload []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to decompress payload")
+	}
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to decompress payload")
+	}
+	return body, nil
+}
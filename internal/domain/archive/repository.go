@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// ArchiveRepository stores raw provider responses in PostgreSQL
+type ArchiveRepository struct {
+	db *database.DB
+}
+
+// NewArchiveRepository creates a new payload archive repository
+func NewArchiveRepository(db *database.DB) *ArchiveRepository {
+	return &ArchiveRepository{
+		db: db,
+	}
+}
+
+// SavePayloads archives the response bodies of a provider and returns their content addresses in the same
+// order. A body with already archived content is not stored again.
+func (r *ArchiveRepository) SavePayloads(ctx context.Context, provider string, bodies [][]byte) ([]string, error) {
+	query := `
+		INSERT INTO raw_payloads (hash, provider, encoding, size, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (hash) DO NOTHING
+	`
+
+	hashes := make([]string, 0, len(bodies))
+	batch := &pgx.Batch{}
+	for _, body := range bodies {
+		payload, err := compress(body)
+		if err != nil {
+			return nil, err
+		}
+		hash := Hash(body)
+		hashes = append(hashes, hash)
+		batch.Queue(query, hash, provider, EncodingGzip, len(body), payload)
+	}
+
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to archive payloads")
+	}
+	return hashes, nil
+}
+
+// GetFetches retrieves the archived responses of the logged fetches of a symbol in [from, to), oldest first.
+func (r *ArchiveRepository) GetFetches(ctx context.Context, symbol string, from, to time.Time) ([]Fetch, error) {
+	query := `
+		SELECT l.id, l.symbol, l.fetched_at, COALESCE(l.bar_interval, '1d'), p.provider, p.hash, p.payload
+		FROM price_fetch_logs l
+		CROSS JOIN LATERAL unnest(l.payload_hashes) WITH ORDINALITY AS h(hash, position)
+		JOIN raw_payloads p ON p.hash = h.hash
+		WHERE l.symbol = $1 AND l.fetched_at >= $2 AND l.fetched_at < $3
+		ORDER BY l.fetched_at, l.id, h.position
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, to)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query archived fetches for symbol: %s", symbol)
+	}
+	defer rows.Close()
+
+	var fetches []Fetch
+	for rows.Next() {
+		var (
+			logID     int
+			fetchSym  string
+			fetchedAt time.Time
+			interval  string
+			provider  string
+			hash      string
+			payload   []byte
+		)
+		if err := rows.Scan(&logID, &fetchSym, &fetchedAt, &interval, &provider, &hash, &payload); err != nil {
+			return nil, eris.Wrap(err, "failed to scan archived payload")
+		}
+		body, err := decompress(payload)
+		if err != nil {
+			return nil, eris.Wrapf(err, "payload %s", hash)
+		}
+
+		if len(fetches) == 0 || fetches[len(fetches)-1].LogID != logID {
+			fetches = append(fetches, Fetch{LogID: logID, Symbol: fetchSym, FetchedAt: fetchedAt,
+				Interval: yahoo.IntervalAPI(interval), Provider: provider})
+		}
+		fetch := &fetches[len(fetches)-1]
+		fetch.Hashes = append(fetch.Hashes, hash)
+		fetch.Payloads = append(fetch.Payloads, body)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, eris.Wrapf(err, "failed to read archived fetches for symbol: %s", symbol)
+	}
+	return fetches, nil
+}
@@ -0,0 +1,68 @@
+package archive_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/archive"
+	"github.com/market-data/internal/domain/market"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRepository_GetFetches(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := archive.NewArchiveRepository(db)
+	marketRepo := market.NewMarketRepository(db)
+	ctx := context.TODO()
+
+	pages := [][]byte{[]byte(`[[1,"1.0"]]`), []byte(`[[2,"2.0"]]`)}
+	hashes, err := repo.SavePayloads(ctx, "binance", pages)
+	require.NoError(t, err)
+	require.Equal(t, []string{archive.Hash(pages[0]), archive.Hash(pages[1])}, hashes)
+
+	// identical content is stored once and keeps its address
+	again, err := repo.SavePayloads(ctx, "binance", pages[:1])
+	require.NoError(t, err)
+	require.Equal(t, hashes[:1], again)
+
+	first := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+	_, err = marketRepo.SavePriceFetchLog(ctx, &market.PriceFetchLog{
+		Symbol: "BTCUSDT", FetchedAt: first, DataPoints: testsTools.Ptr(2), ErrorMsg: testsTools.Ptr("parse error"),
+	}, hashes...)
+	require.NoError(t, err)
+	_, err = marketRepo.SavePriceFetchLog(ctx, &market.PriceFetchLog{
+		Symbol: "BTCUSDT", FetchedAt: second, DataPoints: testsTools.Ptr(1), Success: true,
+	}, again...)
+	require.NoError(t, err)
+	_, err = marketRepo.SavePriceFetchLog(ctx, &market.PriceFetchLog{
+		Symbol: "BTCUSDT", FetchedAt: second, DataPoints: testsTools.Ptr(0), ErrorMsg: testsTools.Ptr("timeout"),
+	})
+	require.NoError(t, err)
+
+	fetches, err := repo.GetFetches(ctx, "BTCUSDT", first, second.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, fetches, 2, "fetches without archived responses are skipped")
+
+	assert.True(t, first.Equal(fetches[0].FetchedAt))
+	assert.Equal(t, "binance", fetches[0].Provider)
+	assert.Equal(t, hashes, fetches[0].Hashes)
+	assert.Equal(t, pages, fetches[0].Payloads)
+	assert.Equal(t, pages[:1], fetches[1].Payloads)
+
+	fetches, err = repo.GetFetches(ctx, "BTCUSDT", second, second.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, fetches, 1)
+}
@@ -0,0 +1,74 @@
+package freshness
+
+import (
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// Reasons a symbol is stale
+const (
+	ReasonNoData = "no_data" // no bar is stored for the symbol
+	ReasonLagged = "lagged"  // the latest bar is older than the expected one
+)
+
+// SymbolFreshness tells whether the latest bar of a tracked symbol is as recent as its cadence requires
+type SymbolFreshness struct {
+	Symbol      string
+	Provider    string
+	Interval    yahoo.IntervalAPI // finest tracked interval, the cadence the symbol is expected to update at
+	LatestBar   *time.Time        // nil when no bar is stored
+	ExpectedBar time.Time         // start of the oldest bar which keeps the symbol fresh
+	Lag         *time.Duration    // time since the latest bar
+	Stale       bool
+	StaleSince  *time.Time // when the symbol was first seen stale by this replica
+	Reason      string     // empty for fresh symbols
+}
+
+// Report is the outcome of checking the active tracked symbols
+type Report struct {
+	CheckedAt time.Time
+	Symbols   []SymbolFreshness // stale symbols first, then by symbol
+	Stale     int
+}
+
+// barDurations orders the bar intervals from the finest to the coarsest
+var barDurations = map[yahoo.IntervalAPI]time.Duration{
+	yahoo.Interval1m:  time.Minute,
+	yahoo.Interval5m:  5 * time.Minute,
+	yahoo.Interval15m: 15 * time.Minute,
+	yahoo.Interval30m: 30 * time.Minute,
+	yahoo.Interval1h:  time.Hour,
+	yahoo.Interval1d:  24 * time.Hour,
+	yahoo.Interval1wk: 7 * 24 * time.Hour,
+	yahoo.Interval1mo: 31 * 24 * time.Hour,
+}
+
+// finestInterval returns the finest of the intervals, unknown ones are ignored
+func finestInterval(intervals []string) (yahoo.IntervalAPI, bool) {
+	var finest yahoo.IntervalAPI
+	for _, interval := range intervals {
+		duration, ok := barDurations[yahoo.IntervalAPI(interval)]
+		if ok && (finest == "" || duration < barDurations[finest]) {
+			finest = yahoo.IntervalAPI(interval)
+		}
+	}
+	return finest, finest != ""
+}
+
+// barStart returns the start of the bar of an interval containing t. Intraday bars are aligned to their
+// length, daily and longer bars to UTC days, weeks starting on Monday and months.
+func barStart(interval yahoo.IntervalAPI, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case yahoo.Interval1wk:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case yahoo.Interval1mo:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case yahoo.Interval1d:
+		return day
+	default:
+		return t.Truncate(barDurations[interval])
+	}
+}
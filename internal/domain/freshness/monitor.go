@@ -0,0 +1,185 @@
+package freshness
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
+)
+
+// TrackedSymbols lists the tracked symbols, implemented by the tracking service
+type TrackedSymbols interface {
+	ListTrackedSymbols(ctx context.Context, activeOnly bool) ([]tracking.TrackedSymbol, error)
+}
+
+// Settings configures a Monitor
+type Settings struct {
+	DefaultProvider string        // provider of tracked symbols without one
+	Grace           time.Duration // lag tolerated on top of the refresh interval of a symbol
+	MaxAge          time.Duration // age up to which Latest reuses the last report
+}
+
+// Monitor checks that the active tracked symbols keep updating. A symbol is stale when its latest bar is
+// older than the bar its finest interval should have produced by the last time its market was open, allowing
+// for its refresh interval and a grace period. Markets follow the calendar of the provider of the symbol,
+// the NYSE sessions for providers without one.
+type Monitor struct {
+	repo      Repository
+	tracked   TrackedSymbols
+	settings  Settings
+	calendars map[string]calendar.Calendar
+	now       func() time.Time
+
+	mu         sync.Mutex
+	last       *Report
+	staleSince map[string]time.Time
+}
+
+// NewMonitor creates a freshness monitor
+func NewMonitor(repo Repository, tracked TrackedSymbols, settings Settings) *Monitor {
+	return &Monitor{
+		repo:       repo,
+		tracked:    tracked,
+		settings:   settings,
+		calendars:  make(map[string]calendar.Calendar),
+		now:        time.Now,
+		staleSince: make(map[string]time.Time),
+	}
+}
+
+// SetClock replaces the clock of the monitor, e.g. with a fixed time in tests
+func (m *Monitor) SetClock(now func() time.Time) {
+	m.now = now
+}
+
+// SetCalendar sets the trading calendar of the symbols tracked with a provider
+func (m *Monitor) SetCalendar(provider string, cal calendar.Calendar) {
+	m.calendars[provider] = cal
+}
+
+// Latest returns the last report when it is younger than the configured max age, otherwise checks again
+func (m *Monitor) Latest(ctx context.Context) (*Report, error) {
+	m.mu.Lock()
+	last := m.last
+	m.mu.Unlock()
+	if last != nil && m.now().Sub(last.CheckedAt) < m.settings.MaxAge {
+		return last, nil
+	}
+	return m.Check(ctx)
+}
+
+// Check compares the latest bar of every active tracked symbol against its cadence. Symbols turning stale
+// are logged as warnings, symbols updating again as infos.
+func (m *Monitor) Check(ctx context.Context) (*Report, error) {
+	tracked, err := m.tracked.ListTrackedSymbols(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	// the finest interval sets the cadence, symbols without a known interval are skipped
+	intervals := make(map[string]yahoo.IntervalAPI, len(tracked))
+	for _, t := range tracked {
+		if interval, ok := finestInterval(t.Intervals); ok {
+			intervals[t.Symbol] = interval
+		}
+	}
+	latest, err := m.repo.GetLatestBars(ctx, intervals)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	report := &Report{CheckedAt: now, Symbols: make([]SymbolFreshness, 0, len(tracked))}
+	for i := range tracked {
+		interval, ok := intervals[tracked[i].Symbol]
+		if !ok {
+			continue
+		}
+		freshness := m.check(&tracked[i], interval, latest, now)
+		if freshness.Stale {
+			report.Stale++
+		}
+		report.Symbols = append(report.Symbols, freshness)
+	}
+	slices.SortFunc(report.Symbols, func(a, b SymbolFreshness) int {
+		if a.Stale != b.Stale {
+			if a.Stale {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	m.flag(report)
+	return report, nil
+}
+
+// check computes the freshness of a tracked symbol from the latest bar of its finest interval
+func (m *Monitor) check(t *tracking.TrackedSymbol, interval yahoo.IntervalAPI, latest map[string]time.Time,
+	now time.Time) SymbolFreshness {
+	provider := t.Provider
+	if provider == "" {
+		provider = m.settings.DefaultProvider
+	}
+	cal, ok := m.calendars[provider]
+	if !ok {
+		cal = calendar.NYSE()
+	}
+
+	// the bar of the last session the refreshes had time to collect. After a session LastOpen is its close,
+	// which starts no bar of the session, so the expected bar is the one containing the instant before.
+	deadline := now.Add(-t.RefreshInterval() - m.settings.Grace)
+	freshness := SymbolFreshness{
+		Symbol:      t.Symbol,
+		Provider:    provider,
+		Interval:    interval,
+		ExpectedBar: barStart(interval, cal.LastOpen(deadline).Add(-time.Nanosecond)),
+	}
+	bar, ok := latest[t.Symbol]
+	switch {
+	case !ok:
+		freshness.Stale, freshness.Reason = true, ReasonNoData
+	case bar.Before(freshness.ExpectedBar):
+		freshness.Stale, freshness.Reason = true, ReasonLagged
+	}
+	if ok {
+		lag := now.Sub(bar)
+		freshness.LatestBar, freshness.Lag = &bar, &lag
+	}
+	return freshness
+}
+
+// flag records since when the symbols of a report are stale and keeps the report for Latest
+func (m *Monitor) flag(report *Report) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stale := make(map[string]time.Time, report.Stale)
+	for i := range report.Symbols {
+		freshness := &report.Symbols[i]
+		if !freshness.Stale {
+			if _, ok := m.staleSince[freshness.Symbol]; ok {
+				log.Info().Str("symbol", freshness.Symbol).Msg("Tracked symbol is updating again")
+			}
+			continue
+		}
+		since, ok := m.staleSince[freshness.Symbol]
+		if !ok {
+			since = report.CheckedAt
+			log.Warn().
+				Str("symbol", freshness.Symbol).
+				Str("reason", freshness.Reason).
+				Time("expectedBar", freshness.ExpectedBar).
+				Msg("Tracked symbol is stale")
+		}
+		stale[freshness.Symbol] = since
+		freshness.StaleSince = &since
+	}
+	m.staleSince = stale
+	m.last = report
+}
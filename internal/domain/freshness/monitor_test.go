@@ -0,0 +1,159 @@
+package freshness_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	latest  map[string]time.Time
+	queries int
+}
+
+func (r *fakeRepository) GetLatestBars(_ context.Context,
+	intervals map[string]yahoo.IntervalAPI) (map[string]time.Time, error) {
+	r.queries++
+	latest := make(map[string]time.Time)
+	for symbol := range intervals {
+		if bar, ok := r.latest[symbol]; ok {
+			latest[symbol] = bar
+		}
+	}
+	return latest, nil
+}
+
+type fakeTracked []tracking.TrackedSymbol
+
+func (f fakeTracked) ListTrackedSymbols(_ context.Context, activeOnly bool) ([]tracking.TrackedSymbol, error) {
+	var tracked []tracking.TrackedSymbol
+	for _, t := range f {
+		if t.Active || !activeOnly {
+			tracked = append(tracked, t)
+		}
+	}
+	return tracked, nil
+}
+
+func TestMonitor_Check(t *testing.T) {
+	// a Wednesday, 14:00 in New York
+	now := time.Date(2025, time.June, 11, 18, 0, 0, 0, time.UTC)
+	// the daily bar of the session the refreshes of STOCK had time to collect
+	sessionBar := time.Date(2025, time.June, 11, 0, 0, 0, 0, time.UTC)
+
+	repo := &fakeRepository{latest: map[string]time.Time{
+		"FRESH":  now.Add(-10 * time.Minute),
+		"LAGGED": now.Add(-2 * time.Hour),
+		"DAILY":  now.Add(-time.Hour),
+		"STOCK":  sessionBar.Add(13*time.Hour + 30*time.Minute),
+		"OLD":    sessionBar.AddDate(0, 0, -7),
+		"PAUSED": now.AddDate(0, 0, -30),
+	}}
+	tracked := fakeTracked{
+		{Symbol: "FRESH", Intervals: []string{"1d", "5m"}, RefreshMinutes: 15, Provider: "binance", Active: true},
+		{Symbol: "LAGGED", Intervals: []string{"5m"}, RefreshMinutes: 15, Provider: "binance", Active: true},
+		{Symbol: "DAILY", Intervals: []string{"1d"}, RefreshMinutes: 60, Provider: "binance", Active: true},
+		{Symbol: "NODATA", Intervals: []string{"1d"}, RefreshMinutes: 60, Provider: "binance", Active: true},
+		{Symbol: "STOCK", Intervals: []string{"1d"}, RefreshMinutes: 60, Active: true},
+		{Symbol: "OLD", Intervals: []string{"1d"}, RefreshMinutes: 60, Active: true},
+		{Symbol: "PAUSED", Intervals: []string{"1d"}, RefreshMinutes: 60, Provider: "binance"},
+	}
+	monitor := freshness.NewMonitor(repo, tracked, freshness.Settings{
+		DefaultProvider: yahoo.Provider,
+		Grace:           30 * time.Minute,
+		MaxAge:          time.Minute,
+	})
+	monitor.SetCalendar("binance", calendar.AlwaysOpen{})
+	monitor.SetClock(func() time.Time { return now })
+
+	report, err := monitor.Check(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Stale)
+
+	bySymbol := make(map[string]freshness.SymbolFreshness)
+	var order []string
+	for _, s := range report.Symbols {
+		bySymbol[s.Symbol] = s
+		order = append(order, s.Symbol)
+	}
+	assert.Equal(t, []string{"LAGGED", "NODATA", "OLD", "DAILY", "FRESH", "STOCK"}, order,
+		"stale symbols come first, inactive ones are not checked")
+
+	assert.False(t, bySymbol["FRESH"].Stale)
+	assert.Equal(t, yahoo.Interval5m, bySymbol["FRESH"].Interval, "the finest interval sets the cadence")
+	assert.Nil(t, bySymbol["FRESH"].StaleSince)
+
+	lagged := bySymbol["LAGGED"]
+	assert.True(t, lagged.Stale)
+	assert.Equal(t, freshness.ReasonLagged, lagged.Reason)
+	require.NotNil(t, lagged.Lag)
+	assert.InDelta(t, 2*time.Hour, *lagged.Lag, float64(time.Second))
+	require.NotNil(t, lagged.StaleSince)
+	assert.True(t, report.CheckedAt.Equal(*lagged.StaleSince))
+
+	assert.Equal(t, freshness.ReasonNoData, bySymbol["NODATA"].Reason)
+	assert.Nil(t, bySymbol["NODATA"].LatestBar)
+
+	// symbols of providers without a calendar follow the NYSE sessions
+	assert.Equal(t, yahoo.Provider, bySymbol["STOCK"].Provider)
+	assert.False(t, bySymbol["STOCK"].Stale)
+	assert.True(t, sessionBar.Equal(bySymbol["STOCK"].ExpectedBar))
+	assert.True(t, bySymbol["OLD"].Stale)
+
+	// stale symbols keep the time they were first seen stale
+	repo.latest["LAGGED"] = now.Add(-3 * time.Hour)
+	repo.latest["OLD"] = repo.latest["STOCK"]
+	again, err := monitor.Check(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 2, again.Stale)
+	assert.Equal(t, "LAGGED", again.Symbols[0].Symbol)
+	assert.True(t, report.CheckedAt.Equal(*again.Symbols[0].StaleSince))
+}
+
+func TestMonitor_CheckAfterClose(t *testing.T) {
+	// a Wednesday, 18:00 in New York, the session closed at 16:00 (20:00 UTC)
+	now := time.Date(2025, time.June, 11, 22, 0, 0, 0, time.UTC)
+	lastBar := time.Date(2025, time.June, 11, 19, 55, 0, 0, time.UTC)
+
+	repo := &fakeRepository{latest: map[string]time.Time{"INTRA": lastBar, "DAILY": lastBar.Truncate(24 * time.Hour)}}
+	tracked := fakeTracked{
+		{Symbol: "INTRA", Intervals: []string{"5m"}, RefreshMinutes: 15, Active: true},
+		{Symbol: "DAILY", Intervals: []string{"1d"}, RefreshMinutes: 15, Active: true},
+	}
+	monitor := freshness.NewMonitor(repo, tracked, freshness.Settings{Grace: 30 * time.Minute})
+	monitor.SetClock(func() time.Time { return now })
+
+	report, err := monitor.Check(context.TODO())
+	require.NoError(t, err)
+	assert.Zero(t, report.Stale)
+	require.Len(t, report.Symbols, 2)
+	// the last bar of the session starts before the close
+	assert.True(t, lastBar.Equal(report.Symbols[1].ExpectedBar))
+	assert.True(t, lastBar.Truncate(24*time.Hour).Equal(report.Symbols[0].ExpectedBar))
+}
+
+func TestMonitor_Latest(t *testing.T) {
+	repo := &fakeRepository{latest: map[string]time.Time{}}
+	tracked := fakeTracked{{Symbol: "AAPL", Intervals: []string{"1d"}, RefreshMinutes: 60, Active: true}}
+	monitor := freshness.NewMonitor(repo, tracked, freshness.Settings{MaxAge: time.Hour})
+
+	report, err := monitor.Latest(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Stale)
+
+	cached, err := monitor.Latest(context.TODO())
+	require.NoError(t, err)
+	assert.Same(t, report, cached, "reports younger than the max age are reused")
+	assert.Equal(t, 1, repo.queries)
+
+	_, err = monitor.Check(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.queries, "checks always query")
+}
@@ -0,0 +1,76 @@
+package freshness
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the queries of the freshness monitor
+type Repository interface {
+	// GetLatestBars returns the time of the latest stored bar of each symbol in the interval mapped to it,
+	// symbols without bars of their interval are missing
+	GetLatestBars(ctx context.Context, intervals map[string]yahoo.IntervalAPI) (map[string]time.Time, error)
+}
+
+// FreshnessRepository reads the latest bars of symbols from PostgreSQL
+type FreshnessRepository struct {
+	db *database.DB
+}
+
+// NewFreshnessRepository creates a new freshness repository
+func NewFreshnessRepository(db *database.DB) *FreshnessRepository {
+	return &FreshnessRepository{
+		db: db,
+	}
+}
+
+type latestBar struct {
+	Symbol string    `db:"symbol"`
+	Time   time.Time `db:"time"`
+}
+
+// GetLatestBars returns the time of the latest stored bar of each symbol in the interval mapped to it, symbols
+// without bars of their interval are missing
+func (r *FreshnessRepository) GetLatestBars(ctx context.Context,
+	intervals map[string]yahoo.IntervalAPI) (map[string]time.Time, error) {
+	symbols := make([]string, 0, len(intervals))
+	barIntervals := make([]string, 0, len(intervals))
+	for symbol, interval := range intervals {
+		symbols = append(symbols, symbol)
+		barIntervals = append(barIntervals, string(interval))
+	}
+	// one index lookup per symbol instead of aggregating its whole history
+	query := `
+		SELECT s.symbol, p.time
+		FROM unnest($1::text[], $2::text[]) AS t(symbol, bar_interval)
+		JOIN symbols s ON s.symbol = t.symbol
+		CROSS JOIN LATERAL (
+			SELECT time
+			FROM stock_prices
+			WHERE symbol_id = s.id AND bar_interval = t.bar_interval
+			ORDER BY time DESC
+			LIMIT 1
+		) p
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbols, barIntervals)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query latest bars")
+	}
+
+	bars, err := pgx.CollectRows(rows, pgx.RowToStructByName[latestBar])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect latest bar rows")
+	}
+
+	latest := make(map[string]time.Time, len(bars))
+	for _, bar := range bars {
+		latest[bar.Symbol] = bar.Time
+	}
+	return latest, nil
+}
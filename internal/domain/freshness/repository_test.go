@@ -0,0 +1,56 @@
+package freshness_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreshnessRepository_GetLatestBars(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.TODO()
+	latest := time.Now().UTC().Truncate(time.Hour)
+	marketRepo := market.NewMarketRepository(db)
+	_, err = marketRepo.SaveMarketData(ctx, &yahoo.MarketData{
+		Symbol: "AAPL", Name: "Apple Inc.", Exchange: "NMS",
+		Prices: []yahoo.StockPrice{
+			{Time: latest.Add(-time.Hour), Close: 200},
+			{Time: latest, Close: 201},
+		},
+	})
+	require.NoError(t, err)
+	_, err = marketRepo.SaveMarketData(ctx, &yahoo.MarketData{
+		Symbol: "AAPL", Name: "Apple Inc.", Exchange: "NMS", Interval: yahoo.Interval5m,
+		Prices: []yahoo.StockPrice{{Time: latest.Add(-5 * time.Minute), Close: 201}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, marketRepo.SaveSymbol(ctx, &market.Symbol{Symbol: "MSFT", Name: "Microsoft", Exchange: "NMS"}))
+
+	repo := freshness.NewFreshnessRepository(db)
+	bars, err := repo.GetLatestBars(ctx, map[string]yahoo.IntervalAPI{
+		"AAPL": yahoo.Interval1d, "MSFT": yahoo.Interval1d, "NOPE": yahoo.Interval1d,
+	})
+	require.NoError(t, err)
+	require.Len(t, bars, 1, "symbols without bars are missing")
+	assert.True(t, latest.Equal(bars["AAPL"]))
+
+	bars, err = repo.GetLatestBars(ctx, map[string]yahoo.IntervalAPI{"AAPL": yahoo.Interval5m})
+	require.NoError(t, err)
+	assert.True(t, latest.Add(-5*time.Minute).Equal(bars["AAPL"]), "bars of other intervals are ignored")
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"errors"
+	"time"
+)
+
+// Domain errors
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobNotDead     = errors.New("job is not dead-lettered")
+	ErrUnknownJobType = errors.New("unknown job type")
+	ErrLeaseLost      = errors.New("job lease lost")
+)
+
+// Job types
+const (
+	TypeBackfill = "backfill" // initial history of a newly registered symbol
+	TypeRefresh  = "refresh"  // fetch of a symbol requested by an operator
+)
+
+// Job priorities, jobs with a higher priority are claimed first
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// Status is the state of a job
+type Status string
+
+// Job states. A queued job becomes running when a worker claims it; a failed run puts it back to queued
+// with a later start until its attempts are used up and it is dead-lettered.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusDead      Status = "dead"
+)
+
+// Job represents a unit of background work on a symbol.
+type Job struct {
+	ID          int64      `db:"id"`
+	Type        string     `db:"job_type"`
+	Symbol      string     `db:"symbol"`
+	Priority    int        `db:"priority"`
+	Status      Status     `db:"status"`
+	Attempts    int        `db:"attempts"`
+	MaxAttempts int        `db:"max_attempts"`
+	RunAt       time.Time  `db:"run_at"`
+	LockedBy    *string    `db:"locked_by"`
+	Error       *string    `db:"last_error"` // error of the last failed run
+	CreatedAt   time.Time  `db:"created_at"`
+	StartedAt   *time.Time `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+}
+
+// Done reports whether the job finished, successfully or dead-lettered.
+func (j *Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusDead
+}
+
+// Filter selects jobs to list, zero fields match every job
+type Filter struct {
+	Status Status
+	Symbol string
+	Limit  int
+}
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Handler executes the work of a job
+type Handler func(ctx context.Context, job *Job) error
+
+// Settings configure how a queue runs and retries jobs
+type Settings struct {
+	Workers      int           // jobs run in parallel by this process
+	PollInterval time.Duration // wait before looking for jobs again when none is runnable
+	Timeout      time.Duration // maximum duration of a run, zero means no limit
+	MaxAttempts  int           // runs before a job is dead-lettered
+	BackoffBase  time.Duration // wait before the second run, doubled for every further run
+	BackoffMax   time.Duration // longest wait between two runs
+}
+
+// Queue runs jobs persisted in the repository on a fixed number of workers. Several processes can share
+// a queue, every job is claimed by a single worker at a time.
+type Queue struct {
+	repo     Repository
+	settings Settings
+	worker   string
+	handlers map[string]Handler
+	wake     chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewQueue creates a queue on a job repository
+func NewQueue(repo Repository, settings Settings) *Queue {
+	hostname, _ := os.Hostname()
+	return &Queue{
+		repo:     repo,
+		settings: settings,
+		worker:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// RegisterHandler sets the handler of a job type, handlers must be registered before Start
+func (q *Queue) RegisterHandler(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+// Enqueue creates a queued job, picked up by the next idle worker of any process.
+func (q *Queue) Enqueue(ctx context.Context, jobType, symbol string, priority int) (*Job, error) {
+	if _, ok := q.handlers[jobType]; !ok {
+		return nil, eris.Wrapf(ErrUnknownJobType, "job type %s", jobType)
+	}
+
+	job := &Job{Type: jobType, Symbol: symbol, Priority: priority, MaxAttempts: max(q.settings.MaxAttempts, 1)}
+	if err := q.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	log.Info().Int64("job", job.ID).Str("type", jobType).Str("symbol", symbol).Msg("Job enqueued")
+	return job, nil
+}
+
+// GetJob returns the current state of a job, ErrJobNotFound if it is unknown.
+func (q *Queue) GetJob(ctx context.Context, id int64) (*Job, error) {
+	return q.repo.GetJob(ctx, id)
+}
+
+// ListJobs returns the jobs matching the filter, most recent first.
+func (q *Queue) ListJobs(ctx context.Context, filter Filter) ([]Job, error) {
+	return q.repo.ListJobs(ctx, filter)
+}
+
+// Requeue queues a dead-lettered job again with fresh attempts.
+func (q *Queue) Requeue(ctx context.Context, id int64) (*Job, error) {
+	job, err := q.repo.RequeueJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Purge deletes the succeeded jobs finished longer than the retention ago.
+func (q *Queue) Purge(ctx context.Context, retention time.Duration) error {
+	purged, err := q.repo.PurgeJobs(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Info().Int64("jobs", purged).Msg("Finished jobs purged")
+	}
+	return nil
+}
+
+// Start runs the workers until Stop is called
+func (q *Queue) Start(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+	for range max(q.settings.Workers, 1) {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Stop cancels the running jobs and waits for the workers to finish. Interrupted jobs are retried.
+func (q *Queue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+	q.wg.Wait()
+	log.Info().Msg("Job queue stopped")
+}
+
+// lease is how long a run may take before other workers consider its worker dead and take the job over
+func (q *Queue) lease() time.Duration {
+	if q.settings.Timeout > 0 {
+		return q.settings.Timeout + time.Minute
+	}
+	return time.Hour
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		job, err := q.repo.ClaimJob(ctx, q.worker, q.lease())
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to claim job")
+		}
+		if job != nil {
+			q.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-q.wake:
+		case <-time.After(q.settings.PollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) {
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// taken over after the lease of its last attempt expired
+		err = eris.New("job lease expired")
+	} else {
+		runCtx := ctx
+		if q.settings.Timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, q.settings.Timeout)
+			defer cancel()
+		}
+		err = q.execute(runCtx, job)
+	}
+	q.finish(ctx, job, err)
+}
+
+// execute runs the handler of a job, turning a panic into an error so a broken job does not stop its worker
+func (q *Queue) execute(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return eris.Wrapf(ErrUnknownJobType, "job type %s", job.Type)
+	}
+	return handler(ctx, job)
+}
+
+// backoff returns the wait before the run following the given attempt
+func (q *Queue) backoff(attempt int) time.Duration {
+	wait := q.settings.BackoffBase
+	for i := 1; i < attempt && wait < q.settings.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, q.settings.BackoffMax)
+}
+
+func (q *Queue) finish(ctx context.Context, job *Job, err error) {
+	// the state is saved also when the queue is stopping, so the job does not stay running
+	ctx = context.WithoutCancel(ctx)
+
+	logger := log.With().
+		Int64("job", job.ID).
+		Str("type", job.Type).
+		Str("symbol", job.Symbol).
+		Int("attempt", job.Attempts).
+		Logger()
+
+	var saveErr error
+	switch {
+	case err == nil:
+		saveErr = q.repo.CompleteJob(ctx, job)
+		logger.Info().Msg("Job succeeded")
+	case job.Attempts >= job.MaxAttempts:
+		saveErr = q.repo.KillJob(ctx, job, err.Error())
+		logger.Error().Err(err).Msg("Job dead-lettered")
+	default:
+		wait := q.backoff(job.Attempts)
+		saveErr = q.repo.RetryJob(ctx, job, err.Error(), time.Now().Add(wait))
+		logger.Warn().Err(err).Dur("retryIn", wait).Msg("Job failed, retrying")
+	}
+	switch {
+	case errors.Is(saveErr, ErrLeaseLost):
+		logger.Warn().Err(saveErr).Msg("Job lease lost, the state is left to the worker which took the job over")
+	case saveErr != nil:
+		logger.Error().Err(saveErr).Msg("Failed to save job state")
+	}
+}
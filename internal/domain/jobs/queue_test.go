@@ -0,0 +1,296 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository keeps jobs in memory, claiming them like the PostgreSQL repository
+type fakeRepository struct {
+	mu     sync.Mutex
+	jobs   map[int64]*jobs.Job
+	nextID int64
+	claims []string // symbols in claim order
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{jobs: make(map[int64]*jobs.Job)}
+}
+
+func (r *fakeRepository) CreateJob(_ context.Context, job *jobs.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	job.ID = r.nextID
+	job.Status = jobs.StatusQueued
+	job.RunAt = time.Now()
+	job.CreatedAt = time.Now()
+	stored := *job
+	r.jobs[job.ID] = &stored
+	return nil
+}
+
+func (r *fakeRepository) GetJob(_ context.Context, id int64) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, jobs.ErrJobNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+func (r *fakeRepository) ListJobs(_ context.Context, filter jobs.Filter) ([]jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []jobs.Job
+	for _, job := range r.jobs {
+		if (filter.Status == "" || job.Status == filter.Status) && (filter.Symbol == "" || job.Symbol == filter.Symbol) {
+			list = append(list, *job)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepository) ClaimJob(_ context.Context, worker string, _ time.Duration) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var runnable []*jobs.Job
+	for _, job := range r.jobs {
+		if job.Status == jobs.StatusQueued && !job.RunAt.After(time.Now()) {
+			runnable = append(runnable, job)
+		}
+	}
+	if len(runnable) == 0 {
+		return nil, nil
+	}
+	sort.Slice(runnable, func(i, j int) bool {
+		if runnable[i].Priority != runnable[j].Priority {
+			return runnable[i].Priority > runnable[j].Priority
+		}
+		return runnable[i].ID < runnable[j].ID
+	})
+	job := runnable[0]
+	job.Status = jobs.StatusRunning
+	job.Attempts++
+	job.LockedBy = &worker
+	now := time.Now()
+	job.StartedAt = &now
+	r.claims = append(r.claims, job.Symbol)
+	claimed := *job
+	return &claimed, nil
+}
+
+func (r *fakeRepository) CompleteJob(_ context.Context, claimed *jobs.Job) error {
+	return r.finish(claimed, func(job *jobs.Job) {
+		job.Status = jobs.StatusSucceeded
+		now := time.Now()
+		job.FinishedAt = &now
+	})
+}
+
+func (r *fakeRepository) RetryJob(_ context.Context, claimed *jobs.Job, msg string, runAt time.Time) error {
+	return r.finish(claimed, func(job *jobs.Job) {
+		job.Status = jobs.StatusQueued
+		job.Error = &msg
+		job.RunAt = runAt
+	})
+}
+
+func (r *fakeRepository) KillJob(_ context.Context, claimed *jobs.Job, msg string) error {
+	return r.finish(claimed, func(job *jobs.Job) {
+		job.Status = jobs.StatusDead
+		job.Error = &msg
+		now := time.Now()
+		job.FinishedAt = &now
+	})
+}
+
+func (r *fakeRepository) RequeueJob(_ context.Context, id int64) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, jobs.ErrJobNotFound
+	}
+	if job.Status != jobs.StatusDead {
+		return nil, jobs.ErrJobNotDead
+	}
+	job.Status = jobs.StatusQueued
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	job.FinishedAt = nil
+	requeued := *job
+	return &requeued, nil
+}
+
+func (r *fakeRepository) PurgeJobs(_ context.Context, finishedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged int64
+	for id, job := range r.jobs {
+		if job.Status == jobs.StatusSucceeded && job.FinishedAt.Before(finishedBefore) {
+			delete(r.jobs, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *fakeRepository) finish(claimed *jobs.Job, change func(job *jobs.Job)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[claimed.ID]
+	if !ok || job.Status != jobs.StatusRunning || job.Attempts != claimed.Attempts {
+		return jobs.ErrLeaseLost
+	}
+	job.LockedBy = nil
+	change(job)
+	return nil
+}
+
+func waitDone(t *testing.T, queue *jobs.Queue, id int64) *jobs.Job {
+	t.Helper()
+	var job *jobs.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = queue.GetJob(context.TODO(), id)
+		require.NoError(t, err)
+		return job.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+var testSettings = jobs.Settings{
+	Workers:      2,
+	PollInterval: 10 * time.Millisecond,
+	Timeout:      200 * time.Millisecond,
+	MaxAttempts:  3,
+	BackoffBase:  10 * time.Millisecond,
+	BackoffMax:   20 * time.Millisecond,
+}
+
+func TestQueue(t *testing.T) {
+	queue := jobs.NewQueue(newFakeRepository(), testSettings)
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	queue.RegisterHandler(jobs.TypeBackfill, func(ctx context.Context, job *jobs.Job) error {
+		mu.Lock()
+		runs[job.Symbol]++
+		mu.Unlock()
+		switch job.Symbol {
+		case "FLAKY":
+			if job.Attempts < 2 {
+				return errors.New("provider unavailable")
+			}
+		case "FAIL":
+			return errors.New("provider unavailable")
+		case "PANIC":
+			panic("broken handler")
+		case "HANG":
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	queue.Start(context.Background())
+	defer queue.Stop()
+	ctx := context.TODO()
+
+	ok, err := queue.Enqueue(ctx, jobs.TypeBackfill, "AAPL", jobs.PriorityNormal)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, ok.Status)
+	assert.Equal(t, 3, ok.MaxAttempts)
+	flaky, err := queue.Enqueue(ctx, jobs.TypeBackfill, "FLAKY", jobs.PriorityNormal)
+	require.NoError(t, err)
+	failed, err := queue.Enqueue(ctx, jobs.TypeBackfill, "FAIL", jobs.PriorityNormal)
+	require.NoError(t, err)
+	panicked, err := queue.Enqueue(ctx, jobs.TypeBackfill, "PANIC", jobs.PriorityNormal)
+	require.NoError(t, err)
+	hung, err := queue.Enqueue(ctx, jobs.TypeBackfill, "HANG", jobs.PriorityNormal)
+	require.NoError(t, err)
+
+	job := waitDone(t, queue, ok.ID)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Nil(t, job.Error)
+
+	job = waitDone(t, queue, flaky.ID)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status, "failed runs are retried")
+	assert.Equal(t, 2, job.Attempts)
+
+	job = waitDone(t, queue, failed.ID)
+	assert.Equal(t, jobs.StatusDead, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	require.NotNil(t, job.Error)
+	assert.Equal(t, "provider unavailable", *job.Error)
+
+	job = waitDone(t, queue, panicked.ID)
+	assert.Equal(t, jobs.StatusDead, job.Status)
+	assert.Contains(t, *job.Error, "broken handler")
+
+	job = waitDone(t, queue, hung.ID)
+	assert.Equal(t, jobs.StatusDead, job.Status, "jobs are stopped at the timeout")
+
+	mu.Lock()
+	assert.Equal(t, 3, runs["FAIL"])
+	mu.Unlock()
+
+	dead, err := queue.ListJobs(ctx, jobs.Filter{Status: jobs.StatusDead})
+	require.NoError(t, err)
+	assert.Len(t, dead, 3)
+
+	_, err = queue.Requeue(ctx, ok.ID)
+	assert.ErrorIs(t, err, jobs.ErrJobNotDead)
+	requeued, err := queue.Requeue(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, requeued.Status)
+	job = waitDone(t, queue, failed.ID)
+	assert.Equal(t, jobs.StatusDead, job.Status)
+	mu.Lock()
+	assert.Equal(t, 6, runs["FAIL"], "requeued jobs get fresh attempts")
+	mu.Unlock()
+
+	require.NoError(t, queue.Purge(ctx, 0))
+	_, err = queue.GetJob(ctx, ok.ID)
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound, "succeeded jobs are purged")
+	_, err = queue.GetJob(ctx, failed.ID)
+	assert.NoError(t, err, "dead jobs are kept")
+
+	_, err = queue.Enqueue(ctx, "unknown", "AAPL", jobs.PriorityNormal)
+	assert.ErrorIs(t, err, jobs.ErrUnknownJobType)
+}
+
+func TestQueue_Priority(t *testing.T) {
+	repo := newFakeRepository()
+	settings := testSettings
+	settings.Workers = 1
+	queue := jobs.NewQueue(repo, settings)
+	queue.RegisterHandler(jobs.TypeBackfill, func(context.Context, *jobs.Job) error { return nil })
+	ctx := context.TODO()
+
+	// enqueued before the workers start, so all are runnable at the first claim
+	low, err := queue.Enqueue(ctx, jobs.TypeBackfill, "LOW", jobs.PriorityLow)
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, jobs.TypeBackfill, "NORMAL", jobs.PriorityNormal)
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, jobs.TypeBackfill, "HIGH", jobs.PriorityHigh)
+	require.NoError(t, err)
+
+	queue.Start(context.Background())
+	defer queue.Stop()
+	waitDone(t, queue, low.ID)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, []string{"HIGH", "NORMAL", "LOW"}, repo.claims)
+}
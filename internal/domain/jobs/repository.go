@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the persistence operations of the job queue
+type Repository interface {
+	CreateJob(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, id int64) (*Job, error)
+	ListJobs(ctx context.Context, filter Filter) ([]Job, error)
+	ClaimJob(ctx context.Context, worker string, lease time.Duration) (*Job, error)
+	// CompleteJob, RetryJob and KillJob finish the run of a claimed job. They return ErrLeaseLost when the
+	// job is no longer running under that claim, e.g. because another worker took it over.
+	CompleteJob(ctx context.Context, job *Job) error
+	RetryJob(ctx context.Context, job *Job, msg string, runAt time.Time) error
+	KillJob(ctx context.Context, job *Job, msg string) error
+	RequeueJob(ctx context.Context, id int64) (*Job, error)
+	PurgeJobs(ctx context.Context, finishedBefore time.Time) (int64, error)
+}
+
+const jobColumns = `
+	id, job_type, symbol, priority, status, attempts, max_attempts, run_at, locked_by, last_error,
+	created_at, started_at, finished_at
+`
+
+// JobRepository persists the job queue in PostgreSQL
+type JobRepository struct {
+	db *database.DB
+}
+
+// NewJobRepository creates a new job repository
+func NewJobRepository(db *database.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// CreateJob inserts a queued job and sets its id, state and timestamps.
+func (r *JobRepository) CreateJob(ctx context.Context, job *Job) error {
+	query := `
+		INSERT INTO fetch_jobs (job_type, symbol, priority, max_attempts)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + jobColumns
+
+	rows, err := r.db.QueryContext(ctx, query, job.Type, job.Symbol, job.Priority, job.MaxAttempts)
+	if err != nil {
+		return eris.Wrapf(err, "failed to create %s job for symbol: %s", job.Type, job.Symbol)
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Job])
+	if err != nil {
+		return eris.Wrapf(err, "failed to create %s job for symbol: %s", job.Type, job.Symbol)
+	}
+	*job = created
+	return nil
+}
+
+// GetJob retrieves a job. Returns ErrJobNotFound if not found.
+func (r *JobRepository) GetJob(ctx context.Context, id int64) (*Job, error) {
+	return r.queryJob(ctx, `SELECT `+jobColumns+` FROM fetch_jobs WHERE id = $1`, id)
+}
+
+// ListJobs retrieves the jobs matching the filter, most recent first.
+func (r *JobRepository) ListJobs(ctx context.Context, filter Filter) ([]Job, error) {
+	query := `SELECT ` + jobColumns + `
+		FROM fetch_jobs
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR symbol = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($3, 0)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(filter.Status), filter.Symbol, filter.Limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query jobs")
+	}
+
+	jobs, err := pgx.CollectRows(rows, pgx.RowToStructByName[Job])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect job rows")
+	}
+	return jobs, nil
+}
+
+// ClaimJob marks the next runnable job as running by the worker and returns it, nil when no job is runnable.
+// Runnable are queued jobs whose start time passed, by priority and start time, and running jobs whose lease
+// expired, e.g. because their worker died. Rows locked by other workers are skipped, so concurrent workers
+// across replicas claim different jobs.
+func (r *JobRepository) ClaimJob(ctx context.Context, worker string, lease time.Duration) (*Job, error) {
+	query := `
+		UPDATE fetch_jobs
+		SET status = 'running',
+			attempts = attempts + 1,
+			locked_by = $1,
+			locked_at = now(),
+			started_at = COALESCE(started_at, now())
+		WHERE id = (
+			SELECT id
+			FROM fetch_jobs
+			WHERE (status = 'queued' AND run_at <= now())
+			   OR (status = 'running' AND locked_at < now() - make_interval(secs => $2))
+			ORDER BY priority DESC, run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	job, err := r.queryJob(ctx, query, worker, lease.Seconds())
+	if errors.Is(err, ErrJobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to claim job")
+	}
+	return job, nil
+}
+
+// CompleteJob marks a claimed job as succeeded. Returns ErrLeaseLost when the claim expired and the job was
+// taken over or finished since.
+func (r *JobRepository) CompleteJob(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE fetch_jobs
+		SET status = 'succeeded', locked_by = NULL, locked_at = NULL, finished_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3
+	`
+	return r.finish(ctx, job, query)
+}
+
+// RetryJob records a failed run of a claimed job and queues it again, starting at runAt at the earliest.
+// Returns ErrLeaseLost when the claim expired and the job was taken over or finished since.
+func (r *JobRepository) RetryJob(ctx context.Context, job *Job, msg string, runAt time.Time) error {
+	query := `
+		UPDATE fetch_jobs
+		SET status = 'queued', locked_by = NULL, locked_at = NULL, last_error = $4, run_at = $5
+		WHERE id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3
+	`
+	return r.finish(ctx, job, query, msg, runAt)
+}
+
+// KillJob records a failed run of a claimed job and dead-letters it. Returns ErrLeaseLost when the claim
+// expired and the job was taken over or finished since.
+func (r *JobRepository) KillJob(ctx context.Context, job *Job, msg string) error {
+	query := `
+		UPDATE fetch_jobs
+		SET status = 'dead', locked_by = NULL, locked_at = NULL, last_error = $4, finished_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3
+	`
+	return r.finish(ctx, job, query, msg)
+}
+
+// RequeueJob queues a dead-lettered job again with fresh attempts. Returns ErrJobNotFound for unknown jobs
+// and ErrJobNotDead for jobs which are not dead-lettered.
+func (r *JobRepository) RequeueJob(ctx context.Context, id int64) (*Job, error) {
+	query := `
+		UPDATE fetch_jobs
+		SET status = 'queued', attempts = 0, run_at = now(), finished_at = NULL
+		WHERE id = $1 AND status = 'dead'
+		RETURNING ` + jobColumns
+
+	job, err := r.queryJob(ctx, query, id)
+	if errors.Is(err, ErrJobNotFound) {
+		if _, getErr := r.GetJob(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrJobNotDead
+	}
+	return job, err
+}
+
+// PurgeJobs deletes the succeeded jobs finished before the time and returns their number. Dead-lettered jobs
+// are kept for review.
+func (r *JobRepository) PurgeJobs(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	query := `DELETE FROM fetch_jobs WHERE status = 'succeeded' AND finished_at < $1`
+	tag, err := r.db.ExecContext(ctx, query, finishedBefore)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to purge jobs")
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *JobRepository) queryJob(ctx context.Context, query string, args ...any) (*Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query job")
+	}
+	job, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Job])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, eris.Wrap(err, "cannot collect exactly one job row")
+	}
+	return &job, nil
+}
+
+// finish updates a job still running under the claim of job, the worker and the attempt it was claimed
+// for. Another claim of the job bumps its attempts, so a worker whose lease expired cannot overwrite the
+// state saved by the worker which took the job over, even within the same process.
+func (r *JobRepository) finish(ctx context.Context, job *Job, query string, args ...any) error {
+	if job.LockedBy == nil {
+		return eris.Wrapf(ErrLeaseLost, "job %d was not claimed", job.ID)
+	}
+	tag, err := r.db.ExecContext(ctx, query, append([]any{job.ID, *job.LockedBy, job.Attempts}, args...)...)
+	if err != nil {
+		return eris.Wrapf(err, "failed to update job: %d", job.ID)
+	}
+	if tag.RowsAffected() == 0 {
+		return eris.Wrapf(ErrLeaseLost, "job %d attempt %d", job.ID, job.Attempts)
+	}
+	return nil
+}
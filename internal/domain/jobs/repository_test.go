@@ -0,0 +1,110 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/jobs"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRepository(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := jobs.NewJobRepository(db)
+	ctx := context.TODO()
+
+	low := &jobs.Job{Type: jobs.TypeBackfill, Symbol: "AAPL", Priority: jobs.PriorityLow, MaxAttempts: 2}
+	require.NoError(t, repo.CreateJob(ctx, low))
+	assert.NotZero(t, low.ID)
+	assert.Equal(t, jobs.StatusQueued, low.Status)
+	high := &jobs.Job{Type: jobs.TypeBackfill, Symbol: "MSFT", Priority: jobs.PriorityHigh, MaxAttempts: 2}
+	require.NoError(t, repo.CreateJob(ctx, high))
+
+	// higher priorities first, claimed jobs are locked for other workers
+	claimed, err := repo.ClaimJob(ctx, "worker-1", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, high.ID, claimed.ID)
+	assert.Equal(t, jobs.StatusRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+	require.NotNil(t, claimed.LockedBy)
+	assert.Equal(t, "worker-1", *claimed.LockedBy)
+
+	lowClaim, err := repo.ClaimJob(ctx, "worker-2", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, lowClaim)
+	assert.Equal(t, low.ID, lowClaim.ID)
+
+	claimed, err = repo.ClaimJob(ctx, "worker-3", time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "no runnable job left")
+
+	// running jobs with an expired lease are taken over
+	expired, err := repo.GetJob(ctx, high.ID)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	claimed, err = repo.ClaimJob(ctx, "worker-3", time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, high.ID, claimed.ID)
+	assert.Equal(t, 2, claimed.Attempts)
+
+	// the worker whose lease expired cannot finish the job anymore
+	assert.ErrorIs(t, repo.CompleteJob(ctx, expired), jobs.ErrLeaseLost)
+	require.NoError(t, repo.CompleteJob(ctx, claimed))
+	assert.ErrorIs(t, repo.KillJob(ctx, claimed, "finished twice"), jobs.ErrLeaseLost)
+
+	require.NoError(t, repo.KillJob(ctx, lowClaim, "provider unavailable"))
+	dead, err := repo.ListJobs(ctx, jobs.Filter{Status: jobs.StatusDead, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, low.ID, dead[0].ID)
+	assert.NotNil(t, dead[0].FinishedAt)
+
+	_, err = repo.RequeueJob(ctx, high.ID)
+	assert.ErrorIs(t, err, jobs.ErrJobNotDead)
+	_, err = repo.RequeueJob(ctx, 42)
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound)
+	requeued, err := repo.RequeueJob(ctx, low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, requeued.Status)
+	assert.Zero(t, requeued.Attempts)
+	assert.Nil(t, requeued.FinishedAt)
+
+	// retried jobs wait for their start time
+	lowClaim, err = repo.ClaimJob(ctx, "worker-1", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, lowClaim)
+	require.NoError(t, repo.RetryJob(ctx, lowClaim, "provider unavailable", time.Now().Add(time.Hour)))
+	claimed, err = repo.ClaimJob(ctx, "worker-1", time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+	got, err := repo.GetJob(ctx, low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, got.Status)
+	require.NotNil(t, got.Error)
+	assert.Equal(t, "provider unavailable", *got.Error)
+	assert.ErrorIs(t, repo.RetryJob(ctx, lowClaim, "retried twice", time.Now()), jobs.ErrLeaseLost)
+
+	purged, err := repo.PurgeJobs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged, "only succeeded jobs are purged")
+	_, err = repo.GetJob(ctx, high.ID)
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound)
+
+	all, err := repo.ListJobs(ctx, jobs.Filter{Symbol: "AAPL", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
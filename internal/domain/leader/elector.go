@@ -0,0 +1,146 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Status describes who leads a role, as seen by this replica
+type Status struct {
+	Role      string
+	Self      string     // identity of this replica
+	Leader    string     // holder of the lease, empty when no replica leads
+	IsLeader  bool       // whether this replica leads
+	Since     *time.Time // start of the current leadership
+	ExpiresAt *time.Time // end of the lease unless renewed
+}
+
+// Elector campaigns for a role on behalf of this replica. It renews its lease three times per ttl, so a
+// replica that dies is replaced by another one at most a ttl after its last renewal.
+type Elector struct {
+	repo   Repository
+	role   string
+	self   string
+	ttl    time.Duration
+	mu     sync.RWMutex
+	lease  *Lease
+	leadTo time.Time // local end of the leadership, the lease cannot be taken over before
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Identity returns the name of this replica, its hostname and process id
+func Identity() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// NewElector creates an elector campaigning for a role as the given replica, usually its Identity
+func NewElector(repo Repository, role, self string, ttl time.Duration) *Elector {
+	return &Elector{
+		repo: repo,
+		role: role,
+		self: self,
+		ttl:  ttl,
+	}
+}
+
+// IsLeader reports whether this replica currently leads the role. Leadership ends with the lease as known
+// locally, also when it cannot be renewed because the database is unreachable.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return time.Now().Before(e.leadTo)
+}
+
+// Status returns the leader of the role as of the last campaign
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	status := Status{Role: e.role, Self: e.self, IsLeader: time.Now().Before(e.leadTo)}
+	if e.lease != nil {
+		status.Leader = e.lease.Holder
+		status.Since = &e.lease.AcquiredAt
+		status.ExpiresAt = &e.lease.ExpiresAt
+	}
+	return status
+}
+
+// Start campaigns once, so the leadership is known when Start returns, and keeps campaigning until Stop
+func (e *Elector) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.campaign(ctx)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.campaign(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the campaign and releases the lease, so another replica takes over without waiting for it to expire
+func (e *Elector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	e.wg.Wait()
+
+	if !e.IsLeader() {
+		return
+	}
+	e.mu.Lock()
+	e.leadTo = time.Time{}
+	e.mu.Unlock()
+	if err := e.repo.ReleaseLease(context.Background(), e.role, e.self); err != nil {
+		log.Error().Err(err).Str("role", e.role).Msg("Failed to release leadership")
+		return
+	}
+	log.Info().Str("role", e.role).Str("holder", e.self).Msg("Leadership released")
+}
+
+// campaign acquires or renews the lease
+func (e *Elector) campaign(ctx context.Context) {
+	start := time.Now()
+	lease, err := e.repo.AcquireLease(ctx, e.role, e.self, e.ttl)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Str("role", e.role).Msg("Failed to campaign for leadership")
+		}
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	wasLeader := start.Before(e.leadTo)
+	e.lease = lease
+	if lease != nil && lease.Holder == e.self {
+		// measured from before the request, the lease may have been written at its start
+		e.leadTo = start.Add(e.ttl)
+	} else {
+		e.leadTo = time.Time{}
+	}
+
+	isLeader := !e.leadTo.IsZero()
+	switch {
+	case isLeader && !wasLeader:
+		log.Info().Str("role", e.role).Str("holder", e.self).Msg("Leadership acquired")
+	case !isLeader && wasLeader:
+		log.Warn().Str("role", e.role).Str("holder", e.self).Msg("Leadership lost")
+	}
+}
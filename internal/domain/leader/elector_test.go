@@ -0,0 +1,123 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/leader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository keeps a single lease in memory, granting it like the PostgreSQL repository
+type fakeRepository struct {
+	mu    sync.Mutex
+	lease *leader.Lease
+	err   error
+}
+
+func (r *fakeRepository) AcquireLease(_ context.Context, name, holder string, ttl time.Duration) (*leader.Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	now := time.Now()
+	switch {
+	case r.lease == nil || r.lease.ExpiresAt.Before(now):
+		r.lease = &leader.Lease{Name: name, Holder: holder, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+	case r.lease.Holder == holder:
+		r.lease.RenewedAt = now
+		r.lease.ExpiresAt = now.Add(ttl)
+	}
+	lease := *r.lease
+	return &lease, nil
+}
+
+func (r *fakeRepository) ReleaseLease(_ context.Context, _, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lease != nil && r.lease.Holder == holder {
+		r.lease = nil
+	}
+	return nil
+}
+
+func (r *fakeRepository) GetLease(context.Context, string) (*leader.Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lease == nil {
+		return nil, nil
+	}
+	lease := *r.lease
+	return &lease, nil
+}
+
+func (r *fakeRepository) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func TestElector(t *testing.T) {
+	repo := &fakeRepository{}
+	ttl := 60 * time.Millisecond
+
+	first := leader.NewElector(repo, leader.RoleScheduler, "first", ttl)
+	first.Start(context.Background())
+	assert.True(t, first.IsLeader(), "the leadership is known once started")
+
+	status := first.Status()
+	assert.Equal(t, leader.RoleScheduler, status.Role)
+	assert.Equal(t, "first", status.Self)
+	assert.Equal(t, "first", status.Leader)
+	assert.True(t, status.IsLeader)
+	require.NotNil(t, status.Since)
+
+	// another replica takes the lease over, the first one notices at its next renewal
+	repo.mu.Lock()
+	repo.lease.Holder = "other"
+	repo.mu.Unlock()
+	assert.Eventually(t, func() bool {
+		return !first.IsLeader()
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "other", first.Status().Leader)
+
+	// the lease of the dead replica expires and is taken over
+	second := leader.NewElector(repo, leader.RoleScheduler, "second", ttl)
+	second.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		return first.IsLeader() != second.IsLeader()
+	}, time.Second, 5*time.Millisecond, "expired leases are taken over by a single replica")
+
+	// a stopped leader releases the lease for the follower
+	current, follower := first, second
+	if second.IsLeader() {
+		current, follower = second, first
+	}
+	current.Stop()
+	assert.False(t, current.IsLeader())
+	assert.Eventually(t, follower.IsLeader, time.Second, 5*time.Millisecond, "released leases are taken over")
+	follower.Stop()
+}
+
+func TestElector_Unreachable(t *testing.T) {
+	repo := &fakeRepository{}
+	ttl := 60 * time.Millisecond
+
+	elector := leader.NewElector(repo, leader.RoleScheduler, leader.Identity(), ttl)
+	elector.Start(context.Background())
+	defer elector.Stop()
+	require.True(t, elector.IsLeader())
+
+	repo.setErr(errors.New("connection refused"))
+	assert.Eventually(t, func() bool {
+		return !elector.IsLeader()
+	}, time.Second, 5*time.Millisecond, "leadership ends with the lease when it cannot be renewed")
+
+	repo.setErr(nil)
+	assert.Eventually(t, elector.IsLeader, time.Second, 5*time.Millisecond)
+}
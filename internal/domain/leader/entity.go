@@ -0,0 +1,20 @@
+package leader
+
+import (
+	"time"
+)
+
+// Elected roles
+const (
+	RoleScheduler = "scheduler" // replica running the periodic jobs
+)
+
+// Lease is the claim of a replica on a role. The holder renews it periodically; once it expired, e.g. because
+// the holder died, another replica takes it over.
+type Lease struct {
+	Name       string    `db:"name"`
+	Holder     string    `db:"holder"`
+	AcquiredAt time.Time `db:"acquired_at"`
+	RenewedAt  time.Time `db:"renewed_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
@@ -0,0 +1,92 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the persistence operations of leases
+type Repository interface {
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+	GetLease(ctx context.Context, name string) (*Lease, error)
+}
+
+const leaseColumns = `name, holder, acquired_at, renewed_at, expires_at`
+
+// LeaseRepository persists leases in PostgreSQL
+type LeaseRepository struct {
+	db *database.DB
+}
+
+// NewLeaseRepository creates a new lease repository
+func NewLeaseRepository(db *database.DB) *LeaseRepository {
+	return &LeaseRepository{
+		db: db,
+	}
+}
+
+// AcquireLease takes or renews the lease of a role for the holder, for the ttl from now. A lease held by another
+// replica is only taken over once it expired. Returns the current lease, held by the given holder if acquired.
+func (r *LeaseRepository) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (*Lease, error) {
+	query := `
+		INSERT INTO leader_leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, now(), now(), now() + make_interval(secs => $3))
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN leader_leases.holder = EXCLUDED.holder
+				THEN leader_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+			renewed_at = EXCLUDED.renewed_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < now()
+		RETURNING ` + leaseColumns
+
+	lease, err := r.queryLease(ctx, query, name, holder, ttl.Seconds())
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to acquire lease: %s", name)
+	}
+	if lease != nil {
+		return lease, nil
+	}
+
+	// held by another replica
+	return r.GetLease(ctx, name)
+}
+
+// ReleaseLease gives up the lease of a role if the holder has it, so another replica can take over at once.
+func (r *LeaseRepository) ReleaseLease(ctx context.Context, name, holder string) error {
+	query := `DELETE FROM leader_leases WHERE name = $1 AND holder = $2`
+	if _, err := r.db.ExecContext(ctx, query, name, holder); err != nil {
+		return eris.Wrapf(err, "failed to release lease: %s", name)
+	}
+	return nil
+}
+
+// GetLease retrieves the lease of a role, nil if no replica holds it.
+func (r *LeaseRepository) GetLease(ctx context.Context, name string) (*Lease, error) {
+	lease, err := r.queryLease(ctx, `SELECT `+leaseColumns+` FROM leader_leases WHERE name = $1`, name)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to get lease: %s", name)
+	}
+	return lease, nil
+}
+
+func (r *LeaseRepository) queryLease(ctx context.Context, query string, args ...any) (*Lease, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Lease])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lease, nil
+}
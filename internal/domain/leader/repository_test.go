@@ -0,0 +1,68 @@
+package leader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/leader"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseRepository(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := leader.NewLeaseRepository(db)
+	ctx := context.TODO()
+
+	lease, err := repo.GetLease(ctx, leader.RoleScheduler)
+	require.NoError(t, err)
+	assert.Nil(t, lease, "no replica leads yet")
+
+	lease, err = repo.AcquireLease(ctx, leader.RoleScheduler, "replica-1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, lease)
+	assert.Equal(t, "replica-1", lease.Holder)
+	acquiredAt := lease.AcquiredAt
+
+	// renewals keep the start of the leadership
+	lease, err = repo.AcquireLease(ctx, leader.RoleScheduler, "replica-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-1", lease.Holder)
+	assert.True(t, acquiredAt.Equal(lease.AcquiredAt))
+
+	// valid leases are not taken over
+	lease, err = repo.AcquireLease(ctx, leader.RoleScheduler, "replica-2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-1", lease.Holder)
+
+	// other replicas cannot release the lease
+	require.NoError(t, repo.ReleaseLease(ctx, leader.RoleScheduler, "replica-2"))
+	lease, err = repo.GetLease(ctx, leader.RoleScheduler)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-1", lease.Holder)
+
+	// expired leases are taken over
+	_, err = repo.AcquireLease(ctx, leader.RoleScheduler, "replica-1", time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	lease, err = repo.AcquireLease(ctx, leader.RoleScheduler, "replica-2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-2", lease.Holder)
+	assert.True(t, lease.AcquiredAt.After(acquiredAt))
+
+	require.NoError(t, repo.ReleaseLease(ctx, leader.RoleScheduler, "replica-2"))
+	lease, err = repo.GetLease(ctx, leader.RoleScheduler)
+	require.NoError(t, err)
+	assert.Nil(t, lease)
+}
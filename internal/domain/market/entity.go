@@ -49,15 +49,151 @@ type StockPrice struct {
 	LowPrice   *float64  `db:"low_price"`   // NUMERIC(18, 6) nullable
 	ClosePrice *float64  `db:"close_price"` // NUMERIC(18, 6) nullable
 	AdjClose   *float64  `db:"adj_close"`   // NUMERIC(18, 6) nullable
-	Volume     *int64    `db:"volume"`      // BIGINT nullable
+	Volume     *float64  `db:"volume"`      // NUMERIC(28, 8) nullable
 }
 
 // PriceFetchLog represents a log of price fetching activity, including metadata such as success status and error details.
 type PriceFetchLog struct {
-	ID         int       `db:"id"`          // SERIAL PRIMARY KEY
-	SymbolID   *int      `db:"symbol_id"`   // INTEGER (nullable), FK to symbols(id)
-	FetchedAt  time.Time `db:"fetched_at"`  // TIMESTAMPTZ NOT NULL DEFAULT now()
-	DataPoints *int      `db:"data_points"` // INTEGER (nullable)
-	Success    bool      `db:"success"`     // BOOLEAN NOT NULL
-	ErrorMsg   *string   `db:"error_msg"`   // TEXT (nullable)
+	ID           int        `db:"id"`            // SERIAL PRIMARY KEY
+	SymbolID     *int       `db:"symbol_id"`     // INTEGER (nullable), FK to symbols(id)
+	Symbol       string     `db:"symbol"`        // TEXT
+	FetchedAt    time.Time  `db:"fetched_at"`    // TIMESTAMPTZ NOT NULL DEFAULT now()
+	DataPoints   *int       `db:"data_points"`   // INTEGER (nullable)
+	Success      bool       `db:"success"`       // BOOLEAN NOT NULL
+	ErrorMsg     *string    `db:"error_msg"`     // TEXT (nullable)
+	Provider     *string    `db:"provider"`      // TEXT (nullable)
+	Interval     *string    `db:"bar_interval"`  // TEXT (nullable)
+	RangeStart   *time.Time `db:"range_start"`   // TIMESTAMPTZ (nullable)
+	RangeEnd     *time.Time `db:"range_end"`     // TIMESTAMPTZ (nullable)
+	DurationMs   *int       `db:"duration_ms"`   // INTEGER (nullable)
+	HTTPStatus   *int       `db:"http_status"`   // INTEGER (nullable), status of the last provider response
+	Retries      *int       `db:"retries"`       // INTEGER (nullable)
+	RowsInserted *int       `db:"rows_inserted"` // INTEGER (nullable)
+	RowsUpdated  *int       `db:"rows_updated"`  // INTEGER (nullable)
+	// INTEGER (nullable), bars failing validation, nil when validation is disabled
+	RowsQuarantined *int `db:"rows_quarantined"`
+}
+
+// FetchLogFilter selects the fetch log entries of a symbol, zero fields do not filter
+type FetchLogFilter struct {
+	FailedOnly bool
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Limit      int
+}
+
+// Review states of quarantined bars
+const (
+	QuarantinePending   = "pending"
+	QuarantineReleased  = "released"
+	QuarantineDiscarded = "discarded"
+)
+
+// QuarantinedBar is an incoming bar which failed validation and is held back until it is reviewed.
+type QuarantinedBar struct {
+	ID         int64             `db:"id"`           // BIGSERIAL PRIMARY KEY
+	Symbol     string            `db:"symbol"`       // TEXT NOT NULL
+	Interval   yahoo.IntervalAPI `db:"bar_interval"` // TEXT NOT NULL DEFAULT '1d'
+	Time       time.Time         `db:"time"`         // TIMESTAMPTZ NOT NULL
+	OpenPrice  *float64          `db:"open_price"`   // NUMERIC(18, 6) nullable
+	HighPrice  *float64          `db:"high_price"`   // NUMERIC(18, 6) nullable
+	LowPrice   *float64          `db:"low_price"`    // NUMERIC(18, 6) nullable
+	ClosePrice *float64          `db:"close_price"`  // NUMERIC(18, 6) nullable
+	AdjClose   *float64          `db:"adj_close"`    // NUMERIC(18, 6) nullable
+	Volume     *float64          `db:"volume"`       // NUMERIC(28, 8) nullable
+	Rule       string            `db:"rule"`         // TEXT NOT NULL
+	Reason     string            `db:"reason"`       // TEXT NOT NULL
+	FetchLogID *int              `db:"fetch_log_id"` // INTEGER (nullable), FK to price_fetch_logs(id)
+	Status     string            `db:"status"`       // TEXT NOT NULL DEFAULT 'pending'
+	CreatedAt  time.Time         `db:"created_at"`   // TIMESTAMPTZ NOT NULL DEFAULT now()
+	ReviewedAt *time.Time        `db:"reviewed_at"`  // TIMESTAMPTZ (nullable)
+}
+
+// NewQuarantinedBar creates a pending quarantined bar of an interval of a symbol from an incoming bar
+func NewQuarantinedBar(symbol string, interval yahoo.IntervalAPI, bar *yahoo.StockPrice,
+	violation *Violation) QuarantinedBar {
+	return QuarantinedBar{
+		Symbol:     symbol,
+		Interval:   interval,
+		Time:       bar.Time,
+		OpenPrice:  ptr(bar.Open),
+		HighPrice:  ptr(bar.High),
+		LowPrice:   ptr(bar.Low),
+		ClosePrice: ptr(bar.Close),
+		AdjClose:   ptr(bar.AdjClose),
+		Volume:     ptr(bar.Volume),
+		Rule:       violation.Rule,
+		Reason:     violation.Reason,
+		Status:     QuarantinePending,
+	}
+}
+
+// QuarantineFilter selects quarantined bars, zero fields do not filter
+type QuarantineFilter struct {
+	Symbol string
+	Status string
+	Limit  int
+}
+
+// BarInterval returns the interval the prices of market data are stored as, daily unless set
+func BarInterval(data *yahoo.MarketData) yahoo.IntervalAPI {
+	if data.Interval == "" {
+		return yahoo.Interval1d
+	}
+	return data.Interval
+}
+
+// SaveResult summarises the outcome of storing market data, distinguishing new prices from overwritten ones.
+type SaveResult struct {
+	Inserted    int
+	Updated     int
+	Quarantined int // bars which failed validation and await review instead of being stored
+
+	quarantinedIDs []int64 // ids of the quarantined bars awaiting review
+}
+
+// Total returns the number of stored prices.
+func (r *SaveResult) Total() int {
+	return r.Inserted + r.Updated
+}
+
+// Quote represents the latest known market quote of a symbol.
+type Quote struct {
+	SymbolID      int       `db:"symbol_id"`      // INTEGER PRIMARY KEY (FK)
+	Symbol        string    `db:"symbol"`         // joined from symbols
+	Price         float64   `db:"price"`          // NUMERIC(18, 6) NOT NULL
+	PreviousClose *float64  `db:"previous_close"` // NUMERIC(18, 6) nullable
+	Change        *float64  `db:"change"`         // NUMERIC(18, 6) nullable
+	ChangePercent *float64  `db:"change_percent"` // NUMERIC(12, 6) nullable
+	Currency      string    `db:"currency"`       // TEXT NOT NULL
+	MarketTime    time.Time `db:"market_time"`    // TIMESTAMPTZ NOT NULL
+	UpdatedAt     time.Time `db:"updated_at"`     // TIMESTAMPTZ NOT NULL
+}
+
+// NewQuoteFromMarketQuote derives the change against the previous close of a provider quote.
+func NewQuoteFromMarketQuote(symbol string, q *yahoo.MarketQuote) *Quote {
+	quote := &Quote{
+		Symbol:     symbol,
+		Price:      q.Price,
+		Currency:   q.Currency,
+		MarketTime: q.Time,
+	}
+	if q.PreviousClose > 0 {
+		previousClose := q.PreviousClose
+		change := q.Price - previousClose
+		changePercent := change / previousClose * 100
+		quote.PreviousClose = &previousClose
+		quote.Change = &change
+		quote.ChangePercent = &changePercent
+	}
+	return quote
+}
+
+// ReprocessResult reports the outcome of parsing and storing an archived fetch again.
+type ReprocessResult struct {
+	FetchedAt time.Time
+	Provider  string
+	Prices    int
+	Saved     *SaveResult // nil when the fetch could not be reprocessed
+	Err       error
 }
@@ -0,0 +1,84 @@
+package market
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// FetchLocker serializes the fetches of an interval of a symbol across replicas
+type FetchLocker interface {
+	// LockFetch blocks until the lock is held, reporting whether another replica held it meanwhile
+	LockFetch(ctx context.Context, symbol string, interval yahoo.IntervalAPI) (unlock func(), waited bool, err error)
+}
+
+// fetchFlight is a fetch of an interval of a symbol in progress in this process
+type fetchFlight struct {
+	start time.Time // start of the fetched window
+	done  chan struct{}
+	err   error
+}
+
+// fetchFlights coalesces the concurrent fetches of an interval of a symbol within this process
+type fetchFlights struct {
+	mu      sync.Mutex
+	flights map[string]*fetchFlight
+}
+
+// do runs fetch unless a fetch of the interval of the symbol is in progress whose window starts no later than
+// start, in which case it waits for that fetch and returns its result. Fetches of windows starting earlier wait
+// for the one in progress and run after it. With a locker, fetches also wait for those of other replicas. The
+// fetch is told whether it waited for another one, so it can shrink its window to what remains to be fetched.
+func (f *fetchFlights) do(ctx context.Context, locker FetchLocker, symbol string, interval yahoo.IntervalAPI,
+	start time.Time, fetch func(ctx context.Context, waited bool) error) error {
+	key := symbol + ":" + string(interval)
+	waited := false
+	var own *fetchFlight
+	for {
+		f.mu.Lock()
+		if f.flights == nil {
+			f.flights = make(map[string]*fetchFlight)
+		}
+		flight, ok := f.flights[key]
+		if !ok {
+			own = &fetchFlight{start: start, done: make(chan struct{})}
+			f.flights[key] = own
+			f.mu.Unlock()
+			break
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !flight.start.After(start) {
+			return flight.err
+		}
+		waited = true
+	}
+
+	own.err = f.fetchLocked(ctx, locker, symbol, interval, waited, fetch)
+
+	f.mu.Lock()
+	delete(f.flights, key)
+	f.mu.Unlock()
+	close(own.done)
+	return own.err
+}
+
+func (f *fetchFlights) fetchLocked(ctx context.Context, locker FetchLocker, symbol string, interval yahoo.IntervalAPI,
+	waited bool, fetch func(ctx context.Context, waited bool) error) error {
+	if locker != nil {
+		unlock, lockWaited, err := locker.LockFetch(ctx, symbol, interval)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		waited = waited || lockWaited
+	}
+	return fetch(ctx, waited)
+}
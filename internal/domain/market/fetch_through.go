@@ -0,0 +1,124 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrFetchTimeout is returned when a fetch-through does not finish within its time limit
+var ErrFetchTimeout = errors.New("fetch-through timed out")
+
+// unknownSymbols remembers the symbols the provider confirmed not to exist until their entry expires. At
+// most max symbols are remembered, the one expiring first is forgotten to make room for another.
+type unknownSymbols struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	expires map[string]time.Time
+}
+
+func newUnknownSymbols(ttl time.Duration, maxSymbols int) *unknownSymbols {
+	return &unknownSymbols{ttl: ttl, max: max(maxSymbols, 1), expires: make(map[string]time.Time)}
+}
+
+func (u *unknownSymbols) add(symbol string, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.expires[symbol]; !ok && len(u.expires) >= u.max {
+		u.evict(now)
+	}
+	u.expires[symbol] = now.Add(u.ttl)
+}
+
+// evict drops the expired symbols, or the one expiring first when none has expired
+func (u *unknownSymbols) evict(now time.Time) {
+	var first string
+	var firstExpiry time.Time
+	for symbol, expiresAt := range u.expires {
+		if !now.Before(expiresAt) {
+			delete(u.expires, symbol)
+			continue
+		}
+		if first == "" || expiresAt.Before(firstExpiry) {
+			first, firstExpiry = symbol, expiresAt
+		}
+	}
+	if len(u.expires) >= u.max {
+		delete(u.expires, first)
+	}
+}
+
+func (u *unknownSymbols) contains(symbol string, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	expiresAt, ok := u.expires[symbol]
+	if ok && !now.Before(expiresAt) {
+		delete(u.expires, symbol)
+		return false
+	}
+	return ok
+}
+
+// QuotaChecker reports whether a provider may still be called today
+type QuotaChecker interface {
+	// CheckQuota returns an error when the daily request cap of the provider is reached
+	CheckQuota(provider string) error
+}
+
+// SetFetchThrough makes GetOrFetchMarketData fetch symbols missing from the database from the provider,
+// waiting at most timeout. Up to maxUnknown symbols the provider does not know are not asked for again
+// within unknownTTL.
+func (s *MarketService) SetFetchThrough(timeout, unknownTTL time.Duration, maxUnknown int) {
+	s.fetchThroughTimeout = timeout
+	s.unknown = newUnknownSymbols(unknownTTL, maxUnknown)
+}
+
+// SetQuotaChecker stops fetch-throughs once the daily request cap of the provider is reached
+func (s *MarketService) SetQuotaChecker(quota QuotaChecker) {
+	s.quota = quota
+}
+
+// GetOrFetchMarketData retrieves the stored bars of an interval of a symbol. With fetch-through enabled, a symbol
+// missing from the database is fetched from the provider and stored first. Returns ErrUnknownSymbol for
+// symbols the provider does not know, also when remembered from an earlier fetch, ErrFetchTimeout when
+// the fetch takes longer than allowed and an error wrapping usage.ErrQuotaExceeded when the provider may not
+// be called anymore today.
+func (s *MarketService) GetOrFetchMarketData(ctx context.Context, symbol string,
+	interval yahoo.IntervalAPI) (*Symbol, *StockPrices, error) {
+	symbolData, stockPrices, err := s.GetMarketData(ctx, symbol, interval)
+	if s.unknown == nil || s.provider == nil || !errors.Is(err, ErrSymbolNotFound) {
+		return symbolData, stockPrices, err
+	}
+
+	if s.unknown.contains(symbol, time.Now()) {
+		return nil, nil, ErrUnknownSymbol
+	}
+	if provider := s.fetchLogProvider(); s.quota != nil && provider != nil {
+		if err := s.quota.CheckQuota(*provider); err != nil {
+			return nil, nil, eris.Wrapf(err, "symbol %s not fetched", symbol)
+		}
+	}
+
+	// a client giving up must not abort storing data already fetched
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.fetchThroughTimeout)
+	defer cancel()
+	log.Info().Str("symbol", symbol).Msg("Symbol not stored, fetching through")
+	if err := s.FetchAndStoreMarketData(fetchCtx, symbol); err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownSymbol):
+			s.unknown.add(symbol, time.Now())
+			return nil, nil, ErrUnknownSymbol
+		case errors.Is(fetchCtx.Err(), context.DeadlineExceeded):
+			return nil, nil, eris.Wrapf(ErrFetchTimeout, "symbol %s", symbol)
+		default:
+			return nil, nil, eris.Wrapf(err, "failed to fetch symbol %s", symbol)
+		}
+	}
+	return s.GetMarketData(ctx, symbol, interval)
+}
@@ -0,0 +1,229 @@
+package market
+
+import (
+	"context"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// HistoryLimiter is implemented by data providers which serve limited history for some intervals
+type HistoryLimiter interface {
+	// HistoryLimits returns the per-interval limits of the provider
+	HistoryLimits() yahoo.HistoryLimits
+}
+
+// RangeDataProvider is implemented by data providers able to fetch an absolute time range instead of a period
+// ending now, which allows splitting long backfills into several requests
+type RangeDataProvider interface {
+	// GetMarketDataRange fetches the bars of a symbol starting in [start, end)
+	GetMarketDataRange(
+		ctx context.Context,
+		symbol string,
+		interval yahoo.IntervalAPI,
+		start, end time.Time,
+	) (*yahoo.MarketData, error)
+}
+
+// RawRangeDataProvider is implemented by range data providers able to return the unparsed responses of a range
+type RawRangeDataProvider interface {
+	RawDataProvider
+	// FetchRawMarketDataRange fetches the responses GetMarketDataRange would parse, in request order
+	FetchRawMarketDataRange(
+		ctx context.Context,
+		symbol string,
+		interval yahoo.IntervalAPI,
+		start, end time.Time,
+	) ([][]byte, error)
+}
+
+// Reasons of unreachable windows
+const (
+	ReasonBeyondLookback = "beyond provider lookback"
+	ReasonNoRangeSupport = "provider only serves periods ending now"
+)
+
+// FetchRequest is a single provider request of a fetch plan. Requests with a Period are executed as the period
+// ending now, which covers at least [Start, End), the others as the range [Start, End).
+type FetchRequest struct {
+	Interval yahoo.IntervalAPI
+	Start    time.Time
+	End      time.Time
+	Period   yahoo.PeriodAPI
+}
+
+// UnreachableWindow is a part of a fetch window the provider cannot serve
+type UnreachableWindow struct {
+	Interval yahoo.IntervalAPI
+	Start    time.Time
+	End      time.Time
+	Reason   string
+}
+
+// FetchPlan is the set of valid provider requests covering a fetch window, oldest first, and the parts of the
+// window which cannot be fetched
+type FetchPlan struct {
+	Requests    []FetchRequest
+	Unreachable []UnreachableWindow
+}
+
+// periods are the periods requested from providers, shortest first. A period is used when the history it
+// is guaranteed to cover reaches back to the start of the window, 1d is left out as it only covers the
+// current session.
+var periods = []struct {
+	period yahoo.PeriodAPI
+	start  func(now time.Time) time.Time
+}{
+	{yahoo.Period5d, func(now time.Time) time.Time { return now.AddDate(0, 0, -5) }},
+	{yahoo.Period1mo, func(now time.Time) time.Time { return now.AddDate(0, -1, 0) }},
+	{yahoo.Period3mo, func(now time.Time) time.Time { return now.AddDate(0, -3, 0) }},
+	{yahoo.Period6mo, func(now time.Time) time.Time { return now.AddDate(0, -6, 0) }},
+	{yahoo.Period1y, func(now time.Time) time.Time { return now.AddDate(-1, 0, 0) }},
+	{yahoo.Period2y, func(now time.Time) time.Time { return now.AddDate(-2, 0, 0) }},
+	{yahoo.Period5y, func(now time.Time) time.Time { return now.AddDate(-5, 0, 0) }},
+	{yahoo.Period10y, func(now time.Time) time.Time { return now.AddDate(-10, 0, 0) }},
+}
+
+// FetchPlanner splits fetch windows into requests a provider can serve
+type FetchPlanner struct {
+	limits        yahoo.HistoryLimits
+	rangeRequests bool
+}
+
+// NewFetchPlanner creates a planner for a provider, using its history limits and range support when available
+func NewFetchPlanner(provider DataProvider) *FetchPlanner {
+	p := &FetchPlanner{}
+	if limiter, ok := provider.(HistoryLimiter); ok {
+		p.limits = limiter.HistoryLimits()
+	}
+	_, p.rangeRequests = provider.(RangeDataProvider)
+	return p
+}
+
+// Plan covers the window [start, now) of an interval. Parts older than the lookback of the interval are
+// unreachable. The most recent part is requested as the shortest period covering it within the max range,
+// older parts as ranges no longer than the max range. Without range support these older parts are unreachable.
+func (p *FetchPlanner) Plan(interval yahoo.IntervalAPI, start, now time.Time) FetchPlan {
+	var plan FetchPlan
+	if !start.Before(now) {
+		return plan
+	}
+
+	limit := p.limits[interval]
+	if limit.Lookback > 0 {
+		if earliest := now.Add(-limit.Lookback); start.Before(earliest) {
+			plan.Unreachable = append(plan.Unreachable, UnreachableWindow{
+				Interval: interval, Start: start, End: earliest, Reason: ReasonBeyondLookback,
+			})
+			start = earliest
+			if !start.Before(now) {
+				return plan
+			}
+		}
+	}
+
+	// the most recent request, a period ending now when one fits the max range
+	last := FetchRequest{Interval: interval, End: now}
+	for _, ps := range periods {
+		periodStart := ps.start(now)
+		if limit.MaxRange > 0 && now.Sub(periodStart) > limit.MaxRange {
+			break
+		}
+		last.Start, last.Period = periodStart, ps.period
+		if !periodStart.After(start) {
+			break
+		}
+	}
+	switch {
+	case last.Period == "" && p.rangeRequests:
+		// no period fits, everything is requested as ranges
+		last.Start = now
+	case last.Period == "":
+		plan.Unreachable = append(plan.Unreachable, UnreachableWindow{
+			Interval: interval, Start: start, End: now, Reason: ReasonNoRangeSupport,
+		})
+		return plan
+	case !last.Start.After(start):
+		plan.Requests = append(plan.Requests, last)
+		return plan
+	case limit.MaxRange == 0 && !p.rangeRequests:
+		// longer than the longest period, the provider serves all history at once
+		last.Start, last.Period = start, yahoo.PeriodMax
+		plan.Requests = append(plan.Requests, last)
+		return plan
+	}
+
+	if !p.rangeRequests {
+		plan.Unreachable = append(plan.Unreachable, UnreachableWindow{
+			Interval: interval, Start: start, End: last.Start, Reason: ReasonNoRangeSupport,
+		})
+		plan.Requests = append(plan.Requests, last)
+		return plan
+	}
+
+	plan.Requests = appendRanges(plan.Requests, interval, start, last.Start, limit.MaxRange)
+	if last.Period != "" {
+		plan.Requests = append(plan.Requests, last)
+	}
+	return plan
+}
+
+// PlanRange covers the window [start, end) of an interval, which may end before now. Providers with range
+// support get ranges of the window only, the others the plan of [start, now) as their periods end now.
+func (p *FetchPlanner) PlanRange(interval yahoo.IntervalAPI, start, end, now time.Time) FetchPlan {
+	if !end.Before(now) || !p.rangeRequests {
+		return p.Plan(interval, start, now)
+	}
+
+	var plan FetchPlan
+	limit := p.limits[interval]
+	if earliest := now.Add(-limit.Lookback); limit.Lookback > 0 && start.Before(earliest) {
+		plan.Unreachable = append(plan.Unreachable, UnreachableWindow{
+			Interval: interval, Start: start, End: minTime(earliest, end), Reason: ReasonBeyondLookback,
+		})
+		start = earliest
+	}
+	plan.Requests = appendRanges(plan.Requests, interval, start, end, limit.MaxRange)
+	return plan
+}
+
+// appendRanges splits [start, end) into range requests no longer than maxRange, zero meaning unlimited
+func appendRanges(requests []FetchRequest, interval yahoo.IntervalAPI, start, end time.Time,
+	maxRange time.Duration) []FetchRequest {
+	for chunkStart := start; chunkStart.Before(end); {
+		chunkEnd := end
+		if maxRange > 0 && chunkStart.Add(maxRange).Before(chunkEnd) {
+			chunkEnd = chunkStart.Add(maxRange)
+		}
+		requests = append(requests, FetchRequest{Interval: interval, Start: chunkStart, End: chunkEnd})
+		chunkStart = chunkEnd
+	}
+	return requests
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// AlignStart truncates a time to the start of the bar of an interval containing it, UTC days for daily and
+// longer intervals, so an incremental fetch requests the bar in progress at the previous fetch again.
+func AlignStart(interval yahoo.IntervalAPI, t time.Time) time.Time {
+	switch interval {
+	case yahoo.Interval1m:
+		return t.Truncate(time.Minute)
+	case yahoo.Interval5m:
+		return t.Truncate(5 * time.Minute)
+	case yahoo.Interval15m:
+		return t.Truncate(15 * time.Minute)
+	case yahoo.Interval30m:
+		return t.Truncate(30 * time.Minute)
+	case yahoo.Interval1h:
+		return t.Truncate(time.Hour)
+	default:
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
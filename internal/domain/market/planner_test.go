@@ -0,0 +1,133 @@
+package market_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// periodProvider serves periods ending now only, with Yahoo-like intraday limits
+type periodProvider struct{}
+
+func (periodProvider) GetMarketData(context.Context, string, yahoo.IntervalAPI, yahoo.PeriodAPI) (*yahoo.MarketData, error) {
+	return &yahoo.MarketData{}, nil
+}
+
+func (periodProvider) HistoryLimits() yahoo.HistoryLimits {
+	return yahoo.HistoryLimits{
+		yahoo.Interval1m: {Lookback: 30 * 24 * time.Hour, MaxRange: 7 * 24 * time.Hour},
+	}
+}
+
+// rangeProvider also serves absolute ranges
+type rangeProvider struct{ periodProvider }
+
+func (rangeProvider) GetMarketDataRange(context.Context, string, yahoo.IntervalAPI, time.Time, time.Time) (*yahoo.MarketData, error) {
+	return &yahoo.MarketData{}, nil
+}
+
+func TestFetchPlanner_Plan(t *testing.T) {
+	now := time.Date(2025, time.June, 13, 15, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	t.Run("recent window is a single period", func(t *testing.T) {
+		plan := market.NewFetchPlanner(rangeProvider{}).Plan(yahoo.Interval1d, now.AddDate(0, 0, -3), now)
+		assert.Empty(t, plan.Unreachable)
+		require.Len(t, plan.Requests, 1)
+		assert.Equal(t, yahoo.Period5d, plan.Requests[0].Period)
+
+		plan = market.NewFetchPlanner(rangeProvider{}).Plan(yahoo.Interval1d, now.AddDate(-5, 0, 0), now)
+		require.Len(t, plan.Requests, 1)
+		assert.Equal(t, yahoo.Period5y, plan.Requests[0].Period)
+	})
+
+	t.Run("long backfill is split into ranges within the max range", func(t *testing.T) {
+		start := now.Add(-20 * day)
+		plan := market.NewFetchPlanner(rangeProvider{}).Plan(yahoo.Interval1m, start, now)
+		assert.Empty(t, plan.Unreachable)
+
+		// 15 days of ranges up to the most recent 5d period
+		require.Len(t, plan.Requests, 4)
+		assert.Equal(t, market.FetchRequest{Interval: yahoo.Interval1m, Start: start, End: start.Add(7 * day)}, plan.Requests[0])
+		assert.Equal(t, market.FetchRequest{Interval: yahoo.Interval1m, Start: start.Add(7 * day), End: start.Add(14 * day)}, plan.Requests[1])
+		assert.Equal(t, market.FetchRequest{Interval: yahoo.Interval1m, Start: start.Add(14 * day), End: now.AddDate(0, 0, -5)}, plan.Requests[2])
+		assert.Equal(t, market.FetchRequest{Interval: yahoo.Interval1m, Start: now.AddDate(0, 0, -5), End: now, Period: yahoo.Period5d}, plan.Requests[3])
+	})
+
+	t.Run("history beyond the lookback is unreachable", func(t *testing.T) {
+		start := now.Add(-45 * day)
+		plan := market.NewFetchPlanner(rangeProvider{}).Plan(yahoo.Interval1m, start, now)
+		require.Len(t, plan.Unreachable, 1)
+		assert.Equal(t, market.UnreachableWindow{
+			Interval: yahoo.Interval1m, Start: start, End: now.Add(-30 * day), Reason: market.ReasonBeyondLookback,
+		}, plan.Unreachable[0])
+		assert.Equal(t, now.Add(-30*day), plan.Requests[0].Start)
+	})
+
+	t.Run("providers without range support only get the most recent period", func(t *testing.T) {
+		start := now.Add(-20 * day)
+		plan := market.NewFetchPlanner(periodProvider{}).Plan(yahoo.Interval1m, start, now)
+		require.Len(t, plan.Unreachable, 1)
+		assert.Equal(t, market.UnreachableWindow{
+			Interval: yahoo.Interval1m, Start: start, End: now.AddDate(0, 0, -5), Reason: market.ReasonNoRangeSupport,
+		}, plan.Unreachable[0])
+		require.Len(t, plan.Requests, 1)
+		assert.Equal(t, yahoo.Period5d, plan.Requests[0].Period)
+
+		// without limits the whole history is one request
+		plan = market.NewFetchPlanner(periodProvider{}).Plan(yahoo.Interval1d, now.AddDate(-15, 0, 0), now)
+		assert.Empty(t, plan.Unreachable)
+		require.Len(t, plan.Requests, 1)
+		assert.Equal(t, yahoo.PeriodMax, plan.Requests[0].Period)
+	})
+
+	t.Run("empty window", func(t *testing.T) {
+		plan := market.NewFetchPlanner(rangeProvider{}).Plan(yahoo.Interval1d, now, now)
+		assert.Empty(t, plan.Requests)
+		assert.Empty(t, plan.Unreachable)
+	})
+}
+
+func TestFetchPlanner_PlanRange(t *testing.T) {
+	now := time.Date(2025, time.June, 13, 15, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	t.Run("past window is requested as ranges", func(t *testing.T) {
+		start, end := now.Add(-40*day), now.Add(-10*day)
+		plan := market.NewFetchPlanner(rangeProvider{}).PlanRange(yahoo.Interval1m, start, end, now)
+		require.Len(t, plan.Unreachable, 1)
+		assert.Equal(t, market.UnreachableWindow{Interval: yahoo.Interval1m, Start: start, End: now.Add(-30 * day),
+			Reason: market.ReasonBeyondLookback}, plan.Unreachable[0])
+		require.Len(t, plan.Requests, 3)
+		assert.Equal(t, now.Add(-30*day), plan.Requests[0].Start)
+		assert.Equal(t, end, plan.Requests[2].End)
+		for _, request := range plan.Requests {
+			assert.Empty(t, request.Period)
+		}
+	})
+
+	t.Run("window ending now is planned like Plan", func(t *testing.T) {
+		start := now.AddDate(0, 0, -3)
+		planner := market.NewFetchPlanner(rangeProvider{})
+		assert.Equal(t, planner.Plan(yahoo.Interval1d, start, now), planner.PlanRange(yahoo.Interval1d, start, now, now))
+	})
+
+	t.Run("periods of providers without range support end now", func(t *testing.T) {
+		start := now.AddDate(0, 0, -20)
+		plan := market.NewFetchPlanner(periodProvider{}).PlanRange(yahoo.Interval1d, start, now.AddDate(0, 0, -10), now)
+		require.Len(t, plan.Requests, 1)
+		assert.Equal(t, yahoo.Period1mo, plan.Requests[0].Period)
+	})
+}
+
+func TestAlignStart(t *testing.T) {
+	at := time.Date(2025, time.June, 12, 15, 7, 30, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.June, 12, 15, 5, 0, 0, time.UTC), market.AlignStart(yahoo.Interval5m, at))
+	assert.Equal(t, time.Date(2025, time.June, 12, 15, 0, 0, 0, time.UTC), market.AlignStart(yahoo.Interval1h, at))
+	assert.Equal(t, time.Date(2025, time.June, 12, 0, 0, 0, 0, time.UTC), market.AlignStart(yahoo.Interval1d, at))
+}
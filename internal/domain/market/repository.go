@@ -1,10 +1,12 @@
 package market
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"github.com/market-data/internal/providers/yahoo"
 	"github.com/rotisserie/eris"
+	"slices"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/market-data/internal/database"
@@ -14,12 +16,22 @@ import (
 // Repository defines the interface for managing financial symbols in a data store.
 type Repository interface {
 	GetSymbol(ctx context.Context, symbol string) (*Symbol, error)
-	GetStockPrice(ctx context.Context, symbol string) (*StockPrices, error)
+	GetStockPrice(ctx context.Context, symbol string, interval yahoo.IntervalAPI) (*StockPrices, error)
 	SaveSymbol(ctx context.Context, s *Symbol) error
-	SaveMarketData(ctx context.Context, data *yahoo.MarketData) error
-	SavePriceFetchLogs(ctx context.Context, symbol string, fetchedAt time.Time, dataPoints int,
-		success bool, msg string) error
-	GetLastFetchTime(ctx context.Context, symbol string) (*time.Time, error)
+	SaveMarketData(ctx context.Context, data *yahoo.MarketData, quarantined ...QuarantinedBar) (*SaveResult, error)
+	GetLastClose(ctx context.Context, symbol string, interval yahoo.IntervalAPI, before time.Time) (*float64, error)
+	SavePriceFetchLog(ctx context.Context, entry *PriceFetchLog, payloadHashes ...string) (int, error)
+	GetPriceFetchLogs(ctx context.Context, ids []int) ([]PriceFetchLog, error)
+	ListPriceFetchLogs(ctx context.Context, symbol string, filter FetchLogFilter) ([]PriceFetchLog, error)
+	GetLastFetchEnd(ctx context.Context, symbol string) (*time.Time, error)
+	SaveQuote(ctx context.Context, data *yahoo.MarketData) error
+	GetQuotes(ctx context.Context, symbols []string) ([]Quote, error)
+	SaveUnreachableWindows(ctx context.Context, symbol string, windows []UnreachableWindow) error
+	SaveQuarantinedBars(ctx context.Context, bars []QuarantinedBar) error
+	LinkQuarantinedBars(ctx context.Context, fetchLogID int, ids []int64) error
+	ListQuarantinedBars(ctx context.Context, filter QuarantineFilter) ([]QuarantinedBar, error)
+	ReleaseQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error)
+	DiscardQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error)
 }
 
 // MarketRepository implements the market.Repository interface using PostgreSQL
@@ -81,20 +93,59 @@ func (r *MarketRepository) SaveSymbol(ctx context.Context, s *Symbol) error {
 	return nil
 }
 
-func (r *MarketRepository) SaveMarketData(ctx context.Context, data *yahoo.MarketData) error {
-	querySymbol := `
-		INSERT INTO symbols (symbol, name, exchange, created_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (symbol) DO UPDATE
-		SET name = EXCLUDED.name,
-			exchange = EXCLUDED.exchange
-		RETURNING id
-	`
+// querySymbolUpsert keeps the stored name and exchange when the provider does not report them, e.g. in
+// batch quote responses.
+const querySymbolUpsert = `
+	INSERT INTO symbols (symbol, name, exchange, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (symbol) DO UPDATE
+	SET name = COALESCE(NULLIF(EXCLUDED.name, ''), symbols.name),
+		exchange = COALESCE(NULLIF(EXCLUDED.exchange, ''), symbols.exchange)
+	RETURNING id
+`
+
+// queryQuoteUpsert keeps the stored quote when the incoming one is older, e.g. a delayed history fetch
+// finishing after the quote poller.
+const queryQuoteUpsert = `
+	INSERT INTO latest_quotes (
+		symbol_id, price, previous_close, change, change_percent, currency, market_time, updated_at
+	) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, now()
+	)
+	ON CONFLICT (symbol_id) DO UPDATE SET
+		price = EXCLUDED.price,
+		previous_close = EXCLUDED.previous_close,
+		change = EXCLUDED.change,
+		change_percent = EXCLUDED.change_percent,
+		currency = EXCLUDED.currency,
+		market_time = EXCLUDED.market_time,
+		updated_at = EXCLUDED.updated_at
+	WHERE latest_quotes.market_time <= EXCLUDED.market_time
+`
+
+func saveQuote(ctx context.Context, tx pgx.Tx, symbolId int, data *yahoo.MarketData) error {
+	if data.Quote == nil {
+		return nil
+	}
+	q := NewQuoteFromMarketQuote(data.Symbol, data.Quote)
+	_, err := tx.Exec(ctx, queryQuoteUpsert,
+		symbolId, q.Price, q.PreviousClose, q.Change, q.ChangePercent, q.Currency, q.MarketTime)
+	if err != nil {
+		return eris.Wrap(err, "failed to upsert latest quote")
+	}
+	return nil
+}
 
+// SaveMarketData upserts the symbol, its prices and its latest quote and quarantines the bars which failed
+// validation in a single transaction. Reports how many prices were inserted, how many replaced an existing
+// row and how many bars await review.
+func (r *MarketRepository) SaveMarketData(ctx context.Context, data *yahoo.MarketData,
+	quarantined ...QuarantinedBar) (*SaveResult, error) {
 	queryStockPrice := `
 		INSERT INTO stock_prices (
 			time,
 			symbol_id,
+			bar_interval,
 			open_price,
 			high_price,
 			low_price,
@@ -102,28 +153,35 @@ func (r *MarketRepository) SaveMarketData(ctx context.Context, data *yahoo.Marke
 			adj_close,
 			volume
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
 		)
-		ON CONFLICT (symbol_id, time) DO UPDATE SET
+		ON CONFLICT (symbol_id, bar_interval, time) DO UPDATE SET
 			open_price = EXCLUDED.open_price,
 			high_price = EXCLUDED.high_price,
 			low_price = EXCLUDED.low_price,
 			close_price = EXCLUDED.close_price,
 			adj_close = EXCLUDED.adj_close,
-			volume = EXCLUDED.volume;
+			volume = EXCLUDED.volume
+		RETURNING (xmax = 0) AS inserted;
 	`
 
-	return r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+	result := &SaveResult{}
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
 		var symbolId int
-		err := tx.QueryRow(ctx, querySymbol, data.Symbol, data.Name, data.Exchange, time.Now()).Scan(&symbolId)
+		err := tx.QueryRow(ctx, querySymbolUpsert, data.Symbol, data.Name, data.Exchange, time.Now()).Scan(&symbolId)
 		if err != nil {
 			return eris.Wrap(err, "failed to insert symbol")
 		}
 
+		if err := saveQuote(ctx, tx, symbolId, data); err != nil {
+			return err
+		}
+
+		interval := BarInterval(data)
 		batch := &pgx.Batch{}
 		for _, price := range data.Prices {
-			batch.Queue(queryStockPrice,
-				price.Time, symbolId, price.Open, price.High, price.Low, price.Close, price.AdjClose, price.Volume)
+			batch.Queue(queryStockPrice, price.Time, symbolId, interval,
+				price.Open, price.High, price.Low, price.Close, price.AdjClose, price.Volume)
 		}
 
 		// Send the batch and get results
@@ -132,71 +190,502 @@ func (r *MarketRepository) SaveMarketData(ctx context.Context, data *yahoo.Marke
 		// Always close the batch results when done
 		defer results.Close()
 
-		return nil
+		for range data.Prices {
+			var inserted bool
+			if err := results.QueryRow().Scan(&inserted); err != nil {
+				return eris.Wrap(err, "failed to upsert stock price")
+			}
+			if inserted {
+				result.Inserted++
+			} else {
+				result.Updated++
+			}
+		}
+		if err := results.Close(); err != nil {
+			return eris.Wrap(err, "failed to upsert stock prices")
+		}
+
+		result.quarantinedIDs, err = saveQuarantinedBars(ctx, tx, quarantined)
+		result.Quarantined = len(result.quarantinedIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLastClose retrieves the close of the last stored bar of a symbol before the given time, nil if there
+// is none.
+func (r *MarketRepository) GetLastClose(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	before time.Time) (*float64, error) {
+	query := `
+		SELECT sp.close_price
+		FROM stock_prices sp
+		JOIN symbols s ON sp.symbol_id = s.id
+		WHERE s.symbol = $1 AND sp.bar_interval = $2 AND sp.time < $3
+		ORDER BY sp.time DESC
+		LIMIT 1
+	`
+
+	var closePrice float64
+	err := r.db.QueryRowContext(ctx, query, symbol, interval, before).Scan(&closePrice)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query last close for symbol: %s", symbol)
+	}
+	return &closePrice, nil
+}
+
+// SaveQuote upserts the symbol and its latest quote without touching stored prices.
+func (r *MarketRepository) SaveQuote(ctx context.Context, data *yahoo.MarketData) error {
+	if data.Quote == nil {
+		return eris.Errorf("no quote for symbol: %s", data.Symbol)
+	}
+	return r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		var symbolId int
+		err := tx.QueryRow(ctx, querySymbolUpsert, data.Symbol, data.Name, data.Exchange, time.Now()).Scan(&symbolId)
+		if err != nil {
+			return eris.Wrap(err, "failed to insert symbol")
+		}
+		return saveQuote(ctx, tx, symbolId, data)
 	})
 }
 
-func (r *MarketRepository) SavePriceFetchLogs(ctx context.Context, symbol string, fetchedAt time.Time, dataPoints int,
-	success bool, msg string) error {
+// GetQuotes retrieves the latest quotes of the given symbols. Symbols without a stored quote are omitted.
+func (r *MarketRepository) GetQuotes(ctx context.Context, symbols []string) ([]Quote, error) {
+	query := `
+		SELECT q.symbol_id, s.symbol, q.price, q.previous_close, q.change, q.change_percent,
+		       q.currency, q.market_time, q.updated_at
+		FROM latest_quotes q
+		JOIN symbols s ON q.symbol_id = s.id
+		WHERE s.symbol = ANY($1)
+		ORDER BY s.symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbols)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query latest quotes")
+	}
+
+	quotes, err := pgx.CollectRows(rows, pgx.RowToStructByName[Quote])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect latest quote rows")
+	}
+	return quotes, nil
+}
+
+// priceFetchLogColumns are the columns of PriceFetchLog, selected from price_fetch_logs as l
+const priceFetchLogColumns = `
+	l.id, l.symbol_id, COALESCE(l.symbol, '') AS symbol, l.fetched_at, l.data_points, l.success, l.error_msg,
+	l.provider, l.bar_interval, l.range_start, l.range_end, l.duration_ms, l.http_status, l.retries,
+	l.rows_inserted, l.rows_updated, l.rows_quarantined`
+
+// SavePriceFetchLog records a fetch, linking the stored symbol and the archived provider responses it was
+// parsed from, and returns the id of the log entry. The id and symbol id of the entry are ignored.
+func (r *MarketRepository) SavePriceFetchLog(ctx context.Context, entry *PriceFetchLog,
+	payloadHashes ...string) (int, error) {
 
 	queryPriceFetchLog := `
 		INSERT INTO price_fetch_logs (
-			symbol,     -- nullable INTEGER, FK to symbols(id)
-			fetched_at,    -- TIMESTAMPTZ, NOT NULL, defaults to NOW() if not provided
-			data_points,   -- nullable INTEGER
-			success,       -- BOOLEAN, NOT NULL
-			error_msg      -- nullable TEXT
+			symbol,           -- nullable TEXT
+			symbol_id,        -- nullable INTEGER, FK to symbols(id)
+			fetched_at,       -- TIMESTAMPTZ, NOT NULL, defaults to NOW() if not provided
+			data_points,      -- nullable INTEGER
+			success,          -- BOOLEAN, NOT NULL
+			error_msg,        -- nullable TEXT
+			payload_hashes,   -- nullable TEXT[], raw_payloads(hash)
+			provider,         -- nullable TEXT
+			bar_interval,     -- nullable TEXT
+			range_start,      -- nullable TIMESTAMPTZ
+			range_end,        -- nullable TIMESTAMPTZ
+			duration_ms,      -- nullable INTEGER
+			http_status,      -- nullable INTEGER
+			retries,          -- nullable INTEGER
+			rows_inserted,    -- nullable INTEGER
+			rows_updated,     -- nullable INTEGER
+			rows_quarantined  -- nullable INTEGER
 		) VALUES (
-			$1,  -- symbol_id
+			$1,  -- symbol
+			(SELECT id FROM symbols WHERE symbol = $1),
 			$2,  -- fetched_at
 			$3,  -- data_points
 			$4,  -- success
-			$5   -- error_msg
+			$5,  -- error_msg
+			$6,  -- payload_hashes
+			$7,  -- provider
+			$8,  -- bar_interval
+			$9,  -- range_start
+			$10, -- range_end
+			$11, -- duration_ms
+			$12, -- http_status
+			$13, -- retries
+			$14, -- rows_inserted
+			$15, -- rows_updated
+			$16  -- rows_quarantined
 		)
 		RETURNING id;
 	`
 
 	var fetchID int
-	err := r.db.QueryRowContext(ctx, queryPriceFetchLog, symbol, fetchedAt, dataPoints, success, msg).Scan(&fetchID)
+	err := r.db.QueryRowContext(ctx, queryPriceFetchLog, entry.Symbol, entry.FetchedAt, entry.DataPoints,
+		entry.Success, entry.ErrorMsg, payloadHashes, entry.Provider, entry.Interval, entry.RangeStart,
+		entry.RangeEnd, entry.DurationMs, entry.HTTPStatus, entry.Retries, entry.RowsInserted,
+		entry.RowsUpdated, entry.RowsQuarantined).Scan(&fetchID)
 	if err != nil {
-		return eris.Wrap(err, "failed to insert price fetch log")
+		return 0, eris.Wrap(err, "failed to insert price fetch log")
 	}
-	return nil
+	return fetchID, nil
 }
 
-func (r *MarketRepository) GetLastFetchTime(ctx context.Context, symbol string) (*time.Time, error) {
+// GetPriceFetchLogs retrieves fetch log entries by id, in id order
+func (r *MarketRepository) GetPriceFetchLogs(ctx context.Context, ids []int) ([]PriceFetchLog, error) {
 	query := `
-		SELECT fetched_at
+		SELECT ` + priceFetchLogColumns + `
+		FROM price_fetch_logs l
+		WHERE l.id = ANY($1)
+		ORDER BY l.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query price fetch logs")
+	}
+
+	logs, err := pgx.CollectRows(rows, pgx.RowToStructByName[PriceFetchLog])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect price fetch log rows")
+	}
+	return logs, nil
+}
+
+// ListPriceFetchLogs retrieves the fetch log entries of a symbol matching the filter, latest first
+func (r *MarketRepository) ListPriceFetchLogs(ctx context.Context, symbol string,
+	filter FetchLogFilter) ([]PriceFetchLog, error) {
+	query := `
+		SELECT ` + priceFetchLogColumns + `
+		FROM price_fetch_logs l
+		WHERE l.symbol = $1
+		  AND (NOT $2::boolean OR NOT l.success)
+		  AND ($3::timestamptz IS NULL OR l.fetched_at >= $3)
+		  AND ($4::timestamptz IS NULL OR l.fetched_at < $4)
+		ORDER BY l.fetched_at DESC, l.id DESC
+		LIMIT NULLIF($5, 0)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, filter.FailedOnly, nullableTime(filter.From),
+		nullableTime(filter.To), filter.Limit)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query price fetch logs of symbol: %s", symbol)
+	}
+
+	logs, err := pgx.CollectRows(rows, pgx.RowToStructByName[PriceFetchLog])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect price fetch log rows")
+	}
+	return logs, nil
+}
+
+// nullableTime converts zero times to NULL query arguments
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// GetLastFetchEnd returns the latest time covered by a successful fetch of a symbol, nil when it was never
+// fetched. Past windows fetched later do not move it back. Entries without a range count until the time they
+// were fetched.
+func (r *MarketRepository) GetLastFetchEnd(ctx context.Context, symbol string) (*time.Time, error) {
+	query := `
+		SELECT MAX(LEAST(COALESCE(range_end, fetched_at), fetched_at))
 		FROM price_fetch_logs
 		WHERE symbol = $1 AND success = true
-		ORDER BY fetched_at DESC
-		LIMIT 1
 	`
 
-	var fetchTime time.Time
-	err := r.db.QueryRowContext(ctx, query, symbol).Scan(&fetchTime)
+	var fetchEnd *time.Time
+	err := r.db.QueryRowContext(ctx, query, symbol).Scan(&fetchEnd)
 	if err != nil {
+		return nil, eris.Wrapf(err, "failed to get last fetch end for symbol: %s", symbol)
+	}
+
+	return fetchEnd, nil
+}
+
+// SaveUnreachableWindows records parts of a fetch window of a symbol the provider cannot serve
+func (r *MarketRepository) SaveUnreachableWindows(ctx context.Context, symbol string, windows []UnreachableWindow) error {
+	query := `
+		INSERT INTO unreachable_windows (symbol, bar_interval, start_time, end_time, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	batch := &pgx.Batch{}
+	for _, w := range windows {
+		batch.Queue(query, symbol, string(w.Interval), w.Start, w.End, w.Reason)
+	}
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	return eris.Wrapf(err, "failed to save unreachable windows for symbol: %s", symbol)
+}
+
+// LockFetch takes the cross-replica lock of fetching an interval of a symbol, waiting while another replica
+// holds it, and reports whether it had to wait.
+func (r *MarketRepository) LockFetch(ctx context.Context, symbol string, interval yahoo.IntervalAPI) (func(), bool, error) {
+	return r.db.AdvisoryLock(ctx, "fetch:"+symbol+":"+string(interval))
+}
+
+// GetUnreachableWindows retrieves the recorded unreachable windows of a symbol, oldest first
+func (r *MarketRepository) GetUnreachableWindows(ctx context.Context, symbol string) ([]UnreachableWindow, error) {
+	query := `
+		SELECT bar_interval, start_time, end_time, reason
+		FROM unreachable_windows
+		WHERE symbol = $1
+		ORDER BY start_time, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query unreachable windows for symbol: %s", symbol)
+	}
+	defer rows.Close()
+
+	var windows []UnreachableWindow
+	for rows.Next() {
+		var (
+			w        UnreachableWindow
+			interval string
+		)
+		if err := rows.Scan(&interval, &w.Start, &w.End, &w.Reason); err != nil {
+			return nil, eris.Wrap(err, "failed to scan unreachable window")
+		}
+		w.Interval = yahoo.IntervalAPI(interval)
+		windows = append(windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, eris.Wrapf(err, "failed to read unreachable windows for symbol: %s", symbol)
+	}
+	return windows, nil
+}
+
+// quarantinedBarColumns are the columns of QuarantinedBar
+const quarantinedBarColumns = `
+	id, symbol, bar_interval, time, open_price, high_price, low_price, close_price, adj_close, volume,
+	rule, reason, fetch_log_id, status, created_at, reviewed_at`
+
+// queryQuarantinedBarInsert quarantines a bar unless the same bar with the same values was already
+// released or discarded. A bar already awaiting review is replaced, so fetching a window again does not
+// quarantine its bars twice.
+const queryQuarantinedBarInsert = `
+	INSERT INTO quarantined_bars (
+		symbol, bar_interval, time, open_price, high_price, low_price, close_price, adj_close, volume,
+		rule, reason, fetch_log_id
+	)
+	SELECT $1::text, $2::text, $3::timestamptz, $4::numeric(18, 6), $5::numeric(18, 6), $6::numeric(18, 6),
+	       $7::numeric(18, 6), $8::numeric(18, 6), $9::bigint, $10::text, $11::text, $12::integer
+	WHERE NOT EXISTS (
+		SELECT 1
+		FROM quarantined_bars q
+		WHERE q.symbol = $1 AND q.bar_interval = $2 AND q.time = $3
+		  AND q.status <> 'pending'
+		  AND q.open_price IS NOT DISTINCT FROM $4::numeric(18, 6)
+		  AND q.high_price IS NOT DISTINCT FROM $5::numeric(18, 6)
+		  AND q.low_price IS NOT DISTINCT FROM $6::numeric(18, 6)
+		  AND q.close_price IS NOT DISTINCT FROM $7::numeric(18, 6)
+		  AND q.adj_close IS NOT DISTINCT FROM $8::numeric(18, 6)
+		  AND q.volume IS NOT DISTINCT FROM $9::bigint
+	)
+	ON CONFLICT (symbol, bar_interval, time) WHERE status = 'pending' DO UPDATE SET
+		open_price = EXCLUDED.open_price,
+		high_price = EXCLUDED.high_price,
+		low_price = EXCLUDED.low_price,
+		close_price = EXCLUDED.close_price,
+		adj_close = EXCLUDED.adj_close,
+		volume = EXCLUDED.volume,
+		rule = EXCLUDED.rule,
+		reason = EXCLUDED.reason,
+		fetch_log_id = EXCLUDED.fetch_log_id,
+		created_at = now()
+	RETURNING id
+`
+
+// saveQuarantinedBars quarantines bars within a transaction and returns the ids of the bars awaiting review,
+// bars already reviewed with the same values are skipped.
+func saveQuarantinedBars(ctx context.Context, tx pgx.Tx, bars []QuarantinedBar) ([]int64, error) {
+	if len(bars) == 0 {
+		return nil, nil
+	}
+	batch := &pgx.Batch{}
+	for _, bar := range bars {
+		batch.Queue(queryQuarantinedBarInsert, bar.Symbol, bar.Interval, bar.Time, bar.OpenPrice, bar.HighPrice,
+			bar.LowPrice, bar.ClosePrice, bar.AdjClose, bar.Volume, bar.Rule, bar.Reason, bar.FetchLogID)
+	}
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	var ids []int64
+	for range bars {
+		var id int64
+		err := results.QueryRow().Scan(&id)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+			continue
 		}
-		return nil, eris.Wrapf(err, "failed to get last fetch time for symbol: %s", symbol)
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to insert quarantined bar")
+		}
+		ids = append(ids, id)
+	}
+	if err := results.Close(); err != nil {
+		return nil, eris.Wrap(err, "failed to insert quarantined bars")
 	}
+	return ids, nil
+}
 
-	return &fetchTime, nil
+// SaveQuarantinedBars stores bars which failed validation. A bar already awaiting review is replaced and a
+// bar already released or discarded with the same values is skipped.
+func (r *MarketRepository) SaveQuarantinedBars(ctx context.Context, bars []QuarantinedBar) error {
+	return r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := saveQuarantinedBars(ctx, tx, bars)
+		return err
+	})
+}
+
+// LinkQuarantinedBars links quarantined bars to the fetch log entry which received them
+func (r *MarketRepository) LinkQuarantinedBars(ctx context.Context, fetchLogID int, ids []int64) error {
+	query := `UPDATE quarantined_bars SET fetch_log_id = $1 WHERE id = ANY($2)`
+	if _, err := r.db.ExecContext(ctx, query, fetchLogID, ids); err != nil {
+		return eris.Wrapf(err, "failed to link quarantined bars to fetch log %d", fetchLogID)
+	}
+	return nil
 }
 
-// GetStockPrice retrieves stock price data for a specific symbol from the database
-func (r *MarketRepository) GetStockPrice(ctx context.Context, symbol string) (*StockPrices, error) {
+// ListQuarantinedBars retrieves the quarantined bars matching the filter, latest first
+func (r *MarketRepository) ListQuarantinedBars(ctx context.Context,
+	filter QuarantineFilter) ([]QuarantinedBar, error) {
+	query := `
+		SELECT ` + quarantinedBarColumns + `
+		FROM quarantined_bars
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR symbol = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT NULLIF($3, 0)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Status, filter.Symbol, filter.Limit)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query quarantined bars")
+	}
+
+	bars, err := pgx.CollectRows(rows, pgx.RowToStructByName[QuarantinedBar])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect quarantined bar rows")
+	}
+	return bars, nil
+}
+
+// ReleaseQuarantinedBars stores the pending bars among ids as prices, overwriting stored ones, and marks them
+// released. Returns the released bars, ids of bars not pending are skipped.
+func (r *MarketRepository) ReleaseQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error) {
+	queryStockPrice := `
+		INSERT INTO stock_prices (
+			time, symbol_id, bar_interval, open_price, high_price, low_price, close_price, adj_close, volume
+		)
+		SELECT $1, s.id, $3, $4, $5, $6, $7, $8, $9
+		FROM symbols s
+		WHERE s.symbol = $2
+		ON CONFLICT (symbol_id, bar_interval, time) DO UPDATE SET
+			open_price = EXCLUDED.open_price,
+			high_price = EXCLUDED.high_price,
+			low_price = EXCLUDED.low_price,
+			close_price = EXCLUDED.close_price,
+			adj_close = EXCLUDED.adj_close,
+			volume = EXCLUDED.volume
+	`
+
+	var released []QuarantinedBar
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		released, err = reviewQuarantinedBars(ctx, tx, ids, QuarantineReleased)
+		if err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		symbols := make(map[string]bool)
+		for _, bar := range released {
+			// fetches store their symbol also when all bars are quarantined, this only guards against a missing one
+			if !symbols[bar.Symbol] {
+				if _, err := tx.Exec(ctx, querySymbolUpsert, bar.Symbol, "", "", time.Now()); err != nil {
+					return eris.Wrap(err, "failed to insert symbol")
+				}
+				symbols[bar.Symbol] = true
+			}
+			batch.Queue(queryStockPrice, bar.Time, bar.Symbol, bar.Interval, bar.OpenPrice, bar.HighPrice,
+				bar.LowPrice, bar.ClosePrice, bar.AdjClose, bar.Volume)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return eris.Wrap(err, "failed to upsert released stock prices")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// DiscardQuarantinedBars marks the pending bars among ids discarded, they are never stored as prices. Returns
+// the discarded bars, ids of bars not pending are skipped.
+func (r *MarketRepository) DiscardQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error) {
+	var discarded []QuarantinedBar
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		discarded, err = reviewQuarantinedBars(ctx, tx, ids, QuarantineDiscarded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return discarded, nil
+}
+
+// reviewQuarantinedBars moves the pending bars among ids to a review state and returns them in id order
+func reviewQuarantinedBars(ctx context.Context, tx pgx.Tx, ids []int64, status string) ([]QuarantinedBar, error) {
+	query := `
+		UPDATE quarantined_bars
+		SET status = $2, reviewed_at = now()
+		WHERE id = ANY($1) AND status = 'pending'
+		RETURNING ` + quarantinedBarColumns
+
+	rows, err := tx.Query(ctx, query, ids, status)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to update quarantined bars")
+	}
+
+	bars, err := pgx.CollectRows(rows, pgx.RowToStructByName[QuarantinedBar])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect quarantined bar rows")
+	}
+	slices.SortFunc(bars, func(a, b QuarantinedBar) int { return cmp.Compare(a.ID, b.ID) })
+	return bars, nil
+}
+
+// GetStockPrice retrieves the bars of an interval of a symbol from the database
+func (r *MarketRepository) GetStockPrice(ctx context.Context, symbol string,
+	interval yahoo.IntervalAPI) (*StockPrices, error) {
 	query := `
 		SELECT sp.time, sp.open_price, sp.high_price, sp.low_price, 
 		       sp.close_price, sp.adj_close, sp.volume, sp.symbol_id
 		FROM stock_prices sp
 		JOIN symbols s ON sp.symbol_id = s.id
-		WHERE s.symbol = $1
+		WHERE s.symbol = $1 AND sp.bar_interval = $2
 		ORDER BY sp.time DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, symbol)
+	rows, err := r.db.QueryContext(ctx, query, symbol, interval)
 	if err != nil {
 		return nil, eris.Wrapf(err, "failed to query stock prices for symbol: %s", symbol)
 	}
@@ -12,6 +12,7 @@ import (
 	testsTools "github.com/market-data/internal/tests"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 var (
@@ -122,6 +123,178 @@ func TestMarketRepository_SaveMarketData(t *testing.T) {
 	err = json.Unmarshal(yahooData, &data)
 	require.NoError(t, err)
 
-	err = marketRepo.SaveMarketData(context.TODO(), &data)
+	result, err := marketRepo.SaveMarketData(context.TODO(), &data)
 	require.NoError(t, err)
+	require.Equal(t, len(data.Prices), result.Inserted)
+	require.Zero(t, result.Updated)
+
+	result, err = marketRepo.SaveMarketData(context.TODO(), &data)
+	require.NoError(t, err)
+	require.Zero(t, result.Inserted)
+	require.Equal(t, len(data.Prices), result.Updated)
+
+	// bars of another interval at the same times do not overwrite the daily ones
+	intraday := data
+	intraday.Interval = yahoo.Interval5m
+	intraday.Prices = []yahoo.StockPrice{data.Prices[0]}
+	intraday.Prices[0].Close++
+	result, err = marketRepo.SaveMarketData(context.TODO(), &intraday)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Inserted)
+
+	daily, err := marketRepo.GetStockPrice(context.TODO(), data.Symbol, yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Len(t, *daily, len(data.Prices))
+	fiveMinutes, err := marketRepo.GetStockPrice(context.TODO(), data.Symbol, yahoo.Interval5m)
+	require.NoError(t, err)
+	require.Len(t, *fiveMinutes, 1)
+}
+
+func TestMarketRepository_Quotes(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	marketRepo := market.NewMarketRepository(db)
+
+	var data yahoo.MarketData
+	err = json.Unmarshal(yahooData, &data)
+	require.NoError(t, err)
+
+	marketTime := data.Prices[len(data.Prices)-1].Time
+	data.Quote = &yahoo.MarketQuote{Price: 200, PreviousClose: 196.58, Currency: "USD", Time: marketTime}
+	_, err = marketRepo.SaveMarketData(context.TODO(), &data)
+	require.NoError(t, err)
+
+	quotes, err := marketRepo.GetQuotes(context.TODO(), []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 1)
+	require.Equal(t, "AAPL", quotes[0].Symbol)
+	require.Equal(t, 200.0, quotes[0].Price)
+	require.InDelta(t, 3.42, *quotes[0].Change, 1e-6)
+	require.InDelta(t, 1.739749, *quotes[0].ChangePercent, 1e-6)
+
+	// an older quote does not replace the stored one
+	stale := yahoo.MarketData{Symbol: "AAPL", Name: data.Name, Exchange: data.Exchange,
+		Quote: &yahoo.MarketQuote{Price: 150, Currency: "USD", Time: marketTime.Add(-time.Hour)}}
+	require.NoError(t, marketRepo.SaveQuote(context.TODO(), &stale))
+
+	quotes, err = marketRepo.GetQuotes(context.TODO(), []string{"AAPL"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 1)
+	require.Equal(t, 200.0, quotes[0].Price)
+}
+
+func TestMarketRepository_UnreachableWindows(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	marketRepo := market.NewMarketRepository(db)
+	ctx := context.TODO()
+
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	windows := []market.UnreachableWindow{
+		{Interval: yahoo.Interval1m, Start: start.AddDate(0, 0, 10), End: start.AddDate(0, 0, 20), Reason: market.ReasonNoRangeSupport},
+		{Interval: yahoo.Interval1m, Start: start, End: start.AddDate(0, 0, 10), Reason: market.ReasonBeyondLookback},
+	}
+	require.NoError(t, marketRepo.SaveUnreachableWindows(ctx, "AAPL", windows))
+
+	stored, err := marketRepo.GetUnreachableWindows(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	require.Equal(t, market.ReasonBeyondLookback, stored[0].Reason)
+	require.Equal(t, yahoo.Interval1m, stored[0].Interval)
+	require.True(t, start.Equal(stored[0].Start))
+	require.True(t, start.AddDate(0, 0, 20).Equal(stored[1].End))
+
+	stored, err = marketRepo.GetUnreachableWindows(ctx, "MSFT")
+	require.NoError(t, err)
+	require.Empty(t, stored)
+}
+
+func TestMarketRepository_QuarantinedBars(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	marketRepo := market.NewMarketRepository(db)
+	ctx := context.TODO()
+
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	violation := &market.Violation{Rule: market.RuleHighLow, Reason: "high 90 below low 110"}
+	bars := []market.QuarantinedBar{
+		market.NewQuarantinedBar("AAPL", yahoo.Interval1d, &yahoo.StockPrice{Time: start, Open: 100, High: 90, Low: 110, Close: 100}, violation),
+		market.NewQuarantinedBar("AAPL", yahoo.Interval1d, &yahoo.StockPrice{Time: start.AddDate(0, 0, 1), Open: 100, High: 90, Low: 110, Close: 100}, violation),
+	}
+	require.NoError(t, marketRepo.SaveQuarantinedBars(ctx, bars))
+	// fetching the same window again does not quarantine its bars twice
+	require.NoError(t, marketRepo.SaveQuarantinedBars(ctx, bars))
+
+	pending, err := marketRepo.ListQuarantinedBars(ctx, market.QuarantineFilter{Status: market.QuarantinePending})
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, market.RuleHighLow, pending[0].Rule)
+
+	released, err := marketRepo.ReleaseQuarantinedBars(ctx, []int64{pending[0].ID})
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	require.Equal(t, market.QuarantineReleased, released[0].Status)
+	require.NotNil(t, released[0].ReviewedAt)
+
+	prices, err := marketRepo.GetStockPrice(ctx, "AAPL", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Len(t, *prices, 1, "released bars are stored as prices")
+
+	// reviewed bars are skipped
+	discarded, err := marketRepo.DiscardQuarantinedBars(ctx, []int64{pending[0].ID, pending[1].ID})
+	require.NoError(t, err)
+	require.Len(t, discarded, 1)
+	require.Equal(t, pending[1].ID, discarded[0].ID)
+
+	pending, err = marketRepo.ListQuarantinedBars(ctx, market.QuarantineFilter{Status: market.QuarantinePending})
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	all, err := marketRepo.ListQuarantinedBars(ctx, market.QuarantineFilter{Symbol: "AAPL"})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	// refetched bars already reviewed are not quarantined again, changed ones are, along with the prices
+	changed := market.NewQuarantinedBar("AAPL", yahoo.Interval1d,
+		&yahoo.StockPrice{Time: start.AddDate(0, 0, 1), Open: 100, High: 80, Low: 120, Close: 100}, violation)
+	result, err := marketRepo.SaveMarketData(ctx, &yahoo.MarketData{
+		Symbol:   "AAPL",
+		Interval: yahoo.Interval1d,
+		Prices:   []yahoo.StockPrice{{Time: start.AddDate(0, 0, 2), Open: 100, High: 105, Low: 95, Close: 102}},
+	}, bars[0], bars[1], changed)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Inserted)
+	require.Equal(t, 1, result.Quarantined)
+
+	pending, err = marketRepo.ListQuarantinedBars(ctx, market.QuarantineFilter{Status: market.QuarantinePending})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, 80.0, *pending[0].HighPrice)
+
+	lastClose, err := marketRepo.GetLastClose(ctx, "AAPL", yahoo.Interval1d, start.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	require.NotNil(t, lastClose)
+	require.Equal(t, 100.0, *lastClose)
+
+	lastClose, err = marketRepo.GetLastClose(ctx, "AAPL", yahoo.Interval1d, start)
+	require.NoError(t, err)
+	require.Nil(t, lastClose)
 }
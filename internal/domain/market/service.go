@@ -3,8 +3,11 @@ package market
 import (
 	"context"
 	"errors"
+	"github.com/market-data/internal/domain/archive"
+	"github.com/market-data/internal/domain/usage"
 	"github.com/market-data/internal/providers/yahoo"
 	"github.com/rotisserie/eris"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -14,6 +17,11 @@ import (
 var (
 	ErrSymbolNotFound = errors.New("symbol not found")
 	ErrInvalidData    = errors.New("invalid market data")
+	ErrQuoteNotFound  = errors.New("quote not found")
+	ErrSymbolExists   = errors.New("symbol already exists")
+	ErrInvalidRefresh = errors.New("invalid refresh request")
+	// ErrUnknownSymbol is returned for symbols the provider does not know
+	ErrUnknownSymbol = yahoo.ErrUnknownSymbol
 )
 
 // DataProvider defines the interface for market data providers
@@ -27,68 +35,114 @@ type DataProvider interface {
 	) (*yahoo.MarketData, error)
 }
 
+// BatchQuoteProvider is implemented by data providers able to fetch quotes of many symbols per request. Bars
+// have no batch counterpart, multi-symbol endpoints carry no OHLCV bars, so they are fetched per symbol.
+type BatchQuoteProvider interface {
+	// GetQuotesBatch fetches the latest quotes of the symbols, reporting a result or an error per symbol
+	GetQuotesBatch(ctx context.Context, symbols []string) []yahoo.BatchResult
+}
+
+// PayloadParser converts raw provider responses into market data
+type PayloadParser interface {
+	// ParseMarketData parses the responses of a single fetch of a symbol
+	ParseMarketData(symbol string, payloads [][]byte) (*yahoo.MarketData, error)
+}
+
+// RawDataProvider is implemented by data providers able to return the unparsed responses of a fetch,
+// which allows archiving them and parsing them again later
+type RawDataProvider interface {
+	PayloadParser
+	// Name returns the provider name stored with archived responses
+	Name() string
+	// FetchRawMarketData fetches the responses GetMarketData would parse, in request order
+	FetchRawMarketData(
+		ctx context.Context,
+		symbol string,
+		interval yahoo.IntervalAPI,
+		period yahoo.PeriodAPI,
+	) ([][]byte, error)
+}
+
+// PayloadArchive stores raw provider responses content-addressed and finds them through the fetch log
+type PayloadArchive interface {
+	SavePayloads(ctx context.Context, provider string, bodies [][]byte) ([]string, error)
+	GetFetches(ctx context.Context, symbol string, from, to time.Time) ([]archive.Fetch, error)
+}
+
+// AnomalyDetector scores stored bars for anomalies such as bad ticks
+type AnomalyDetector interface {
+	// DetectAnomalies scores the bars of an interval of a symbol stored from a time on
+	DetectAnomalies(ctx context.Context, symbol string, interval yahoo.IntervalAPI, from time.Time) error
+}
+
 // MarketService provides core domain operations for market data
 type MarketService struct {
 	repo     Repository
 	provider DataProvider
+	archive  PayloadArchive
+	planner  *FetchPlanner
+	locker   FetchLocker
+	flights  *fetchFlights
+	// validator is nil when incoming bars are stored unchecked
+	validator *Validator
+	// detector is nil when stored bars are not scored for anomalies
+	detector AnomalyDetector
+
+	// providerName is recorded in the fetch log, the name of the provider when empty
+	providerName string
+
+	// Fetch-through settings, unknown is nil when disabled and quota nil when fetch-throughs are not limited
+	// by the daily request cap of the provider
+	fetchThroughTimeout time.Duration
+	unknown             *unknownSymbols
+	quota               QuotaChecker
 
 	// Auto-update settings
 	updateInterval   time.Duration
 	enableAutoUpdate bool
 	stopChan         chan struct{}
-
-	periodConv   map[int]yahoo.PeriodAPI
-	intervalConv map[int]yahoo.IntervalAPI
 }
 
 // NewMarketService creates a new market data service
-func NewMarketService(repo Repository, provider *yahoo.Client) *MarketService {
-	var periodConv = map[int]yahoo.PeriodAPI{
-		1:       yahoo.Period1d,
-		5:       yahoo.Period5d,
-		30:      yahoo.Period1mo,
-		3 * 30:  yahoo.Period3mo,
-		6 * 30:  yahoo.Period6mo,
-		360:     yahoo.Period1y,
-		2 * 360: yahoo.Period2y,
-		5 * 360: yahoo.Period5y,
-	}
-	var intervalConv = map[int]yahoo.IntervalAPI{
-		1:       yahoo.Interval5m,
-		5 * 360: yahoo.Interval1d,
-	}
-
+func NewMarketService(repo Repository, provider DataProvider) *MarketService {
 	return &MarketService{
-		repo:         repo,
-		stopChan:     make(chan struct{}),
-		periodConv:   periodConv,
-		intervalConv: intervalConv,
-		provider:     provider,
+		repo:     repo,
+		stopChan: make(chan struct{}),
+		provider: provider,
+		planner:  NewFetchPlanner(provider),
+		flights:  &fetchFlights{},
 	}
 }
 
-// SetAutoUpdateSettings sets the auto-update settings for the service
-func (s *MarketService) SetAutoUpdateSettings(interval time.Duration, enable bool) {
-	s.updateInterval = interval
-	s.enableAutoUpdate = enable
+// SetFetchLocker serializes the fetches of an interval of a symbol with those of other replicas
+func (s *MarketService) SetFetchLocker(locker FetchLocker) {
+	s.locker = locker
 }
 
-func (s *MarketService) getPeriodAPI(period int) yahoo.PeriodAPI {
-	for i, periodAPI := range s.periodConv {
-		if period < i {
-			return periodAPI
-		}
-	}
-	return yahoo.Period5y
+// SetProviderName sets the provider name recorded in the fetch log, for providers without a Name method
+func (s *MarketService) SetProviderName(name string) {
+	s.providerName = name
 }
 
-func (s *MarketService) getIntervalAPI(interval int) yahoo.IntervalAPI {
-	for i, intervalAPI := range s.intervalConv {
-		if interval < i {
-			return intervalAPI
-		}
-	}
-	return yahoo.Interval1d
+// SetValidator checks incoming bars before they are stored, bars failing validation are quarantined
+func (s *MarketService) SetValidator(validator *Validator) {
+	s.validator = validator
+}
+
+// SetAnomalyDetector scores the bars stored by fetches and imports for anomalies
+func (s *MarketService) SetAnomalyDetector(detector AnomalyDetector) {
+	s.detector = detector
+}
+
+// SetPayloadArchive enables archiving of raw provider responses
+func (s *MarketService) SetPayloadArchive(payloadArchive PayloadArchive) {
+	s.archive = payloadArchive
+}
+
+// SetAutoUpdateSettings sets the auto-update settings for the service
+func (s *MarketService) SetAutoUpdateSettings(interval time.Duration, enable bool) {
+	s.updateInterval = interval
+	s.enableAutoUpdate = enable
 }
 
 //// GetMarketData retrieves market data for a specific symbol
@@ -112,97 +166,607 @@ func (s *MarketService) getIntervalAPI(interval int) yahoo.IntervalAPI {
 //	return s.repo.SaveMarketData(ctx, md)
 //}
 
-// FetchAndStoreMarketData fetches market data for a symbol from the provider and stores it
+// RegisterSymbol validates a symbol against the provider and stores it without prices, so its next fetch
+// downloads the initial history. The symbol is stored as normalized by the provider, e.g. "btc-usdt" as
+// "BTCUSDT", which is the symbol its bars are stored under. Returns ErrSymbolExists for stored symbols and
+// ErrUnknownSymbol for symbols the provider does not know.
+func (s *MarketService) RegisterSymbol(ctx context.Context, symbol string) (*Symbol, error) {
+	if s.provider == nil {
+		return nil, errors.New("no data provider configured")
+	}
+
+	_, err := s.repo.GetSymbol(ctx, symbol)
+	if err == nil {
+		return nil, ErrSymbolExists
+	}
+	if !errors.Is(err, ErrSymbolNotFound) {
+		return nil, eris.Wrap(err, "failed to get symbol")
+	}
+
+	data, err := s.provider.GetMarketData(ctx, symbol, yahoo.Interval1d, yahoo.Period5d)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to validate symbol %s", symbol)
+	}
+
+	registered := NewSymbolFromMarketData(data)
+	if registered.Symbol == "" {
+		registered.Symbol = symbol
+	}
+	if registered.Symbol != symbol {
+		_, err := s.repo.GetSymbol(ctx, registered.Symbol)
+		if err == nil {
+			return nil, ErrSymbolExists
+		}
+		if !errors.Is(err, ErrSymbolNotFound) {
+			return nil, eris.Wrap(err, "failed to get symbol")
+		}
+	}
+	if err := s.repo.SaveSymbol(ctx, registered); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("symbol", registered.Symbol).
+		Str("requested", symbol).
+		Str("name", registered.Name).
+		Str("exchange", registered.Exchange).
+		Msg("Symbol registered")
+	return registered, nil
+}
+
+// initialHistoryYears is how far back the first fetch of a symbol reaches
+const initialHistoryYears = 5
+
+// FetchAndStoreMarketData fetches market data for a symbol from the provider and stores it. The first fetch
+// of a symbol downloads years of daily bars, later fetches cover the time since the last successful fetch.
+// The fetch window is split into requests the provider can serve, parts it cannot serve are recorded as
+// unreachable windows. A failed request stops the fetch, the next one starts after the last stored request.
+// Concurrent fetches of a symbol are coalesced, a fetch waiting for another one only covers the time since that one.
 func (s *MarketService) FetchAndStoreMarketData(ctx context.Context, symbol string) error {
 	if s.provider == nil {
 		return errors.New("no data provider configured")
 	}
 
 	fetchedAt := time.Now()
-	symbolData, err := s.repo.GetSymbol(ctx, symbol)
-	if errors.Is(err, ErrSymbolNotFound) {
-		data, err := s.provider.GetMarketData(ctx, symbol, yahoo.Interval1d, yahoo.Period5y)
-		if err != nil {
-			err := s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, 0, false, err.Error())
-			if err != nil {
-				return eris.Wrap(err, "failed to save price fetch logs")
+	interval, start, err := s.nextWindow(ctx, symbol, fetchedAt)
+	if err != nil {
+		return err
+	}
+
+	return s.flights.do(ctx, s.locker, symbol, interval, start, func(ctx context.Context, waited bool) error {
+		if waited {
+			fetchedAt = time.Now()
+			if interval, start, err = s.nextWindow(ctx, symbol, fetchedAt); err != nil {
+				return err
 			}
-			return eris.Wrap(err, "failed to get market data")
 		}
+		_, err := s.fetchWindow(ctx, symbol, interval, start, fetchedAt, fetchedAt)
+		return err
+	})
+}
+
+// nextWindow returns the interval and start of the next fetch of a symbol ending at fetchedAt
+func (s *MarketService) nextWindow(ctx context.Context, symbol string, fetchedAt time.Time) (yahoo.IntervalAPI,
+	time.Time, error) {
+	interval := yahoo.Interval1d
+	start := fetchedAt.AddDate(-initialHistoryYears, 0, 0)
 
-		err = s.repo.SaveMarketData(ctx, data)
+	_, err := s.repo.GetSymbol(ctx, symbol)
+	if err != nil && !errors.Is(err, ErrSymbolNotFound) {
+		return "", time.Time{}, eris.Wrap(err, "failed to get symbol")
+	}
+	if err == nil {
+		// get the end of the last fetch of the symbol from the log
+		fetchTime, err := s.repo.GetLastFetchEnd(ctx, symbol)
 		if err != nil {
-			err := s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, len(data.Prices), false, err.Error())
-			if err != nil {
-				return eris.Wrap(err, "failed to save price fetch logs")
+			return "", time.Time{}, eris.Wrap(err, "failed to get last fetch end")
+		}
+		// symbols stored without a successful fetch get the initial history, imported ones continue from
+		// their last imported bar
+		if fetchTime != nil {
+			interval = fetchInterval(fetchedAt.Sub(*fetchTime))
+			start = AlignStart(interval, *fetchTime)
+		}
+	}
+	return interval, start, nil
+}
+
+// FetchAndStoreRange fetches the bars of an interval of a symbol from start to now and stores them, start is
+// aligned to the bar containing it. Like FetchAndStoreMarketData, the window is split into requests the
+// provider can serve. A concurrent fetch of the interval of the symbol covering the window is awaited instead
+// of fetching again.
+func (s *MarketService) FetchAndStoreRange(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	start time.Time) error {
+	if s.provider == nil {
+		return errors.New("no data provider configured")
+	}
+	start = AlignStart(interval, start)
+	return s.flights.do(ctx, s.locker, symbol, interval, start, func(ctx context.Context, _ bool) error {
+		fetchedAt := time.Now()
+		_, err := s.fetchWindow(ctx, symbol, interval, start, fetchedAt, fetchedAt)
+		return err
+	})
+}
+
+// RefreshRequest selects the window of a manual refresh, zero fields default to those of the next scheduled fetch
+type RefreshRequest struct {
+	Interval yahoo.IntervalAPI
+	From     time.Time
+	To       time.Time // defaults to now
+}
+
+// Refresh fetches and stores a window of a stored symbol on demand and returns the fetch log entries written,
+// also when some requests failed. A window reaching past the last fetch also covers the time since it, so the
+// scheduled fetches are not left with a gap. Returns ErrSymbolNotFound for symbols not stored and
+// ErrInvalidRefresh for empty windows.
+func (s *MarketService) Refresh(ctx context.Context, symbol string, request RefreshRequest) ([]PriceFetchLog, error) {
+	if s.provider == nil {
+		return nil, errors.New("no data provider configured")
+	}
+	if _, err := s.repo.GetSymbol(ctx, symbol); err != nil {
+		return nil, err
+	}
+
+	// fetch log times are stored in microseconds
+	now := time.Now().Truncate(time.Microsecond)
+	interval, nextStart, err := s.nextWindow(ctx, symbol, now)
+	if err != nil {
+		return nil, err
+	}
+	if request.Interval != "" {
+		interval = request.Interval
+	}
+	start, end := nextStart, now
+	if !request.From.IsZero() {
+		start = request.From
+	}
+	if !request.To.IsZero() && request.To.Before(now) {
+		end = request.To
+	}
+	if end.After(nextStart) && nextStart.Before(start) {
+		start = nextStart
+	}
+	start = AlignStart(interval, start)
+	if !start.Before(end) {
+		return nil, eris.Wrapf(ErrInvalidRefresh, "window from %s to %s is empty", start, end)
+	}
+
+	log.Info().
+		Str("symbol", symbol).
+		Str("interval", string(interval)).
+		Time("from", start).
+		Time("to", end).
+		Msg("Refreshing symbol")
+
+	var logIDs []int
+	err = s.flights.fetchLocked(ctx, s.locker, symbol, interval, false, func(ctx context.Context, _ bool) error {
+		var err error
+		logIDs, err = s.fetchWindow(ctx, symbol, interval, start, end, now)
+		return err
+	})
+	if len(logIDs) == 0 {
+		return nil, err
+	}
+
+	logs, logErr := s.repo.GetPriceFetchLogs(ctx, logIDs)
+	if logErr != nil {
+		return nil, errors.Join(err, logErr)
+	}
+	return logs, err
+}
+
+// fetchWindow plans the window [start, end) of an interval, records its unreachable parts and fetches and
+// stores the planned requests in time order. It returns the ids of the fetch log entries written, one per
+// request. Each entry is logged at now with the range its request covers within the window, the next fetch
+// starts at the latest range end, so a past window does not move it. The requests following a failed one are
+// not sent: the next fetch starts after the last stored request and covers them together with the failed one.
+func (s *MarketService) fetchWindow(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	start, end, now time.Time) ([]int, error) {
+	plan := s.planner.PlanRange(interval, start, end, now)
+	windowEnd := minTime(end, now)
+	if len(plan.Unreachable) > 0 {
+		log.Warn().
+			Str("symbol", symbol).
+			Interface("windows", plan.Unreachable).
+			Msg("Parts of the fetch window cannot be served by the provider")
+		if err := s.repo.SaveUnreachableWindows(ctx, symbol, plan.Unreachable); err != nil {
+			return nil, eris.Wrap(err, "failed to save unreachable windows")
+		}
+	}
+
+	log.Debug().
+		Str("symbol", symbol).
+		Int("requests", len(plan.Requests)).
+		Msg("fetching market data from provider")
+	var logIDs []int
+	for i, request := range plan.Requests {
+		logID, err := s.fetchAndStore(ctx, symbol, request, now, minTime(request.End, windowEnd))
+		if logID != 0 {
+			logIDs = append(logIDs, logID)
+		}
+		if err != nil {
+			if skipped := len(plan.Requests) - i - 1; skipped > 0 {
+				log.Warn().
+					Str("symbol", symbol).
+					Int("skipped", skipped).
+					Msg("Fetch request failed, the remaining requests of the window are left to the next fetch")
 			}
-			return eris.Wrap(err, "failed to save market data")
+			return logIDs, err
 		}
+	}
+	return logIDs, nil
+}
 
-		err = s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, len(data.Prices), true, "")
+// fetchInterval selects the bar interval of an incremental fetch, intraday bars for symbols fetched within the
+// last day.
+func fetchInterval(sinceLastFetch time.Duration) yahoo.IntervalAPI {
+	if sinceLastFetch < 24*time.Hour {
+		return yahoo.Interval5m
+	}
+	return yahoo.Interval1d
+}
+
+// fetchAndStore fetches market data from the provider, stores it and records the outcome in the fetch log,
+// returning the id of the log entry. The entry covers the request until rangeEnd.
+func (s *MarketService) fetchAndStore(ctx context.Context, symbol string, request FetchRequest,
+	fetchedAt, rangeEnd time.Time) (int, error) {
+	entry := &PriceFetchLog{
+		Symbol:     symbol,
+		FetchedAt:  fetchedAt,
+		Provider:   s.fetchLogProvider(),
+		Interval:   ptr(string(request.Interval)),
+		RangeStart: ptr(request.Start),
+		RangeEnd:   ptr(rangeEnd),
+	}
+	started := time.Now()
+	trace := &usage.Trace{}
+	data, payloadHashes, err := s.fetchMarketData(usage.WithTrace(ctx, trace), symbol, request)
+	if trace.Requests() > 0 {
+		entry.HTTPStatus = ptr(trace.Status())
+		entry.Retries = ptr(trace.Retries())
+	}
+	if err != nil {
+		entry.failed(started, 0, err)
+		logID, logErr := s.repo.SavePriceFetchLog(ctx, entry, payloadHashes...)
+		if logErr != nil {
+			return 0, eris.Wrap(logErr, "failed to save price fetch logs")
+		}
+		return logID, eris.Wrap(err, "failed to get market data")
+	}
+
+	data.Interval = request.Interval
+	valid, saved, err := s.store(ctx, data)
+	if err != nil {
+		entry.failed(started, len(data.Prices), err)
+		logID, logErr := s.repo.SavePriceFetchLog(ctx, entry, payloadHashes...)
+		if logErr != nil {
+			return 0, eris.Wrap(logErr, "failed to save price fetch logs")
+		}
+		return logID, eris.Wrap(err, "failed to save market data")
+	}
+
+	entry.succeeded(started, len(data.Prices), saved)
+	if s.validator != nil {
+		entry.RowsQuarantined = ptr(saved.Quarantined)
+	}
+	logID, err := s.repo.SavePriceFetchLog(ctx, entry, payloadHashes...)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to save price fetch logs")
+	}
+	s.detectAnomalies(ctx, valid)
+	return logID, s.linkQuarantined(ctx, logID, saved)
+}
+
+// store validates market data and saves the bars passing validation, quarantining the others in the same
+// transaction. All bars pass without a validator. The first bar is checked against the last stored close
+// when the validator bounds the move of the close. Returns the valid market data.
+func (s *MarketService) store(ctx context.Context, data *yahoo.MarketData) (*yahoo.MarketData, *SaveResult,
+	error) {
+	if s.validator == nil {
+		saved, err := s.repo.SaveMarketData(ctx, data)
+		return data, saved, err
+	}
+
+	var lastClose *float64
+	if s.validator.ChecksChange() && len(data.Prices) > 0 {
+		var err error
+		lastClose, err = s.repo.GetLastClose(ctx, data.Symbol, BarInterval(data), data.Prices[0].Time)
 		if err != nil {
-			return eris.Wrap(err, "failed to save price fetch logs")
+			return nil, nil, err
 		}
+	}
+	valid, quarantined := s.validator.Split(data, lastClose)
+	saved, err := s.repo.SaveMarketData(ctx, valid, quarantined...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if saved.Quarantined > 0 {
+		log.Warn().
+			Str("symbol", data.Symbol).
+			Int("bars", saved.Quarantined).
+			Str("rule", quarantined[0].Rule).
+			Msg("Bars failed validation and were quarantined")
+	}
+	return valid, saved, nil
+}
 
+// linkQuarantined links the bars quarantined when saving to the fetch log entry which received them
+func (s *MarketService) linkQuarantined(ctx context.Context, logID int, saved *SaveResult) error {
+	if len(saved.quarantinedIDs) == 0 {
 		return nil
 	}
+	if err := s.repo.LinkQuarantinedBars(ctx, logID, saved.quarantinedIDs); err != nil {
+		return eris.Wrap(err, "failed to link quarantined bars")
+	}
+	return nil
+}
+
+// detectAnomalies scores the bars just stored. Failures are only logged, the bars are stored either way.
+func (s *MarketService) detectAnomalies(ctx context.Context, data *yahoo.MarketData) {
+	if s.detector == nil || len(data.Prices) == 0 {
+		return
+	}
+	from := data.Prices[0].Time
+	for _, price := range data.Prices[1:] {
+		if price.Time.Before(from) {
+			from = price.Time
+		}
+	}
+	if err := s.detector.DetectAnomalies(ctx, data.Symbol, BarInterval(data), from); err != nil {
+		log.Error().Err(err).Str("symbol", data.Symbol).Msg("Failed to detect price anomalies")
+	}
+}
 
+// fetchLogProvider returns the provider name recorded in the fetch log, nil when unknown
+func (s *MarketService) fetchLogProvider() *string {
+	if s.providerName != "" {
+		return &s.providerName
+	}
+	if named, ok := s.provider.(interface{ Name() string }); ok {
+		return ptr(named.Name())
+	}
+	return nil
+}
+
+// failed completes a fetch log entry of a fetch started at started which failed with err
+func (l *PriceFetchLog) failed(started time.Time, dataPoints int, err error) {
+	l.DataPoints = &dataPoints
+	l.ErrorMsg = ptr(err.Error())
+	l.DurationMs = ptr(int(time.Since(started).Milliseconds()))
+}
+
+// succeeded completes a fetch log entry of a fetch started at started which stored its data
+func (l *PriceFetchLog) succeeded(started time.Time, dataPoints int, saved *SaveResult) {
+	l.Success = true
+	l.DataPoints = &dataPoints
+	l.RowsInserted = &saved.Inserted
+	l.RowsUpdated = &saved.Updated
+	l.DurationMs = ptr(int(time.Since(started).Milliseconds()))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// fetchMarketData fetches market data from the provider. With an archive configured, the raw responses of
+// providers supporting it are archived before parsing and their content addresses returned, also when
+// parsing fails.
+func (s *MarketService) fetchMarketData(ctx context.Context, symbol string,
+	request FetchRequest) (*yahoo.MarketData, []string, error) {
+	if request.Period == "" {
+		return s.fetchMarketDataRange(ctx, symbol, request)
+	}
+
+	rawProvider, ok := s.provider.(RawDataProvider)
+	if s.archive == nil || !ok {
+		data, err := s.provider.GetMarketData(ctx, symbol, request.Interval, request.Period)
+		return data, nil, err
+	}
+
+	payloads, err := rawProvider.FetchRawMarketData(ctx, symbol, request.Interval, request.Period)
 	if err != nil {
-		return eris.Wrap(err, "failed to get symbol")
+		return nil, nil, err
 	}
+	return s.archiveAndParse(ctx, rawProvider, symbol, payloads)
+}
 
-	// get last fetch time for specific symbol form log
-	fetchTime, err := s.repo.GetLastFetchTime(ctx, symbol)
+// fetchMarketDataRange fetches market data of an absolute range, which the planner only requests from
+// providers supporting it.
+func (s *MarketService) fetchMarketDataRange(ctx context.Context, symbol string,
+	request FetchRequest) (*yahoo.MarketData, []string, error) {
+	rawProvider, ok := s.provider.(RawRangeDataProvider)
+	if s.archive == nil || !ok {
+		rangeProvider, ok := s.provider.(RangeDataProvider)
+		if !ok {
+			return nil, nil, eris.New("provider does not support range requests")
+		}
+		data, err := rangeProvider.GetMarketDataRange(ctx, symbol, request.Interval, request.Start, request.End)
+		return data, nil, err
+	}
+
+	payloads, err := rawProvider.FetchRawMarketDataRange(ctx, symbol, request.Interval, request.Start, request.End)
 	if err != nil {
-		return eris.Wrap(err, "failed to get last fetch time")
+		return nil, nil, err
 	}
+	return s.archiveAndParse(ctx, rawProvider, symbol, payloads)
+}
 
-	if fetchTime == nil {
-		return eris.New("fetch time is nil")
+// archiveAndParse archives fetched responses and parses them, an archive failure is only logged.
+func (s *MarketService) archiveAndParse(ctx context.Context, rawProvider RawDataProvider, symbol string,
+	payloads [][]byte) (*yahoo.MarketData, []string, error) {
+	payloadHashes, err := s.archive.SavePayloads(ctx, rawProvider.Name(), payloads)
+	if err != nil {
+		// losing the archive copy must not lose the data itself
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to archive provider responses")
 	}
 
-	days := int(time.Since(*fetchTime).Hours() / 24)
+	data, err := rawProvider.ParseMarketData(symbol, payloads)
+	return data, payloadHashes, err
+}
 
-	intervalAPI := s.getIntervalAPI(days)
-	periodAPI := s.getPeriodAPI(days)
+// Reprocess parses the archived responses of the fetches of a symbol logged in [from, to) again and stores
+// the result, without calling the provider. Parsers are looked up by the provider name of the archived fetch.
+// Reprocessing does not write fetch logs, so it does not affect the range of the next scheduled fetch.
+func (s *MarketService) Reprocess(ctx context.Context, symbol string, from, to time.Time,
+	parsers map[string]PayloadParser) ([]ReprocessResult, error) {
+	if s.archive == nil {
+		return nil, errors.New("no payload archive configured")
+	}
 
-	data, err := s.provider.GetMarketData(ctx, symbol, intervalAPI, periodAPI)
+	fetches, err := s.archive.GetFetches(ctx, symbol, from, to)
 	if err != nil {
-		err := s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, 0, false, err.Error())
-		if err != nil {
-			return eris.Wrap(err, "failed to save price fetch logs")
+		return nil, err
+	}
+
+	results := make([]ReprocessResult, 0, len(fetches))
+	for _, fetch := range fetches {
+		result := ReprocessResult{FetchedAt: fetch.FetchedAt, Provider: fetch.Provider}
+		result.Prices, result.Saved, result.Err = s.reprocessFetch(ctx, fetch, parsers)
+		if result.Err != nil {
+			log.Error().Err(result.Err).
+				Str("symbol", symbol).
+				Time("fetchedAt", fetch.FetchedAt).
+				Msg("Failed to reprocess archived fetch")
 		}
-		return eris.Wrap(err, "failed to get market data")
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *MarketService) reprocessFetch(ctx context.Context, fetch archive.Fetch,
+	parsers map[string]PayloadParser) (int, *SaveResult, error) {
+	parser, ok := parsers[fetch.Provider]
+	if !ok {
+		return 0, nil, eris.Errorf("no parser for provider %s", fetch.Provider)
 	}
-	err = s.repo.SaveMarketData(ctx, data)
+
+	data, err := parser.ParseMarketData(fetch.Symbol, fetch.Payloads)
 	if err != nil {
-		err := s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, len(data.Prices), false, err.Error())
-		if err != nil {
-			return eris.Wrap(err, "failed to save price fetch logs")
+		return 0, nil, eris.Wrap(err, "failed to parse archived responses")
+	}
+	data.Interval = fetch.Interval
+
+	valid, saved, err := s.store(ctx, data)
+	if err != nil {
+		return len(data.Prices), nil, eris.Wrap(err, "failed to save market data")
+	}
+	s.detectAnomalies(ctx, valid)
+	return len(data.Prices), saved, nil
+}
+
+// ImportMarketData stores market data obtained outside the configured provider (e.g. vendor files)
+// through the same persistence path as fetched data, including validation, and records the import in the
+// fetch log. Like a past refresh window, the import is logged at its last bar, so the next fetch of the
+// symbol starts there instead of skipping the time between the last bar and the import.
+func (s *MarketService) ImportMarketData(ctx context.Context, data *yahoo.MarketData) (*SaveResult, error) {
+	importedAt := time.Now()
+	entry := &PriceFetchLog{
+		Symbol:    data.Symbol,
+		FetchedAt: importedAt,
+		Interval:  ptr(string(BarInterval(data))),
+	}
+	if len(data.Prices) > 0 {
+		first, last := data.Prices[0].Time, data.Prices[0].Time
+		for _, price := range data.Prices[1:] {
+			if price.Time.Before(first) {
+				first = price.Time
+			}
+			if price.Time.After(last) {
+				last = price.Time
+			}
 		}
-		return eris.Wrap(err, "failed to save market data")
+		// the next fetch of the symbol continues from the last imported bar
+		entry.RangeStart, entry.RangeEnd = &first, &last
+	}
+	valid, result, err := s.store(ctx, data)
+	if err != nil {
+		entry.failed(importedAt, 0, err)
+		_, logErr := s.repo.SavePriceFetchLog(ctx, entry)
+		if logErr != nil {
+			return nil, eris.Wrap(logErr, "failed to save price fetch logs")
+		}
+		return nil, eris.Wrap(err, "failed to save market data")
 	}
 
-	err = s.repo.SavePriceFetchLogs(ctx, symbol, fetchedAt, len(data.Prices), true, "")
+	entry.succeeded(importedAt, result.Total(), result)
+	if s.validator != nil {
+		entry.RowsQuarantined = ptr(result.Quarantined)
+	}
+	logID, err := s.repo.SavePriceFetchLog(ctx, entry)
 	if err != nil {
-		return eris.Wrap(err, "failed to save price fetch logs")
+		return nil, eris.Wrap(err, "failed to save price fetch logs")
 	}
+	if err := s.linkQuarantined(ctx, logID, result); err != nil {
+		return nil, err
+	}
+	s.detectAnomalies(ctx, valid)
 
-	// download last data
-	log.Debug().
-		Str("symbol", symbol).
-		Interface("symbolData", symbolData).
-		Msg("fetching market data from provider")
-	return nil
+	log.Info().
+		Str("symbol", data.Symbol).
+		Int("inserted", result.Inserted).
+		Int("updated", result.Updated).
+		Int("quarantined", result.Quarantined).
+		Msg("Market data imported")
+
+	return result, nil
+}
+
+// ListFetchLogs retrieves the fetch log entries of a symbol matching the filter, latest first. Symbols which
+// were never stored are logged too when their fetches failed, ErrSymbolNotFound is only returned for symbols
+// neither stored nor logged.
+func (s *MarketService) ListFetchLogs(ctx context.Context, symbol string,
+	filter FetchLogFilter) ([]PriceFetchLog, error) {
+	logs, err := s.repo.ListPriceFetchLogs(ctx, symbol, filter)
+	if err != nil || len(logs) > 0 {
+		return logs, err
+	}
+	if _, err := s.repo.GetSymbol(ctx, symbol); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ListQuarantinedBars retrieves the quarantined bars matching the filter, latest first
+func (s *MarketService) ListQuarantinedBars(ctx context.Context, filter QuarantineFilter) ([]QuarantinedBar, error) {
+	return s.repo.ListQuarantinedBars(ctx, filter)
+}
+
+// ReleaseQuarantinedBars stores the pending quarantined bars among ids as prices despite failing validation
+// and returns them. Bars already reviewed are skipped.
+func (s *MarketService) ReleaseQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error) {
+	released, err := s.repo.ReleaseQuarantinedBars(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Ints64("ids", ids).Int("released", len(released)).Msg("Quarantined bars released")
+	return released, nil
+}
+
+// DiscardQuarantinedBars drops the pending quarantined bars among ids and returns them. Bars already reviewed
+// are skipped.
+func (s *MarketService) DiscardQuarantinedBars(ctx context.Context, ids []int64) ([]QuarantinedBar, error) {
+	discarded, err := s.repo.DiscardQuarantinedBars(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Ints64("ids", ids).Int("discarded", len(discarded)).Msg("Quarantined bars discarded")
+	return discarded, nil
+}
+
+// GetSymbol retrieves a stored symbol, ErrSymbolNotFound if it is not stored
+func (s *MarketService) GetSymbol(ctx context.Context, symbol string) (*Symbol, error) {
+	return s.repo.GetSymbol(ctx, symbol)
 }
 
-func (s *MarketService) GetMarketData(ctx context.Context, symbol string) (*Symbol, *StockPrices, error) {
+// GetMarketData retrieves a stored symbol with its bars of an interval, ErrSymbolNotFound when it has none
+func (s *MarketService) GetMarketData(ctx context.Context, symbol string,
+	interval yahoo.IntervalAPI) (*Symbol, *StockPrices, error) {
 	symbolData, err := s.repo.GetSymbol(ctx, symbol)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	stockPrices, err := s.repo.GetStockPrice(ctx, symbol)
+	stockPrices, err := s.repo.GetStockPrice(ctx, symbol, interval)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -210,6 +774,84 @@ func (s *MarketService) GetMarketData(ctx context.Context, symbol string) (*Symb
 	return symbolData, stockPrices, nil
 }
 
+// GetQuote returns the latest stored quote of a symbol.
+func (s *MarketService) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	quotes, err := s.repo.GetQuotes(ctx, []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, ErrQuoteNotFound
+	}
+	return &quotes[0], nil
+}
+
+// GetQuotes returns the latest stored quotes of the symbols, symbols without a quote are omitted.
+func (s *MarketService) GetQuotes(ctx context.Context, symbols []string) ([]Quote, error) {
+	return s.repo.GetQuotes(ctx, symbols)
+}
+
+// RefreshQuotes fetches the current quote of every symbol and updates the latest-quote store. Providers
+// supporting batches are asked for all symbols at once, others with at most concurrency requests in flight.
+// A failing symbol does not stop the others, all failures are returned together.
+func (s *MarketService) RefreshQuotes(ctx context.Context, symbols []string, concurrency int) error {
+	if s.provider == nil {
+		return errors.New("no data provider configured")
+	}
+	if batchProvider, ok := s.provider.(BatchQuoteProvider); ok {
+		return s.refreshQuotesBatch(ctx, batchProvider, symbols)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+		sem  = make(chan struct{}, max(concurrency, 1))
+	)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.refreshQuote(ctx, symbol); err != nil {
+				log.Error().Err(err).Str("symbol", symbol).Msg("Failed to refresh quote")
+				mu.Lock()
+				errs = append(errs, eris.Wrapf(err, "symbol %s", symbol))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (s *MarketService) refreshQuotesBatch(ctx context.Context, provider BatchQuoteProvider, symbols []string) error {
+	var errs []error
+	for _, result := range provider.GetQuotesBatch(ctx, symbols) {
+		err := result.Err
+		if err == nil {
+			err = s.repo.SaveQuote(ctx, result.Data)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("symbol", result.Symbol).Msg("Failed to refresh quote")
+			errs = append(errs, eris.Wrapf(err, "symbol %s", result.Symbol))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *MarketService) refreshQuote(ctx context.Context, symbol string) error {
+	data, err := s.provider.GetMarketData(ctx, symbol, yahoo.Interval1d, yahoo.Period1d)
+	if err != nil {
+		return eris.Wrap(err, "failed to get market data")
+	}
+	if data.Quote == nil {
+		return eris.New("provider did not report a quote")
+	}
+	return s.repo.SaveQuote(ctx, data)
+}
+
 //// FetchAndStoreAllMarketData fetches market data for all symbols from the provider and stores it
 //func (s *MarketService) FetchAndStoreAllMarketData(ctx context.Context) error {
 //	if s.provider == nil {
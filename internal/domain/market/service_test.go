@@ -0,0 +1,526 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/market-data/internal/providers/yahoo"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/market-data/internal/tests/fakeyahoo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketService_FetchAndStoreMarketData(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t,
+		fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc.", Price: 200, NullBars: []int{0}},
+		fakeyahoo.Symbol{Symbol: "BUSY", Name: "Busy Corp.", RateLimit: 1, RetryAfter: 1},
+	)
+	marketRepo := market.NewMarketRepository(db)
+	marketSvc := market.NewMarketService(marketRepo, yahoo.NewClient(server.Config()))
+	ctx := context.TODO()
+
+	// a new symbol downloads five years of daily bars, null bars are skipped
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "AAPL"))
+	symbol, prices, err := marketSvc.GetMarketData(ctx, "AAPL", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Equal(t, "Apple Inc.", symbol.Name)
+	require.Greater(t, len(*prices), 1200)
+	require.Equal(t, fakeyahoo.DefaultEnd.Add(-7*time.Hour-30*time.Minute), (*prices)[0].Time.UTC())
+
+	quote, err := marketSvc.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Equal(t, *(*prices)[0].ClosePrice, quote.Price)
+
+	// rate limited requests are retried
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "BUSY"))
+	require.Equal(t, 2, server.Requests("BUSY"))
+
+	// unknown symbols fail and are recorded as failed fetches
+	require.Error(t, marketSvc.FetchAndStoreMarketData(ctx, "NOPE"))
+	lastFetch, err := marketRepo.GetLastFetchEnd(ctx, "NOPE")
+	require.NoError(t, err)
+	require.Nil(t, lastFetch)
+}
+
+func TestMarketService_RegisterSymbol(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t, fakeyahoo.Symbol{Symbol: "MSFT", Name: "Microsoft Corporation"})
+	marketSvc := market.NewMarketService(market.NewMarketRepository(db), yahoo.NewClient(server.Config()))
+	ctx := context.TODO()
+
+	symbol, err := marketSvc.RegisterSymbol(ctx, "MSFT")
+	require.NoError(t, err)
+	require.NotZero(t, symbol.ID)
+	require.Equal(t, "Microsoft Corporation", symbol.Name)
+
+	_, err = marketSvc.RegisterSymbol(ctx, "MSFT")
+	require.ErrorIs(t, err, market.ErrSymbolExists)
+	_, err = marketSvc.RegisterSymbol(ctx, "NOPE")
+	require.ErrorIs(t, err, market.ErrUnknownSymbol)
+
+	// the first fetch of a registered symbol downloads the initial history
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "MSFT"))
+	_, prices, err := marketSvc.GetMarketData(ctx, "MSFT", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Greater(t, len(*prices), 1200)
+}
+
+// normalizingProvider looks symbols up without separators, like "btc-usdt" as "BTCUSDT" in crypto providers
+type normalizingProvider struct {
+	*yahoo.Client
+}
+
+func (p normalizingProvider) GetMarketData(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI) (*yahoo.MarketData, error) {
+	return p.Client.GetMarketData(ctx, strings.ToUpper(strings.ReplaceAll(symbol, "-", "")), interval, period)
+}
+
+func TestMarketService_RegisterNormalizedSymbol(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t, fakeyahoo.Symbol{Symbol: "BTCUSDT", Name: "Bitcoin"})
+	marketRepo := market.NewMarketRepository(db)
+	marketSvc := market.NewMarketService(marketRepo, normalizingProvider{yahoo.NewClient(server.Config())})
+	ctx := context.TODO()
+
+	// the symbol is stored as the provider reports it
+	symbol, err := marketSvc.RegisterSymbol(ctx, "btc-usdt")
+	require.NoError(t, err)
+	require.Equal(t, "BTCUSDT", symbol.Symbol)
+	_, err = marketSvc.RegisterSymbol(ctx, "BTC-USDT")
+	require.ErrorIs(t, err, market.ErrSymbolExists)
+
+	// its bars are stored with the registered symbol instead of a second one
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, symbol.Symbol))
+	stored, prices, err := marketSvc.GetMarketData(ctx, "BTCUSDT", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Equal(t, symbol.ID, stored.ID)
+	require.NotEmpty(t, *prices)
+	_, err = marketRepo.GetSymbol(ctx, "btc-usdt")
+	require.ErrorIs(t, err, market.ErrSymbolNotFound)
+}
+
+func TestMarketService_ConcurrentFetches(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	delay := 200 * time.Millisecond
+	server := fakeyahoo.New(t,
+		fakeyahoo.Symbol{Symbol: "MSFT", Delay: delay},
+		fakeyahoo.Symbol{Symbol: "AAPL", Delay: delay},
+		fakeyahoo.Symbol{Symbol: "GOOG", Delay: delay},
+	)
+	marketRepo := market.NewMarketRepository(db)
+	newService := func() *market.MarketService {
+		svc := market.NewMarketService(marketRepo, yahoo.NewClient(server.Config()))
+		svc.SetFetchLocker(marketRepo)
+		return svc
+	}
+	marketSvc := newService()
+	ctx := context.TODO()
+
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "MSFT"))
+	single := server.Requests("MSFT")
+
+	// fetches of a process are coalesced into one
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "AAPL"))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, single, server.Requests("AAPL"))
+
+	// replicas wait for each other, the later fetch only covers the time since the earlier one
+	replicas := []*market.MarketService{newService(), newService()}
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, replica.FetchAndStoreMarketData(ctx, "GOOG"))
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, server.Requests("GOOG"), single+1)
+}
+
+func TestMarketService_GetOrFetchMarketData(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t,
+		fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc."},
+		fakeyahoo.Symbol{Symbol: "SLOW", Delay: time.Second},
+	)
+	marketSvc := market.NewMarketService(market.NewMarketRepository(db), yahoo.NewClient(server.Config()))
+	ctx := context.TODO()
+
+	// without fetch-through, missing symbols are not fetched
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "AAPL", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrSymbolNotFound)
+	require.Zero(t, server.Requests("AAPL"))
+
+	marketSvc.SetFetchThrough(200*time.Millisecond, time.Hour, 2)
+	symbol, prices, err := marketSvc.GetOrFetchMarketData(ctx, "AAPL", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Equal(t, "Apple Inc.", symbol.Name)
+	require.Greater(t, len(*prices), 1200)
+
+	// stored symbols are served from the database
+	requests := server.Requests("AAPL")
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "AAPL", yahoo.Interval1d)
+	require.NoError(t, err)
+	require.Equal(t, requests, server.Requests("AAPL"))
+
+	// unknown symbols are remembered
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "NOPE", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrUnknownSymbol)
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "NOPE", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrUnknownSymbol)
+	require.Equal(t, 1, server.Requests("NOPE"))
+
+	// the oldest unknown symbol is forgotten to remember more than max unknown symbols
+	for _, symbol := range []string{"NOPE2", "NOPE3"} {
+		_, _, err = marketSvc.GetOrFetchMarketData(ctx, symbol, yahoo.Interval1d)
+		require.ErrorIs(t, err, market.ErrUnknownSymbol)
+	}
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "NOPE3", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrUnknownSymbol)
+	require.Equal(t, 1, server.Requests("NOPE3"))
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "NOPE", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrUnknownSymbol)
+	require.Equal(t, 2, server.Requests("NOPE"))
+
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "SLOW", yahoo.Interval1d)
+	require.ErrorIs(t, err, market.ErrFetchTimeout)
+
+	// missing symbols are not fetched once the provider quota is exhausted
+	marketSvc.SetQuotaChecker(exhaustedQuota{})
+	_, _, err = marketSvc.GetOrFetchMarketData(ctx, "MSFT", yahoo.Interval1d)
+	require.ErrorIs(t, err, usage.ErrQuotaExceeded)
+	require.Zero(t, server.Requests("MSFT"))
+}
+
+// exhaustedQuota reports the daily cap of every provider as reached
+type exhaustedQuota struct{}
+
+func (exhaustedQuota) CheckQuota(provider string) error {
+	return fmt.Errorf("%s: %w", provider, usage.ErrQuotaExceeded)
+}
+
+func TestMarketService_Refresh(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t, fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc."})
+	marketRepo := market.NewMarketRepository(db)
+	marketSvc := market.NewMarketService(marketRepo, yahoo.NewClient(server.Config()))
+	ctx := context.TODO()
+
+	_, err = marketSvc.Refresh(ctx, "AAPL", market.RefreshRequest{})
+	require.ErrorIs(t, err, market.ErrSymbolNotFound)
+
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "AAPL"))
+	lastFetch, err := marketRepo.GetLastFetchEnd(ctx, "AAPL")
+	require.NoError(t, err)
+
+	// the next window by default
+	logs, err := marketSvc.Refresh(ctx, "AAPL", market.RefreshRequest{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.True(t, logs[0].Success)
+	require.NotNil(t, logs[0].SymbolID)
+	require.NotNil(t, logs[0].DataPoints)
+	refreshedAt, err := marketRepo.GetLastFetchEnd(ctx, "AAPL")
+	require.NoError(t, err)
+	require.True(t, refreshedAt.After(*lastFetch))
+
+	// past windows are logged when fetched, ranging to their end, and do not move the next fetch
+	to := time.Now().AddDate(0, 0, -30).Truncate(time.Microsecond)
+	logs, err = marketSvc.Refresh(ctx, "AAPL", market.RefreshRequest{
+		Interval: yahoo.Interval1d,
+		From:     to.AddDate(0, 0, -10),
+		To:       to,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, logs)
+	require.True(t, to.Equal(*logs[0].RangeEnd))
+	require.True(t, logs[0].FetchedAt.After(*refreshedAt))
+	lastFetch, err = marketRepo.GetLastFetchEnd(ctx, "AAPL")
+	require.NoError(t, err)
+	require.True(t, refreshedAt.Equal(*lastFetch))
+
+	_, err = marketSvc.Refresh(ctx, "AAPL", market.RefreshRequest{From: to, To: to.AddDate(0, 0, -1)})
+	require.ErrorIs(t, err, market.ErrInvalidRefresh)
+}
+
+func TestMarketService_ImportMarketData(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t, fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc."})
+	marketRepo := market.NewMarketRepository(db)
+	marketSvc := market.NewMarketService(marketRepo, yahoo.NewClient(server.Config()))
+	ctx := context.TODO()
+
+	lastBar := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -30)
+	result, err := marketSvc.ImportMarketData(ctx, &yahoo.MarketData{
+		Symbol: "AAPL", Name: "Apple Inc.", Exchange: "NMS",
+		Prices: []yahoo.StockPrice{
+			{Time: lastBar.AddDate(0, 0, -1), Open: 199, High: 201, Low: 198, Close: 200, AdjClose: 200},
+			{Time: lastBar, Open: 200, High: 202, Low: 199, Close: 201, AdjClose: 201},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Inserted)
+
+	// the import is logged when imported, ranging to its last bar
+	lastFetch, err := marketRepo.GetLastFetchEnd(ctx, "AAPL")
+	require.NoError(t, err)
+	require.True(t, lastBar.Equal(*lastFetch))
+	imports, err := marketSvc.ListFetchLogs(ctx, "AAPL", market.FetchLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, imports, 1)
+	require.WithinDuration(t, time.Now(), imports[0].FetchedAt, time.Minute)
+
+	// the next fetch continues from the last imported bar
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "AAPL"))
+	logs, err := marketSvc.ListFetchLogs(ctx, "AAPL", market.FetchLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, yahoo.Provider, *logs[0].Provider)
+	require.Equal(t, string(yahoo.Interval1d), *logs[0].Interval)
+	require.True(t, lastBar.Equal(*logs[0].RangeStart))
+}
+
+// logRepository keeps fetch log entries in memory, the other methods of the repository are not used
+type logRepository struct {
+	market.Repository
+	lastFetch time.Time
+	logs      []market.PriceFetchLog
+}
+
+func (r *logRepository) GetSymbol(_ context.Context, symbol string) (*market.Symbol, error) {
+	return &market.Symbol{Symbol: symbol}, nil
+}
+
+func (r *logRepository) GetLastFetchEnd(context.Context, string) (*time.Time, error) {
+	return &r.lastFetch, nil
+}
+
+func (r *logRepository) SaveMarketData(_ context.Context, data *yahoo.MarketData,
+	_ ...market.QuarantinedBar) (*market.SaveResult, error) {
+	return &market.SaveResult{Inserted: len(data.Prices)}, nil
+}
+
+func (r *logRepository) SavePriceFetchLog(_ context.Context, entry *market.PriceFetchLog, _ ...string) (int, error) {
+	entry.ID = len(r.logs) + 1
+	r.logs = append(r.logs, *entry)
+	return entry.ID, nil
+}
+
+func (r *logRepository) GetPriceFetchLogs(_ context.Context, ids []int) ([]market.PriceFetchLog, error) {
+	logs := make([]market.PriceFetchLog, 0, len(ids))
+	for _, id := range ids {
+		logs = append(logs, r.logs[id-1])
+	}
+	return logs, nil
+}
+
+// failingRangeProvider serves ranges of at most a week and fails its second request
+type failingRangeProvider struct {
+	rangeProvider
+	requests int
+}
+
+func (p *failingRangeProvider) GetMarketDataRange(_ context.Context, symbol string, _ yahoo.IntervalAPI, _,
+	_ time.Time) (*yahoo.MarketData, error) {
+	p.requests++
+	if p.requests == 2 {
+		return nil, errors.New("provider unavailable")
+	}
+	return &yahoo.MarketData{Symbol: symbol}, nil
+}
+
+func TestMarketService_FetchWindowFailure(t *testing.T) {
+	provider := &failingRangeProvider{}
+	repo := &logRepository{lastFetch: time.Now().Add(-time.Hour)}
+	marketSvc := market.NewMarketService(repo, provider)
+
+	// three weekly requests, the second one fails
+	to := time.Now().AddDate(0, 0, -1).Truncate(time.Minute)
+	logs, err := marketSvc.Refresh(context.TODO(), "AAPL", market.RefreshRequest{
+		Interval: yahoo.Interval1m,
+		From:     to.AddDate(0, 0, -20),
+		To:       to,
+	})
+	require.Error(t, err)
+	require.Equal(t, 2, provider.requests, "requests after a failed one are left to the next fetch")
+	require.Len(t, logs, 2)
+
+	// each request is logged when fetched, ranging to its own end, so the next fetch starts before the failed one
+	require.True(t, logs[0].Success)
+	require.True(t, logs[0].RangeEnd.Before(to))
+	require.True(t, logs[0].FetchedAt.After(to))
+	require.False(t, logs[1].Success)
+	require.True(t, logs[1].RangeStart.Equal(*logs[0].RangeEnd))
+}
+
+// quarantineRepository serves the last stored close and keeps the bars quarantined when saving
+type quarantineRepository struct {
+	logRepository
+	lastClose   float64
+	quarantined []market.QuarantinedBar
+}
+
+func (r *quarantineRepository) GetLastClose(context.Context, string, yahoo.IntervalAPI, time.Time) (*float64,
+	error) {
+	return &r.lastClose, nil
+}
+
+func (r *quarantineRepository) SaveMarketData(_ context.Context, data *yahoo.MarketData,
+	quarantined ...market.QuarantinedBar) (*market.SaveResult, error) {
+	r.quarantined = append(r.quarantined, quarantined...)
+	return &market.SaveResult{Inserted: len(data.Prices), Quarantined: len(quarantined)}, nil
+}
+
+func TestMarketService_ImportMarketDataLastClose(t *testing.T) {
+	repo := &quarantineRepository{lastClose: 100}
+	marketSvc := market.NewMarketService(repo, &rangeProvider{})
+	validator, err := market.NewValidator(append(market.DefaultRules, market.RuleMaxChange), 50)
+	require.NoError(t, err)
+	marketSvc.SetValidator(validator)
+
+	// the first imported bar moved too far from the last stored close
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	result, err := marketSvc.ImportMarketData(context.TODO(), &yahoo.MarketData{
+		Symbol: "AAPL",
+		Prices: []yahoo.StockPrice{
+			{Time: start, Open: 300, High: 310, Low: 290, Close: 300},
+			{Time: start.AddDate(0, 0, 1), Open: 101, High: 106, Low: 96, Close: 102},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Inserted)
+	require.Equal(t, 1, result.Quarantined)
+	require.Len(t, repo.quarantined, 1)
+	require.Equal(t, market.RuleMaxChange, repo.quarantined[0].Rule)
+	require.True(t, start.Equal(repo.quarantined[0].Time))
+	require.Equal(t, 1, *repo.logs[0].RowsQuarantined)
+}
+
+func TestMarketService_FetchLogs(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	server := fakeyahoo.New(t,
+		fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc."},
+		fakeyahoo.Symbol{Symbol: "BUSY", Name: "Busy Corp.", RateLimit: 1, RetryAfter: 1},
+	)
+	client := yahoo.NewClient(server.Config())
+	client.SetTransport(usage.NewTransport(nil, yahoo.Provider, nil))
+	marketSvc := market.NewMarketService(market.NewMarketRepository(db), client)
+	ctx := context.TODO()
+
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "AAPL"))
+	logs, err := marketSvc.ListFetchLogs(ctx, "AAPL", market.FetchLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	entry := logs[0]
+	require.True(t, entry.Success)
+	require.NotNil(t, entry.SymbolID, "fetch logs are linked to the stored symbol")
+	require.Equal(t, yahoo.Provider, *entry.Provider)
+	require.Equal(t, string(yahoo.Interval1d), *entry.Interval)
+	require.NotNil(t, entry.RangeStart)
+	require.NotNil(t, entry.RangeEnd)
+	require.NotNil(t, entry.DurationMs)
+	require.Equal(t, 200, *entry.HTTPStatus)
+	require.Equal(t, 0, *entry.Retries)
+	require.Equal(t, *entry.DataPoints, *entry.RowsInserted)
+	require.Equal(t, 0, *entry.RowsUpdated)
+
+	// retried requests are counted
+	require.NoError(t, marketSvc.FetchAndStoreMarketData(ctx, "BUSY"))
+	logs, err = marketSvc.ListFetchLogs(ctx, "BUSY", market.FetchLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, 1, *logs[0].Retries)
+
+	// failures of symbols never stored are logged too
+	require.ErrorIs(t, marketSvc.FetchAndStoreMarketData(ctx, "NOPE"), market.ErrUnknownSymbol)
+	logs, err = marketSvc.ListFetchLogs(ctx, "NOPE", market.FetchLogFilter{FailedOnly: true})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.False(t, logs[0].Success)
+	require.Nil(t, logs[0].SymbolID)
+	require.Equal(t, 404, *logs[0].HTTPStatus)
+	require.NotNil(t, logs[0].ErrorMsg)
+
+	logs, err = marketSvc.ListFetchLogs(ctx, "AAPL", market.FetchLogFilter{FailedOnly: true})
+	require.NoError(t, err)
+	require.Empty(t, logs)
+	logs, err = marketSvc.ListFetchLogs(ctx, "AAPL", market.FetchLogFilter{From: time.Now()})
+	require.NoError(t, err)
+	require.Empty(t, logs)
+
+	_, err = marketSvc.ListFetchLogs(ctx, "NEVER", market.FetchLogFilter{})
+	require.ErrorIs(t, err, market.ErrSymbolNotFound)
+}
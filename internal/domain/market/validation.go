@@ -0,0 +1,126 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Validation rules applied to incoming bars
+const (
+	RulePositivePrices    = "positive_prices"     // open, high, low and close are above zero
+	RuleHighLow           = "high_low"            // the high is not below the low
+	RuleOpenCloseRange    = "open_close_range"    // open and close lie within the low and the high
+	RuleNonNegativeVolume = "non_negative_volume" // the volume is not negative
+	RuleMaxChange         = "max_change"          // the close moved at most the max change from the previous bar
+)
+
+// DefaultRules are the validation rules applied unless configured otherwise
+var DefaultRules = []string{RulePositivePrices, RuleHighLow, RuleOpenCloseRange, RuleNonNegativeVolume}
+
+var rules = []string{RulePositivePrices, RuleHighLow, RuleOpenCloseRange, RuleNonNegativeVolume, RuleMaxChange}
+
+// ErrInvalidRules is returned for validation rule sets naming unknown rules
+var ErrInvalidRules = errors.New("invalid validation rules")
+
+// Violation names the rule a bar violated and how
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+// Validator checks incoming bars against a rule set before they are stored
+type Validator struct {
+	rules     []string
+	maxChange float64 // fraction of the previous close
+}
+
+// NewValidator creates a validator applying the rules in order. maxChangePercent bounds the move of the close
+// from the previous bar checked by the max_change rule. Returns an error wrapping ErrInvalidRules for unknown
+// rules.
+func NewValidator(ruleSet []string, maxChangePercent float64) (*Validator, error) {
+	for _, rule := range ruleSet {
+		if !slices.Contains(rules, rule) {
+			return nil, eris.Wrapf(ErrInvalidRules, "unknown rule %q, supported are %s", rule,
+				strings.Join(rules, ", "))
+		}
+	}
+	if slices.Contains(ruleSet, RuleMaxChange) && maxChangePercent <= 0 {
+		return nil, eris.Wrap(ErrInvalidRules, "max change must be positive")
+	}
+	return &Validator{rules: slices.Clone(ruleSet), maxChange: maxChangePercent / 100}, nil
+}
+
+// Check returns the first rule a bar violates, nil for valid bars. prev is the previous valid bar, nil when
+// there is none.
+func (v *Validator) Check(bar, prev *yahoo.StockPrice) *Violation {
+	for _, rule := range v.rules {
+		if reason := v.violation(rule, bar, prev); reason != "" {
+			return &Violation{Rule: rule, Reason: reason}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) violation(rule string, bar, prev *yahoo.StockPrice) string {
+	switch rule {
+	case RulePositivePrices:
+		if bar.Open <= 0 || bar.High <= 0 || bar.Low <= 0 || bar.Close <= 0 {
+			return fmt.Sprintf("non-positive price (open %g, high %g, low %g, close %g)",
+				bar.Open, bar.High, bar.Low, bar.Close)
+		}
+	case RuleHighLow:
+		if bar.High < bar.Low {
+			return fmt.Sprintf("high %g below low %g", bar.High, bar.Low)
+		}
+	case RuleOpenCloseRange:
+		if bar.Open < bar.Low || bar.Open > bar.High || bar.Close < bar.Low || bar.Close > bar.High {
+			return fmt.Sprintf("open %g or close %g outside low %g and high %g", bar.Open, bar.Close, bar.Low,
+				bar.High)
+		}
+	case RuleNonNegativeVolume:
+		if bar.Volume < 0 {
+			return fmt.Sprintf("negative volume %g", bar.Volume)
+		}
+	case RuleMaxChange:
+		if prev != nil && prev.Close > 0 && math.Abs(bar.Close/prev.Close-1) > v.maxChange {
+			return fmt.Sprintf("close moved from %g to %g, more than %g%%", prev.Close, bar.Close, v.maxChange*100)
+		}
+	}
+	return ""
+}
+
+// ChecksChange reports whether the validator checks the move of the close from the previous bar, which then
+// needs the last stored close.
+func (v *Validator) ChecksChange() bool {
+	return slices.Contains(v.rules, RuleMaxChange)
+}
+
+// Split separates the bars of market data passing validation from those to quarantine. lastClose is the
+// close of the last stored bar before the first one, the first bar is checked against it, nil when there is
+// none. The returned market data holds the valid bars, the original is not modified.
+func (v *Validator) Split(data *yahoo.MarketData, lastClose *float64) (*yahoo.MarketData, []QuarantinedBar) {
+	valid := *data
+	valid.Prices = make([]yahoo.StockPrice, 0, len(data.Prices))
+	var quarantined []QuarantinedBar
+	var prev *yahoo.StockPrice
+	if lastClose != nil {
+		prev = &yahoo.StockPrice{Close: *lastClose}
+	}
+	for i := range data.Prices {
+		bar := &data.Prices[i]
+		violation := v.Check(bar, prev)
+		if violation == nil {
+			valid.Prices = append(valid.Prices, *bar)
+			prev = bar
+			continue
+		}
+		quarantined = append(quarantined, NewQuarantinedBar(data.Symbol, BarInterval(data), bar, violation))
+	}
+	return &valid, quarantined
+}
@@ -0,0 +1,109 @@
+package market_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewValidator(t *testing.T) {
+	_, err := market.NewValidator([]string{market.RuleHighLow, "no_gaps"}, 0)
+	assert.ErrorIs(t, err, market.ErrInvalidRules)
+
+	_, err = market.NewValidator([]string{market.RuleMaxChange}, 0)
+	assert.ErrorIs(t, err, market.ErrInvalidRules, "the max change rule needs a bound")
+
+	_, err = market.NewValidator(market.DefaultRules, 0)
+	assert.NoError(t, err)
+}
+
+func TestValidator_Check(t *testing.T) {
+	validator, err := market.NewValidator(append(market.DefaultRules, market.RuleMaxChange), 50)
+	require.NoError(t, err)
+
+	prev := &yahoo.StockPrice{Open: 100, High: 105, Low: 95, Close: 100, Volume: 1000}
+	tests := []struct {
+		name string
+		bar  yahoo.StockPrice
+		prev *yahoo.StockPrice
+		rule string
+	}{
+		{"Valid bar", yahoo.StockPrice{Open: 100, High: 110, Low: 90, Close: 105, Volume: 10}, prev, ""},
+		{"Zero price", yahoo.StockPrice{Open: 0, High: 110, Low: 90, Close: 105}, prev, market.RulePositivePrices},
+		{"High below low", yahoo.StockPrice{Open: 100, High: 90, Low: 110, Close: 100}, prev, market.RuleHighLow},
+		{"Close above high", yahoo.StockPrice{Open: 100, High: 110, Low: 90, Close: 120}, prev, market.RuleOpenCloseRange},
+		{"Negative volume", yahoo.StockPrice{Open: 100, High: 110, Low: 90, Close: 105, Volume: -1}, prev, market.RuleNonNegativeVolume},
+		{"Price jump", yahoo.StockPrice{Open: 200, High: 210, Low: 190, Close: 200}, prev, market.RuleMaxChange},
+		{"Price jump on the first bar", yahoo.StockPrice{Open: 200, High: 210, Low: 190, Close: 200}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := validator.Check(&tt.bar, tt.prev)
+			if tt.rule == "" {
+				assert.Nil(t, violation)
+				return
+			}
+			require.NotNil(t, violation)
+			assert.Equal(t, tt.rule, violation.Rule)
+			assert.NotEmpty(t, violation.Reason)
+		})
+	}
+}
+
+func TestValidator_Split(t *testing.T) {
+	validator, err := market.NewValidator(append(market.DefaultRules, market.RuleMaxChange), 50)
+	require.NoError(t, err)
+
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	data := &yahoo.MarketData{
+		Symbol: "AAPL",
+		Prices: []yahoo.StockPrice{
+			{Time: start, Open: 100, High: 105, Low: 95, Close: 100, Volume: 1000},
+			{Time: start.AddDate(0, 0, 1), Open: 100, High: 95, Low: 105, Close: 100, Volume: 1000},
+			{Time: start.AddDate(0, 0, 2), Open: 1, High: 2, Low: 1, Close: 1, Volume: 1000},
+			{Time: start.AddDate(0, 0, 3), Open: 101, High: 106, Low: 96, Close: 102, Volume: 1000},
+		},
+	}
+
+	valid, quarantined := validator.Split(data, nil)
+	require.Len(t, valid.Prices, 2)
+	assert.True(t, start.Equal(valid.Prices[0].Time))
+	assert.True(t, start.AddDate(0, 0, 3).Equal(valid.Prices[1].Time), "bars are compared to the last valid one")
+	assert.Len(t, data.Prices, 4, "the original is not modified")
+
+	require.Len(t, quarantined, 2)
+	assert.Equal(t, "AAPL", quarantined[0].Symbol)
+	assert.Equal(t, market.RuleHighLow, quarantined[0].Rule)
+	assert.Equal(t, market.QuarantinePending, quarantined[0].Status)
+	assert.Equal(t, market.RuleMaxChange, quarantined[1].Rule)
+	assert.Equal(t, 1.0, *quarantined[1].ClosePrice)
+}
+
+func TestValidator_SplitLastClose(t *testing.T) {
+	validator, err := market.NewValidator(append(market.DefaultRules, market.RuleMaxChange), 50)
+	require.NoError(t, err)
+
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	data := &yahoo.MarketData{
+		Symbol: "AAPL",
+		Prices: []yahoo.StockPrice{
+			{Time: start, Open: 1, High: 2, Low: 1, Close: 1, Volume: 1000},
+			{Time: start.AddDate(0, 0, 1), Open: 101, High: 106, Low: 96, Close: 102, Volume: 1000},
+		},
+	}
+
+	lastClose := 100.0
+	valid, quarantined := validator.Split(data, &lastClose)
+	require.Len(t, quarantined, 1, "the first bar is checked against the last stored close")
+	assert.Equal(t, market.RuleMaxChange, quarantined[0].Rule)
+	assert.True(t, start.Equal(quarantined[0].Time))
+	require.Len(t, valid.Prices, 1)
+	assert.Equal(t, 102.0, valid.Prices[0].Close)
+
+	_, quarantined = validator.Split(data, nil)
+	assert.Len(t, quarantined, 1, "without a stored close the second bar jumps from the first")
+}
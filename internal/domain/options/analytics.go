@@ -0,0 +1,220 @@
+package options
+
+import (
+	"context"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// historicalVolatilityWindow is the number of stored closes used to estimate the pricing volatility.
+const historicalVolatilityWindow = 21
+
+// UnderlyingPriceSource provides stored prices of underlying symbols
+type UnderlyingPriceSource interface {
+	GetMarketData(ctx context.Context, symbol string, interval yahoo.IntervalAPI) (*market.Symbol,
+		*market.StockPrices, error)
+}
+
+// ContractInput describes an option contract to analyze.
+type ContractInput struct {
+	ContractSymbol string // optional, used to persist analytics of stored contracts
+	Underlying     string
+	Expiry         time.Time
+	Strike         float64
+	Type           yahoo.OptionType
+	MarketPrice    *float64 // last traded price, used when bid or ask is missing
+	Bid            *float64
+	Ask            *float64
+	Volatility     *float64 // optional pricing volatility, defaults to the historical volatility of the underlying
+}
+
+// ContractAnalytics holds the Black-Scholes analytics of a contract. Greeks are evaluated at the implied
+// volatility when it could be determined, otherwise at the pricing volatility. The pricing volatility and
+// theoretical price are nil when neither the contract nor the history of the underlying provide a volatility.
+type ContractAnalytics struct {
+	Input             ContractInput
+	UnderlyingPrice   float64
+	UnderlyingTime    time.Time
+	Years             float64
+	MidPrice          *float64
+	PricingVolatility *float64
+	TheoreticalPrice  *float64
+	ImpliedVolatility *float64
+	Greeks            Greeks
+	Err               error // set when the contract could not be analyzed
+}
+
+type underlyingState struct {
+	price      float64
+	time       time.Time
+	volatility float64
+	err        error
+}
+
+// SetAnalyticsSettings configures the source of underlying prices, the risk-free rate and whether analytics
+// are persisted with every chain snapshot
+func (s *OptionsService) SetAnalyticsSettings(underlying UnderlyingPriceSource, riskFreeRate float64, persist bool) {
+	s.underlying = underlying
+	s.riskFreeRate = riskFreeRate
+	s.persistAnalytics = persist
+}
+
+// RiskFreeRate returns the configured risk-free rate.
+func (s *OptionsService) RiskFreeRate() float64 {
+	return s.riskFreeRate
+}
+
+// Analyze computes theoretical prices, implied volatilities and Greeks of the contracts using the latest stored
+// close of each underlying. Contracts which cannot be analyzed carry an error instead of failing the whole request.
+func (s *OptionsService) Analyze(ctx context.Context, contracts []ContractInput, rate float64, now time.Time) ([]ContractAnalytics, error) {
+	if s.underlying == nil {
+		return nil, eris.New("no underlying price source configured")
+	}
+
+	underlyings := make(map[string]*underlyingState)
+	results := make([]ContractAnalytics, 0, len(contracts))
+	for _, contract := range contracts {
+		state, ok := underlyings[contract.Underlying]
+		if !ok {
+			state = s.loadUnderlying(ctx, contract.Underlying)
+			underlyings[contract.Underlying] = state
+		}
+		results = append(results, analyzeContract(contract, state, rate, now))
+	}
+	return results, nil
+}
+
+func (s *OptionsService) loadUnderlying(ctx context.Context, symbol string) *underlyingState {
+	// the historical volatility is annualized from daily closes
+	_, prices, err := s.underlying.GetMarketData(ctx, symbol, yahoo.Interval1d)
+	if err != nil {
+		return &underlyingState{err: err}
+	}
+
+	// prices are ordered from the most recent one
+	state := &underlyingState{}
+	var closes []float64
+	for _, p := range *prices {
+		if p.ClosePrice == nil {
+			continue
+		}
+		if len(closes) == 0 {
+			state.price = *p.ClosePrice
+			state.time = p.Time
+		}
+		closes = append(closes, *p.ClosePrice)
+		if len(closes) == historicalVolatilityWindow {
+			break
+		}
+	}
+	if len(closes) == 0 {
+		state.err = eris.Errorf("no stored close for symbol %s", symbol)
+		return state
+	}
+	state.volatility, _ = HistoricalVolatility(closes)
+	return state
+}
+
+func analyzeContract(contract ContractInput, underlying *underlyingState, rate float64, now time.Time) ContractAnalytics {
+	result := ContractAnalytics{Input: contract}
+	if underlying.err != nil {
+		result.Err = eris.Wrap(underlying.err, "underlying price not available")
+		return result
+	}
+	result.UnderlyingPrice = underlying.price
+	result.UnderlyingTime = underlying.time
+	result.Years = yearsToExpiry(contract.Expiry, now)
+
+	in := PricingInput{
+		Type:       contract.Type,
+		Spot:       underlying.price,
+		Strike:     contract.Strike,
+		Years:      result.Years,
+		Rate:       rate,
+		Volatility: underlying.volatility,
+	}
+	if contract.Volatility != nil {
+		in.Volatility = *contract.Volatility
+	}
+	result.MidPrice = midPrice(contract)
+	if result.MidPrice != nil {
+		if iv, err := ImpliedVolatility(in, *result.MidPrice); err == nil {
+			result.ImpliedVolatility = &iv
+		}
+	}
+
+	if in.Volatility > 0 {
+		price, err := BlackScholesPrice(in)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.PricingVolatility = &in.Volatility
+		result.TheoreticalPrice = &price
+	}
+
+	greeksInput := in
+	if result.ImpliedVolatility != nil {
+		greeksInput.Volatility = *result.ImpliedVolatility
+	}
+	greeks, err := BlackScholesGreeks(greeksInput)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Greeks = *greeks
+	return result
+}
+
+// midPrice returns the bid/ask midpoint, or the market price when the quote is incomplete.
+func midPrice(contract ContractInput) *float64 {
+	if contract.Bid != nil && contract.Ask != nil && *contract.Bid > 0 && *contract.Ask >= *contract.Bid {
+		mid := (*contract.Bid + *contract.Ask) / 2
+		return &mid
+	}
+	if contract.MarketPrice != nil && *contract.MarketPrice > 0 {
+		return contract.MarketPrice
+	}
+	return nil
+}
+
+// yearsToExpiry measures the time until the regular session close of the expiration day in years.
+func yearsToExpiry(expiry, now time.Time) float64 {
+	nyse := calendar.NYSE()
+	expiryClose := time.Date(expiry.Year(), expiry.Month(), expiry.Day(), 0, 0, 0, 0, nyse.Location).Add(nyse.Close)
+	return expiryClose.Sub(now).Hours() / (24 * 365)
+}
+
+// snapshotAnalytics computes analytics of a fetched chain for persisting with its snapshot.
+func (s *OptionsService) snapshotAnalytics(ctx context.Context, chain *yahoo.OptionChain, now time.Time) ([]ContractAnalytics, error) {
+	inputs := make([]ContractInput, 0, len(chain.Contracts))
+	for _, c := range chain.Contracts {
+		inputs = append(inputs, ContractInput{
+			ContractSymbol: c.ContractSymbol,
+			Underlying:     chain.Symbol,
+			Expiry:         c.Expiry,
+			Strike:         c.Strike,
+			Type:           c.Type,
+			MarketPrice:    &c.LastPrice,
+			Bid:            &c.Bid,
+			Ask:            &c.Ask,
+		})
+	}
+
+	analytics, err := s.Analyze(ctx, inputs, s.riskFreeRate, now)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := analytics[:0]
+	for _, a := range analytics {
+		if a.Err == nil {
+			valid = append(valid, a)
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,45 @@
+package options
+
+import (
+	"time"
+)
+
+// Contract represents an option contract on an underlying symbol.
+type Contract struct {
+	ID             int       `db:"id"`
+	SymbolID       int       `db:"symbol_id"`
+	ContractSymbol string    `db:"contract_symbol"`
+	OptionType     string    `db:"option_type"`
+	Expiry         time.Time `db:"expiry"`
+	Strike         float64   `db:"strike"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// Snapshot represents the daily quote of an option contract.
+type Snapshot struct {
+	ContractID        int       `db:"contract_id"`        // INTEGER NOT NULL (FK)
+	Time              time.Time `db:"time"`               // TIMESTAMPTZ NOT NULL
+	Bid               *float64  `db:"bid"`                // NUMERIC(18, 6) nullable
+	Ask               *float64  `db:"ask"`                // NUMERIC(18, 6) nullable
+	LastPrice         *float64  `db:"last_price"`         // NUMERIC(18, 6) nullable
+	Volume            *int64    `db:"volume"`             // BIGINT nullable
+	OpenInterest      *int64    `db:"open_interest"`      // BIGINT nullable
+	ImpliedVolatility *float64  `db:"implied_volatility"` // NUMERIC(12, 6) nullable
+}
+
+type Chain []ChainEntry
+
+// ChainEntry is an option contract together with its latest snapshot.
+type ChainEntry struct {
+	ContractSymbol    string    `db:"contract_symbol"`
+	OptionType        string    `db:"option_type"`
+	Expiry            time.Time `db:"expiry"`
+	Strike            float64   `db:"strike"`
+	Time              time.Time `db:"time"`
+	Bid               *float64  `db:"bid"`
+	Ask               *float64  `db:"ask"`
+	LastPrice         *float64  `db:"last_price"`
+	Volume            *int64    `db:"volume"`
+	OpenInterest      *int64    `db:"open_interest"`
+	ImpliedVolatility *float64  `db:"implied_volatility"`
+}
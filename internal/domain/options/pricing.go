@@ -0,0 +1,183 @@
+package options
+
+import (
+	"errors"
+	"math"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// Pricing errors
+var (
+	ErrInvalidPricingInput   = errors.New("invalid pricing input")
+	ErrNoImpliedVolatility   = errors.New("price outside of no-arbitrage bounds, no implied volatility")
+	ErrUnsupportedOptionType = errors.New("unsupported option type")
+)
+
+// Implied volatility search bounds and precision
+const (
+	minVolatility   = 1e-6
+	maxVolatility   = 5.0
+	ivTolerance     = 1e-8
+	ivMaxIterations = 200
+)
+
+// PricingInput holds the parameters of the Black-Scholes model for a European option.
+type PricingInput struct {
+	Type       yahoo.OptionType
+	Spot       float64 // price of the underlying
+	Strike     float64
+	Years      float64 // time to expiry in years
+	Rate       float64 // continuously compounded risk-free rate, e.g. 0.045
+	Volatility float64 // annualized volatility, e.g. 0.25
+}
+
+// Greeks are the sensitivities of the option price. Vega and rho are per one percentage point change,
+// theta is per calendar day.
+type Greeks struct {
+	Delta float64
+	Gamma float64
+	Vega  float64
+	Theta float64
+	Rho   float64
+}
+
+func (in PricingInput) validate() error {
+	if in.Type != yahoo.OptionCall && in.Type != yahoo.OptionPut {
+		return ErrUnsupportedOptionType
+	}
+	if in.Spot <= 0 || in.Strike <= 0 || in.Years <= 0 || in.Volatility <= 0 {
+		return ErrInvalidPricingInput
+	}
+	return nil
+}
+
+func (in PricingInput) d1d2() (float64, float64) {
+	sqrtT := math.Sqrt(in.Years)
+	d1 := (math.Log(in.Spot/in.Strike) + (in.Rate+in.Volatility*in.Volatility/2)*in.Years) / (in.Volatility * sqrtT)
+	return d1, d1 - in.Volatility*sqrtT
+}
+
+// BlackScholesPrice returns the theoretical price of a European option.
+func BlackScholesPrice(in PricingInput) (float64, error) {
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+
+	d1, d2 := in.d1d2()
+	discount := math.Exp(-in.Rate * in.Years)
+	if in.Type == yahoo.OptionCall {
+		return in.Spot*normCDF(d1) - in.Strike*discount*normCDF(d2), nil
+	}
+	return in.Strike*discount*normCDF(-d2) - in.Spot*normCDF(-d1), nil
+}
+
+// BlackScholesGreeks returns delta, gamma, vega, theta and rho of a European option.
+func BlackScholesGreeks(in PricingInput) (*Greeks, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+
+	d1, d2 := in.d1d2()
+	sqrtT := math.Sqrt(in.Years)
+	discount := math.Exp(-in.Rate * in.Years)
+	pdf := normPDF(d1)
+
+	greeks := &Greeks{
+		Gamma: pdf / (in.Spot * in.Volatility * sqrtT),
+		Vega:  in.Spot * pdf * sqrtT / 100,
+	}
+	decay := -in.Spot * pdf * in.Volatility / (2 * sqrtT)
+	if in.Type == yahoo.OptionCall {
+		greeks.Delta = normCDF(d1)
+		greeks.Theta = (decay - in.Rate*in.Strike*discount*normCDF(d2)) / 365
+		greeks.Rho = in.Strike * in.Years * discount * normCDF(d2) / 100
+	} else {
+		greeks.Delta = normCDF(d1) - 1
+		greeks.Theta = (decay + in.Rate*in.Strike*discount*normCDF(-d2)) / 365
+		greeks.Rho = -in.Strike * in.Years * discount * normCDF(-d2) / 100
+	}
+	return greeks, nil
+}
+
+// ImpliedVolatility finds the volatility at which the Black-Scholes price equals the given price by bisection.
+// The Volatility of the input is ignored.
+func ImpliedVolatility(in PricingInput, price float64) (float64, error) {
+	in.Volatility = maxVolatility
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+
+	discountedStrike := in.Strike * math.Exp(-in.Rate*in.Years)
+	lower, upper := math.Max(in.Spot-discountedStrike, 0), in.Spot
+	if in.Type == yahoo.OptionPut {
+		lower, upper = math.Max(discountedStrike-in.Spot, 0), discountedStrike
+	}
+	if price <= lower || price >= upper {
+		return 0, ErrNoImpliedVolatility
+	}
+
+	low, high := minVolatility, maxVolatility
+	for i := 0; i < ivMaxIterations; i++ {
+		in.Volatility = (low + high) / 2
+		p, err := BlackScholesPrice(in)
+		if err != nil {
+			return 0, err
+		}
+		if math.Abs(p-price) < ivTolerance {
+			break
+		}
+		// the option price increases monotonically with volatility
+		if p > price {
+			high = in.Volatility
+		} else {
+			low = in.Volatility
+		}
+	}
+
+	if in.Volatility >= maxVolatility-ivTolerance || in.Volatility <= minVolatility+ivTolerance {
+		return 0, ErrNoImpliedVolatility
+	}
+	return in.Volatility, nil
+}
+
+// HistoricalVolatility returns the annualized standard deviation of daily log returns of the closes,
+// which must be ordered in time (either direction). At least three closes are required.
+func HistoricalVolatility(closes []float64) (float64, bool) {
+	if len(closes) < 3 {
+		return 0, false
+	}
+
+	returns := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			return 0, false
+		}
+		returns = append(returns, math.Log(closes[i]/closes[i-1]))
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return math.Sqrt(variance * tradingDaysPerYear), true
+}
+
+// tradingDaysPerYear is used to annualize daily volatility.
+const tradingDaysPerYear = 252
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
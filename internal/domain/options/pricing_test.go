@@ -0,0 +1,164 @@
+package options_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func atTheMoney(optionType yahoo.OptionType) options.PricingInput {
+	return options.PricingInput{
+		Type:       optionType,
+		Spot:       100,
+		Strike:     100,
+		Years:      1,
+		Rate:       0.05,
+		Volatility: 0.2,
+	}
+}
+
+func TestBlackScholesPrice(t *testing.T) {
+	call, err := options.BlackScholesPrice(atTheMoney(yahoo.OptionCall))
+	require.NoError(t, err)
+	assert.InDelta(t, 10.4506, call, 1e-4)
+
+	put, err := options.BlackScholesPrice(atTheMoney(yahoo.OptionPut))
+	require.NoError(t, err)
+	assert.InDelta(t, 5.5735, put, 1e-4)
+
+	// put-call parity: C - P = S - K*exp(-rT)
+	assert.InDelta(t, 100-100*0.951229, call-put, 1e-4)
+
+	_, err = options.BlackScholesPrice(options.PricingInput{Type: yahoo.OptionCall, Spot: 100, Strike: 100})
+	assert.ErrorIs(t, err, options.ErrInvalidPricingInput)
+
+	_, err = options.BlackScholesPrice(options.PricingInput{Type: "straddle", Spot: 100, Strike: 100, Years: 1, Volatility: 0.2})
+	assert.ErrorIs(t, err, options.ErrUnsupportedOptionType)
+}
+
+func TestBlackScholesGreeks(t *testing.T) {
+	call, err := options.BlackScholesGreeks(atTheMoney(yahoo.OptionCall))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6368, call.Delta, 1e-4)
+	assert.InDelta(t, 0.018762, call.Gamma, 1e-6)
+	assert.InDelta(t, 0.3752, call.Vega, 1e-4)
+	assert.InDelta(t, -6.4140/365, call.Theta, 1e-5)
+	assert.InDelta(t, 0.5323, call.Rho, 1e-4)
+
+	put, err := options.BlackScholesGreeks(atTheMoney(yahoo.OptionPut))
+	require.NoError(t, err)
+	assert.InDelta(t, call.Delta-1, put.Delta, 1e-9)
+	assert.InDelta(t, call.Gamma, put.Gamma, 1e-9)
+	assert.InDelta(t, call.Vega, put.Vega, 1e-9)
+	assert.InDelta(t, -1.6579/365, put.Theta, 1e-5)
+	assert.InDelta(t, -0.4189, put.Rho, 1e-4)
+}
+
+func TestImpliedVolatility(t *testing.T) {
+	for _, optionType := range []yahoo.OptionType{yahoo.OptionCall, yahoo.OptionPut} {
+		in := atTheMoney(optionType)
+		in.Volatility = 0.35
+		price, err := options.BlackScholesPrice(in)
+		require.NoError(t, err)
+
+		iv, err := options.ImpliedVolatility(in, price)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.35, iv, 1e-6, string(optionType))
+	}
+
+	// a call cannot be worth more than the underlying nor less than its intrinsic value
+	_, err := options.ImpliedVolatility(atTheMoney(yahoo.OptionCall), 120)
+	assert.ErrorIs(t, err, options.ErrNoImpliedVolatility)
+	_, err = options.ImpliedVolatility(atTheMoney(yahoo.OptionCall), 1)
+	assert.ErrorIs(t, err, options.ErrNoImpliedVolatility)
+}
+
+func TestHistoricalVolatility(t *testing.T) {
+	_, ok := options.HistoricalVolatility([]float64{100, 101})
+	assert.False(t, ok, "at least three closes are required")
+
+	vol, ok := options.HistoricalVolatility([]float64{100, 100, 100, 100})
+	require.True(t, ok)
+	assert.Zero(t, vol)
+
+	// alternating +1%/-1% log returns
+	vol, ok = options.HistoricalVolatility([]float64{100, 101.005, 100, 101.005, 100})
+	require.True(t, ok)
+	assert.InDelta(t, 0.1833, vol, 1e-3)
+}
+
+type fakeUnderlying struct {
+	prices map[string]market.StockPrices
+}
+
+func (f *fakeUnderlying) GetMarketData(_ context.Context, symbol string, _ yahoo.IntervalAPI) (*market.Symbol,
+	*market.StockPrices, error) {
+	prices, ok := f.prices[symbol]
+	if !ok {
+		return nil, nil, market.ErrSymbolNotFound
+	}
+	return &market.Symbol{Symbol: symbol}, &prices, nil
+}
+
+func TestOptionsService_Analyze(t *testing.T) {
+	closeTime := time.Date(2025, time.June, 13, 20, 0, 0, 0, time.UTC)
+	price := func(v float64) *float64 { return &v }
+
+	svc := options.NewOptionsService(nil, nil)
+	svc.SetAnalyticsSettings(&fakeUnderlying{prices: map[string]market.StockPrices{
+		"AAPL": {
+			{Time: closeTime, ClosePrice: price(200)},
+			{Time: closeTime.AddDate(0, 0, -1), ClosePrice: price(198)},
+			{Time: closeTime.AddDate(0, 0, -2), ClosePrice: price(201)},
+			{Time: closeTime.AddDate(0, 0, -3), ClosePrice: price(199)},
+		},
+		// a single close has no historical volatility
+		"TSLA": {{Time: closeTime, ClosePrice: price(300)}},
+	}}, 0.04, false)
+
+	expiry := time.Date(2025, time.December, 19, 0, 0, 0, 0, time.UTC)
+	results, err := svc.Analyze(context.TODO(), []options.ContractInput{
+		{Underlying: "AAPL", Expiry: expiry, Strike: 200, Type: yahoo.OptionCall, Bid: price(11.9), Ask: price(12.1)},
+		{Underlying: "AAPL", Expiry: expiry, Strike: 180, Type: yahoo.OptionPut, MarketPrice: price(4.5), Volatility: price(0.3)},
+		{Underlying: "MSFT", Expiry: expiry, Strike: 400, Type: yahoo.OptionCall},
+		{Underlying: "TSLA", Expiry: expiry, Strike: 300, Type: yahoo.OptionCall, Bid: price(24.5), Ask: price(25.5)},
+	}, svc.RiskFreeRate(), closeTime)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	call := results[0]
+	require.NoError(t, call.Err)
+	assert.Equal(t, 200.0, call.UnderlyingPrice)
+	assert.Equal(t, closeTime, call.UnderlyingTime)
+	assert.InDelta(t, 0.5178, call.Years, 1e-3)
+	assert.InDelta(t, 12.0, *call.MidPrice, 1e-9)
+	require.NotNil(t, call.ImpliedVolatility)
+	require.NotNil(t, call.PricingVolatility)
+	assert.Greater(t, *call.PricingVolatility, 0.0, "historical volatility of the stored closes")
+	require.NotNil(t, call.TheoreticalPrice)
+	assert.Greater(t, call.Greeks.Delta, 0.5)
+
+	put := results[1]
+	require.NoError(t, put.Err)
+	require.NotNil(t, put.PricingVolatility)
+	assert.Equal(t, 0.3, *put.PricingVolatility)
+	assert.Equal(t, 4.5, *put.MidPrice, "last price is used without a quote")
+	require.NotNil(t, put.ImpliedVolatility)
+	assert.Less(t, put.Greeks.Delta, 0.0)
+
+	assert.Error(t, results[2].Err, "underlying without stored prices")
+
+	// without a volatility the contract is not priced, its Greeks are evaluated at the implied volatility
+	unpriced := results[3]
+	require.NoError(t, unpriced.Err)
+	assert.Nil(t, unpriced.PricingVolatility)
+	assert.Nil(t, unpriced.TheoreticalPrice)
+	require.NotNil(t, unpriced.ImpliedVolatility)
+	assert.Greater(t, unpriced.Greeks.Delta, 0.5)
+}
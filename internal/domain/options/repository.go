@@ -0,0 +1,191 @@
+package options
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the interface for managing option contracts and their snapshots in a data store.
+type Repository interface {
+	SaveOptionChain(ctx context.Context, chain *yahoo.OptionChain, snapshotTime time.Time) (int, error)
+	GetExpiries(ctx context.Context, symbol string) ([]time.Time, error)
+	GetChain(ctx context.Context, symbol string, expiry time.Time) (Chain, error)
+	SaveAnalytics(ctx context.Context, analytics []ContractAnalytics, snapshotTime time.Time) error
+}
+
+// OptionsRepository implements the options.Repository interface using PostgreSQL
+type OptionsRepository struct {
+	db *database.DB
+}
+
+// NewOptionsRepository creates a new options repository
+func NewOptionsRepository(db *database.DB) *OptionsRepository {
+	return &OptionsRepository{
+		db: db,
+	}
+}
+
+// SaveOptionChain upserts the underlying symbol, the contracts of the chain and their snapshot for the given time.
+// Returns the number of stored snapshots.
+func (r *OptionsRepository) SaveOptionChain(ctx context.Context, chain *yahoo.OptionChain, snapshotTime time.Time) (int, error) {
+	querySymbol := `
+		INSERT INTO symbols (symbol, name, exchange, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (symbol) DO UPDATE
+		SET name = EXCLUDED.name,
+			exchange = EXCLUDED.exchange
+		RETURNING id
+	`
+
+	queryContract := `
+		INSERT INTO option_contracts (symbol_id, contract_symbol, option_type, expiry, strike)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (contract_symbol) DO UPDATE
+		SET contract_symbol = EXCLUDED.contract_symbol
+		RETURNING id
+	`
+
+	querySnapshot := `
+		INSERT INTO option_snapshots (
+			contract_id, time, bid, ask, last_price, volume, open_interest, implied_volatility
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+		ON CONFLICT (contract_id, time) DO UPDATE SET
+			bid = EXCLUDED.bid,
+			ask = EXCLUDED.ask,
+			last_price = EXCLUDED.last_price,
+			volume = EXCLUDED.volume,
+			open_interest = EXCLUDED.open_interest,
+			implied_volatility = EXCLUDED.implied_volatility;
+	`
+
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		var symbolID int
+		err := tx.QueryRow(ctx, querySymbol, chain.Symbol, chain.Name, chain.Exchange, time.Now()).Scan(&symbolID)
+		if err != nil {
+			return eris.Wrap(err, "failed to insert symbol")
+		}
+
+		for _, c := range chain.Contracts {
+			var contractID int
+			err := tx.QueryRow(ctx, queryContract, symbolID, c.ContractSymbol, string(c.Type), c.Expiry, c.Strike).
+				Scan(&contractID)
+			if err != nil {
+				return eris.Wrapf(err, "failed to insert option contract: %s", c.ContractSymbol)
+			}
+
+			_, err = tx.Exec(ctx, querySnapshot,
+				contractID, snapshotTime, c.Bid, c.Ask, c.LastPrice, c.Volume, c.OpenInterest, c.ImpliedVolatility)
+			if err != nil {
+				return eris.Wrapf(err, "failed to insert option snapshot: %s", c.ContractSymbol)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(chain.Contracts), nil
+}
+
+// GetExpiries returns the expiration dates with stored contracts of a symbol in ascending order.
+func (r *OptionsRepository) GetExpiries(ctx context.Context, symbol string) ([]time.Time, error) {
+	query := `
+		SELECT DISTINCT c.expiry
+		FROM option_contracts c
+		JOIN symbols s ON c.symbol_id = s.id
+		WHERE s.symbol = $1
+		ORDER BY c.expiry
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query expiries for symbol: %s", symbol)
+	}
+
+	expiries, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to collect expiries for symbol: %s", symbol)
+	}
+	return expiries, nil
+}
+
+// GetChain returns the contracts of a symbol expiring on the given date with their latest snapshot,
+// ordered by option type and strike.
+func (r *OptionsRepository) GetChain(ctx context.Context, symbol string, expiry time.Time) (Chain, error) {
+	query := `
+		SELECT c.contract_symbol, c.option_type, c.expiry, c.strike,
+		       os.time, os.bid, os.ask, os.last_price, os.volume, os.open_interest, os.implied_volatility
+		FROM option_contracts c
+		JOIN symbols s ON c.symbol_id = s.id
+		JOIN LATERAL (
+			SELECT *
+			FROM option_snapshots
+			WHERE contract_id = c.id
+			ORDER BY time DESC
+			LIMIT 1
+		) os ON true
+		WHERE s.symbol = $1 AND c.expiry = $2
+		ORDER BY c.option_type, c.strike
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, expiry)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query option chain for symbol: %s", symbol)
+	}
+
+	chain, err := pgx.CollectRows(rows, pgx.RowToStructByName[ChainEntry])
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to collect option chain rows for symbol: %s", symbol)
+	}
+	return chain, nil
+}
+
+// SaveAnalytics upserts the analytics of stored contracts for a snapshot. Analytics without a contract symbol are skipped.
+func (r *OptionsRepository) SaveAnalytics(ctx context.Context, analytics []ContractAnalytics, snapshotTime time.Time) error {
+	query := `
+		INSERT INTO option_analytics (
+			contract_id, time, underlying_price, theoretical_price, implied_volatility,
+			delta, gamma, vega, theta, rho
+		)
+		SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM option_contracts
+		WHERE contract_symbol = $1
+		ON CONFLICT (contract_id, time) DO UPDATE SET
+			underlying_price = EXCLUDED.underlying_price,
+			theoretical_price = EXCLUDED.theoretical_price,
+			implied_volatility = EXCLUDED.implied_volatility,
+			delta = EXCLUDED.delta,
+			gamma = EXCLUDED.gamma,
+			vega = EXCLUDED.vega,
+			theta = EXCLUDED.theta,
+			rho = EXCLUDED.rho;
+	`
+
+	return r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, a := range analytics {
+			if a.Input.ContractSymbol == "" {
+				continue
+			}
+			batch.Queue(query, a.Input.ContractSymbol, snapshotTime, a.UnderlyingPrice, a.TheoreticalPrice, a.ImpliedVolatility,
+				a.Greeks.Delta, a.Greeks.Gamma, a.Greeks.Vega, a.Greeks.Theta, a.Greeks.Rho)
+		}
+
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for i := 0; i < batch.Len(); i++ {
+			if _, err := results.Exec(); err != nil {
+				return eris.Wrap(err, "failed to upsert option analytics")
+			}
+		}
+		return results.Close()
+	})
+}
@@ -0,0 +1,125 @@
+package options_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/providers/yahoo"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsRepository(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := options.NewOptionsRepository(db)
+	ctx := context.TODO()
+
+	day := time.Date(2025, time.June, 13, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2025, time.July, 18, 0, 0, 0, 0, time.UTC)
+	chain := func(bid float64) *yahoo.OptionChain {
+		return &yahoo.OptionChain{
+			Symbol:          "AAPL",
+			Name:            "Apple Inc.",
+			Exchange:        "NMS",
+			ExpirationDates: []time.Time{june, july},
+			Contracts: []yahoo.OptionQuoteData{
+				{ContractSymbol: "AAPL250620P00200000", Type: yahoo.OptionPut, Expiry: june, Strike: 200, Bid: bid,
+					Ask: bid + 0.2, LastPrice: bid + 0.1, Volume: 10, OpenInterest: 100, ImpliedVolatility: 0.3},
+				{ContractSymbol: "AAPL250620C00210000", Type: yahoo.OptionCall, Expiry: june, Strike: 210, Bid: bid},
+				{ContractSymbol: "AAPL250620C00200000", Type: yahoo.OptionCall, Expiry: june, Strike: 200, Bid: bid},
+				{ContractSymbol: "AAPL250718C00200000", Type: yahoo.OptionCall, Expiry: july, Strike: 200, Bid: bid},
+			},
+		}
+	}
+
+	stored, err := repo.SaveOptionChain(ctx, chain(1), day.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, 4, stored)
+	_, err = repo.SaveOptionChain(ctx, chain(2), day)
+	require.NoError(t, err)
+
+	t.Run("Get expiries", func(t *testing.T) {
+		expiries, err := repo.GetExpiries(ctx, "AAPL")
+		require.NoError(t, err)
+		require.Len(t, expiries, 2)
+		assert.True(t, june.Equal(expiries[0]))
+		assert.True(t, july.Equal(expiries[1]))
+
+		expiries, err = repo.GetExpiries(ctx, "MSFT")
+		require.NoError(t, err)
+		assert.Empty(t, expiries)
+	})
+
+	t.Run("Get chain", func(t *testing.T) {
+		entries, err := repo.GetChain(ctx, "AAPL", june)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		// calls before puts, by strike
+		assert.Equal(t, "AAPL250620C00200000", entries[0].ContractSymbol)
+		assert.Equal(t, "AAPL250620C00210000", entries[1].ContractSymbol)
+
+		// contracts come with their latest snapshot
+		put := entries[2]
+		assert.Equal(t, string(yahoo.OptionPut), put.OptionType)
+		assert.True(t, june.Equal(put.Expiry))
+		assert.Equal(t, 200.0, put.Strike)
+		assert.True(t, day.Equal(put.Time))
+		assert.Equal(t, 2.0, *put.Bid)
+		assert.Equal(t, 2.2, *put.Ask)
+		assert.Equal(t, 2.1, *put.LastPrice)
+		assert.Equal(t, int64(10), *put.Volume)
+		assert.Equal(t, int64(100), *put.OpenInterest)
+		assert.Equal(t, 0.3, *put.ImpliedVolatility)
+
+		entries, err = repo.GetChain(ctx, "AAPL", june.AddDate(0, 0, 7))
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Save analytics", func(t *testing.T) {
+		theoretical, implied := 2.15, 0.28
+		require.NoError(t, repo.SaveAnalytics(ctx, []options.ContractAnalytics{
+			{Input: options.ContractInput{ContractSymbol: "AAPL250620P00200000"}, UnderlyingPrice: 203,
+				TheoreticalPrice: &theoretical, ImpliedVolatility: &implied, Greeks: options.Greeks{Delta: -0.4}},
+			// contracts which were not priced keep a null theoretical price
+			{Input: options.ContractInput{ContractSymbol: "AAPL250620C00200000"}, UnderlyingPrice: 203},
+			// analytics of contracts which are not stored are skipped
+			{Input: options.ContractInput{Underlying: "AAPL"}, UnderlyingPrice: 203},
+		}, day))
+
+		var (
+			count int
+			price *float64
+			delta float64
+		)
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM option_analytics WHERE time = $1`, day).
+			Scan(&count))
+		assert.Equal(t, 2, count)
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT a.theoretical_price, a.delta
+			FROM option_analytics a
+			JOIN option_contracts c ON a.contract_id = c.id
+			WHERE c.contract_symbol = 'AAPL250620P00200000'`).Scan(&price, &delta))
+		assert.Equal(t, theoretical, *price)
+		assert.Equal(t, -0.4, delta)
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT a.theoretical_price
+			FROM option_analytics a
+			JOIN option_contracts c ON a.contract_id = c.id
+			WHERE c.contract_symbol = 'AAPL250620C00200000'`).Scan(&price))
+		assert.Nil(t, price)
+	})
+}
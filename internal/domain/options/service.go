@@ -0,0 +1,149 @@
+package options
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Domain errors
+var (
+	ErrChainNotFound = errors.New("option chain not found")
+)
+
+// ChainProvider defines the interface for option chain providers
+type ChainProvider interface {
+	// GetOptionChain fetches the chain of a symbol for one expiry, a zero expiry selects the nearest one
+	GetOptionChain(ctx context.Context, symbol string, expiry time.Time) (*yahoo.OptionChain, error)
+}
+
+// OptionsService provides core domain operations for option chains
+type OptionsService struct {
+	repo     Repository
+	provider ChainProvider
+
+	// Analytics settings
+	underlying       UnderlyingPriceSource
+	riskFreeRate     float64
+	persistAnalytics bool
+}
+
+// NewOptionsService creates a new options service
+func NewOptionsService(repo Repository, provider ChainProvider) *OptionsService {
+	return &OptionsService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// SnapshotChain fetches the chain of every available expiry of a symbol and stores it as today's snapshot.
+// Returns the number of stored contract snapshots.
+func (s *OptionsService) SnapshotChain(ctx context.Context, symbol string) (int, error) {
+	if s.provider == nil {
+		return 0, errors.New("no option chain provider configured")
+	}
+
+	snapshotTime := time.Now().UTC().Truncate(24 * time.Hour)
+
+	chain, err := s.provider.GetOptionChain(ctx, symbol, time.Time{})
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to get option chain")
+	}
+
+	stored, err := s.saveSnapshot(ctx, chain, snapshotTime)
+	if err != nil {
+		return 0, err
+	}
+
+	fetched := make(map[time.Time]bool)
+	for _, c := range chain.Contracts {
+		fetched[c.Expiry] = true
+	}
+
+	for _, expiry := range chain.ExpirationDates {
+		if fetched[expiry] {
+			continue
+		}
+
+		expiryChain, err := s.provider.GetOptionChain(ctx, symbol, expiry)
+		if err != nil {
+			return stored, eris.Wrapf(err, "failed to get option chain for expiry %s", expiry.Format(time.DateOnly))
+		}
+
+		n, err := s.saveSnapshot(ctx, expiryChain, snapshotTime)
+		if err != nil {
+			return stored, eris.Wrapf(err, "expiry %s", expiry.Format(time.DateOnly))
+		}
+		stored += n
+	}
+
+	log.Debug().
+		Str("symbol", symbol).
+		Int("expiries", len(chain.ExpirationDates)).
+		Int("contracts", stored).
+		Msg("Option chain snapshot stored")
+
+	return stored, nil
+}
+
+// saveSnapshot stores the chain and, when enabled, the analytics of its contracts.
+func (s *OptionsService) saveSnapshot(ctx context.Context, chain *yahoo.OptionChain, snapshotTime time.Time) (int, error) {
+	stored, err := s.repo.SaveOptionChain(ctx, chain, snapshotTime)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to save option chain")
+	}
+
+	if !s.persistAnalytics || s.underlying == nil {
+		return stored, nil
+	}
+
+	analytics, err := s.snapshotAnalytics(ctx, chain, time.Now())
+	if err != nil {
+		return stored, eris.Wrap(err, "failed to compute option analytics")
+	}
+	if err := s.repo.SaveAnalytics(ctx, analytics, snapshotTime); err != nil {
+		return stored, eris.Wrap(err, "failed to save option analytics")
+	}
+	return stored, nil
+}
+
+// SnapshotChains takes a chain snapshot of every symbol. A failing symbol does not stop the others,
+// all failures are returned together.
+func (s *OptionsService) SnapshotChains(ctx context.Context, symbols []string) error {
+	var errs []error
+	for _, symbol := range symbols {
+		if _, err := s.SnapshotChain(ctx, symbol); err != nil {
+			log.Error().Err(err).Str("symbol", symbol).Msg("Failed to snapshot option chain")
+			errs = append(errs, eris.Wrapf(err, "symbol %s", symbol))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetExpiries returns the expiration dates with stored contracts of a symbol.
+func (s *OptionsService) GetExpiries(ctx context.Context, symbol string) ([]time.Time, error) {
+	expiries, err := s.repo.GetExpiries(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(expiries) == 0 {
+		return nil, ErrChainNotFound
+	}
+	return expiries, nil
+}
+
+// GetChain returns the latest stored chain of a symbol for an expiry.
+func (s *OptionsService) GetChain(ctx context.Context, symbol string, expiry time.Time) (Chain, error) {
+	chain, err := s.repo.GetChain(ctx, symbol, expiry)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 {
+		return nil, ErrChainNotFound
+	}
+	return chain, nil
+}
@@ -0,0 +1,40 @@
+package series
+
+import (
+	"errors"
+	"time"
+)
+
+// Validation errors
+var (
+	ErrCodeRequired = errors.New("series code is required")
+)
+
+// Series represents a scalar time series (e.g. an interest rate or CPI) with its metadata.
+type Series struct {
+	ID        int       `db:"id"`
+	Code      string    `db:"code"`
+	Title     string    `db:"title"`
+	Frequency string    `db:"frequency"`
+	Units     string    `db:"units"`
+	Source    string    `db:"source"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// IsValid validates the Series object and returns an error if the `Code` field is empty.
+func (s *Series) IsValid() error {
+	if s.Code == "" {
+		return ErrCodeRequired
+	}
+
+	return nil
+}
+
+type Observations []Observation
+
+// Observation represents a single value of a series at a point in time.
+type Observation struct {
+	Time     time.Time `db:"time"`      // TIMESTAMPTZ NOT NULL
+	SeriesID int       `db:"series_id"` // INTEGER NOT NULL (FK)
+	Value    *float64  `db:"value"`     // NUMERIC(20, 6) nullable
+}
@@ -0,0 +1,161 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/providers/fred"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the interface for managing scalar time series in a data store.
+type Repository interface {
+	GetSeries(ctx context.Context, code string) (*Series, error)
+	GetObservations(ctx context.Context, code string, from, to time.Time) (*Observations, error)
+	GetLastObservationTime(ctx context.Context, code string) (*time.Time, error)
+	SaveSeriesData(ctx context.Context, data *fred.SeriesData) (int, error)
+}
+
+// SeriesRepository implements the series.Repository interface using PostgreSQL
+type SeriesRepository struct {
+	db *database.DB
+}
+
+// NewSeriesRepository creates a new series repository
+func NewSeriesRepository(db *database.DB) *SeriesRepository {
+	return &SeriesRepository{
+		db: db,
+	}
+}
+
+// GetSeries retrieves the metadata of a series by its code. Returns ErrSeriesNotFound if not found.
+func (r *SeriesRepository) GetSeries(ctx context.Context, code string) (*Series, error) {
+	query := `
+		SELECT id, code, title, frequency, units, source, created_at
+		FROM series
+		WHERE code = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, code)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query series: %s", code)
+	}
+
+	s, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Series])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, eris.Wrapf(err, "cannot collect exactly one row for series: %s", code)
+	}
+
+	return &s, nil
+}
+
+// GetObservations retrieves observations of a series in ascending time order. Zero from/to leave the range open.
+func (r *SeriesRepository) GetObservations(ctx context.Context, code string, from, to time.Time) (*Observations, error) {
+	query := `
+		SELECT o.time, o.series_id, o.value
+		FROM series_observations o
+		JOIN series s ON o.series_id = s.id
+		WHERE s.code = $1
+		  AND ($2::timestamptz IS NULL OR o.time >= $2)
+		  AND ($3::timestamptz IS NULL OR o.time <= $3)
+		ORDER BY o.time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, code, nullableTime(from), nullableTime(to))
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query observations for series: %s", code)
+	}
+
+	observations, err := pgx.CollectRows(rows, pgx.RowToStructByName[Observation])
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to collect observation rows for series: %s", code)
+	}
+
+	result := Observations(observations)
+	return &result, nil
+}
+
+// GetLastObservationTime returns the time of the latest stored observation or nil if the series has none.
+func (r *SeriesRepository) GetLastObservationTime(ctx context.Context, code string) (*time.Time, error) {
+	query := `
+		SELECT max(o.time)
+		FROM series_observations o
+		JOIN series s ON o.series_id = s.id
+		WHERE s.code = $1
+	`
+
+	var last *time.Time
+	if err := r.db.QueryRowContext(ctx, query, code).Scan(&last); err != nil {
+		return nil, eris.Wrapf(err, "failed to get last observation time for series: %s", code)
+	}
+	return last, nil
+}
+
+// SaveSeriesData upserts the series metadata and its observations in a single transaction and returns
+// the number of stored observations.
+func (r *SeriesRepository) SaveSeriesData(ctx context.Context, data *fred.SeriesData) (int, error) {
+	s := &Series{Code: data.Code}
+	if err := s.IsValid(); err != nil {
+		return 0, eris.Wrapf(err, "invalid series: %s", data.Code)
+	}
+
+	querySeries := `
+		INSERT INTO series (code, title, frequency, units, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code) DO UPDATE
+		SET title = EXCLUDED.title,
+			frequency = EXCLUDED.frequency,
+			units = EXCLUDED.units,
+			source = EXCLUDED.source
+		RETURNING id
+	`
+
+	queryObservation := `
+		INSERT INTO series_observations (time, series_id, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (series_id, time) DO UPDATE SET
+			value = EXCLUDED.value;
+	`
+
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		var seriesID int
+		err := tx.QueryRow(ctx, querySeries, data.Code, data.Title, data.Frequency, data.Units, data.Source, time.Now()).
+			Scan(&seriesID)
+		if err != nil {
+			return eris.Wrap(err, "failed to insert series")
+		}
+
+		batch := &pgx.Batch{}
+		for _, point := range data.Points {
+			batch.Queue(queryObservation, point.Time, seriesID, point.Value)
+		}
+
+		results := tx.SendBatch(ctx, batch)
+		defer results.Close()
+
+		for range data.Points {
+			if _, err := results.Exec(); err != nil {
+				return eris.Wrap(err, "failed to upsert series observation")
+			}
+		}
+
+		return results.Close()
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(data.Points), nil
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
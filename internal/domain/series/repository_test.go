@@ -0,0 +1,61 @@
+package series_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/series"
+	"github.com/market-data/internal/providers/fred"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesRepository_SaveSeriesData(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := series.NewSeriesRepository(db)
+	ctx := context.TODO()
+
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	stored, err := repo.SaveSeriesData(ctx, &fred.SeriesData{
+		Source:    fred.Source,
+		Code:      "UNRATE",
+		Title:     "Unemployment Rate",
+		Frequency: "M",
+		Units:     "%",
+		Points: []fred.SeriesPoint{
+			{Time: march, Value: testsTools.Ptr(4.2)},
+			{Time: april, Value: nil},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+
+	s, err := repo.GetSeries(ctx, "UNRATE")
+	require.NoError(t, err)
+	assert.Equal(t, "Unemployment Rate", s.Title)
+
+	observations, err := repo.GetObservations(ctx, "UNRATE", march, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, *observations, 2)
+	assert.Equal(t, 4.2, *(*observations)[0].Value)
+	assert.Nil(t, (*observations)[1].Value)
+
+	last, err := repo.GetLastObservationTime(ctx, "UNRATE")
+	require.NoError(t, err)
+	assert.True(t, april.Equal(*last))
+
+	_, err = repo.GetSeries(ctx, "NOPE")
+	assert.ErrorIs(t, err, series.ErrSeriesNotFound)
+}
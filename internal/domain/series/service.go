@@ -0,0 +1,87 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/market-data/internal/providers/fred"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Domain errors
+var (
+	// ErrSeriesNotFound is returned for series neither stored nor known to the provider
+	ErrSeriesNotFound = fred.ErrSeriesNotFound
+)
+
+// DataProvider defines the interface for economic time series providers
+type DataProvider interface {
+	// GetSeries fetches a series with its observations since start, a zero start fetches the full history
+	GetSeries(ctx context.Context, code string, start time.Time) (*fred.SeriesData, error)
+}
+
+// SeriesService provides core domain operations for scalar time series
+type SeriesService struct {
+	repo     Repository
+	provider DataProvider
+}
+
+// NewSeriesService creates a new series service
+func NewSeriesService(repo Repository, provider DataProvider) *SeriesService {
+	return &SeriesService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// FetchAndStoreSeries fetches a series from the provider and stores it. Only observations since the latest
+// stored one are requested, the latest observation is fetched again to pick up revisions.
+func (s *SeriesService) FetchAndStoreSeries(ctx context.Context, code string) (int, error) {
+	if s.provider == nil {
+		return 0, errors.New("no series provider configured")
+	}
+
+	last, err := s.repo.GetLastObservationTime(ctx, code)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to get last observation time")
+	}
+
+	var start time.Time
+	if last != nil {
+		start = *last
+	}
+
+	data, err := s.provider.GetSeries(ctx, code, start)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to get series")
+	}
+
+	stored, err := s.repo.SaveSeriesData(ctx, data)
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to save series")
+	}
+
+	log.Debug().
+		Str("series", code).
+		Int("observations", stored).
+		Msg("Series stored")
+
+	return stored, nil
+}
+
+// GetSeries retrieves a series and its observations within the given range. Zero from/to leave the range open.
+func (s *SeriesService) GetSeries(ctx context.Context, code string, from, to time.Time) (*Series, *Observations, error) {
+	series, err := s.repo.GetSeries(ctx, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	observations, err := s.repo.GetObservations(ctx, code, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return series, observations, nil
+}
@@ -0,0 +1,119 @@
+package tracking
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Domain errors
+var (
+	ErrTrackedSymbolNotFound = errors.New("tracked symbol not found")
+	ErrTrackedSymbolExists   = errors.New("symbol is already tracked")
+	ErrInvalidPolicy         = errors.New("invalid fetch policy")
+)
+
+// intervals are the bar intervals a tracked symbol can collect
+var intervals = []string{
+	string(yahoo.Interval1m),
+	string(yahoo.Interval5m),
+	string(yahoo.Interval15m),
+	string(yahoo.Interval30m),
+	string(yahoo.Interval1h),
+	string(yahoo.Interval1d),
+	string(yahoo.Interval1wk),
+	string(yahoo.Interval1mo),
+}
+
+// TrackedSymbol represents a symbol refreshed by the scheduler with its fetch policy and refresh state.
+type TrackedSymbol struct {
+	ID             int        `db:"id"`
+	Symbol         string     `db:"symbol"`
+	Intervals      []string   `db:"intervals"`       // bar intervals collected
+	HistoryDays    int        `db:"history_days"`    // history fetched when an interval starts being collected
+	RefreshMinutes int        `db:"refresh_minutes"` // time between two refreshes
+	Provider       string     `db:"provider"`        // empty for the configured provider
+	Active         bool       `db:"active"`
+	LastAttemptAt  *time.Time `db:"last_attempt_at"`
+	LastSuccessAt  *time.Time `db:"last_success_at"` // last refresh of all intervals, reset when the intervals change
+	// IntervalSuccessAt holds the start of the last successful refresh per interval, intervals which never
+	// refreshed successfully are missing and fetch their history
+	IntervalSuccessAt map[string]time.Time `db:"interval_success_at"`
+	LastError         *string              `db:"last_error"`
+	CreatedAt         time.Time            `db:"created_at"`
+	UpdatedAt         time.Time            `db:"updated_at"`
+}
+
+// Policy is the fetch policy applied to tracked symbols created without one.
+type Policy struct {
+	Intervals      []string
+	HistoryDays    int
+	RefreshMinutes int
+}
+
+// Update changes the fetch policy of a tracked symbol, nil fields are left unchanged.
+type Update struct {
+	Intervals      []string
+	HistoryDays    *int
+	RefreshMinutes *int
+	Provider       *string
+	Active         *bool
+}
+
+// RefreshInterval returns the time between two refreshes.
+func (t *TrackedSymbol) RefreshInterval() time.Duration {
+	return time.Duration(t.RefreshMinutes) * time.Minute
+}
+
+// Due reports whether the symbol is active and its last refresh is at least a refresh interval old.
+func (t *TrackedSymbol) Due(now time.Time) bool {
+	return t.Active && (t.LastAttemptAt == nil || now.Sub(*t.LastAttemptAt) >= t.RefreshInterval())
+}
+
+// FetchStart returns the start of the next fetch of an interval, the end of the configured history for
+// intervals without a successful refresh, otherwise the start of their last successful refresh.
+func (t *TrackedSymbol) FetchStart(interval string, now time.Time) time.Time {
+	if successAt, ok := t.IntervalSuccessAt[interval]; ok {
+		return successAt
+	}
+	return now.AddDate(0, 0, -t.HistoryDays)
+}
+
+// normalize upper-cases the symbol and drops duplicated intervals, keeping their order.
+func (t *TrackedSymbol) normalize() {
+	t.Symbol = strings.ToUpper(strings.TrimSpace(t.Symbol))
+	var unique []string
+	for _, interval := range t.Intervals {
+		if !slices.Contains(unique, interval) {
+			unique = append(unique, interval)
+		}
+	}
+	t.Intervals = unique
+}
+
+// IsValid validates the symbol and its fetch policy, errors wrap ErrInvalidPolicy.
+func (t *TrackedSymbol) IsValid() error {
+	if t.Symbol == "" {
+		return eris.Wrap(ErrInvalidPolicy, "symbol is required")
+	}
+	if len(t.Intervals) == 0 {
+		return eris.Wrap(ErrInvalidPolicy, "at least one interval is required")
+	}
+	for _, interval := range t.Intervals {
+		if !slices.Contains(intervals, interval) {
+			return eris.Wrapf(ErrInvalidPolicy, "unsupported interval %q, supported are %s",
+				interval, strings.Join(intervals, ", "))
+		}
+	}
+	if t.HistoryDays <= 0 {
+		return eris.Wrap(ErrInvalidPolicy, "history days must be positive")
+	}
+	if t.RefreshMinutes <= 0 {
+		return eris.Wrap(ErrInvalidPolicy, "refresh minutes must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the persistence operations of tracked symbols
+type Repository interface {
+	ListTrackedSymbols(ctx context.Context, activeOnly bool) ([]TrackedSymbol, error)
+	GetTrackedSymbol(ctx context.Context, symbol string) (*TrackedSymbol, error)
+	CreateTrackedSymbol(ctx context.Context, t *TrackedSymbol) error
+	UpdateTrackedSymbol(ctx context.Context, t *TrackedSymbol) error
+	DeleteTrackedSymbol(ctx context.Context, symbol string) error
+	CountTrackedSymbols(ctx context.Context) (int, error)
+	SaveRefresh(ctx context.Context, id int, attemptAt time.Time, succeeded []string, refreshErr error) error
+}
+
+const trackedSymbolColumns = `
+	id, symbol, intervals, history_days, refresh_minutes, provider, active,
+	last_attempt_at, last_success_at, interval_success_at, last_error, created_at, updated_at
+`
+
+// TrackingRepository persists tracked symbols in PostgreSQL
+type TrackingRepository struct {
+	db *database.DB
+}
+
+// NewTrackingRepository creates a new tracked symbols repository
+func NewTrackingRepository(db *database.DB) *TrackingRepository {
+	return &TrackingRepository{
+		db: db,
+	}
+}
+
+// ListTrackedSymbols retrieves the tracked symbols ordered by symbol, optionally only the active ones.
+func (r *TrackingRepository) ListTrackedSymbols(ctx context.Context, activeOnly bool) ([]TrackedSymbol, error) {
+	query := `SELECT ` + trackedSymbolColumns + `
+		FROM tracked_symbols
+		WHERE active OR NOT $1
+		ORDER BY symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, activeOnly)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query tracked symbols")
+	}
+
+	tracked, err := pgx.CollectRows(rows, pgx.RowToStructByName[TrackedSymbol])
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to collect tracked symbol rows")
+	}
+	return tracked, nil
+}
+
+// GetTrackedSymbol retrieves a tracked symbol. Returns ErrTrackedSymbolNotFound if not found.
+func (r *TrackingRepository) GetTrackedSymbol(ctx context.Context, symbol string) (*TrackedSymbol, error) {
+	query := `SELECT ` + trackedSymbolColumns + `
+		FROM tracked_symbols
+		WHERE symbol = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to query tracked symbol: %s", symbol)
+	}
+
+	t, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[TrackedSymbol])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTrackedSymbolNotFound
+		}
+		return nil, eris.Wrapf(err, "cannot collect exactly one row for tracked symbol: %s", symbol)
+	}
+	return &t, nil
+}
+
+// CreateTrackedSymbol inserts a tracked symbol and sets its id and timestamps. Returns ErrTrackedSymbolExists
+// if the symbol is already tracked.
+func (r *TrackingRepository) CreateTrackedSymbol(ctx context.Context, t *TrackedSymbol) error {
+	query := `
+		INSERT INTO tracked_symbols (symbol, intervals, history_days, refresh_minutes, provider, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (symbol) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, t.Symbol, t.Intervals, t.HistoryDays, t.RefreshMinutes, t.Provider,
+		t.Active).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTrackedSymbolExists
+		}
+		return eris.Wrapf(err, "failed to create tracked symbol: %s", t.Symbol)
+	}
+	return nil
+}
+
+// UpdateTrackedSymbol stores the fetch policy of a tracked symbol. Changing the intervals resets the last
+// successful refresh of all intervals and drops the successes of removed intervals, added intervals fetch
+// their history on the next refresh.
+func (r *TrackingRepository) UpdateTrackedSymbol(ctx context.Context, t *TrackedSymbol) error {
+	query := `
+		UPDATE tracked_symbols
+		SET last_success_at = CASE WHEN intervals = $2 THEN last_success_at END,
+			interval_success_at = (
+				SELECT COALESCE(jsonb_object_agg(key, value), '{}')
+				FROM jsonb_each(interval_success_at)
+				WHERE key = ANY($2)
+			),
+			intervals = $2,
+			history_days = $3,
+			refresh_minutes = $4,
+			provider = $5,
+			active = $6,
+			updated_at = now()
+		WHERE id = $1
+		RETURNING last_success_at, interval_success_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, t.ID, t.Intervals, t.HistoryDays, t.RefreshMinutes, t.Provider,
+		t.Active).Scan(&t.LastSuccessAt, &t.IntervalSuccessAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTrackedSymbolNotFound
+		}
+		return eris.Wrapf(err, "failed to update tracked symbol: %s", t.Symbol)
+	}
+	return nil
+}
+
+// DeleteTrackedSymbol stops tracking a symbol, its stored prices are kept. Returns ErrTrackedSymbolNotFound
+// if the symbol is not tracked.
+func (r *TrackingRepository) DeleteTrackedSymbol(ctx context.Context, symbol string) error {
+	tag, err := r.db.ExecContext(ctx, `DELETE FROM tracked_symbols WHERE symbol = $1`, symbol)
+	if err != nil {
+		return eris.Wrapf(err, "failed to delete tracked symbol: %s", symbol)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTrackedSymbolNotFound
+	}
+	return nil
+}
+
+// CountTrackedSymbols returns the number of tracked symbols, active or not.
+func (r *TrackingRepository) CountTrackedSymbols(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM tracked_symbols`).Scan(&count); err != nil {
+		return 0, eris.Wrap(err, "failed to count tracked symbols")
+	}
+	return count, nil
+}
+
+// SaveRefresh records a refresh started at attemptAt, the intervals which succeeded continue from it. A nil
+// error marks the refresh of all intervals successful.
+func (r *TrackingRepository) SaveRefresh(ctx context.Context, id int, attemptAt time.Time, succeeded []string,
+	refreshErr error) error {
+	query := `
+		UPDATE tracked_symbols
+		SET last_attempt_at = $2,
+			last_success_at = CASE WHEN $3::TEXT IS NULL THEN $2 ELSE last_success_at END,
+			interval_success_at = interval_success_at || (
+				SELECT COALESCE(jsonb_object_agg(i, $2::TIMESTAMPTZ), '{}')
+				FROM unnest($4::TEXT[]) AS i
+			),
+			last_error = $3
+		WHERE id = $1
+	`
+
+	var msg *string
+	if refreshErr != nil {
+		m := refreshErr.Error()
+		msg = &m
+	}
+	if _, err := r.db.ExecContext(ctx, query, id, attemptAt, msg, succeeded); err != nil {
+		return eris.Wrapf(err, "failed to save refresh of tracked symbol %d", id)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package tracking_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/tracking"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackingRepository(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := tracking.NewTrackingRepository(db)
+	ctx := context.TODO()
+
+	aapl := &tracking.TrackedSymbol{Symbol: "AAPL", Intervals: []string{"1d", "5m"}, HistoryDays: 365,
+		RefreshMinutes: 15, Active: true}
+	require.NoError(t, repo.CreateTrackedSymbol(ctx, aapl))
+	assert.NotZero(t, aapl.ID)
+	require.NoError(t, repo.CreateTrackedSymbol(ctx, &tracking.TrackedSymbol{Symbol: "MSFT",
+		Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 60, Provider: "binance"}))
+	assert.ErrorIs(t, repo.CreateTrackedSymbol(ctx, &tracking.TrackedSymbol{Symbol: "AAPL",
+		Intervals: []string{"1d"}, HistoryDays: 1, RefreshMinutes: 1}), tracking.ErrTrackedSymbolExists)
+
+	count, err := repo.CountTrackedSymbols(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	active, err := repo.ListTrackedSymbols(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, []string{"1d", "5m"}, active[0].Intervals)
+
+	attemptAt := time.Date(2025, time.June, 13, 14, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SaveRefresh(ctx, aapl.ID, attemptAt, []string{"1d", "5m"}, nil))
+	require.NoError(t, repo.SaveRefresh(ctx, aapl.ID, attemptAt.Add(time.Hour), []string{"1d"},
+		errors.New("interval 5m: provider unavailable")))
+
+	got, err := repo.GetTrackedSymbol(ctx, "AAPL")
+	require.NoError(t, err)
+	require.NotNil(t, got.LastSuccessAt)
+	assert.True(t, attemptAt.Equal(*got.LastSuccessAt), "failed refreshes keep the last success")
+	assert.True(t, attemptAt.Add(time.Hour).Equal(*got.LastAttemptAt))
+	require.NotNil(t, got.LastError)
+	assert.Equal(t, "interval 5m: provider unavailable", *got.LastError)
+	// the intervals which refreshed continue from their own success
+	require.Len(t, got.IntervalSuccessAt, 2)
+	assert.True(t, attemptAt.Add(time.Hour).Equal(got.IntervalSuccessAt["1d"]))
+	assert.True(t, attemptAt.Equal(got.IntervalSuccessAt["5m"]))
+
+	// changing the intervals restarts the history fetch
+	got.RefreshMinutes = 5
+	require.NoError(t, repo.UpdateTrackedSymbol(ctx, got))
+	assert.NotNil(t, got.LastSuccessAt)
+	got.Intervals = []string{"1d", "5m", "1h"}
+	require.NoError(t, repo.UpdateTrackedSymbol(ctx, got))
+	assert.Nil(t, got.LastSuccessAt)
+	assert.Len(t, got.IntervalSuccessAt, 2, "the added interval fetches its history")
+	got.Intervals = []string{"1d", "1h"}
+	require.NoError(t, repo.UpdateTrackedSymbol(ctx, got))
+	assert.Equal(t, []string{"1d"}, slices.Collect(maps.Keys(got.IntervalSuccessAt)), "removed intervals are dropped")
+
+	require.NoError(t, repo.DeleteTrackedSymbol(ctx, "MSFT"))
+	assert.ErrorIs(t, repo.DeleteTrackedSymbol(ctx, "MSFT"), tracking.ErrTrackedSymbolNotFound)
+	_, err = repo.GetTrackedSymbol(ctx, "MSFT")
+	assert.ErrorIs(t, err, tracking.ErrTrackedSymbolNotFound)
+}
@@ -0,0 +1,254 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Fetcher fetches and stores the bars of a symbol, implemented by the market service of a provider
+type Fetcher interface {
+	// FetchAndStoreRange fetches the bars of an interval of a symbol from start to now and stores them
+	FetchAndStoreRange(ctx context.Context, symbol string, interval yahoo.IntervalAPI, start time.Time) error
+}
+
+// QuotaChecker reports whether a provider may still be called today
+type QuotaChecker interface {
+	// CheckQuota returns an error when the daily request cap of the provider is reached
+	CheckQuota(provider string) error
+}
+
+// TrackingService manages the tracked symbols and refreshes them according to their fetch policy
+type TrackingService struct {
+	repo            Repository
+	fetchers        map[string]Fetcher
+	defaultProvider string
+	defaults        Policy
+	quota           QuotaChecker
+	now             func() time.Time
+}
+
+// NewTrackingService creates a new tracking service. Fetchers are looked up by provider name, symbols
+// without a provider use the default one.
+func NewTrackingService(repo Repository, defaultProvider string, fetchers map[string]Fetcher,
+	defaults Policy) *TrackingService {
+	return &TrackingService{
+		repo:            repo,
+		fetchers:        fetchers,
+		defaultProvider: defaultProvider,
+		defaults:        defaults,
+		now:             time.Now,
+	}
+}
+
+// SetQuotaChecker pauses the refreshes of providers whose daily request cap is reached
+func (s *TrackingService) SetQuotaChecker(quota QuotaChecker) {
+	s.quota = quota
+}
+
+// Providers returns the names of the providers symbols can be tracked with, sorted.
+func (s *TrackingService) Providers() []string {
+	providers := make([]string, 0, len(s.fetchers))
+	for name := range s.fetchers {
+		providers = append(providers, name)
+	}
+	slices.Sort(providers)
+	return providers
+}
+
+// ListTrackedSymbols returns the tracked symbols, optionally only the active ones.
+func (s *TrackingService) ListTrackedSymbols(ctx context.Context, activeOnly bool) ([]TrackedSymbol, error) {
+	return s.repo.ListTrackedSymbols(ctx, activeOnly)
+}
+
+// GetTrackedSymbol returns a tracked symbol, ErrTrackedSymbolNotFound if it is not tracked.
+func (s *TrackingService) GetTrackedSymbol(ctx context.Context, symbol string) (*TrackedSymbol, error) {
+	return s.repo.GetTrackedSymbol(ctx, symbol)
+}
+
+// ActiveSymbols returns the symbols of the active tracked symbols.
+func (s *TrackingService) ActiveSymbols(ctx context.Context) ([]string, error) {
+	tracked, err := s.repo.ListTrackedSymbols(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		symbols = append(symbols, t.Symbol)
+	}
+	return symbols, nil
+}
+
+// Track starts tracking a symbol. Zero policy fields are filled from the default policy. Returns an error
+// wrapping ErrInvalidPolicy for invalid policies and ErrTrackedSymbolExists for tracked symbols.
+func (s *TrackingService) Track(ctx context.Context, t *TrackedSymbol) error {
+	if len(t.Intervals) == 0 {
+		t.Intervals = slices.Clone(s.defaults.Intervals)
+	}
+	if t.HistoryDays == 0 {
+		t.HistoryDays = s.defaults.HistoryDays
+	}
+	if t.RefreshMinutes == 0 {
+		t.RefreshMinutes = s.defaults.RefreshMinutes
+	}
+	t.normalize()
+	if err := s.validate(t); err != nil {
+		return err
+	}
+	return s.repo.CreateTrackedSymbol(ctx, t)
+}
+
+// UpdateTrackedSymbol changes the fetch policy of a tracked symbol and returns the updated symbol.
+func (s *TrackingService) UpdateTrackedSymbol(ctx context.Context, symbol string, update Update) (*TrackedSymbol, error) {
+	t, err := s.repo.GetTrackedSymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if update.Intervals != nil {
+		t.Intervals = update.Intervals
+	}
+	if update.HistoryDays != nil {
+		t.HistoryDays = *update.HistoryDays
+	}
+	if update.RefreshMinutes != nil {
+		t.RefreshMinutes = *update.RefreshMinutes
+	}
+	if update.Provider != nil {
+		t.Provider = *update.Provider
+	}
+	if update.Active != nil {
+		t.Active = *update.Active
+	}
+	t.normalize()
+	if err := s.validate(t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateTrackedSymbol(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Untrack stops tracking a symbol, ErrTrackedSymbolNotFound if it is not tracked.
+func (s *TrackingService) Untrack(ctx context.Context, symbol string) error {
+	return s.repo.DeleteTrackedSymbol(ctx, symbol)
+}
+
+func (s *TrackingService) validate(t *TrackedSymbol) error {
+	if err := t.IsValid(); err != nil {
+		return err
+	}
+	if _, ok := s.fetcher(t.Provider); !ok {
+		return eris.Wrapf(ErrInvalidPolicy, "unknown provider %q", t.Provider)
+	}
+	return nil
+}
+
+func (s *TrackingService) fetcher(provider string) (Fetcher, bool) {
+	if provider == "" {
+		provider = s.defaultProvider
+	}
+	fetcher, ok := s.fetchers[provider]
+	return fetcher, ok
+}
+
+// Seed tracks the symbols with the default policy when no symbol is tracked yet, which carries over a
+// statically configured symbol list on the first start.
+func (s *TrackingService) Seed(ctx context.Context, symbols []string) error {
+	count, err := s.repo.CountTrackedSymbols(ctx)
+	if err != nil || count > 0 {
+		return err
+	}
+	for _, symbol := range symbols {
+		err := s.Track(ctx, &TrackedSymbol{Symbol: symbol, Active: true})
+		if err != nil && !errors.Is(err, ErrTrackedSymbolExists) {
+			return eris.Wrapf(err, "failed to track symbol %s", symbol)
+		}
+	}
+	log.Info().Strs("symbols", symbols).Msg("Tracked symbols seeded")
+	return nil
+}
+
+// RefreshDue refreshes the active tracked symbols whose refresh interval elapsed, with at most concurrency
+// symbols in flight. Symbols of providers whose daily cap is reached are skipped and stay due. A failing
+// symbol does not stop the others, all failures are returned together.
+//
+// Every symbol is fetched on its own, one request per interval at least. The multi-symbol spark endpoint of
+// Yahoo Finance only carries close prices, so it serves batched quotes but cannot fill OHLCV bars.
+func (s *TrackingService) RefreshDue(ctx context.Context, concurrency int) error {
+	tracked, err := s.repo.ListTrackedSymbols(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+		sem  = make(chan struct{}, max(concurrency, 1))
+		now  = s.now()
+	)
+	for _, t := range tracked {
+		if !t.Due(now) {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.refresh(ctx, &t); err != nil {
+				log.Error().Err(err).Str("symbol", t.Symbol).Msg("Failed to refresh tracked symbol")
+				mu.Lock()
+				errs = append(errs, eris.Wrapf(err, "symbol %s", t.Symbol))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// refresh fetches every interval of a tracked symbol and records the outcome. A failing interval does not
+// hold back the others, each continues from its own last successful refresh.
+func (s *TrackingService) refresh(ctx context.Context, t *TrackedSymbol) error {
+	provider := t.Provider
+	if provider == "" {
+		provider = s.defaultProvider
+	}
+	fetcher, ok := s.fetchers[provider]
+	if !ok {
+		return eris.Errorf("unknown provider %q", provider)
+	}
+	if s.quota != nil {
+		if err := s.quota.CheckQuota(provider); err != nil {
+			log.Warn().Err(err).Str("symbol", t.Symbol).Msg("Tracked symbol refresh paused")
+			return nil
+		}
+	}
+
+	attemptAt := s.now()
+	var (
+		succeeded []string
+		errs      []error
+	)
+	for _, interval := range t.Intervals {
+		start := t.FetchStart(interval, attemptAt)
+		if err := fetcher.FetchAndStoreRange(ctx, t.Symbol, yahoo.IntervalAPI(interval), start); err != nil {
+			errs = append(errs, eris.Wrapf(err, "interval %s", interval))
+			continue
+		}
+		succeeded = append(succeeded, interval)
+	}
+	refreshErr := errors.Join(errs...)
+	if err := s.repo.SaveRefresh(ctx, t.ID, attemptAt, succeeded, refreshErr); err != nil {
+		return errors.Join(refreshErr, err)
+	}
+	return refreshErr
+}
@@ -0,0 +1,236 @@
+package tracking_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	mu        sync.Mutex
+	tracked   map[string]*tracking.TrackedSymbol
+	refreshes map[int]error
+	succeeded map[int][]string // intervals which refreshed successfully
+}
+
+func newFakeRepository(tracked ...tracking.TrackedSymbol) *fakeRepository {
+	r := &fakeRepository{tracked: make(map[string]*tracking.TrackedSymbol), refreshes: make(map[int]error),
+		succeeded: make(map[int][]string)}
+	for i := range tracked {
+		tracked[i].ID = i + 1
+		r.tracked[tracked[i].Symbol] = &tracked[i]
+	}
+	return r
+}
+
+func (r *fakeRepository) ListTrackedSymbols(_ context.Context, activeOnly bool) ([]tracking.TrackedSymbol, error) {
+	var list []tracking.TrackedSymbol
+	for _, t := range r.tracked {
+		if t.Active || !activeOnly {
+			list = append(list, *t)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepository) GetTrackedSymbol(_ context.Context, symbol string) (*tracking.TrackedSymbol, error) {
+	t, ok := r.tracked[symbol]
+	if !ok {
+		return nil, tracking.ErrTrackedSymbolNotFound
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *fakeRepository) CreateTrackedSymbol(_ context.Context, t *tracking.TrackedSymbol) error {
+	if _, ok := r.tracked[t.Symbol]; ok {
+		return tracking.ErrTrackedSymbolExists
+	}
+	t.ID = len(r.tracked) + 1
+	c := *t
+	r.tracked[t.Symbol] = &c
+	return nil
+}
+
+func (r *fakeRepository) UpdateTrackedSymbol(_ context.Context, t *tracking.TrackedSymbol) error {
+	c := *t
+	r.tracked[t.Symbol] = &c
+	return nil
+}
+
+func (r *fakeRepository) DeleteTrackedSymbol(_ context.Context, symbol string) error {
+	delete(r.tracked, symbol)
+	return nil
+}
+
+func (r *fakeRepository) CountTrackedSymbols(_ context.Context) (int, error) {
+	return len(r.tracked), nil
+}
+
+func (r *fakeRepository) SaveRefresh(_ context.Context, id int, _ time.Time, succeeded []string,
+	refreshErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshes[id] = refreshErr
+	r.succeeded[id] = succeeded
+	return nil
+}
+
+type fetch struct {
+	symbol   string
+	interval yahoo.IntervalAPI
+	start    time.Time
+}
+
+type fakeFetcher struct {
+	mu           sync.Mutex
+	fetches      []fetch
+	fail         string
+	failInterval yahoo.IntervalAPI
+}
+
+func (f *fakeFetcher) FetchAndStoreRange(_ context.Context, symbol string, interval yahoo.IntervalAPI,
+	start time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetches = append(f.fetches, fetch{symbol: symbol, interval: interval, start: start})
+	if symbol == f.fail || interval == f.failInterval {
+		return errors.New("provider unavailable")
+	}
+	return nil
+}
+
+type fakeQuota map[string]bool
+
+func (q fakeQuota) CheckQuota(provider string) error {
+	if q[provider] {
+		return errors.New("daily cap reached")
+	}
+	return nil
+}
+
+var defaultPolicy = tracking.Policy{Intervals: []string{"1d"}, HistoryDays: 365, RefreshMinutes: 15}
+
+func TestTrackingService_Track(t *testing.T) {
+	repo := newFakeRepository()
+	svc := tracking.NewTrackingService(repo, "yahoo", map[string]tracking.Fetcher{"yahoo": &fakeFetcher{}},
+		defaultPolicy)
+	ctx := context.TODO()
+
+	tracked := &tracking.TrackedSymbol{Symbol: " aapl ", Intervals: []string{"5m", "1d", "5m"}, Active: true}
+	require.NoError(t, svc.Track(ctx, tracked))
+	assert.Equal(t, "AAPL", tracked.Symbol)
+	assert.Equal(t, []string{"5m", "1d"}, tracked.Intervals)
+	assert.Equal(t, 365, tracked.HistoryDays, "defaults fill missing fields")
+	assert.Equal(t, 15, tracked.RefreshMinutes)
+
+	assert.ErrorIs(t, svc.Track(ctx, &tracking.TrackedSymbol{Symbol: "AAPL"}), tracking.ErrTrackedSymbolExists)
+	assert.ErrorIs(t, svc.Track(ctx, &tracking.TrackedSymbol{Symbol: "MSFT", Intervals: []string{"2m"}}),
+		tracking.ErrInvalidPolicy)
+	assert.ErrorIs(t, svc.Track(ctx, &tracking.TrackedSymbol{Symbol: "MSFT", Provider: "binance"}),
+		tracking.ErrInvalidPolicy)
+	assert.ErrorIs(t, svc.Track(ctx, &tracking.TrackedSymbol{Symbol: "MSFT", HistoryDays: -1}),
+		tracking.ErrInvalidPolicy)
+
+	inactive := false
+	updated, err := svc.UpdateTrackedSymbol(ctx, "AAPL", tracking.Update{Active: &inactive})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Equal(t, []string{"5m", "1d"}, updated.Intervals)
+
+	_, err = svc.UpdateTrackedSymbol(ctx, "GOOG", tracking.Update{Active: &inactive})
+	assert.ErrorIs(t, err, tracking.ErrTrackedSymbolNotFound)
+}
+
+func TestTrackingService_Seed(t *testing.T) {
+	repo := newFakeRepository()
+	svc := tracking.NewTrackingService(repo, "yahoo", map[string]tracking.Fetcher{"yahoo": &fakeFetcher{}},
+		defaultPolicy)
+	ctx := context.TODO()
+
+	require.NoError(t, svc.Seed(ctx, []string{"AAPL", "MSFT"}))
+	symbols, err := svc.ActiveSymbols(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"AAPL", "MSFT"}, symbols)
+
+	// symbols are only seeded into an empty table
+	require.NoError(t, svc.Untrack(ctx, "MSFT"))
+	require.NoError(t, svc.Seed(ctx, []string{"AAPL", "MSFT"}))
+	symbols, err = svc.ActiveSymbols(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbols)
+}
+
+func TestTrackingService_RefreshDue(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-5 * time.Minute)
+	old := now.Add(-time.Hour)
+	repo := newFakeRepository(
+		tracking.TrackedSymbol{Symbol: "NEW", Intervals: []string{"1d", "5m"}, HistoryDays: 30, RefreshMinutes: 15, Active: true},
+		tracking.TrackedSymbol{Symbol: "FRESH", Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 15, Active: true,
+			LastAttemptAt: &recent, LastSuccessAt: &recent, IntervalSuccessAt: map[string]time.Time{"1d": recent}},
+		tracking.TrackedSymbol{Symbol: "STALE", Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 15, Active: true,
+			LastAttemptAt: &old, LastSuccessAt: &old, IntervalSuccessAt: map[string]time.Time{"1d": old}},
+		tracking.TrackedSymbol{Symbol: "PAUSED", Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 15, Active: false},
+		tracking.TrackedSymbol{Symbol: "BROKEN", Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 15, Active: true},
+		tracking.TrackedSymbol{Symbol: "BTCUSDT", Intervals: []string{"1h"}, HistoryDays: 30, RefreshMinutes: 15, Active: true,
+			Provider: "binance"},
+	)
+	yahooFetcher := &fakeFetcher{fail: "BROKEN"}
+	binanceFetcher := &fakeFetcher{}
+	svc := tracking.NewTrackingService(repo, "yahoo",
+		map[string]tracking.Fetcher{"yahoo": yahooFetcher, "binance": binanceFetcher}, defaultPolicy)
+	svc.SetQuotaChecker(fakeQuota{"binance": true})
+
+	err := svc.RefreshDue(context.TODO(), 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BROKEN")
+
+	fetched := make(map[string][]fetch)
+	for _, f := range yahooFetcher.fetches {
+		fetched[f.symbol] = append(fetched[f.symbol], f)
+	}
+	assert.Len(t, fetched, 3, "only due symbols are refreshed")
+	require.Len(t, fetched["NEW"], 2)
+	assert.Equal(t, yahoo.Interval1d, fetched["NEW"][0].interval)
+	assert.Equal(t, yahoo.Interval5m, fetched["NEW"][1].interval)
+	assert.WithinDuration(t, now.AddDate(0, 0, -30), fetched["NEW"][0].start, time.Minute,
+		"new symbols fetch their history")
+	assert.Equal(t, old, fetched["STALE"][0].start, "refreshes continue from the last success")
+	assert.Empty(t, binanceFetcher.fetches, "capped providers are paused")
+
+	assert.NoError(t, repo.refreshes[1])
+	assert.Error(t, repo.refreshes[5])
+	assert.NotContains(t, repo.refreshes, 6, "paused symbols stay due")
+}
+
+func TestTrackingService_RefreshIntervals(t *testing.T) {
+	now := time.Now()
+	daily := now.Add(-time.Hour)
+	intraday := now.Add(-3 * time.Hour)
+	repo := newFakeRepository(tracking.TrackedSymbol{Symbol: "AAPL", Intervals: []string{"1d", "5m", "1h"},
+		HistoryDays: 30, RefreshMinutes: 15, Active: true, LastAttemptAt: &daily,
+		IntervalSuccessAt: map[string]time.Time{"1d": daily, "5m": intraday}})
+	fetcher := &fakeFetcher{failInterval: yahoo.Interval5m}
+	svc := tracking.NewTrackingService(repo, "yahoo", map[string]tracking.Fetcher{"yahoo": fetcher}, defaultPolicy)
+
+	err := svc.RefreshDue(context.TODO(), 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interval 5m")
+
+	require.Len(t, fetcher.fetches, 3, "a failing interval does not stop the others")
+	assert.Equal(t, daily, fetcher.fetches[0].start, "intervals continue from their own last success")
+	assert.Equal(t, intraday, fetcher.fetches[1].start)
+	assert.WithinDuration(t, now.AddDate(0, 0, -30), fetcher.fetches[2].start, time.Minute,
+		"intervals without a success fetch their history")
+
+	assert.Error(t, repo.refreshes[1])
+	assert.Equal(t, []string{"1d", "1h"}, repo.succeeded[1], "the intervals which refreshed are recorded")
+}
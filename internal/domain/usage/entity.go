@@ -0,0 +1,40 @@
+package usage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when the daily request cap of a provider is reached
+var ErrQuotaExceeded = errors.New("provider daily quota exceeded")
+
+// Counters are the call counters of a provider
+type Counters struct {
+	Requests int64 // HTTP requests sent, including retries
+	Retries  int64 // requests which were retries of a failed request
+	Failures int64 // requests which failed or were answered with an error status
+	Bytes    int64 // response bytes received
+}
+
+// Add adds other to the counters.
+func (c *Counters) Add(other Counters) {
+	c.Requests += other.Requests
+	c.Retries += other.Retries
+	c.Failures += other.Failures
+	c.Bytes += other.Bytes
+}
+
+// Usage represents the calls made to a provider on a UTC day for a symbol. The symbol is empty for requests
+// which are not made for a single symbol, like batch quotes.
+type Usage struct {
+	Provider string
+	Day      time.Time
+	Symbol   string
+	Counters
+}
+
+// Day truncates a time to its UTC day, the unit usage is counted and capped in.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
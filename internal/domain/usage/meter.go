@@ -0,0 +1,153 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// Repository defines the persistence operations of provider usage
+type Repository interface {
+	AddUsage(ctx context.Context, entries []Usage) error
+	GetUsage(ctx context.Context, from, to time.Time, provider string) ([]Usage, error)
+	GetDailyRequests(ctx context.Context, day time.Time) (map[string]int64, error)
+}
+
+type usageKey struct {
+	provider string
+	day      time.Time
+	symbol   string
+}
+
+// Meter counts provider calls in memory, persists them on Flush and enforces the daily request caps.
+// The requests of the current day are tracked per provider, so checking a cap does not hit the database.
+type Meter struct {
+	repo Repository
+	caps map[string]int64
+	now  func() time.Time
+
+	mu       sync.Mutex
+	pending  map[usageKey]*Counters
+	day      time.Time
+	requests map[string]int64 // requests per provider on day, persisted and pending
+}
+
+// NewMeter creates a new usage meter. Caps limit the daily requests per provider, providers without a
+// positive cap are unlimited.
+func NewMeter(repo Repository, caps map[string]int64) *Meter {
+	return &Meter{
+		repo:     repo,
+		caps:     caps,
+		now:      time.Now,
+		pending:  make(map[usageKey]*Counters),
+		requests: make(map[string]int64),
+	}
+}
+
+// Load reads the requests already made today, e.g. by a previous run of the service, so caps survive restarts.
+func (m *Meter) Load(ctx context.Context) error {
+	day := Day(m.now())
+	persisted, err := m.repo.GetDailyRequests(ctx, day)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover(day)
+	for provider, count := range persisted {
+		m.requests[provider] += count
+	}
+	return nil
+}
+
+// Record adds counters of calls made to a provider for a symbol.
+func (m *Meter) Record(provider, symbol string, counters Counters) {
+	day := Day(m.now())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover(day)
+	key := usageKey{provider: provider, day: day, symbol: symbol}
+	pending, ok := m.pending[key]
+	if !ok {
+		pending = &Counters{}
+		m.pending[key] = pending
+	}
+	pending.Add(counters)
+	m.requests[provider] += counters.Requests
+}
+
+// rollover resets the daily requests when the day changed. It must be called with the mutex held.
+func (m *Meter) rollover(day time.Time) {
+	if !m.day.Equal(day) {
+		m.day = day
+		m.requests = make(map[string]int64)
+	}
+}
+
+// Flush persists the counters recorded since the last flush. Counters which could not be saved are kept
+// for the next flush.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[usageKey]*Counters)
+	m.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	entries := make([]Usage, 0, len(pending))
+	for key, counters := range pending {
+		entries = append(entries, Usage{Provider: key.provider, Day: key.day, Symbol: key.symbol, Counters: *counters})
+	}
+	if err := m.repo.AddUsage(ctx, entries); err != nil {
+		m.mu.Lock()
+		for key, counters := range pending {
+			if current, ok := m.pending[key]; ok {
+				current.Add(*counters)
+			} else {
+				m.pending[key] = counters
+			}
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Cap returns the daily request cap of a provider, zero when unlimited.
+func (m *Meter) Cap(provider string) int64 {
+	return m.caps[provider]
+}
+
+// Requests returns the number of requests made to a provider today.
+func (m *Meter) Requests(provider string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover(Day(m.now()))
+	return m.requests[provider]
+}
+
+// CheckQuota returns ErrQuotaExceeded when the daily cap of the provider is reached.
+func (m *Meter) CheckQuota(provider string) error {
+	limit := m.Cap(provider)
+	if limit <= 0 {
+		return nil
+	}
+	if requests := m.Requests(provider); requests >= limit {
+		return eris.Wrapf(ErrQuotaExceeded, "%s made %d of %d requests today", provider, requests, limit)
+	}
+	return nil
+}
+
+// GetUsage flushes the recorded counters and returns the usage of the UTC days in [from, to], optionally of a
+// single provider.
+func (m *Meter) GetUsage(ctx context.Context, from, to time.Time, provider string) ([]Usage, error) {
+	if err := m.Flush(ctx); err != nil {
+		return nil, eris.Wrap(err, "failed to flush provider usage")
+	}
+	return m.repo.GetUsage(ctx, from, to, provider)
+}
@@ -0,0 +1,146 @@
+package usage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	saved    []usage.Usage
+	daily    map[string]int64
+	failSave bool
+}
+
+func (r *fakeRepository) AddUsage(_ context.Context, entries []usage.Usage) error {
+	if r.failSave {
+		return errors.New("database unavailable")
+	}
+	r.saved = append(r.saved, entries...)
+	return nil
+}
+
+func (r *fakeRepository) GetUsage(_ context.Context, _, _ time.Time, _ string) ([]usage.Usage, error) {
+	return r.saved, nil
+}
+
+func (r *fakeRepository) GetDailyRequests(_ context.Context, _ time.Time) (map[string]int64, error) {
+	return r.daily, nil
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	repo := &fakeRepository{}
+	meter := usage.NewMeter(repo, nil)
+	client := &http.Client{Transport: usage.NewTransport(meter, "yahoo", nil)}
+
+	get := func(ctx context.Context, path string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, _ = io.ReadAll(resp.Body)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	ctx := usage.WithSymbol(context.TODO(), "AAPL")
+	get(usage.WithAttempt(ctx, 0), "/fail")
+	get(usage.WithAttempt(ctx, 1), "/chart")
+	get(context.TODO(), "/spark")
+
+	require.NoError(t, meter.Flush(context.TODO()))
+	sort.Slice(repo.saved, func(i, j int) bool { return repo.saved[i].Symbol < repo.saved[j].Symbol })
+	require.Len(t, repo.saved, 2)
+
+	batch, aapl := repo.saved[0], repo.saved[1]
+	assert.Equal(t, "", batch.Symbol)
+	assert.Equal(t, usage.Counters{Requests: 1, Bytes: 10}, batch.Counters)
+	assert.Equal(t, "yahoo", aapl.Provider)
+	assert.Equal(t, "AAPL", aapl.Symbol)
+	assert.Equal(t, usage.Day(time.Now()), aapl.Day)
+	assert.Equal(t, usage.Counters{Requests: 2, Retries: 1, Failures: 1, Bytes: 10}, aapl.Counters)
+
+	// flushed counters are not saved again
+	require.NoError(t, meter.Flush(context.TODO()))
+	assert.Len(t, repo.saved, 2)
+}
+
+func TestTransport_Trace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	// traces are recorded without a meter too
+	client := &http.Client{Transport: usage.NewTransport(nil, "yahoo", nil)}
+	get := func(ctx context.Context, path string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, _ = io.ReadAll(resp.Body)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	trace := &usage.Trace{}
+	ctx := usage.WithTrace(context.TODO(), trace)
+	get(usage.WithAttempt(ctx, 0), "/fail")
+	assert.Equal(t, http.StatusTooManyRequests, trace.Status())
+	get(usage.WithAttempt(ctx, 1), "/chart")
+	get(context.TODO(), "/spark")
+
+	assert.Equal(t, 2, trace.Requests())
+	assert.Equal(t, 1, trace.Retries())
+	assert.Equal(t, http.StatusOK, trace.Status(), "the status of the last response is kept")
+}
+
+func TestMeter_CheckQuota(t *testing.T) {
+	repo := &fakeRepository{daily: map[string]int64{"yahoo": 8}}
+	meter := usage.NewMeter(repo, map[string]int64{"yahoo": 10})
+	require.NoError(t, meter.Load(context.TODO()))
+
+	require.NoError(t, meter.CheckQuota("yahoo"))
+	require.NoError(t, meter.CheckQuota("binance"), "providers without a cap are unlimited")
+
+	meter.Record("yahoo", "AAPL", usage.Counters{Requests: 2})
+	assert.Equal(t, int64(10), meter.Requests("yahoo"))
+	err := meter.CheckQuota("yahoo")
+	assert.ErrorIs(t, err, usage.ErrQuotaExceeded)
+	assert.ErrorContains(t, err, "yahoo made 10 of 10 requests today")
+}
+
+func TestMeter_FlushKeepsCountersOnError(t *testing.T) {
+	repo := &fakeRepository{failSave: true}
+	meter := usage.NewMeter(repo, nil)
+
+	meter.Record("binance", "BTCUSDT", usage.Counters{Requests: 1, Bytes: 100})
+	require.Error(t, meter.Flush(context.TODO()))
+	meter.Record("binance", "BTCUSDT", usage.Counters{Requests: 1, Failures: 1})
+
+	repo.failSave = false
+	require.NoError(t, meter.Flush(context.TODO()))
+	require.Len(t, repo.saved, 1)
+	assert.Equal(t, usage.Counters{Requests: 2, Failures: 1, Bytes: 100}, repo.saved[0].Counters)
+}
@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/market-data/internal/database"
+	"github.com/rotisserie/eris"
+)
+
+// UsageRepository persists provider usage counters in PostgreSQL
+type UsageRepository struct {
+	db *database.DB
+}
+
+// NewUsageRepository creates a new provider usage repository
+func NewUsageRepository(db *database.DB) *UsageRepository {
+	return &UsageRepository{
+		db: db,
+	}
+}
+
+// AddUsage adds the counters of the entries to the stored ones, creating missing entries.
+func (r *UsageRepository) AddUsage(ctx context.Context, entries []Usage) error {
+	query := `
+		INSERT INTO provider_usage (provider, day, symbol, requests, retries, failures, bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (provider, day, symbol) DO UPDATE
+		SET requests = provider_usage.requests + EXCLUDED.requests,
+			retries = provider_usage.retries + EXCLUDED.retries,
+			failures = provider_usage.failures + EXCLUDED.failures,
+			bytes = provider_usage.bytes + EXCLUDED.bytes,
+			updated_at = now()
+	`
+
+	batch := &pgx.Batch{}
+	for _, u := range entries {
+		batch.Queue(query, u.Provider, u.Day, u.Symbol, u.Requests, u.Retries, u.Failures, u.Bytes)
+	}
+
+	err := r.db.InTransaction(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	return eris.Wrap(err, "failed to save provider usage")
+}
+
+// GetUsage retrieves the usage per provider, day and symbol of the UTC days in [from, to], optionally of a
+// single provider, ordered by day, provider and symbol.
+func (r *UsageRepository) GetUsage(ctx context.Context, from, to time.Time, provider string) ([]Usage, error) {
+	query := `
+		SELECT provider, day, symbol, requests, retries, failures, bytes
+		FROM provider_usage
+		WHERE day >= $1 AND day <= $2 AND ($3 = '' OR provider = $3)
+		ORDER BY day, provider, symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, Day(from), Day(to), provider)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query provider usage")
+	}
+	defer rows.Close()
+
+	var entries []Usage
+	for rows.Next() {
+		var u Usage
+		if err := rows.Scan(&u.Provider, &u.Day, &u.Symbol, &u.Requests, &u.Retries, &u.Failures, &u.Bytes); err != nil {
+			return nil, eris.Wrap(err, "failed to scan provider usage")
+		}
+		entries = append(entries, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, eris.Wrap(err, "failed to read provider usage")
+	}
+	return entries, nil
+}
+
+// GetDailyRequests returns the number of requests made to every provider on the UTC day.
+func (r *UsageRepository) GetDailyRequests(ctx context.Context, day time.Time) (map[string]int64, error) {
+	query := `
+		SELECT provider, sum(requests)
+		FROM provider_usage
+		WHERE day = $1
+		GROUP BY provider
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, Day(day))
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to query daily provider requests")
+	}
+	defer rows.Close()
+
+	requests := make(map[string]int64)
+	for rows.Next() {
+		var (
+			provider string
+			count    int64
+		)
+		if err := rows.Scan(&provider, &count); err != nil {
+			return nil, eris.Wrap(err, "failed to scan daily provider requests")
+		}
+		requests[provider] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, eris.Wrap(err, "failed to read daily provider requests")
+	}
+	return requests, nil
+}
@@ -0,0 +1,55 @@
+package usage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	data "github.com/market-data/db"
+	"github.com/market-data/internal/database"
+	"github.com/market-data/internal/domain/usage"
+	testsTools "github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRepository(t *testing.T) {
+	timescaleDB := testsTools.NewTimescaleDB(t)
+	defer timescaleDB.Terminate()
+	timescaleDB.ApplyMigrations(data.Migrations)
+
+	db, err := database.NewWithConfig(timescaleDB.DatabaseConfigForTimescale())
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := usage.NewUsageRepository(db)
+	ctx := context.TODO()
+
+	day := time.Date(2025, time.June, 13, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.AddUsage(ctx, []usage.Usage{
+		{Provider: "yahoo", Day: day, Symbol: "AAPL", Counters: usage.Counters{Requests: 2, Retries: 1, Failures: 1, Bytes: 500}},
+		{Provider: "yahoo", Day: day, Symbol: "", Counters: usage.Counters{Requests: 1, Bytes: 200}},
+		{Provider: "binance", Day: day.AddDate(0, 0, -1), Symbol: "BTCUSDT", Counters: usage.Counters{Requests: 3}},
+	}))
+	// counters are added to the stored ones
+	require.NoError(t, repo.AddUsage(ctx, []usage.Usage{
+		{Provider: "yahoo", Day: day, Symbol: "AAPL", Counters: usage.Counters{Requests: 1, Bytes: 250}},
+	}))
+
+	entries, err := repo.GetUsage(ctx, day, day, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "", entries[0].Symbol)
+	assert.Equal(t, "AAPL", entries[1].Symbol)
+	assert.True(t, day.Equal(entries[1].Day))
+	assert.Equal(t, usage.Counters{Requests: 3, Retries: 1, Failures: 1, Bytes: 750}, entries[1].Counters)
+
+	entries, err = repo.GetUsage(ctx, day.AddDate(0, 0, -7), day, "binance")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "BTCUSDT", entries[0].Symbol)
+
+	requests, err := repo.GetDailyRequests(ctx, day.Add(15*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"yahoo": 4}, requests)
+}
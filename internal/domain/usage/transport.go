@@ -0,0 +1,145 @@
+package usage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type contextKey int
+
+const (
+	symbolKey contextKey = iota
+	attemptKey
+	traceKey
+)
+
+// WithSymbol attributes the provider requests made with the context to a symbol.
+func WithSymbol(ctx context.Context, symbol string) context.Context {
+	return context.WithValue(ctx, symbolKey, symbol)
+}
+
+// WithAttempt marks the provider requests made with the context as the given attempt, counting as retries
+// from the second attempt (1) on.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey, attempt)
+}
+
+// Trace collects the outcome of the provider requests made with a context, e.g. for the fetch log
+type Trace struct {
+	mu       sync.Mutex
+	requests int
+	retries  int
+	status   int
+}
+
+// WithTrace records the provider requests made with the context in trace.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey, trace)
+}
+
+// Requests returns the number of recorded requests
+func (t *Trace) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+// Retries returns the number of recorded requests repeating a failed one
+func (t *Trace) Retries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.retries
+}
+
+// Status returns the HTTP status of the last response, 0 when no response was received
+func (t *Trace) Status() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+func (t *Trace) record(retry bool, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	if retry {
+		t.retries++
+	}
+	t.status = status
+}
+
+// Transport is an HTTP transport counting the requests, retries, failures and response bytes of a provider.
+// Requests are attributed to the symbol and attempt stored in their context and recorded in its trace. Without
+// a meter, only traces are recorded.
+type Transport struct {
+	meter    *Meter
+	provider string
+	base     http.RoundTripper
+}
+
+// NewTransport creates a metering transport around base, http.DefaultTransport when nil. The meter may be nil.
+func NewTransport(meter *Meter, provider string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		meter:    meter,
+		provider: provider,
+		base:     base,
+	}
+}
+
+// RoundTrip sends the request through the base transport and records it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	symbol, _ := req.Context().Value(symbolKey).(string)
+	attempt, _ := req.Context().Value(attemptKey).(int)
+
+	counters := Counters{Requests: 1}
+	if attempt > 0 {
+		counters.Retries = 1
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		counters.Failures = 1
+	}
+	if trace, ok := req.Context().Value(traceKey).(*Trace); ok {
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
+		trace.record(attempt > 0, status)
+	}
+	if t.meter == nil {
+		return resp, err
+	}
+	t.meter.Record(t.provider, symbol, counters)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, record: func(n int64) {
+		t.meter.Record(t.provider, symbol, Counters{Bytes: n})
+	}}
+	return resp, nil
+}
+
+// countingBody records the number of bytes read from a response body when it is closed.
+type countingBody struct {
+	io.ReadCloser
+	n      int64
+	once   sync.Once
+	record func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() { b.record(b.n) })
+	return b.ReadCloser.Close()
+}
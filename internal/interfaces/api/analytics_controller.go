@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+type OptionContractRequest struct {
+	Underlying  string   `json:"underlying" binding:"required"`
+	Expiry      string   `json:"expiry" binding:"required"`
+	Strike      float64  `json:"strike" binding:"required,gt=0"`
+	Type        string   `json:"type" binding:"required,oneof=call put"`
+	MarketPrice *float64 `json:"marketPrice"`
+	Bid         *float64 `json:"bid"`
+	Ask         *float64 `json:"ask"`
+	Volatility  *float64 `json:"volatility"`
+}
+
+type OptionAnalyticsRequest struct {
+	RiskFreeRate *float64                `json:"riskFreeRate"`
+	Contracts    []OptionContractRequest `json:"contracts" binding:"required,min=1,dive"`
+}
+
+type OptionGreeks struct {
+	Delta float64 `json:"delta"`
+	Gamma float64 `json:"gamma"`
+	Vega  float64 `json:"vega"`
+	Theta float64 `json:"theta"`
+	Rho   float64 `json:"rho"`
+}
+
+type OptionAnalytics struct {
+	Underlying        string        `json:"underlying"`
+	Expiry            string        `json:"expiry"`
+	Strike            float64       `json:"strike"`
+	Type              string        `json:"type"`
+	UnderlyingPrice   *float64      `json:"underlyingPrice,omitempty"`
+	UnderlyingTime    *time.Time    `json:"underlyingTime,omitempty"`
+	YearsToExpiry     *float64      `json:"yearsToExpiry,omitempty"`
+	MidPrice          *float64      `json:"midPrice,omitempty"`
+	PricingVolatility *float64      `json:"pricingVolatility,omitempty"`
+	TheoreticalPrice  *float64      `json:"theoreticalPrice,omitempty"`
+	ImpliedVolatility *float64      `json:"impliedVolatility,omitempty"`
+	Greeks            *OptionGreeks `json:"greeks,omitempty"`
+	Error             string        `json:"error,omitempty"`
+}
+
+type OptionAnalyticsResponse struct {
+	RiskFreeRate float64           `json:"riskFreeRate"`
+	Results      []OptionAnalytics `json:"results"`
+}
+
+func buildOptionAnalytics(a options.ContractAnalytics) OptionAnalytics {
+	result := OptionAnalytics{
+		Underlying: a.Input.Underlying,
+		Expiry:     a.Input.Expiry.Format(time.DateOnly),
+		Strike:     a.Input.Strike,
+		Type:       string(a.Input.Type),
+	}
+	if a.Err != nil {
+		result.Error = a.Err.Error()
+		return result
+	}
+	result.UnderlyingPrice = &a.UnderlyingPrice
+	result.UnderlyingTime = &a.UnderlyingTime
+	result.YearsToExpiry = &a.Years
+	result.MidPrice = a.MidPrice
+	result.PricingVolatility = a.PricingVolatility
+	result.TheoreticalPrice = a.TheoreticalPrice
+	result.ImpliedVolatility = a.ImpliedVolatility
+	result.Greeks = &OptionGreeks{
+		Delta: a.Greeks.Delta,
+		Gamma: a.Greeks.Gamma,
+		Vega:  a.Greeks.Vega,
+		Theta: a.Greeks.Theta,
+		Rho:   a.Greeks.Rho,
+	}
+	return result
+}
+
+// AnalyticsController handles analytics endpoints
+type AnalyticsController struct {
+	optionsService *options.OptionsService
+}
+
+// NewAnalyticsController creates a new analytics controller
+func NewAnalyticsController(optionsService *options.OptionsService) *AnalyticsController {
+	return &AnalyticsController{
+		optionsService: optionsService,
+	}
+}
+
+// RegisterRoutes registers the routes for the analytics controller
+func (c *AnalyticsController) RegisterRoutes(router *gin.Engine) {
+	router.POST("/analytics/options", c.analyzeOptions)
+}
+
+// analyzeOptions computes Black-Scholes prices, implied volatilities and Greeks of the posted contracts
+func (c *AnalyticsController) analyzeOptions(ctx *gin.Context) {
+	var request OptionAnalyticsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	contracts := make([]options.ContractInput, 0, len(request.Contracts))
+	for _, contract := range request.Contracts {
+		expiry, err := time.Parse(time.DateOnly, contract.Expiry)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be a date in YYYY-MM-DD format"})
+			return
+		}
+		contracts = append(contracts, options.ContractInput{
+			Underlying:  contract.Underlying,
+			Expiry:      expiry,
+			Strike:      contract.Strike,
+			Type:        yahoo.OptionType(contract.Type),
+			MarketPrice: contract.MarketPrice,
+			Bid:         contract.Bid,
+			Ask:         contract.Ask,
+			Volatility:  contract.Volatility,
+		})
+	}
+
+	rate := c.optionsService.RiskFreeRate()
+	if request.RiskFreeRate != nil {
+		rate = *request.RiskFreeRate
+	}
+
+	analytics, err := c.optionsService.Analyze(ctx, contracts, rate, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error computing option analytics"})
+		return
+	}
+
+	response := OptionAnalyticsResponse{RiskFreeRate: rate, Results: []OptionAnalytics{}}
+	for _, a := range analytics {
+		response.Results = append(response.Results, buildOptionAnalytics(a))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// underlyingSource serves a single stored close per known symbol
+type underlyingSource map[string]float64
+
+func (s underlyingSource) GetMarketData(_ context.Context, symbol string, _ yahoo.IntervalAPI) (*market.Symbol,
+	*market.StockPrices, error) {
+	price, ok := s[symbol]
+	if !ok {
+		return nil, nil, eris.Errorf("symbol %s not found", symbol)
+	}
+	return &market.Symbol{Symbol: symbol}, &market.StockPrices{{Time: time.Now(), ClosePrice: &price}}, nil
+}
+
+func TestAnalyticsController(t *testing.T) {
+	service := options.NewOptionsService(nil, nil)
+	service.SetAnalyticsSettings(underlyingSource{"AAPL": 100}, 0, false)
+	router := newRouter(api.NewAnalyticsController(service))
+
+	expiry := time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+	body := fmt.Sprintf(`{"contracts": [
+		{"underlying": "AAPL", "expiry": %q, "strike": 10000, "type": "call", "volatility": 0.2},
+		{"underlying": "AAPL", "expiry": %q, "strike": 100, "type": "call", "bid": 2.2, "ask": 2.4},
+		{"underlying": "MSFT", "expiry": %q, "strike": 100, "type": "call"}
+	]}`, expiry, expiry, expiry)
+	response := decode[struct {
+		Results []map[string]json.RawMessage `json:"results"`
+	}](t, serve(t, router, http.MethodPost, "/analytics/options", body), http.StatusOK)
+	require.Len(t, response.Results, 3)
+
+	// a call far out of the money is worth nothing, the zeros are still reported
+	analyzed := response.Results[0]
+	assert.JSONEq(t, "0", string(analyzed["theoreticalPrice"]))
+	assert.Contains(t, analyzed, "underlyingPrice")
+	assert.Contains(t, analyzed, "yearsToExpiry")
+	assert.Contains(t, analyzed, "pricingVolatility")
+	var greeks api.OptionGreeks
+	require.NoError(t, json.Unmarshal(analyzed["greeks"], &greeks))
+	assert.Zero(t, greeks.Delta)
+
+	// a single stored close has no historical volatility, contracts without one are not priced
+	unpriced := response.Results[1]
+	assert.NotContains(t, unpriced, "pricingVolatility")
+	assert.NotContains(t, unpriced, "theoreticalPrice")
+	assert.Contains(t, unpriced, "impliedVolatility")
+	assert.Contains(t, unpriced, "greeks")
+
+	// contracts which could not be analyzed only report the error
+	failed := response.Results[2]
+	assert.Contains(t, failed, "error")
+	for _, key := range []string{"underlyingPrice", "yearsToExpiry", "pricingVolatility", "theoreticalPrice", "greeks"} {
+		assert.NotContains(t, failed, key)
+	}
+
+	assertStatuses(t, router, []statusCase{
+		{"Analyze without contracts", http.MethodPost, "/analytics/options", `{"contracts": []}`, http.StatusBadRequest},
+		{"Analyze an invalid expiry", http.MethodPost, "/analytics/options",
+			`{"contracts": [{"underlying": "AAPL", "expiry": "soon", "strike": 100, "type": "call"}]}`, http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/anomaly"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
+)
+
+type Anomaly struct {
+	ID         int64      `json:"id"`
+	Symbol     string     `json:"symbol"`
+	Interval   string     `json:"interval"`
+	Time       time.Time  `json:"time"`
+	Metric     string     `json:"metric"`
+	Method     string     `json:"method"`
+	Value      float64    `json:"value"`
+	Baseline   float64    `json:"baseline"`
+	Score      float64    `json:"score"`
+	Close      *float64   `json:"close"`
+	Volume     *float64   `json:"volume"`
+	Status     string     `json:"status"`
+	DetectedAt time.Time  `json:"detectedAt"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+}
+
+type AnomaliesResponse struct {
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+// CorrectAnomalyRequest holds the corrected values of a bar, omitted values keep the stored ones
+type CorrectAnomalyRequest struct {
+	Open     *float64 `json:"open" binding:"omitempty,gt=0"`
+	High     *float64 `json:"high" binding:"omitempty,gt=0"`
+	Low      *float64 `json:"low" binding:"omitempty,gt=0"`
+	Close    *float64 `json:"close" binding:"omitempty,gt=0"`
+	AdjClose *float64 `json:"adjClose" binding:"omitempty,gt=0"`
+	Volume   *float64 `json:"volume" binding:"omitempty,gte=0"`
+}
+
+func buildAnomaly(a *anomaly.Anomaly) Anomaly {
+	return Anomaly{
+		ID:         a.ID,
+		Symbol:     a.Symbol,
+		Interval:   string(a.Interval),
+		Time:       a.Time,
+		Metric:     a.Metric,
+		Method:     a.Method,
+		Value:      a.Value,
+		Baseline:   a.Baseline,
+		Score:      a.Score,
+		Close:      a.ClosePrice,
+		Volume:     a.Volume,
+		Status:     a.Status,
+		DetectedAt: a.DetectedAt,
+		ReviewedAt: a.ReviewedAt,
+	}
+}
+
+func buildAnomalies(anomalies []anomaly.Anomaly) []Anomaly {
+	response := make([]Anomaly, 0, len(anomalies))
+	for i := range anomalies {
+		response = append(response, buildAnomaly(&anomalies[i]))
+	}
+	return response
+}
+
+// anomalyStatuses are the states accepted by the status filter of the anomaly list
+var anomalyStatuses = map[string]bool{
+	anomaly.StatusOpen:      true,
+	anomaly.StatusKept:      true,
+	anomaly.StatusCorrected: true,
+}
+
+// AnomalyController handles the detection and review of price and volume anomalies
+type AnomalyController struct {
+	service *anomaly.Service
+}
+
+// NewAnomalyController creates a new anomaly controller
+func NewAnomalyController(service *anomaly.Service) *AnomalyController {
+	return &AnomalyController{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the routes for the anomaly controller
+func (c *AnomalyController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/admin/anomalies", c.listAnomalies)
+	router.POST("/admin/anomalies/scan", c.scanAnomalies)
+	router.POST("/admin/anomalies/:id/keep", c.keepAnomaly)
+	router.POST("/admin/anomalies/:id/correct", c.correctAnomaly)
+}
+
+// listAnomalies lists the anomalies of the latest bars, by default those awaiting review. status=all lists
+// the reviewed ones too.
+func (c *AnomalyController) listAnomalies(ctx *gin.Context) {
+	status := ctx.DefaultQuery("status", anomaly.StatusOpen)
+	if status == "all" {
+		status = ""
+	} else if !anomalyStatuses[status] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, must be one of open, kept, corrected or all"})
+		return
+	}
+	interval := yahoo.IntervalAPI(ctx.Query("interval"))
+	if interval != "" && !slices.Contains(yahoo.Intervals, interval) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval"})
+		return
+	}
+	metric := ctx.Query("metric")
+	if metric != "" && metric != anomaly.MetricReturn && metric != anomaly.MetricVolume {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid metric, must be return or volume"})
+		return
+	}
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	limit := 100
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	anomalies, err := c.service.ListAnomalies(ctx, anomaly.Filter{
+		Symbol:   strings.ToUpper(ctx.Query("symbol")),
+		Interval: interval,
+		Metric:   metric,
+		Status:   status,
+		From:     from,
+		To:       to,
+		Limit:    limit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list anomalies")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving anomalies"})
+		return
+	}
+	ctx.JSON(http.StatusOK, AnomaliesResponse{Anomalies: buildAnomalies(anomalies)})
+}
+
+// scanAnomalies scores the stored bars of an interval (daily by default) of a symbol between from and to, its
+// whole history by default, and lists the anomalies of the range
+func (c *AnomalyController) scanAnomalies(ctx *gin.Context) {
+	symbol := strings.ToUpper(ctx.Query("symbol"))
+	if symbol == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Symbol is required"})
+		return
+	}
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	interval, ok := parseInterval(ctx)
+	if !ok {
+		return
+	}
+
+	anomalies, err := c.service.Scan(ctx, symbol, interval, from, to)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to scan for anomalies")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error scanning for anomalies"})
+		return
+	}
+	ctx.JSON(http.StatusOK, AnomaliesResponse{Anomalies: buildAnomalies(anomalies)})
+}
+
+// keepAnomaly marks an open anomaly as a genuine move, its bar stays as stored
+func (c *AnomalyController) keepAnomaly(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly id"})
+		return
+	}
+
+	kept, err := c.service.Keep(ctx, id)
+	if err != nil {
+		c.reviewError(ctx, id, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, buildAnomaly(kept))
+}
+
+// correctAnomaly overwrites the bar of an open anomaly with the values of the request body
+func (c *AnomalyController) correctAnomaly(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid anomaly id"})
+		return
+	}
+	var request CorrectAnomalyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if request == (CorrectAnomalyRequest{}) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "At least one corrected value is required"})
+		return
+	}
+
+	corrected, err := c.service.Correct(ctx, id, &anomaly.Correction{
+		OpenPrice:  request.Open,
+		HighPrice:  request.High,
+		LowPrice:   request.Low,
+		ClosePrice: request.Close,
+		AdjClose:   request.AdjClose,
+		Volume:     request.Volume,
+	})
+	if err != nil {
+		c.reviewError(ctx, id, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, buildAnomaly(corrected))
+}
+
+func (c *AnomalyController) reviewError(ctx *gin.Context, id int64, err error) {
+	switch {
+	case errors.Is(err, anomaly.ErrAnomalyNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Anomaly not found"})
+	case errors.Is(err, anomaly.ErrAnomalyReviewed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Anomaly already reviewed"})
+	default:
+		log.Error().Err(err).Int64("id", id).Msg("Failed to review anomaly")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing anomaly"})
+	}
+}
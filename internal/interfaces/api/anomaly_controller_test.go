@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/anomaly"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anomalyRepository serves stored bars and keeps the anomalies detected in memory
+type anomalyRepository struct {
+	bars       []anomaly.Bar
+	anomalies  []anomaly.Anomaly
+	filter     anomaly.Filter // of the last listing
+	correction *anomaly.Correction
+}
+
+func (r *anomalyRepository) GetBars(context.Context, string, yahoo.IntervalAPI, time.Time, time.Time,
+	int) ([]anomaly.Bar, error) {
+	return r.bars, nil
+}
+
+func (r *anomalyRepository) SaveAnomalies(_ context.Context, anomalies []anomaly.Anomaly) error {
+	for _, a := range anomalies {
+		a.ID = int64(len(r.anomalies) + 1)
+		a.DetectedAt = time.Now()
+		r.anomalies = append(r.anomalies, a)
+	}
+	return nil
+}
+
+// ListAnomalies lists the anomalies latest first, only the symbol and status filter
+func (r *anomalyRepository) ListAnomalies(_ context.Context, filter anomaly.Filter) ([]anomaly.Anomaly, error) {
+	r.filter = filter
+	var anomalies []anomaly.Anomaly
+	for i := len(r.anomalies) - 1; i >= 0; i-- {
+		a := r.anomalies[i]
+		if (filter.Symbol == "" || a.Symbol == filter.Symbol) && (filter.Status == "" || a.Status == filter.Status) {
+			anomalies = append(anomalies, a)
+		}
+	}
+	return anomalies, nil
+}
+
+func (r *anomalyRepository) KeepAnomaly(_ context.Context, id int64) (*anomaly.Anomaly, error) {
+	return r.review(id, anomaly.StatusKept)
+}
+
+func (r *anomalyRepository) CorrectAnomaly(_ context.Context, id int64,
+	correction *anomaly.Correction) (*anomaly.Anomaly, error) {
+	r.correction = correction
+	return r.review(id, anomaly.StatusCorrected)
+}
+
+func (r *anomalyRepository) review(id int64, status string) (*anomaly.Anomaly, error) {
+	if id < 1 || id > int64(len(r.anomalies)) {
+		return nil, anomaly.ErrAnomalyNotFound
+	}
+	a := &r.anomalies[id-1]
+	if a.Status != anomaly.StatusOpen {
+		return nil, anomaly.ErrAnomalyReviewed
+	}
+	now := time.Now()
+	a.Status, a.ReviewedAt = status, &now
+	return a, nil
+}
+
+func TestAnomalyController(t *testing.T) {
+	// the closes alternate between 100 and 101 until a bad tick at 130
+	start := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+	repo := &anomalyRepository{}
+	for i := range 12 {
+		closePrice := 100 + float64(i%2)
+		if i == 11 {
+			closePrice = 130
+		}
+		repo.bars = append(repo.bars, anomaly.Bar{Time: start.AddDate(0, 0, i), ClosePrice: &closePrice})
+	}
+	detector, err := anomaly.NewDetector(anomaly.Settings{Method: anomaly.MethodZScore, Window: 5, Threshold: 4})
+	require.NoError(t, err)
+	router := newRouter(api.NewAnomalyController(anomaly.NewService(repo, detector)))
+
+	t.Run("Scan a symbol", func(t *testing.T) {
+		response := decode[api.AnomaliesResponse](t, serve(t, router, http.MethodPost,
+			"/admin/anomalies/scan?symbol=aapl", ""), http.StatusOK)
+		require.Len(t, response.Anomalies, 1)
+		spike := response.Anomalies[0]
+		assert.Equal(t, "AAPL", spike.Symbol)
+		assert.Equal(t, string(yahoo.Interval1d), spike.Interval)
+		assert.True(t, start.AddDate(0, 0, 11).Equal(spike.Time))
+		assert.Equal(t, anomaly.MetricReturn, spike.Metric)
+		assert.Equal(t, 130.0, *spike.Close)
+		assert.Greater(t, spike.Score, 4.0)
+		assert.Equal(t, anomaly.StatusOpen, spike.Status)
+	})
+
+	t.Run("List anomalies", func(t *testing.T) {
+		response := decode[api.AnomaliesResponse](t, serve(t, router, http.MethodGet,
+			"/admin/anomalies?symbol=aapl&interval=1d&metric=return&limit=10", ""), http.StatusOK)
+		require.Len(t, response.Anomalies, 1)
+		assert.Equal(t, anomaly.Filter{Symbol: "AAPL", Interval: yahoo.Interval1d, Metric: anomaly.MetricReturn,
+			Status: anomaly.StatusOpen, Limit: 10}, repo.filter)
+
+		serve(t, router, http.MethodGet, "/admin/anomalies?status=all", "")
+		assert.Empty(t, repo.filter.Status, "all states")
+		assert.Equal(t, 100, repo.filter.Limit, "default limit")
+	})
+
+	t.Run("Keep an anomaly", func(t *testing.T) {
+		kept := decode[api.Anomaly](t, serve(t, router, http.MethodPost, "/admin/anomalies/1/keep", ""), http.StatusOK)
+		assert.Equal(t, anomaly.StatusKept, kept.Status)
+		assert.NotNil(t, kept.ReviewedAt)
+	})
+
+	t.Run("Correct an anomaly", func(t *testing.T) {
+		repo.anomalies = append(repo.anomalies, anomaly.Anomaly{ID: 2, Symbol: "MSFT", Status: anomaly.StatusOpen})
+		corrected := decode[api.Anomaly](t, serve(t, router, http.MethodPost, "/admin/anomalies/2/correct",
+			`{"close": 101.5, "volume": 0}`), http.StatusOK)
+		assert.Equal(t, anomaly.StatusCorrected, corrected.Status)
+		require.NotNil(t, repo.correction)
+		assert.Equal(t, 101.5, *repo.correction.ClosePrice)
+		assert.Equal(t, 0.0, *repo.correction.Volume)
+		assert.Nil(t, repo.correction.OpenPrice, "omitted values keep the stored ones")
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Keep a reviewed anomaly", http.MethodPost, "/admin/anomalies/1/keep", "", http.StatusConflict},
+		{"Keep an unknown anomaly", http.MethodPost, "/admin/anomalies/99/keep", "", http.StatusNotFound},
+		{"Correct an unknown anomaly", http.MethodPost, "/admin/anomalies/99/correct", `{"close": 100}`, http.StatusNotFound},
+		{"List with an invalid status", http.MethodGet, "/admin/anomalies?status=pending", "", http.StatusBadRequest},
+		{"List with an invalid interval", http.MethodGet, "/admin/anomalies?interval=2d", "", http.StatusBadRequest},
+		{"List with an invalid metric", http.MethodGet, "/admin/anomalies?metric=price", "", http.StatusBadRequest},
+		{"List with an invalid from", http.MethodGet, "/admin/anomalies?from=today", "", http.StatusBadRequest},
+		{"List with a limit above 1000", http.MethodGet, "/admin/anomalies?limit=1001", "", http.StatusBadRequest},
+		{"Scan without symbol", http.MethodPost, "/admin/anomalies/scan", "", http.StatusBadRequest},
+		{"Scan with an invalid interval", http.MethodPost, "/admin/anomalies/scan?symbol=AAPL&interval=2d", "", http.StatusBadRequest},
+		{"Keep an invalid anomaly id", http.MethodPost, "/admin/anomalies/first/keep", "", http.StatusBadRequest},
+		{"Correct without values", http.MethodPost, "/admin/anomalies/2/correct", `{}`, http.StatusBadRequest},
+		{"Correct with a negative close", http.MethodPost, "/admin/anomalies/2/correct", `{"close": -1}`, http.StatusBadRequest},
+	})
+}
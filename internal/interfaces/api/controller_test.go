@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouter registers the routes of the controllers on a router in test mode
+func newRouter(controllers ...api.Controller) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	for _, controller := range controllers {
+		controller.RegisterRoutes(router)
+	}
+	return router
+}
+
+// serve sends a request with an optional JSON body to the router and records the response
+func serve(t *testing.T, router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode asserts the status of a response and decodes its JSON body
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+	require.Equal(t, status, w.Code, w.Body.String())
+	var response T
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+// statusCase is a request and the status it is answered with
+type statusCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	status int
+}
+
+func assertStatuses(t *testing.T, router *gin.Engine, cases []statusCase) {
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(t, router, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/rs/zerolog/log"
+)
+
+type SymbolStaleness struct {
+	Symbol      string     `json:"symbol"`
+	Provider    string     `json:"provider"`
+	Interval    string     `json:"interval"`
+	LatestBar   *time.Time `json:"latestBar"`
+	ExpectedBar time.Time  `json:"expectedBar"`
+	LagSeconds  *int64     `json:"lagSeconds"`
+	Stale       bool       `json:"stale"`
+	StaleSince  *time.Time `json:"staleSince"`
+	Reason      string     `json:"reason,omitempty"`
+}
+
+type StalenessReport struct {
+	CheckedAt time.Time         `json:"checkedAt"`
+	Tracked   int               `json:"tracked"`
+	Stale     int               `json:"stale"`
+	Symbols   []SymbolStaleness `json:"symbols"`
+}
+
+func buildSymbolStaleness(s *freshness.SymbolFreshness) SymbolStaleness {
+	staleness := SymbolStaleness{
+		Symbol:      s.Symbol,
+		Provider:    s.Provider,
+		Interval:    string(s.Interval),
+		LatestBar:   s.LatestBar,
+		ExpectedBar: s.ExpectedBar,
+		Stale:       s.Stale,
+		StaleSince:  s.StaleSince,
+		Reason:      s.Reason,
+	}
+	if s.Lag != nil {
+		lag := int64(s.Lag.Seconds())
+		staleness.LagSeconds = &lag
+	}
+	return staleness
+}
+
+// FreshnessController handles the data freshness report
+type FreshnessController struct {
+	monitor *freshness.Monitor
+}
+
+// NewFreshnessController creates a new freshness controller
+func NewFreshnessController(monitor *freshness.Monitor) *FreshnessController {
+	return &FreshnessController{
+		monitor: monitor,
+	}
+}
+
+// RegisterRoutes registers the routes for the freshness controller
+func (c *FreshnessController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/admin/staleness", c.getStaleness)
+}
+
+// getStaleness reports the freshness of the active tracked symbols, only the stale ones with staleOnly=true.
+// The last report is reused until it is older than the check interval, refresh=true checks again.
+func (c *FreshnessController) getStaleness(ctx *gin.Context) {
+	var report *freshness.Report
+	var err error
+	if ctx.Query("refresh") == "true" {
+		report, err = c.monitor.Check(ctx)
+	} else {
+		report, err = c.monitor.Latest(ctx)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check data freshness")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking data freshness"})
+		return
+	}
+
+	staleOnly := ctx.Query("staleOnly") == "true"
+	response := StalenessReport{
+		CheckedAt: report.CheckedAt,
+		Tracked:   len(report.Symbols),
+		Stale:     report.Stale,
+		Symbols:   []SymbolStaleness{},
+	}
+	for i := range report.Symbols {
+		if staleOnly && !report.Symbols[i].Stale {
+			continue
+		}
+		response.Symbols = append(response.Symbols, buildSymbolStaleness(&report.Symbols[i]))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
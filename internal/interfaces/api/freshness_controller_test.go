@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latestBars serves the time of the latest stored bar per symbol and counts the queries
+type latestBars struct {
+	latest  map[string]time.Time
+	queries int
+}
+
+func (r *latestBars) GetLatestBars(_ context.Context,
+	intervals map[string]yahoo.IntervalAPI) (map[string]time.Time, error) {
+	r.queries++
+	latest := make(map[string]time.Time)
+	for symbol := range intervals {
+		if bar, ok := r.latest[symbol]; ok {
+			latest[symbol] = bar
+		}
+	}
+	return latest, nil
+}
+
+// trackedSymbols lists the active tracked symbols
+type trackedSymbols []tracking.TrackedSymbol
+
+func (s trackedSymbols) ListTrackedSymbols(context.Context, bool) ([]tracking.TrackedSymbol, error) {
+	return s, nil
+}
+
+func TestFreshnessController(t *testing.T) {
+	now := time.Date(2025, time.June, 11, 18, 0, 0, 0, time.UTC)
+	repo := &latestBars{latest: map[string]time.Time{
+		"BTCUSDT": now.Add(-10 * time.Minute),
+		"ETHUSDT": now.Add(-2 * time.Hour),
+	}}
+	tracked := trackedSymbols{
+		{Symbol: "BTCUSDT", Intervals: []string{"1d", "5m"}, RefreshMinutes: 15, Provider: "binance", Active: true},
+		{Symbol: "ETHUSDT", Intervals: []string{"5m"}, RefreshMinutes: 15, Provider: "binance", Active: true},
+		{Symbol: "SOLUSDT", Intervals: []string{"1h"}, RefreshMinutes: 60, Provider: "binance", Active: true},
+	}
+	monitor := freshness.NewMonitor(repo, tracked, freshness.Settings{Grace: 30 * time.Minute, MaxAge: time.Hour})
+	monitor.SetCalendar("binance", calendar.AlwaysOpen{})
+	monitor.SetClock(func() time.Time { return now })
+	router := newRouter(api.NewFreshnessController(monitor))
+
+	t.Run("Report staleness", func(t *testing.T) {
+		report := decode[api.StalenessReport](t, serve(t, router, http.MethodGet, "/admin/staleness", ""), http.StatusOK)
+		assert.True(t, now.Equal(report.CheckedAt))
+		assert.Equal(t, 3, report.Tracked)
+		assert.Equal(t, 2, report.Stale)
+		require.Len(t, report.Symbols, 3)
+
+		lagged := report.Symbols[0]
+		assert.Equal(t, "ETHUSDT", lagged.Symbol)
+		assert.Equal(t, "binance", lagged.Provider)
+		assert.Equal(t, string(yahoo.Interval5m), lagged.Interval)
+		assert.True(t, now.Add(-2*time.Hour).Equal(*lagged.LatestBar))
+		assert.Equal(t, int64(7200), *lagged.LagSeconds)
+		assert.True(t, lagged.Stale)
+		assert.True(t, now.Equal(*lagged.StaleSince))
+		assert.Equal(t, freshness.ReasonLagged, lagged.Reason)
+
+		noData := report.Symbols[1]
+		assert.Equal(t, "SOLUSDT", noData.Symbol)
+		assert.Nil(t, noData.LatestBar)
+		assert.Nil(t, noData.LagSeconds)
+		assert.Equal(t, freshness.ReasonNoData, noData.Reason)
+
+		fresh := report.Symbols[2]
+		assert.Equal(t, "BTCUSDT", fresh.Symbol)
+		assert.False(t, fresh.Stale)
+		assert.Nil(t, fresh.StaleSince)
+		assert.Empty(t, fresh.Reason)
+	})
+
+	t.Run("Report stale symbols only", func(t *testing.T) {
+		report := decode[api.StalenessReport](t, serve(t, router, http.MethodGet, "/admin/staleness?staleOnly=true", ""),
+			http.StatusOK)
+		assert.Equal(t, 3, report.Tracked)
+		require.Len(t, report.Symbols, 2)
+		for _, s := range report.Symbols {
+			assert.True(t, s.Stale, s.Symbol)
+		}
+		assert.Equal(t, 1, repo.queries, "the last report is reused")
+	})
+
+	t.Run("Check again", func(t *testing.T) {
+		repo.latest["SOLUSDT"] = now.Add(-30 * time.Minute)
+		report := decode[api.StalenessReport](t, serve(t, router, http.MethodGet, "/admin/staleness?refresh=true", ""),
+			http.StatusOK)
+		assert.Equal(t, 2, repo.queries)
+		assert.Equal(t, 1, report.Stale)
+	})
+}
@@ -2,18 +2,57 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/freshness"
+	"github.com/market-data/internal/domain/leader"
+	"github.com/rs/zerolog/log"
 )
 
+type HealthResponse struct {
+	Status    string           `json:"status"`
+	Scheduler *SchedulerStatus `json:"scheduler,omitempty"`
+	Freshness *FreshnessStatus `json:"freshness,omitempty"`
+}
+
+// FreshnessStatus counts the tracked symbols which stopped updating, details are at /admin/staleness
+type FreshnessStatus struct {
+	Tracked   int       `json:"tracked"`
+	Stale     int       `json:"stale"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// SchedulerStatus tells which replica runs the scheduled jobs
+type SchedulerStatus struct {
+	Replica   string     `json:"replica"`
+	Leader    string     `json:"leader"`
+	IsLeader  bool       `json:"isLeader"`
+	Since     *time.Time `json:"since"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
 // HealthController handles health check and status endpoints
-type HealthController struct{}
+type HealthController struct {
+	elector *leader.Elector    // nil without leader election
+	monitor *freshness.Monitor // nil without freshness monitoring
+}
 
 // NewHealthController creates a new health controller
 func NewHealthController() *HealthController {
 	return &HealthController{}
 }
 
+// SetElector reports the scheduler leader on the health endpoint
+func (c *HealthController) SetElector(elector *leader.Elector) {
+	c.elector = elector
+}
+
+// SetFreshnessMonitor reports the number of stale tracked symbols on the health endpoint
+func (c *HealthController) SetFreshnessMonitor(monitor *freshness.Monitor) {
+	c.monitor = monitor
+}
+
 // RegisterRoutes registers the routes for the health controller
 func (c *HealthController) RegisterRoutes(router *gin.Engine) {
 	router.GET("/", c.getStatus)
@@ -27,5 +66,29 @@ func (c *HealthController) getStatus(ctx *gin.Context) {
 
 // getHealth handles the health check endpoint
 func (c *HealthController) getHealth(ctx *gin.Context) {
-	ctx.String(http.StatusOK, "OK")
+	response := HealthResponse{Status: "OK"}
+	if c.elector != nil {
+		status := c.elector.Status()
+		response.Scheduler = &SchedulerStatus{
+			Replica:   status.Self,
+			Leader:    status.Leader,
+			IsLeader:  status.IsLeader,
+			Since:     status.Since,
+			ExpiresAt: status.ExpiresAt,
+		}
+	}
+	if c.monitor != nil {
+		// stale data does not make the service unhealthy, the counts are informative
+		report, err := c.monitor.Latest(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to check data freshness")
+		} else {
+			response.Freshness = &FreshnessStatus{
+				Tracked:   len(report.Symbols),
+				Stale:     report.Stale,
+				CheckedAt: report.CheckedAt,
+			}
+		}
+	}
+	ctx.JSON(http.StatusOK, response)
 }
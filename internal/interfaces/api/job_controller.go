@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/rs/zerolog/log"
+)
+
+type Job struct {
+	ID          int64      `json:"id"`
+	Type        string     `json:"type"`
+	Symbol      string     `json:"symbol"`
+	Priority    int        `json:"priority"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"maxAttempts"`
+	RunAt       time.Time  `json:"runAt"`
+	Error       *string    `json:"error"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+}
+
+type JobsResponse struct {
+	Jobs []Job `json:"jobs"`
+}
+
+func buildJob(j *jobs.Job) Job {
+	return Job{
+		ID:          j.ID,
+		Type:        j.Type,
+		Symbol:      j.Symbol,
+		Priority:    j.Priority,
+		Status:      string(j.Status),
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt,
+		Error:       j.Error,
+		CreatedAt:   j.CreatedAt,
+		StartedAt:   j.StartedAt,
+		FinishedAt:  j.FinishedAt,
+	}
+}
+
+// jobStatuses are the states accepted by the status filter of the job list
+var jobStatuses = map[jobs.Status]bool{
+	jobs.StatusQueued:    true,
+	jobs.StatusRunning:   true,
+	jobs.StatusSucceeded: true,
+	jobs.StatusDead:      true,
+}
+
+// jobLocation returns the path clients poll for the state of a job
+func jobLocation(id int64) string {
+	return "/jobs/" + strconv.FormatInt(id, 10)
+}
+
+// JobController handles the background job status endpoints
+type JobController struct {
+	queue *jobs.Queue
+}
+
+// NewJobController creates a new job controller
+func NewJobController(queue *jobs.Queue) *JobController {
+	return &JobController{
+		queue: queue,
+	}
+}
+
+// RegisterRoutes registers the routes for the job controller
+func (c *JobController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/jobs", c.listJobs)
+	router.GET("/jobs/:id", c.getJob)
+	router.POST("/jobs/:id/retry", c.retryJob)
+}
+
+// listJobs lists the most recent jobs, optionally filtered by status and symbol, e.g. the dead-lettered ones
+func (c *JobController) listJobs(ctx *gin.Context) {
+	status := jobs.Status(ctx.Query("status"))
+	if status != "" && !jobStatuses[status] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, must be one of queued, running, succeeded or dead"})
+		return
+	}
+	limit := 100
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	list, err := c.queue.ListJobs(ctx, jobs.Filter{
+		Status: status,
+		Symbol: strings.ToUpper(ctx.Query("symbol")),
+		Limit:  limit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list jobs")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving jobs"})
+		return
+	}
+
+	response := JobsResponse{Jobs: make([]Job, 0, len(list))}
+	for i := range list {
+		response.Jobs = append(response.Jobs, buildJob(&list[i]))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *JobController) getJob(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job id"})
+		return
+	}
+
+	job, err := c.queue.GetJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		log.Error().Err(err).Int64("job", id).Msg("Failed to get job")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving job"})
+		return
+	}
+	ctx.JSON(http.StatusOK, buildJob(job))
+}
+
+// retryJob queues a dead-lettered job again with fresh attempts
+func (c *JobController) retryJob(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job id"})
+		return
+	}
+
+	job, err := c.queue.Requeue(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrJobNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, jobs.ErrJobNotDead):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Only dead-lettered jobs can be retried"})
+		default:
+			log.Error().Err(err).Int64("job", id).Msg("Failed to retry job")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrying job"})
+		}
+		return
+	}
+	ctx.Header("Location", jobLocation(job.ID))
+	ctx.JSON(http.StatusAccepted, buildJob(job))
+}
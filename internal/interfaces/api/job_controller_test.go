@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobController(t *testing.T) {
+	finished := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	repo := &jobRepository{jobs: []jobs.Job{
+		{ID: 1, Type: jobs.TypeBackfill, Symbol: "AAPL", Priority: jobs.PriorityHigh, Status: jobs.StatusSucceeded,
+			Attempts: 1, MaxAttempts: 5, StartedAt: tests.Ptr(finished.Add(-time.Minute)), FinishedAt: &finished},
+		{ID: 2, Type: jobs.TypeRefresh, Symbol: "AAPL", Status: jobs.StatusDead, Attempts: 5, MaxAttempts: 5,
+			Error: tests.Ptr("provider unavailable"), FinishedAt: &finished},
+		{ID: 3, Type: jobs.TypeRefresh, Symbol: "MSFT", Status: jobs.StatusQueued, MaxAttempts: 5},
+	}}
+	router := newRouter(api.NewJobController(newQueue(repo)))
+
+	t.Run("Get a job", func(t *testing.T) {
+		job := decode[api.Job](t, serve(t, router, http.MethodGet, "/jobs/1", ""), http.StatusOK)
+		assert.Equal(t, int64(1), job.ID)
+		assert.Equal(t, jobs.TypeBackfill, job.Type)
+		assert.Equal(t, "AAPL", job.Symbol)
+		assert.Equal(t, jobs.PriorityHigh, job.Priority)
+		assert.Equal(t, string(jobs.StatusSucceeded), job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, 5, job.MaxAttempts)
+		assert.Nil(t, job.Error)
+		assert.True(t, finished.Add(-time.Minute).Equal(*job.StartedAt))
+		assert.True(t, finished.Equal(*job.FinishedAt))
+	})
+
+	t.Run("List jobs", func(t *testing.T) {
+		response := decode[api.JobsResponse](t, serve(t, router, http.MethodGet, "/jobs", ""), http.StatusOK)
+		require.Len(t, response.Jobs, 3)
+		assert.Equal(t, int64(3), response.Jobs[0].ID, "latest first")
+		assert.Equal(t, 100, repo.filter.Limit, "default limit")
+	})
+
+	t.Run("List dead jobs of a symbol", func(t *testing.T) {
+		response := decode[api.JobsResponse](t, serve(t, router, http.MethodGet, "/jobs?status=dead&symbol=aapl&limit=10",
+			""), http.StatusOK)
+		require.Len(t, response.Jobs, 1)
+		assert.Equal(t, "provider unavailable", *response.Jobs[0].Error)
+		assert.Equal(t, jobs.Filter{Status: jobs.StatusDead, Symbol: "AAPL", Limit: 10}, repo.filter)
+	})
+
+	t.Run("Retry a dead job", func(t *testing.T) {
+		w := serve(t, router, http.MethodPost, "/jobs/2/retry", "")
+		job := decode[api.Job](t, w, http.StatusAccepted)
+		assert.Equal(t, "/jobs/2", w.Header().Get("Location"))
+		assert.Equal(t, string(jobs.StatusQueued), job.Status)
+		assert.Zero(t, job.Attempts)
+		assert.Nil(t, job.FinishedAt)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Get an unknown job", http.MethodGet, "/jobs/99", "", http.StatusNotFound},
+		{"Retry an unknown job", http.MethodPost, "/jobs/99/retry", "", http.StatusNotFound},
+		{"Retry a job which is not dead", http.MethodPost, "/jobs/3/retry", "", http.StatusConflict},
+		{"List with an invalid status", http.MethodGet, "/jobs?status=failed", "", http.StatusBadRequest},
+		{"List with a zero limit", http.MethodGet, "/jobs?limit=0", "", http.StatusBadRequest},
+		{"List with a limit above 1000", http.MethodGet, "/jobs?limit=1001", "", http.StatusBadRequest},
+		{"Get an invalid job id", http.MethodGet, "/jobs/first", "", http.StatusBadRequest},
+		{"Retry an invalid job id", http.MethodPost, "/jobs/first/retry", "", http.StatusBadRequest},
+	})
+}
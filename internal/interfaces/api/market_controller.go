@@ -3,10 +3,14 @@ package api
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
 )
 
 type SymbolPrice struct {
@@ -16,7 +20,7 @@ type SymbolPrice struct {
 	Low      *float64  `json:"low"`
 	Close    *float64  `json:"close"`
 	AdjClose *float64  `json:"adjClose"`
-	Volume   *int64    `json:"volume"`
+	Volume   *float64  `json:"volume"`
 }
 
 type MarketData struct {
@@ -60,6 +64,7 @@ func (c *MarketController) RegisterRoutes(router *gin.Engine) {
 	router.GET("/symbols/:symbol", c.getMarketData)
 }
 
+// getMarketData returns the stored bars of a symbol, of the interval query parameter (daily by default)
 func (c *MarketController) getMarketData(ctx *gin.Context) {
 	symbol := ctx.Param("symbol")
 	if symbol == "" {
@@ -67,14 +72,24 @@ func (c *MarketController) getMarketData(ctx *gin.Context) {
 		return
 	}
 
-	symbolData, stockPricesData, err := c.service.GetMarketData(ctx, symbol)
+	interval, ok := parseInterval(ctx)
+	if !ok {
+		return
+	}
+
+	symbolData, stockPricesData, err := c.service.GetOrFetchMarketData(ctx, symbol, interval)
 	if err != nil {
-		if errors.Is(err, market.ErrSymbolNotFound) {
+		switch {
+		case errors.Is(err, market.ErrSymbolNotFound), errors.Is(err, market.ErrUnknownSymbol):
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Symbol not found"})
-			return
+		case errors.Is(err, market.ErrFetchTimeout):
+			ctx.JSON(http.StatusGatewayTimeout, gin.H{"error": "Symbol not stored, fetching it from the provider timed out"})
+		case errors.Is(err, usage.ErrQuotaExceeded):
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Symbol not stored, the provider quota is exhausted for today"})
+		default:
+			log.Error().Err(err).Str("symbol", symbol).Msg("Failed to get market data")
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving market data"})
 		}
-		// Handle different types of errors appropriately
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving market data"})
 		return
 	}
 
@@ -83,3 +98,14 @@ func (c *MarketController) getMarketData(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, marketData)
 }
+
+// parseInterval reads the optional interval query parameter, daily by default. On invalid input it writes a
+// bad request response and returns ok=false.
+func parseInterval(ctx *gin.Context) (yahoo.IntervalAPI, bool) {
+	interval := yahoo.IntervalAPI(ctx.DefaultQuery("interval", string(yahoo.Interval1d)))
+	if !slices.Contains(yahoo.Intervals, interval) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval"})
+		return "", false
+	}
+	return interval, true
+}
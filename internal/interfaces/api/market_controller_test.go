@@ -38,7 +38,7 @@ func TestMarketController_GetMarketData(t *testing.T) {
 	// Insert test data
 	testSymbol := "AAPL"
 	testPrice := 228.95
-	testVolume := int64(53740000)
+	testVolume := 53740000.0
 
 	//testData := &market.MarketData{
 	//	Symbol:    testSymbol,
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/options"
+)
+
+type OptionQuote struct {
+	ContractSymbol    string    `json:"contractSymbol"`
+	Strike            float64   `json:"strike"`
+	Time              time.Time `json:"time"`
+	Bid               *float64  `json:"bid"`
+	Ask               *float64  `json:"ask"`
+	LastPrice         *float64  `json:"lastPrice"`
+	Volume            *int64    `json:"volume"`
+	OpenInterest      *int64    `json:"openInterest"`
+	ImpliedVolatility *float64  `json:"impliedVolatility"`
+}
+
+type OptionChain struct {
+	Symbol string        `json:"symbol"`
+	Expiry string        `json:"expiry"`
+	Calls  []OptionQuote `json:"calls"`
+	Puts   []OptionQuote `json:"puts"`
+}
+
+type OptionExpirations struct {
+	Symbol      string   `json:"symbol"`
+	Expirations []string `json:"expirations"`
+}
+
+func buildOptionChain(symbol string, expiry time.Time, chain options.Chain) *OptionChain {
+	result := &OptionChain{
+		Symbol: symbol,
+		Expiry: expiry.Format(time.DateOnly),
+		Calls:  []OptionQuote{},
+		Puts:   []OptionQuote{},
+	}
+	for _, entry := range chain {
+		quote := OptionQuote{
+			ContractSymbol:    entry.ContractSymbol,
+			Strike:            entry.Strike,
+			Time:              entry.Time,
+			Bid:               entry.Bid,
+			Ask:               entry.Ask,
+			LastPrice:         entry.LastPrice,
+			Volume:            entry.Volume,
+			OpenInterest:      entry.OpenInterest,
+			ImpliedVolatility: entry.ImpliedVolatility,
+		}
+		if entry.OptionType == "put" {
+			result.Puts = append(result.Puts, quote)
+		} else {
+			result.Calls = append(result.Calls, quote)
+		}
+	}
+	return result
+}
+
+// OptionsController handles option chain related endpoints
+type OptionsController struct {
+	service *options.OptionsService
+}
+
+// NewOptionsController creates a new options controller
+func NewOptionsController(service *options.OptionsService) *OptionsController {
+	return &OptionsController{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the routes for the options controller
+func (c *OptionsController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/symbols/:symbol/options", c.getExpirations)
+	router.GET("/symbols/:symbol/options/:expiry", c.getChain)
+}
+
+func (c *OptionsController) getExpirations(ctx *gin.Context) {
+	symbol := ctx.Param("symbol")
+
+	expiries, err := c.service.GetExpiries(ctx, symbol)
+	if err != nil {
+		if errors.Is(err, options.ErrChainNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Option chain not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving option expirations"})
+		return
+	}
+
+	response := OptionExpirations{Symbol: symbol}
+	for _, expiry := range expiries {
+		response.Expirations = append(response.Expirations, expiry.Format(time.DateOnly))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *OptionsController) getChain(ctx *gin.Context) {
+	symbol := ctx.Param("symbol")
+	expiry, err := time.Parse(time.DateOnly, ctx.Param("expiry"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be a date in YYYY-MM-DD format"})
+		return
+	}
+
+	chain, err := c.service.GetChain(ctx, symbol, expiry)
+	if err != nil {
+		if errors.Is(err, options.ErrChainNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Option chain not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving option chain"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildOptionChain(symbol, expiry, chain))
+}
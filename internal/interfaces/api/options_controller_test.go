@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/options"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainRepository serves the stored chain of a single symbol
+type chainRepository struct {
+	options.Repository
+	symbol string
+	chain  options.Chain
+}
+
+func (r *chainRepository) GetExpiries(_ context.Context, symbol string) ([]time.Time, error) {
+	var expiries []time.Time
+	for _, entry := range r.chain {
+		if symbol == r.symbol && (len(expiries) == 0 || !expiries[len(expiries)-1].Equal(entry.Expiry)) {
+			expiries = append(expiries, entry.Expiry)
+		}
+	}
+	return expiries, nil
+}
+
+func (r *chainRepository) GetChain(_ context.Context, symbol string, expiry time.Time) (options.Chain, error) {
+	var chain options.Chain
+	for _, entry := range r.chain {
+		if symbol == r.symbol && entry.Expiry.Equal(expiry) {
+			chain = append(chain, entry)
+		}
+	}
+	return chain, nil
+}
+
+func TestOptionsController(t *testing.T) {
+	snapshot := time.Date(2025, time.June, 13, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2025, time.July, 18, 0, 0, 0, 0, time.UTC)
+	repo := &chainRepository{symbol: "AAPL", chain: options.Chain{
+		{ContractSymbol: "AAPL250620C00200000", OptionType: string(yahoo.OptionCall), Expiry: june, Strike: 200,
+			Time: snapshot, Bid: tests.Ptr(5.1), Ask: tests.Ptr(5.3), LastPrice: tests.Ptr(5.2), Volume: tests.Ptr[int64](10),
+			OpenInterest: tests.Ptr[int64](100), ImpliedVolatility: tests.Ptr(0.3)},
+		{ContractSymbol: "AAPL250620P00200000", OptionType: string(yahoo.OptionPut), Expiry: june, Strike: 200,
+			Time: snapshot},
+		{ContractSymbol: "AAPL250718C00200000", OptionType: string(yahoo.OptionCall), Expiry: july, Strike: 200,
+			Time: snapshot},
+	}}
+	router := newRouter(api.NewOptionsController(options.NewOptionsService(repo, nil)))
+
+	t.Run("Get expirations", func(t *testing.T) {
+		response := decode[api.OptionExpirations](t, serve(t, router, http.MethodGet, "/symbols/AAPL/options", ""),
+			http.StatusOK)
+		assert.Equal(t, api.OptionExpirations{Symbol: "AAPL", Expirations: []string{"2025-06-20", "2025-07-18"}}, response)
+	})
+
+	t.Run("Get a chain", func(t *testing.T) {
+		chain := decode[api.OptionChain](t, serve(t, router, http.MethodGet, "/symbols/AAPL/options/2025-06-20", ""),
+			http.StatusOK)
+		assert.Equal(t, "AAPL", chain.Symbol)
+		assert.Equal(t, "2025-06-20", chain.Expiry)
+		require.Len(t, chain.Calls, 1)
+		require.Len(t, chain.Puts, 1)
+
+		call := chain.Calls[0]
+		assert.Equal(t, "AAPL250620C00200000", call.ContractSymbol)
+		assert.Equal(t, 200.0, call.Strike)
+		assert.True(t, snapshot.Equal(call.Time))
+		assert.Equal(t, 5.1, *call.Bid)
+		assert.Equal(t, 5.3, *call.Ask)
+		assert.Equal(t, 5.2, *call.LastPrice)
+		assert.Equal(t, int64(10), *call.Volume)
+		assert.Equal(t, int64(100), *call.OpenInterest)
+		assert.Equal(t, 0.3, *call.ImpliedVolatility)
+
+		assert.Equal(t, "AAPL250620P00200000", chain.Puts[0].ContractSymbol)
+		assert.Nil(t, chain.Puts[0].Bid)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Get expirations of a symbol without chain", http.MethodGet, "/symbols/MSFT/options", "", http.StatusNotFound},
+		{"Get a chain of an expiry not stored", http.MethodGet, "/symbols/AAPL/options/2025-06-27", "", http.StatusNotFound},
+		{"Get a chain of an invalid expiry", http.MethodGet, "/symbols/AAPL/options/june", "", http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/market"
+	"github.com/rs/zerolog/log"
+)
+
+type QuarantinedBar struct {
+	ID         int64      `json:"id"`
+	Symbol     string     `json:"symbol"`
+	Time       time.Time  `json:"time"`
+	Open       *float64   `json:"open"`
+	High       *float64   `json:"high"`
+	Low        *float64   `json:"low"`
+	Close      *float64   `json:"close"`
+	AdjClose   *float64   `json:"adjClose"`
+	Volume     *float64   `json:"volume"`
+	Rule       string     `json:"rule"`
+	Reason     string     `json:"reason"`
+	FetchLogID *int       `json:"fetchLogId"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+}
+
+type QuarantineResponse struct {
+	Bars []QuarantinedBar `json:"bars"`
+}
+
+type ReviewQuarantineRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1,max=1000"`
+}
+
+func buildQuarantinedBars(bars []market.QuarantinedBar) []QuarantinedBar {
+	response := make([]QuarantinedBar, 0, len(bars))
+	for _, b := range bars {
+		response = append(response, QuarantinedBar{
+			ID:         b.ID,
+			Symbol:     b.Symbol,
+			Time:       b.Time,
+			Open:       b.OpenPrice,
+			High:       b.HighPrice,
+			Low:        b.LowPrice,
+			Close:      b.ClosePrice,
+			AdjClose:   b.AdjClose,
+			Volume:     b.Volume,
+			Rule:       b.Rule,
+			Reason:     b.Reason,
+			FetchLogID: b.FetchLogID,
+			Status:     b.Status,
+			CreatedAt:  b.CreatedAt,
+			ReviewedAt: b.ReviewedAt,
+		})
+	}
+	return response
+}
+
+// quarantineStatuses are the states accepted by the status filter of the quarantine list
+var quarantineStatuses = map[string]bool{
+	market.QuarantinePending:   true,
+	market.QuarantineReleased:  true,
+	market.QuarantineDiscarded: true,
+}
+
+// QuarantineController handles the review of bars which failed validation
+type QuarantineController struct {
+	service *market.MarketService
+}
+
+// NewQuarantineController creates a new quarantine controller
+func NewQuarantineController(service *market.MarketService) *QuarantineController {
+	return &QuarantineController{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the routes for the quarantine controller
+func (c *QuarantineController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/admin/quarantine", c.listQuarantine)
+	router.POST("/admin/quarantine/release", c.releaseQuarantine)
+	router.POST("/admin/quarantine/discard", c.discardQuarantine)
+}
+
+// listQuarantine lists the latest quarantined bars, by default those awaiting review. status=all lists the
+// reviewed ones too.
+func (c *QuarantineController) listQuarantine(ctx *gin.Context) {
+	status := ctx.DefaultQuery("status", market.QuarantinePending)
+	if status == "all" {
+		status = ""
+	} else if !quarantineStatuses[status] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, must be one of pending, released, discarded or all"})
+		return
+	}
+	limit := 100
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	bars, err := c.service.ListQuarantinedBars(ctx, market.QuarantineFilter{
+		Symbol: strings.ToUpper(ctx.Query("symbol")),
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list quarantined bars")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving quarantined bars"})
+		return
+	}
+	ctx.JSON(http.StatusOK, QuarantineResponse{Bars: buildQuarantinedBars(bars)})
+}
+
+// releaseQuarantine stores quarantined bars as prices despite failing validation, e.g. a genuine price jump.
+// The response lists the bars released, bars already reviewed are skipped.
+func (c *QuarantineController) releaseQuarantine(ctx *gin.Context) {
+	c.review(ctx, "release", c.service.ReleaseQuarantinedBars)
+}
+
+// discardQuarantine drops quarantined bars for good. The response lists the bars discarded, bars already
+// reviewed are skipped.
+func (c *QuarantineController) discardQuarantine(ctx *gin.Context) {
+	c.review(ctx, "discard", c.service.DiscardQuarantinedBars)
+}
+
+func (c *QuarantineController) review(ctx *gin.Context, action string,
+	review func(ctx context.Context, ids []int64) ([]market.QuarantinedBar, error)) {
+	var request ReviewQuarantineRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	bars, err := review(ctx, request.IDs)
+	if err != nil {
+		log.Error().Err(err).Ints64("ids", request.IDs).Msgf("Failed to %s quarantined bars", action)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error reviewing quarantined bars"})
+		return
+	}
+	ctx.JSON(http.StatusOK, QuarantineResponse{Bars: buildQuarantinedBars(bars)})
+}
@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quarantineRepository keeps quarantined bars in memory
+type quarantineRepository struct {
+	market.Repository
+	bars   []market.QuarantinedBar
+	filter market.QuarantineFilter // of the last listing
+}
+
+// ListQuarantinedBars lists the bars latest first, only the symbol and status filter
+func (r *quarantineRepository) ListQuarantinedBars(_ context.Context,
+	filter market.QuarantineFilter) ([]market.QuarantinedBar, error) {
+	r.filter = filter
+	var bars []market.QuarantinedBar
+	for i := len(r.bars) - 1; i >= 0; i-- {
+		if b := r.bars[i]; (filter.Symbol == "" || b.Symbol == filter.Symbol) &&
+			(filter.Status == "" || b.Status == filter.Status) {
+			bars = append(bars, b)
+		}
+	}
+	return bars, nil
+}
+
+func (r *quarantineRepository) ReleaseQuarantinedBars(_ context.Context, ids []int64) ([]market.QuarantinedBar,
+	error) {
+	return r.review(ids, market.QuarantineReleased), nil
+}
+
+func (r *quarantineRepository) DiscardQuarantinedBars(_ context.Context, ids []int64) ([]market.QuarantinedBar,
+	error) {
+	return r.review(ids, market.QuarantineDiscarded), nil
+}
+
+// review moves the pending bars among ids to the status, unknown and reviewed bars are skipped
+func (r *quarantineRepository) review(ids []int64, status string) []market.QuarantinedBar {
+	var reviewed []market.QuarantinedBar
+	for _, id := range ids {
+		if id < 1 || id > int64(len(r.bars)) || r.bars[id-1].Status != market.QuarantinePending {
+			continue
+		}
+		b := &r.bars[id-1]
+		b.Status, b.ReviewedAt = status, tests.Ptr(time.Now())
+		reviewed = append(reviewed, *b)
+	}
+	return reviewed
+}
+
+func TestQuarantineController(t *testing.T) {
+	start := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	violation := &market.Violation{Rule: market.RuleHighLow, Reason: "high 90 below low 110"}
+	repo := &quarantineRepository{}
+	for i, symbol := range []string{"AAPL", "AAPL", "MSFT"} {
+		bar := market.NewQuarantinedBar(symbol, yahoo.Interval1d, &yahoo.StockPrice{Time: start.AddDate(0, 0, i),
+			Open: 100, High: 90, Low: 110, Close: 100, Volume: 1000}, violation)
+		bar.ID, bar.FetchLogID, bar.CreatedAt = int64(i+1), tests.Ptr(7), time.Now()
+		repo.bars = append(repo.bars, bar)
+	}
+	router := newRouter(api.NewQuarantineController(market.NewMarketService(repo, nil)))
+
+	t.Run("List quarantined bars", func(t *testing.T) {
+		response := decode[api.QuarantineResponse](t, serve(t, router, http.MethodGet,
+			"/admin/quarantine?symbol=aapl&limit=10", ""), http.StatusOK)
+		require.Len(t, response.Bars, 2)
+		assert.Equal(t, market.QuarantineFilter{Symbol: "AAPL", Status: market.QuarantinePending, Limit: 10}, repo.filter)
+
+		bar := response.Bars[1]
+		assert.Equal(t, int64(1), bar.ID)
+		assert.Equal(t, "AAPL", bar.Symbol)
+		assert.True(t, start.Equal(bar.Time))
+		assert.Equal(t, 100.0, *bar.Open)
+		assert.Equal(t, 90.0, *bar.High)
+		assert.Equal(t, 110.0, *bar.Low)
+		assert.Equal(t, 100.0, *bar.Close)
+		assert.Equal(t, 1000.0, *bar.Volume)
+		assert.Equal(t, market.RuleHighLow, bar.Rule)
+		assert.Equal(t, violation.Reason, bar.Reason)
+		assert.Equal(t, 7, *bar.FetchLogID)
+		assert.Equal(t, market.QuarantinePending, bar.Status)
+		assert.Nil(t, bar.ReviewedAt)
+
+		serve(t, router, http.MethodGet, "/admin/quarantine?status=all", "")
+		assert.Empty(t, repo.filter.Status, "all states")
+		assert.Equal(t, 100, repo.filter.Limit, "default limit")
+	})
+
+	t.Run("Release quarantined bars", func(t *testing.T) {
+		response := decode[api.QuarantineResponse](t, serve(t, router, http.MethodPost, "/admin/quarantine/release",
+			`{"ids": [1]}`), http.StatusOK)
+		require.Len(t, response.Bars, 1)
+		assert.Equal(t, market.QuarantineReleased, response.Bars[0].Status)
+		assert.NotNil(t, response.Bars[0].ReviewedAt)
+	})
+
+	t.Run("Discard quarantined bars", func(t *testing.T) {
+		// bars already reviewed are skipped
+		response := decode[api.QuarantineResponse](t, serve(t, router, http.MethodPost, "/admin/quarantine/discard",
+			`{"ids": [1, 2]}`), http.StatusOK)
+		require.Len(t, response.Bars, 1)
+		assert.Equal(t, int64(2), response.Bars[0].ID)
+		assert.Equal(t, market.QuarantineDiscarded, response.Bars[0].Status)
+	})
+
+	t.Run("Review only reviewed bars", func(t *testing.T) {
+		w := serve(t, router, http.MethodPost, "/admin/quarantine/release", `{"ids": [1, 2]}`)
+		assert.JSONEq(t, `{"bars": []}`, w.Body.String())
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"List with an invalid status", http.MethodGet, "/admin/quarantine?status=open", "", http.StatusBadRequest},
+		{"List with a zero limit", http.MethodGet, "/admin/quarantine?limit=0", "", http.StatusBadRequest},
+		{"List with a limit above 1000", http.MethodGet, "/admin/quarantine?limit=1001", "", http.StatusBadRequest},
+		{"Release without ids", http.MethodPost, "/admin/quarantine/release", `{}`, http.StatusBadRequest},
+		{"Release an empty id list", http.MethodPost, "/admin/quarantine/release", `{"ids": []}`, http.StatusBadRequest},
+		{"Discard invalid ids", http.MethodPost, "/admin/quarantine/discard", `{"ids": ["one"]}`, http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/market"
+)
+
+// maxBatchQuotes limits the number of symbols of a batch quote request
+const maxBatchQuotes = 100
+
+type SymbolQuote struct {
+	Symbol        string    `json:"symbol"`
+	Price         float64   `json:"price"`
+	PreviousClose *float64  `json:"previousClose"`
+	Change        *float64  `json:"change"`
+	ChangePercent *float64  `json:"changePercent"`
+	Currency      string    `json:"currency"`
+	MarketTime    time.Time `json:"marketTime"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type BatchQuotes struct {
+	Quotes  []SymbolQuote `json:"quotes"`
+	Missing []string      `json:"missing"`
+}
+
+func buildSymbolQuote(q *market.Quote) SymbolQuote {
+	return SymbolQuote{
+		Symbol:        q.Symbol,
+		Price:         q.Price,
+		PreviousClose: q.PreviousClose,
+		Change:        q.Change,
+		ChangePercent: q.ChangePercent,
+		Currency:      q.Currency,
+		MarketTime:    q.MarketTime,
+		UpdatedAt:     q.UpdatedAt,
+	}
+}
+
+// QuoteController handles latest quote endpoints
+type QuoteController struct {
+	service *market.MarketService
+}
+
+// NewQuoteController creates a new quote controller
+func NewQuoteController(service *market.MarketService) *QuoteController {
+	return &QuoteController{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the routes for the quote controller
+func (c *QuoteController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/symbols/:symbol/quote", c.getQuote)
+	router.GET("/quotes", c.getQuotes)
+}
+
+func (c *QuoteController) getQuote(ctx *gin.Context) {
+	quote, err := c.service.GetQuote(ctx, ctx.Param("symbol"))
+	if err != nil {
+		if errors.Is(err, market.ErrQuoteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving quote"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildSymbolQuote(quote))
+}
+
+// getQuotes returns the latest quotes of the requested symbols (?symbols=A,B), listing symbols without a quote
+func (c *QuoteController) getQuotes(ctx *gin.Context) {
+	var symbols []string
+	for _, symbol := range strings.Split(ctx.Query("symbols"), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter symbols is required"})
+		return
+	}
+	if len(symbols) > maxBatchQuotes {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Too many symbols requested"})
+		return
+	}
+
+	quotes, err := c.service.GetQuotes(ctx, symbols)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving quotes"})
+		return
+	}
+
+	response := BatchQuotes{Quotes: []SymbolQuote{}, Missing: []string{}}
+	found := make(map[string]bool, len(quotes))
+	for i := range quotes {
+		found[quotes[i].Symbol] = true
+		response.Quotes = append(response.Quotes, buildSymbolQuote(&quotes[i]))
+	}
+	for _, symbol := range symbols {
+		if !found[symbol] {
+			response.Missing = append(response.Missing, symbol)
+		}
+	}
+	ctx.JSON(http.StatusOK, response)
+}
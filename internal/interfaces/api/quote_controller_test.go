@@ -0,0 +1,59 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteController(t *testing.T) {
+	marketTime := time.Date(2025, time.June, 2, 19, 59, 0, 0, time.UTC)
+	repo := newMarketRepository("AAPL", "MSFT")
+	repo.quotes = map[string]market.Quote{
+		"AAPL": *market.NewQuoteFromMarketQuote("AAPL", &yahoo.MarketQuote{Price: 202, PreviousClose: 200,
+			Currency: "USD", Time: marketTime}),
+		// quotes of providers without a previous close carry no change
+		"BTCUSDT": {Symbol: "BTCUSDT", Price: 67000, Currency: "USDT", MarketTime: marketTime},
+	}
+	router := newRouter(api.NewQuoteController(market.NewMarketService(repo, nil)))
+
+	t.Run("Get a quote", func(t *testing.T) {
+		quote := decode[api.SymbolQuote](t, serve(t, router, http.MethodGet, "/symbols/AAPL/quote", ""), http.StatusOK)
+		assert.Equal(t, "AAPL", quote.Symbol)
+		assert.Equal(t, 202.0, quote.Price)
+		assert.Equal(t, 200.0, *quote.PreviousClose)
+		assert.Equal(t, 2.0, *quote.Change)
+		assert.InDelta(t, 1.0, *quote.ChangePercent, 1e-9)
+		assert.Equal(t, "USD", quote.Currency)
+		assert.True(t, marketTime.Equal(quote.MarketTime))
+	})
+
+	t.Run("Get quotes", func(t *testing.T) {
+		response := decode[api.BatchQuotes](t, serve(t, router, http.MethodGet, "/quotes?symbols=AAPL,%20MSFT,BTCUSDT,",
+			""), http.StatusOK)
+		require.Len(t, response.Quotes, 2)
+		assert.Equal(t, "AAPL", response.Quotes[0].Symbol)
+		assert.Equal(t, "BTCUSDT", response.Quotes[1].Symbol)
+		assert.Nil(t, response.Quotes[1].Change)
+		assert.Equal(t, []string{"MSFT"}, response.Missing)
+	})
+
+	t.Run("Get quotes without any stored", func(t *testing.T) {
+		w := serve(t, router, http.MethodGet, "/quotes?symbols=MSFT", "")
+		assert.JSONEq(t, `{"quotes": [], "missing": ["MSFT"]}`, w.Body.String())
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Get the quote of a symbol without one", http.MethodGet, "/symbols/MSFT/quote", "", http.StatusNotFound},
+		{"Quotes without symbols", http.MethodGet, "/quotes", "", http.StatusBadRequest},
+		{"Quotes of blank symbols", http.MethodGet, "/quotes?symbols=,%20,", "", http.StatusBadRequest},
+		{"Quotes of too many symbols", http.MethodGet, "/quotes?symbols=" + strings.Repeat("A,", 101), "", http.StatusBadRequest},
+	})
+}
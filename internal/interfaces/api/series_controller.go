@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/series"
+	"github.com/rs/zerolog/log"
+)
+
+type SeriesObservation struct {
+	Time  time.Time `json:"time"`
+	Value *float64  `json:"value"`
+}
+
+type SeriesData struct {
+	Code         string              `json:"code"`
+	Title        string              `json:"title"`
+	Frequency    string              `json:"frequency"`
+	Units        string              `json:"units"`
+	Source       string              `json:"source"`
+	Observations []SeriesObservation `json:"observations"`
+}
+
+type MarketDataWithSeries struct {
+	MarketData
+	Series []SeriesData `json:"series"`
+}
+
+func buildSeriesData(s *series.Series, observations *series.Observations) *SeriesData {
+	data := &SeriesData{
+		Code:         s.Code,
+		Title:        s.Title,
+		Frequency:    s.Frequency,
+		Units:        s.Units,
+		Source:       s.Source,
+		Observations: []SeriesObservation{},
+	}
+	for _, o := range *observations {
+		data.Observations = append(data.Observations, SeriesObservation{
+			Time:  o.Time,
+			Value: o.Value,
+		})
+	}
+	return data
+}
+
+// SeriesController handles economic time series related endpoints
+type SeriesController struct {
+	service       *series.SeriesService
+	marketService *market.MarketService
+}
+
+// NewSeriesController creates a new series controller
+func NewSeriesController(service *series.SeriesService, marketService *market.MarketService) *SeriesController {
+	return &SeriesController{
+		service:       service,
+		marketService: marketService,
+	}
+}
+
+// RegisterRoutes registers the routes for the series controller
+func (c *SeriesController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/series/:code", c.getSeries)
+	router.POST("/series/:code/fetch", c.fetchSeries)
+	router.GET("/symbols/:symbol/series", c.getMarketDataWithSeries)
+}
+
+func (c *SeriesController) getSeries(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+
+	s, observations, err := c.service.GetSeries(ctx, code, from, to)
+	if err != nil {
+		if errors.Is(err, series.ErrSeriesNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving series"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildSeriesData(s, observations))
+}
+
+func (c *SeriesController) fetchSeries(ctx *gin.Context) {
+	code := ctx.Param("code")
+
+	stored, err := c.service.FetchAndStoreSeries(ctx, code)
+	if err != nil {
+		if errors.Is(err, series.ErrSeriesNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+			return
+		}
+		log.Error().Err(err).Str("series", code).Msg("Failed to fetch series")
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Error fetching series from provider"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": code, "observations": stored})
+}
+
+// getMarketDataWithSeries returns prices of a symbol together with the requested series (?codes=A,B) for the same range
+func (c *SeriesController) getMarketDataWithSeries(ctx *gin.Context) {
+	symbol := ctx.Param("symbol")
+	codes := strings.Split(ctx.Query("codes"), ",")
+	if ctx.Query("codes") == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter codes is required"})
+		return
+	}
+
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	interval, ok := parseInterval(ctx)
+	if !ok {
+		return
+	}
+
+	symbolData, stockPricesData, err := c.marketService.GetMarketData(ctx, symbol, interval)
+	if err != nil {
+		if errors.Is(err, market.ErrSymbolNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Symbol not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving market data"})
+		return
+	}
+
+	var prices market.StockPrices
+	for _, p := range *stockPricesData {
+		if (from.IsZero() || !p.Time.Before(from)) && (to.IsZero() || !p.Time.After(to)) {
+			prices = append(prices, p)
+		}
+	}
+
+	response := MarketDataWithSeries{
+		MarketData: *buildMarketData(symbolData, &prices),
+		Series:     []SeriesData{},
+	}
+	for _, code := range codes {
+		s, observations, err := c.service.GetSeries(ctx, strings.TrimSpace(code), from, to)
+		if err != nil {
+			if errors.Is(err, series.ErrSeriesNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Series not found: " + code})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving series"})
+			return
+		}
+		response.Series = append(response.Series, *buildSeriesData(s, observations))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// parseTimeRange reads the optional from/to query parameters (YYYY-MM-DD or RFC 3339). On invalid input
+// it writes a bad request response and returns ok=false.
+func parseTimeRange(ctx *gin.Context) (from, to time.Time, ok bool) {
+	var err error
+	if from, err = parseTimeParam(ctx.Query("from")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from parameter"})
+		return from, to, false
+	}
+	if to, err = parseTimeParam(ctx.Query("to")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to parameter"})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,145 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/series"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/fred"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seriesRepository keeps series and their observations in memory
+type seriesRepository struct {
+	series       map[string]*series.Series
+	observations map[string]series.Observations
+}
+
+func (r *seriesRepository) GetSeries(_ context.Context, code string) (*series.Series, error) {
+	s, ok := r.series[code]
+	if !ok {
+		return nil, series.ErrSeriesNotFound
+	}
+	return s, nil
+}
+
+func (r *seriesRepository) GetObservations(_ context.Context, code string, from, to time.Time) (*series.Observations,
+	error) {
+	observations := series.Observations{}
+	for _, o := range r.observations[code] {
+		if (from.IsZero() || !o.Time.Before(from)) && (to.IsZero() || !o.Time.After(to)) {
+			observations = append(observations, o)
+		}
+	}
+	return &observations, nil
+}
+
+func (r *seriesRepository) GetLastObservationTime(_ context.Context, code string) (*time.Time, error) {
+	observations := r.observations[code]
+	if len(observations) == 0 {
+		return nil, nil
+	}
+	return &observations[len(observations)-1].Time, nil
+}
+
+func (r *seriesRepository) SaveSeriesData(_ context.Context, data *fred.SeriesData) (int, error) {
+	r.series[data.Code] = &series.Series{Code: data.Code, Title: data.Title, Frequency: data.Frequency,
+		Units: data.Units, Source: data.Source}
+	for _, p := range data.Points {
+		r.observations[data.Code] = append(r.observations[data.Code], series.Observation{Time: p.Time, Value: p.Value})
+	}
+	return len(data.Points), nil
+}
+
+// seriesProvider serves the series it knows and records the start of the last request
+type seriesProvider struct {
+	series map[string]*fred.SeriesData
+	start  time.Time
+}
+
+func (p *seriesProvider) GetSeries(_ context.Context, code string, start time.Time) (*fred.SeriesData, error) {
+	p.start = start
+	data, ok := p.series[code]
+	if !ok {
+		return nil, fred.ErrSeriesNotFound
+	}
+	return data, nil
+}
+
+func TestSeriesController(t *testing.T) {
+	may := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	repo := &seriesRepository{
+		series: map[string]*series.Series{
+			"FEDFUNDS": {Code: "FEDFUNDS", Title: "Federal Funds Effective Rate", Frequency: "M", Units: "%",
+				Source: fred.Source},
+		},
+		observations: map[string]series.Observations{
+			"FEDFUNDS": {{Time: may, Value: tests.Ptr(4.33)}, {Time: june, Value: tests.Ptr(4.33)}},
+		},
+	}
+	provider := &seriesProvider{series: map[string]*fred.SeriesData{
+		"FEDFUNDS": {Source: fred.Source, Code: "FEDFUNDS", Title: "Federal Funds Effective Rate", Frequency: "M",
+			Units: "%", Points: []fred.SeriesPoint{{Time: june, Value: tests.Ptr(4.33)}, {Time: july, Value: nil}}},
+	}}
+	marketRepo := newMarketRepository("AAPL")
+	marketRepo.prices = map[string]market.StockPrices{"AAPL": {
+		{Time: may, ClosePrice: tests.Ptr(210.0)},
+		{Time: june, ClosePrice: tests.Ptr(201.0)},
+	}}
+	router := newRouter(api.NewSeriesController(series.NewSeriesService(repo, provider),
+		market.NewMarketService(marketRepo, nil)))
+
+	t.Run("Get a series", func(t *testing.T) {
+		data := decode[api.SeriesData](t, serve(t, router, http.MethodGet, "/series/FEDFUNDS?from=2025-06-01", ""),
+			http.StatusOK)
+		assert.Equal(t, "FEDFUNDS", data.Code)
+		assert.Equal(t, "Federal Funds Effective Rate", data.Title)
+		assert.Equal(t, "M", data.Frequency)
+		assert.Equal(t, "%", data.Units)
+		assert.Equal(t, fred.Source, data.Source)
+		require.Len(t, data.Observations, 1)
+		assert.True(t, june.Equal(data.Observations[0].Time))
+		assert.Equal(t, 4.33, *data.Observations[0].Value)
+	})
+
+	t.Run("Get prices with series", func(t *testing.T) {
+		data := decode[api.MarketDataWithSeries](t, serve(t, router, http.MethodGet,
+			"/symbols/AAPL/series?codes=FEDFUNDS&to=2025-05-31", ""), http.StatusOK)
+		assert.Equal(t, "AAPL", data.Symbol)
+		require.Len(t, data.SymbolPrice, 1)
+		assert.Equal(t, 210.0, *data.SymbolPrice[0].Close)
+		require.Len(t, data.Series, 1)
+		require.Len(t, data.Series[0].Observations, 1)
+		assert.True(t, may.Equal(data.Series[0].Observations[0].Time))
+	})
+
+	t.Run("Fetch a series", func(t *testing.T) {
+		w := serve(t, router, http.MethodPost, "/series/FEDFUNDS/fetch", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"code": "FEDFUNDS", "observations": 2}`, w.Body.String())
+		assert.True(t, june.Equal(provider.start), "fetched from the latest stored observation")
+		assert.Len(t, repo.observations["FEDFUNDS"], 4)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Get an unknown series", http.MethodGet, "/series/NOPE", "", http.StatusNotFound},
+		{"Fetch an unknown series", http.MethodPost, "/series/NOPE/fetch", "", http.StatusNotFound},
+		{"Get prices with an unknown series", http.MethodGet, "/symbols/AAPL/series?codes=FEDFUNDS,NOPE", "",
+			http.StatusNotFound},
+		{"Get series of an unknown symbol", http.MethodGet, "/symbols/MSFT/series?codes=FEDFUNDS", "", http.StatusNotFound},
+		{"Get prices without series", http.MethodGet, "/symbols/AAPL/series", "", http.StatusBadRequest},
+		{"Get a series with an invalid from", http.MethodGet, "/series/FEDFUNDS?from=yesterday", "", http.StatusBadRequest},
+		{"Get prices with an invalid to", http.MethodGet, "/symbols/AAPL/series?codes=FEDFUNDS&to=soon", "",
+			http.StatusBadRequest},
+		{"Get prices with an invalid interval", http.MethodGet, "/symbols/AAPL/series?codes=FEDFUNDS&interval=2d", "",
+			http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,262 @@
+package api_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// marketRepository keeps symbols and fetch log entries in memory, the methods the handlers under test do not
+// reach are left unimplemented
+type marketRepository struct {
+	market.Repository
+	symbols map[string]*market.Symbol
+	logs    []market.PriceFetchLog
+	// logFilter is the filter of the last listing of fetch log entries
+	logFilter market.FetchLogFilter
+	quotes    map[string]market.Quote
+	prices    map[string]market.StockPrices // daily bars by symbol
+}
+
+func newMarketRepository(symbols ...string) *marketRepository {
+	r := &marketRepository{symbols: make(map[string]*market.Symbol)}
+	for _, symbol := range symbols {
+		r.symbols[symbol] = &market.Symbol{ID: len(r.symbols) + 1, Symbol: symbol, CreatedAt: time.Now()}
+	}
+	return r
+}
+
+func (r *marketRepository) GetSymbol(_ context.Context, symbol string) (*market.Symbol, error) {
+	s, ok := r.symbols[symbol]
+	if !ok {
+		return nil, market.ErrSymbolNotFound
+	}
+	return s, nil
+}
+
+func (r *marketRepository) SaveSymbol(_ context.Context, s *market.Symbol) error {
+	s.ID = len(r.symbols) + 1
+	r.symbols[s.Symbol] = s
+	return nil
+}
+
+func (r *marketRepository) SaveMarketData(_ context.Context, data *yahoo.MarketData,
+	_ ...market.QuarantinedBar) (*market.SaveResult, error) {
+	return &market.SaveResult{Inserted: len(data.Prices)}, nil
+}
+
+func (r *marketRepository) SaveUnreachableWindows(context.Context, string, []market.UnreachableWindow) error {
+	return nil
+}
+
+func (r *marketRepository) SavePriceFetchLog(_ context.Context, entry *market.PriceFetchLog,
+	_ ...string) (int, error) {
+	entry.ID = len(r.logs) + 1
+	r.logs = append(r.logs, *entry)
+	return entry.ID, nil
+}
+
+func (r *marketRepository) GetPriceFetchLogs(_ context.Context, ids []int) ([]market.PriceFetchLog, error) {
+	logs := make([]market.PriceFetchLog, 0, len(ids))
+	for _, id := range ids {
+		logs = append(logs, r.logs[id-1])
+	}
+	return logs, nil
+}
+
+// ListPriceFetchLogs lists the entries of a symbol latest first, only the failed flag and the limit filter
+func (r *marketRepository) ListPriceFetchLogs(_ context.Context, symbol string,
+	filter market.FetchLogFilter) ([]market.PriceFetchLog, error) {
+	r.logFilter = filter
+	var logs []market.PriceFetchLog
+	for i := len(r.logs) - 1; i >= 0 && (filter.Limit == 0 || len(logs) < filter.Limit); i-- {
+		if l := r.logs[i]; l.Symbol == symbol && (!filter.FailedOnly || !l.Success) {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (r *marketRepository) GetStockPrice(_ context.Context, symbol string,
+	interval yahoo.IntervalAPI) (*market.StockPrices, error) {
+	prices := market.StockPrices{}
+	if interval == yahoo.Interval1d {
+		prices = append(prices, r.prices[symbol]...)
+	}
+	return &prices, nil
+}
+
+func (r *marketRepository) GetQuotes(_ context.Context, symbols []string) ([]market.Quote, error) {
+	var quotes []market.Quote
+	for _, symbol := range symbols {
+		if q, ok := r.quotes[symbol]; ok {
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+func (r *marketRepository) GetLastFetchEnd(_ context.Context, symbol string) (*time.Time, error) {
+	var end *time.Time
+	for _, l := range r.logs {
+		if l.Symbol == symbol && l.Success && (end == nil || l.RangeEnd.After(*end)) {
+			end = l.RangeEnd
+		}
+	}
+	return end, nil
+}
+
+// barProvider serves a daily bar at the close price of the symbols it knows
+type barProvider map[string]float64
+
+func (p barProvider) GetMarketData(_ context.Context, symbol string, _ yahoo.IntervalAPI,
+	_ yahoo.PeriodAPI) (*yahoo.MarketData, error) {
+	price, ok := p[symbol]
+	if !ok {
+		return nil, yahoo.ErrUnknownSymbol
+	}
+	return &yahoo.MarketData{
+		Symbol: symbol,
+		Name:   symbol + " Inc.",
+		Prices: []yahoo.StockPrice{{
+			Time:  time.Now().Truncate(24 * time.Hour),
+			Open:  price,
+			High:  price,
+			Low:   price,
+			Close: price,
+		}},
+	}, nil
+}
+
+// jobRepository keeps jobs in memory
+type jobRepository struct {
+	jobs.Repository
+	jobs []jobs.Job
+	// filter is the filter of the last listing
+	filter jobs.Filter
+}
+
+func (r *jobRepository) CreateJob(_ context.Context, job *jobs.Job) error {
+	job.ID = int64(len(r.jobs) + 1)
+	job.Status = jobs.StatusQueued
+	job.RunAt = time.Now()
+	job.CreatedAt = job.RunAt
+	r.jobs = append(r.jobs, *job)
+	return nil
+}
+
+func (r *jobRepository) GetJob(_ context.Context, id int64) (*jobs.Job, error) {
+	if id < 1 || id > int64(len(r.jobs)) {
+		return nil, jobs.ErrJobNotFound
+	}
+	job := r.jobs[id-1]
+	return &job, nil
+}
+
+// ListJobs lists the jobs latest first, only the status and symbol filter
+func (r *jobRepository) ListJobs(_ context.Context, filter jobs.Filter) ([]jobs.Job, error) {
+	r.filter = filter
+	var list []jobs.Job
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		if j := r.jobs[i]; (filter.Status == "" || j.Status == filter.Status) &&
+			(filter.Symbol == "" || j.Symbol == filter.Symbol) {
+			list = append(list, j)
+		}
+	}
+	return list, nil
+}
+
+func (r *jobRepository) RequeueJob(_ context.Context, id int64) (*jobs.Job, error) {
+	if id < 1 || id > int64(len(r.jobs)) {
+		return nil, jobs.ErrJobNotFound
+	}
+	job := &r.jobs[id-1]
+	if job.Status != jobs.StatusDead {
+		return nil, jobs.ErrJobNotDead
+	}
+	job.Status, job.Attempts, job.RunAt, job.FinishedAt = jobs.StatusQueued, 0, time.Now(), nil
+	requeued := *job
+	return &requeued, nil
+}
+
+// trackingRepository keeps tracked symbols in memory
+type trackingRepository struct {
+	tracking.Repository
+	tracked map[string]tracking.TrackedSymbol
+}
+
+func newTrackingRepository(tracked ...tracking.TrackedSymbol) *trackingRepository {
+	r := &trackingRepository{tracked: make(map[string]tracking.TrackedSymbol)}
+	for _, t := range tracked {
+		t.ID = len(r.tracked) + 1
+		r.tracked[t.Symbol] = t
+	}
+	return r
+}
+
+func (r *trackingRepository) GetTrackedSymbol(_ context.Context, symbol string) (*tracking.TrackedSymbol, error) {
+	t, ok := r.tracked[symbol]
+	if !ok {
+		return nil, tracking.ErrTrackedSymbolNotFound
+	}
+	return &t, nil
+}
+
+// ListTrackedSymbols lists the tracked symbols ordered by symbol, optionally only the active ones
+func (r *trackingRepository) ListTrackedSymbols(_ context.Context, activeOnly bool) ([]tracking.TrackedSymbol,
+	error) {
+	var list []tracking.TrackedSymbol
+	for _, t := range r.tracked {
+		if t.Active || !activeOnly {
+			list = append(list, t)
+		}
+	}
+	slices.SortFunc(list, func(a, b tracking.TrackedSymbol) int { return strings.Compare(a.Symbol, b.Symbol) })
+	return list, nil
+}
+
+func (r *trackingRepository) CreateTrackedSymbol(_ context.Context, t *tracking.TrackedSymbol) error {
+	if _, ok := r.tracked[t.Symbol]; ok {
+		return tracking.ErrTrackedSymbolExists
+	}
+	t.ID = len(r.tracked) + 1
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	r.tracked[t.Symbol] = *t
+	return nil
+}
+
+func (r *trackingRepository) UpdateTrackedSymbol(_ context.Context, t *tracking.TrackedSymbol) error {
+	t.UpdatedAt = time.Now()
+	r.tracked[t.Symbol] = *t
+	return nil
+}
+
+func (r *trackingRepository) DeleteTrackedSymbol(_ context.Context, symbol string) error {
+	if _, ok := r.tracked[symbol]; !ok {
+		return tracking.ErrTrackedSymbolNotFound
+	}
+	delete(r.tracked, symbol)
+	return nil
+}
+
+// newTrackingService tracks symbols daily with the fetcher as the Yahoo provider
+func newTrackingService(repo tracking.Repository, fetcher tracking.Fetcher) *tracking.TrackingService {
+	return tracking.NewTrackingService(repo, yahoo.Provider, map[string]tracking.Fetcher{yahoo.Provider: fetcher},
+		tracking.Policy{Intervals: []string{string(yahoo.Interval1d)}, HistoryDays: 30, RefreshMinutes: 60})
+}
+
+// newQueue creates a queue of the jobs of the repository handling backfills and refreshes
+func newQueue(repo jobs.Repository) *jobs.Queue {
+	queue := jobs.NewQueue(repo, jobs.Settings{})
+	noop := func(context.Context, *jobs.Job) error { return nil }
+	queue.RegisterHandler(jobs.TypeBackfill, noop)
+	queue.RegisterHandler(jobs.TypeRefresh, noop)
+	return queue
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rs/zerolog/log"
+)
+
+type RegisterSymbolRequest struct {
+	Symbol string `json:"symbol" binding:"required"`
+}
+
+type RegisteredSymbol struct {
+	Symbol    string    `json:"symbol"`
+	Name      string    `json:"name"`
+	Exchange  string    `json:"exchange"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type RegisterSymbolResponse struct {
+	Symbol  RegisteredSymbol `json:"symbol"`
+	Job     Job              `json:"job"`
+	Tracked *TrackedSymbol   `json:"tracked"`
+}
+
+type FetchLog struct {
+	ID              int        `json:"id"`
+	FetchedAt       time.Time  `json:"fetchedAt"`
+	Provider        *string    `json:"provider"`
+	Interval        *string    `json:"interval"`
+	From            *time.Time `json:"from"`
+	To              *time.Time `json:"to"`
+	DataPoints      *int       `json:"dataPoints"`
+	RowsInserted    *int       `json:"rowsInserted"`
+	RowsUpdated     *int       `json:"rowsUpdated"`
+	RowsQuarantined *int       `json:"rowsQuarantined"`
+	DurationMs      *int       `json:"durationMs"`
+	HTTPStatus      *int       `json:"httpStatus"`
+	Retries         *int       `json:"retries"`
+	Success         bool       `json:"success"`
+	Error           *string    `json:"error"`
+}
+
+type FetchLogsResponse struct {
+	Symbol  string     `json:"symbol"`
+	Fetches []FetchLog `json:"fetches"`
+}
+
+func buildFetchLogs(logs []market.PriceFetchLog) []FetchLog {
+	fetches := make([]FetchLog, 0, len(logs))
+	for _, l := range logs {
+		fetches = append(fetches, FetchLog{
+			ID:              l.ID,
+			FetchedAt:       l.FetchedAt,
+			Provider:        l.Provider,
+			Interval:        l.Interval,
+			From:            l.RangeStart,
+			To:              l.RangeEnd,
+			DataPoints:      l.DataPoints,
+			RowsInserted:    l.RowsInserted,
+			RowsUpdated:     l.RowsUpdated,
+			RowsQuarantined: l.RowsQuarantined,
+			DurationMs:      l.DurationMs,
+			HTTPStatus:      l.HTTPStatus,
+			Retries:         l.Retries,
+			Success:         l.Success,
+			Error:           l.ErrorMsg,
+		})
+	}
+	return fetches
+}
+
+// SymbolController handles the registration of new symbols
+type SymbolController struct {
+	service *market.MarketService
+	queue   *jobs.Queue
+	// tracking is nil when registered symbols are not tracked
+	tracking *tracking.TrackingService
+}
+
+// NewSymbolController creates a new symbol controller
+func NewSymbolController(service *market.MarketService, queue *jobs.Queue) *SymbolController {
+	return &SymbolController{
+		service: service,
+		queue:   queue,
+	}
+}
+
+// SetTracking tracks registered symbols with the default fetch policy, so the scheduler keeps refreshing them
+func (c *SymbolController) SetTracking(trackingSvc *tracking.TrackingService) {
+	c.tracking = trackingSvc
+}
+
+// RegisterRoutes registers the routes for the symbol controller
+func (c *SymbolController) RegisterRoutes(router *gin.Engine) {
+	router.POST("/symbols", c.registerSymbol)
+	router.POST("/symbols/:symbol/refresh", c.refreshSymbol)
+	router.GET("/symbols/:symbol/fetches", c.listFetches)
+}
+
+// registerSymbol validates a symbol against the provider, stores it, tracks it with the default fetch policy
+// and enqueues the backfill of its history. The response links the backfill job, whose state is polled at
+// /jobs/{id}.
+func (c *SymbolController) registerSymbol(ctx *gin.Context) {
+	var request RegisterSymbolRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(request.Symbol))
+	if symbol == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Symbol is required"})
+		return
+	}
+
+	registered, err := c.service.RegisterSymbol(ctx, symbol)
+	if err != nil {
+		switch {
+		case errors.Is(err, market.ErrSymbolExists):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Symbol already exists"})
+		case errors.Is(err, market.ErrUnknownSymbol):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Symbol is unknown to the provider"})
+		default:
+			log.Error().Err(err).Str("symbol", symbol).Msg("Failed to register symbol")
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "Error validating symbol with provider"})
+		}
+		return
+	}
+
+	// providers may normalize the symbol, its bars are stored under the registered one
+	symbol = registered.Symbol
+	tracked := c.track(ctx, symbol)
+
+	job, err := c.queue.Enqueue(ctx, jobs.TypeBackfill, symbol, jobs.PriorityHigh)
+	if err != nil {
+		// the symbol stays registered, its first fetch downloads the history
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to enqueue backfill")
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Symbol registered but backfill could not be enqueued"})
+		return
+	}
+
+	ctx.Header("Location", jobLocation(job.ID))
+	ctx.JSON(http.StatusAccepted, RegisterSymbolResponse{
+		Symbol: RegisteredSymbol{
+			Symbol:    registered.Symbol,
+			Name:      registered.Name,
+			Exchange:  registered.Exchange,
+			CreatedAt: registered.CreatedAt,
+		},
+		Job:     buildJob(job),
+		Tracked: tracked,
+	})
+}
+
+// track tracks a registered symbol with the default fetch policy, keeping the policy of a symbol already
+// tracked. Returns nil when tracking is disabled or fails, the symbol stays registered either way.
+func (c *SymbolController) track(ctx *gin.Context, symbol string) *TrackedSymbol {
+	if c.tracking == nil {
+		return nil
+	}
+	t := &tracking.TrackedSymbol{Symbol: symbol, Active: true}
+	err := c.tracking.Track(ctx, t)
+	if errors.Is(err, tracking.ErrTrackedSymbolExists) {
+		t, err = c.tracking.GetTrackedSymbol(ctx, symbol)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to track registered symbol")
+		return nil
+	}
+	tracked := buildTrackedSymbol(t)
+	return &tracked
+}
+
+// refreshSymbol fetches a stored symbol on demand, by default the window of its next scheduled fetch. The
+// optional interval and from/to query parameters select another window. With async=true the fetch of the
+// next window is enqueued instead and the response links the job.
+func (c *SymbolController) refreshSymbol(ctx *gin.Context) {
+	symbol := strings.ToUpper(ctx.Param("symbol"))
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	interval := yahoo.IntervalAPI(ctx.Query("interval"))
+	if interval != "" && !slices.Contains(yahoo.Intervals, interval) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval"})
+		return
+	}
+
+	if ctx.Query("async") == "true" {
+		if interval != "" || !from.IsZero() || !to.IsZero() {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Refreshes of a custom window cannot run asynchronously"})
+			return
+		}
+		c.enqueueRefresh(ctx, symbol)
+		return
+	}
+
+	logs, err := c.service.Refresh(ctx, symbol, market.RefreshRequest{Interval: interval, From: from, To: to})
+	switch {
+	case errors.Is(err, market.ErrSymbolNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Symbol not found"})
+	case errors.Is(err, market.ErrInvalidRefresh):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Refresh window is empty"})
+	case err != nil && len(logs) == 0:
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to refresh symbol")
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Error refreshing symbol"})
+	case err != nil:
+		// the fetch log entries tell which requests failed
+		log.Error().Err(err).Str("symbol", symbol).Msg("Symbol partially refreshed")
+		ctx.JSON(http.StatusBadGateway, FetchLogsResponse{Symbol: symbol, Fetches: buildFetchLogs(logs)})
+	default:
+		ctx.JSON(http.StatusOK, FetchLogsResponse{Symbol: symbol, Fetches: buildFetchLogs(logs)})
+	}
+}
+
+func (c *SymbolController) enqueueRefresh(ctx *gin.Context, symbol string) {
+	if _, err := c.service.GetSymbol(ctx, symbol); err != nil {
+		if errors.Is(err, market.ErrSymbolNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Symbol not found"})
+			return
+		}
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to get symbol")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving symbol"})
+		return
+	}
+
+	job, err := c.queue.Enqueue(ctx, jobs.TypeRefresh, symbol, jobs.PriorityHigh)
+	if err != nil {
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to enqueue refresh")
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Refresh could not be enqueued"})
+		return
+	}
+	ctx.Header("Location", jobLocation(job.ID))
+	ctx.JSON(http.StatusAccepted, buildJob(job))
+}
+
+// listFetches lists the latest fetch log entries of a symbol, optionally only the failed ones
+// (failed=true) or those logged within from/to
+func (c *SymbolController) listFetches(ctx *gin.Context) {
+	symbol := strings.ToUpper(ctx.Param("symbol"))
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	failedOnly := false
+	if value := ctx.Query("failed"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid failed parameter"})
+			return
+		}
+		failedOnly = parsed
+	}
+	limit := 100
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	logs, err := c.service.ListFetchLogs(ctx, symbol, market.FetchLogFilter{
+		FailedOnly: failedOnly,
+		From:       from,
+		To:         to,
+		Limit:      limit,
+	})
+	if err != nil {
+		if errors.Is(err, market.ErrSymbolNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Symbol not found"})
+			return
+		}
+		log.Error().Err(err).Str("symbol", symbol).Msg("Failed to list fetches")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving fetches"})
+		return
+	}
+	ctx.JSON(http.StatusOK, FetchLogsResponse{Symbol: symbol, Fetches: buildFetchLogs(logs)})
+}
@@ -0,0 +1,191 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/jobs"
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolController_Register(t *testing.T) {
+	repo := newMarketRepository("MSFT")
+	service := market.NewMarketService(repo, barProvider{"AAPL": 200, "GOOG": 170, "MSFT": 450})
+	jobRepo := &jobRepository{}
+	// GOOG is tracked hourly before it is registered
+	trackingRepo := newTrackingRepository(tracking.TrackedSymbol{Symbol: "GOOG", Intervals: []string{"1h"},
+		HistoryDays: 7, RefreshMinutes: 15, Active: true})
+	controller := api.NewSymbolController(service, newQueue(jobRepo))
+	controller.SetTracking(newTrackingService(trackingRepo, service))
+	router := newRouter(controller)
+
+	t.Run("Register a symbol", func(t *testing.T) {
+		w := serve(t, router, http.MethodPost, "/symbols", `{"symbol": " aapl "}`)
+		response := decode[api.RegisterSymbolResponse](t, w, http.StatusAccepted)
+		assert.Equal(t, "AAPL", response.Symbol.Symbol)
+		assert.Equal(t, "AAPL Inc.", response.Symbol.Name)
+		assert.Contains(t, repo.symbols, "AAPL")
+
+		// the backfill of the history is enqueued
+		assert.Equal(t, jobs.TypeBackfill, response.Job.Type)
+		assert.Equal(t, "AAPL", response.Job.Symbol)
+		assert.Equal(t, fmt.Sprintf("/jobs/%d", response.Job.ID), w.Header().Get("Location"))
+		require.Len(t, jobRepo.jobs, 1)
+
+		// registered symbols are refreshed by the scheduler with the default policy
+		require.NotNil(t, response.Tracked)
+		assert.Equal(t, []string{string(yahoo.Interval1d)}, response.Tracked.Intervals)
+		assert.Equal(t, 60, response.Tracked.RefreshMinutes)
+		assert.True(t, response.Tracked.Active)
+		assert.Contains(t, trackingRepo.tracked, "AAPL")
+	})
+
+	t.Run("Register a tracked symbol", func(t *testing.T) {
+		response := decode[api.RegisterSymbolResponse](t, serve(t, router, http.MethodPost, "/symbols",
+			`{"symbol": "GOOG"}`), http.StatusAccepted)
+		require.NotNil(t, response.Tracked)
+		assert.Equal(t, []string{"1h"}, response.Tracked.Intervals, "the policy of tracked symbols is kept")
+		assert.Equal(t, 15, response.Tracked.RefreshMinutes)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Register a stored symbol", http.MethodPost, "/symbols", `{"symbol": "MSFT"}`, http.StatusConflict},
+		{"Register a symbol unknown to the provider", http.MethodPost, "/symbols", `{"symbol": "NOPE"}`, http.StatusUnprocessableEntity},
+		{"Register without symbol", http.MethodPost, "/symbols", `{}`, http.StatusBadRequest},
+		{"Register a blank symbol", http.MethodPost, "/symbols", `{"symbol": " "}`, http.StatusBadRequest},
+	})
+	assert.Len(t, jobRepo.jobs, 2, "rejected symbols are not backfilled")
+}
+
+func TestSymbolController_Refresh(t *testing.T) {
+	repo := newMarketRepository("AAPL", "DLST")
+	jobRepo := &jobRepository{}
+	router := newRouter(api.NewSymbolController(market.NewMarketService(repo, barProvider{"AAPL": 200}),
+		newQueue(jobRepo)))
+
+	t.Run("Refresh a symbol", func(t *testing.T) {
+		response := decode[api.FetchLogsResponse](t, serve(t, router, http.MethodPost, "/symbols/aapl/refresh", ""),
+			http.StatusOK)
+		assert.Equal(t, "AAPL", response.Symbol)
+		require.Len(t, response.Fetches, 1)
+		fetch := response.Fetches[0]
+		assert.True(t, fetch.Success)
+		assert.Equal(t, string(yahoo.Interval1d), *fetch.Interval)
+		assert.Equal(t, 1, *fetch.DataPoints)
+		assert.Equal(t, 1, *fetch.RowsInserted)
+		assert.Nil(t, fetch.Error)
+	})
+
+	t.Run("Refresh a symbol the provider fails for", func(t *testing.T) {
+		// the fetch log entries written are returned with the failure
+		response := decode[api.FetchLogsResponse](t, serve(t, router, http.MethodPost, "/symbols/DLST/refresh", ""),
+			http.StatusBadGateway)
+		require.Len(t, response.Fetches, 1)
+		assert.False(t, response.Fetches[0].Success)
+		assert.Contains(t, *response.Fetches[0].Error, "unknown symbol")
+	})
+
+	t.Run("Refresh a symbol asynchronously", func(t *testing.T) {
+		w := serve(t, router, http.MethodPost, "/symbols/AAPL/refresh?async=true", "")
+		response := decode[api.Job](t, w, http.StatusAccepted)
+		assert.Equal(t, jobs.TypeRefresh, response.Type)
+		assert.Equal(t, "AAPL", response.Symbol)
+		assert.Equal(t, fmt.Sprintf("/jobs/%d", response.ID), w.Header().Get("Location"))
+		require.Len(t, jobRepo.jobs, 1)
+		assert.Equal(t, jobs.PriorityHigh, jobRepo.jobs[0].Priority)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Refresh an unknown symbol", http.MethodPost, "/symbols/NOPE/refresh", "", http.StatusNotFound},
+		{"Refresh an unknown symbol asynchronously", http.MethodPost, "/symbols/NOPE/refresh?async=true", "", http.StatusNotFound},
+		{"Refresh an empty window", http.MethodPost, "/symbols/AAPL/refresh?from=2025-01-02&to=2025-01-01", "", http.StatusBadRequest},
+		{"Refresh with an invalid interval", http.MethodPost, "/symbols/AAPL/refresh?interval=2d", "", http.StatusBadRequest},
+		{"Refresh with an invalid from", http.MethodPost, "/symbols/AAPL/refresh?from=yesterday", "", http.StatusBadRequest},
+		{"Refresh an interval asynchronously", http.MethodPost, "/symbols/AAPL/refresh?async=true&interval=5m", "", http.StatusBadRequest},
+		{"Refresh a window asynchronously", http.MethodPost, "/symbols/AAPL/refresh?async=true&from=2025-01-01", "", http.StatusBadRequest},
+	})
+}
+
+func TestSymbolController_ListFetches(t *testing.T) {
+	repo := newMarketRepository("AAPL")
+	fetchedAt := time.Date(2025, time.June, 2, 14, 0, 0, 0, time.UTC)
+	for i, success := range []bool{true, false, true} {
+		entry := &market.PriceFetchLog{
+			Symbol:       "AAPL",
+			FetchedAt:    fetchedAt.Add(time.Duration(i) * time.Hour),
+			Success:      success,
+			Provider:     tests.Ptr(yahoo.Provider),
+			Interval:     tests.Ptr(string(yahoo.Interval5m)),
+			RangeStart:   tests.Ptr(fetchedAt.Add(time.Duration(i-1) * time.Hour)),
+			RangeEnd:     tests.Ptr(fetchedAt.Add(time.Duration(i) * time.Hour)),
+			DataPoints:   tests.Ptr(12),
+			RowsInserted: tests.Ptr(12),
+			HTTPStatus:   tests.Ptr(http.StatusOK),
+		}
+		if !success {
+			entry.HTTPStatus = tests.Ptr(http.StatusTooManyRequests)
+			entry.ErrorMsg = tests.Ptr("rate limited")
+		}
+		_, err := repo.SavePriceFetchLog(context.TODO(), entry)
+		require.NoError(t, err)
+	}
+	// failed fetches of symbols never stored are logged too
+	_, err := repo.SavePriceFetchLog(context.TODO(), &market.PriceFetchLog{Symbol: "NOPE1", FetchedAt: fetchedAt,
+		ErrorMsg: tests.Ptr("unknown symbol")})
+	require.NoError(t, err)
+	router := newRouter(api.NewSymbolController(market.NewMarketService(repo, nil), nil))
+
+	t.Run("List fetches", func(t *testing.T) {
+		response := decode[api.FetchLogsResponse](t, serve(t, router, http.MethodGet, "/symbols/aapl/fetches", ""),
+			http.StatusOK)
+		assert.Equal(t, "AAPL", response.Symbol)
+		require.Len(t, response.Fetches, 3)
+		assert.Equal(t, 3, response.Fetches[0].ID, "latest first")
+		assert.Equal(t, yahoo.Provider, *response.Fetches[0].Provider)
+		assert.Equal(t, string(yahoo.Interval5m), *response.Fetches[0].Interval)
+		assert.True(t, fetchedAt.Add(time.Hour).Equal(*response.Fetches[0].From))
+		assert.True(t, fetchedAt.Add(2*time.Hour).Equal(*response.Fetches[0].To))
+		assert.Equal(t, 12, *response.Fetches[0].RowsInserted)
+		assert.Equal(t, 100, repo.logFilter.Limit, "default limit")
+	})
+
+	t.Run("List failed fetches", func(t *testing.T) {
+		response := decode[api.FetchLogsResponse](t, serve(t, router, http.MethodGet,
+			"/symbols/AAPL/fetches?failed=true&from=2025-06-02&to=2025-06-03T00:00:00Z&limit=10", ""), http.StatusOK)
+		require.Len(t, response.Fetches, 1)
+		assert.False(t, response.Fetches[0].Success)
+		assert.Equal(t, http.StatusTooManyRequests, *response.Fetches[0].HTTPStatus)
+		assert.Equal(t, "rate limited", *response.Fetches[0].Error)
+		assert.Equal(t, market.FetchLogFilter{
+			FailedOnly: true,
+			From:       time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2025, time.June, 3, 0, 0, 0, 0, time.UTC),
+			Limit:      10,
+		}, repo.logFilter)
+	})
+
+	t.Run("List fetches of a symbol never stored", func(t *testing.T) {
+		response := decode[api.FetchLogsResponse](t, serve(t, router, http.MethodGet, "/symbols/NOPE1/fetches", ""),
+			http.StatusOK)
+		require.Len(t, response.Fetches, 1)
+		assert.Equal(t, "unknown symbol", *response.Fetches[0].Error)
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"List fetches of an unknown symbol", http.MethodGet, "/symbols/NOPE/fetches", "", http.StatusNotFound},
+		{"List fetches with a zero limit", http.MethodGet, "/symbols/AAPL/fetches?limit=0", "", http.StatusBadRequest},
+		{"List fetches with a limit above 1000", http.MethodGet, "/symbols/AAPL/fetches?limit=1001", "", http.StatusBadRequest},
+		{"List fetches with an invalid limit", http.MethodGet, "/symbols/AAPL/fetches?limit=ten", "", http.StatusBadRequest},
+		{"List fetches with an invalid failed flag", http.MethodGet, "/symbols/AAPL/fetches?failed=maybe", "", http.StatusBadRequest},
+		{"List fetches with an invalid to", http.MethodGet, "/symbols/AAPL/fetches?to=tomorrow", "", http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/rs/zerolog/log"
+)
+
+type TrackedSymbol struct {
+	Symbol            string               `json:"symbol"`
+	Intervals         []string             `json:"intervals"`
+	HistoryDays       int                  `json:"historyDays"`
+	RefreshMinutes    int                  `json:"refreshMinutes"`
+	Provider          string               `json:"provider"`
+	Active            bool                 `json:"active"`
+	LastAttemptAt     *time.Time           `json:"lastAttemptAt"`
+	LastSuccessAt     *time.Time           `json:"lastSuccessAt"`
+	IntervalSuccessAt map[string]time.Time `json:"intervalSuccessAt"`
+	LastError         *string              `json:"lastError"`
+	CreatedAt         time.Time            `json:"createdAt"`
+	UpdatedAt         time.Time            `json:"updatedAt"`
+}
+
+// TrackedSymbolRequest creates or updates a tracked symbol, omitted fields keep their default or current value
+type TrackedSymbolRequest struct {
+	Symbol         string   `json:"symbol"`
+	Intervals      []string `json:"intervals"`
+	HistoryDays    *int     `json:"historyDays"`
+	RefreshMinutes *int     `json:"refreshMinutes"`
+	Provider       *string  `json:"provider"`
+	Active         *bool    `json:"active"`
+}
+
+func buildTrackedSymbol(t *tracking.TrackedSymbol) TrackedSymbol {
+	return TrackedSymbol{
+		Symbol:            t.Symbol,
+		Intervals:         t.Intervals,
+		HistoryDays:       t.HistoryDays,
+		RefreshMinutes:    t.RefreshMinutes,
+		Provider:          t.Provider,
+		Active:            t.Active,
+		LastAttemptAt:     t.LastAttemptAt,
+		LastSuccessAt:     t.LastSuccessAt,
+		IntervalSuccessAt: t.IntervalSuccessAt,
+		LastError:         t.LastError,
+		CreatedAt:         t.CreatedAt,
+		UpdatedAt:         t.UpdatedAt,
+	}
+}
+
+// TrackingController handles the endpoints managing the tracked symbols
+type TrackingController struct {
+	service *tracking.TrackingService
+}
+
+// NewTrackingController creates a new tracking controller
+func NewTrackingController(service *tracking.TrackingService) *TrackingController {
+	return &TrackingController{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the routes for the tracking controller
+func (c *TrackingController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/tracked-symbols", c.listTrackedSymbols)
+	router.POST("/tracked-symbols", c.trackSymbol)
+	router.GET("/tracked-symbols/:symbol", c.getTrackedSymbol)
+	router.PATCH("/tracked-symbols/:symbol", c.updateTrackedSymbol)
+	router.DELETE("/tracked-symbols/:symbol", c.untrackSymbol)
+}
+
+// listTrackedSymbols returns the tracked symbols, only the active ones with ?active=true
+func (c *TrackingController) listTrackedSymbols(ctx *gin.Context) {
+	tracked, err := c.service.ListTrackedSymbols(ctx, ctx.Query("active") == "true")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list tracked symbols")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving tracked symbols"})
+		return
+	}
+
+	response := make([]TrackedSymbol, 0, len(tracked))
+	for i := range tracked {
+		response = append(response, buildTrackedSymbol(&tracked[i]))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// trackSymbol starts tracking a symbol, active unless the request says otherwise
+func (c *TrackingController) trackSymbol(ctx *gin.Context) {
+	var request TrackedSymbolRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	tracked := &tracking.TrackedSymbol{
+		Symbol:    request.Symbol,
+		Intervals: request.Intervals,
+		Active:    request.Active == nil || *request.Active,
+	}
+	if request.HistoryDays != nil {
+		tracked.HistoryDays = *request.HistoryDays
+	}
+	if request.RefreshMinutes != nil {
+		tracked.RefreshMinutes = *request.RefreshMinutes
+	}
+	if request.Provider != nil {
+		tracked.Provider = *request.Provider
+	}
+
+	if err := c.service.Track(ctx, tracked); err != nil {
+		c.writeError(ctx, err, "Error tracking symbol")
+		return
+	}
+	ctx.JSON(http.StatusCreated, buildTrackedSymbol(tracked))
+}
+
+func (c *TrackingController) getTrackedSymbol(ctx *gin.Context) {
+	tracked, err := c.service.GetTrackedSymbol(ctx, ctx.Param("symbol"))
+	if err != nil {
+		c.writeError(ctx, err, "Error retrieving tracked symbol")
+		return
+	}
+	ctx.JSON(http.StatusOK, buildTrackedSymbol(tracked))
+}
+
+// updateTrackedSymbol changes the fields of the fetch policy present in the request, the symbol itself
+// cannot be changed
+func (c *TrackingController) updateTrackedSymbol(ctx *gin.Context) {
+	var request TrackedSymbolRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	tracked, err := c.service.UpdateTrackedSymbol(ctx, ctx.Param("symbol"), tracking.Update{
+		Intervals:      request.Intervals,
+		HistoryDays:    request.HistoryDays,
+		RefreshMinutes: request.RefreshMinutes,
+		Provider:       request.Provider,
+		Active:         request.Active,
+	})
+	if err != nil {
+		c.writeError(ctx, err, "Error updating tracked symbol")
+		return
+	}
+	ctx.JSON(http.StatusOK, buildTrackedSymbol(tracked))
+}
+
+// untrackSymbol stops refreshing a symbol, its stored data is kept
+func (c *TrackingController) untrackSymbol(ctx *gin.Context) {
+	if err := c.service.Untrack(ctx, ctx.Param("symbol")); err != nil {
+		c.writeError(ctx, err, "Error untracking symbol")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *TrackingController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, tracking.ErrTrackedSymbolNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tracked symbol not found"})
+	case errors.Is(err, tracking.ErrTrackedSymbolExists):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Symbol is already tracked"})
+	case errors.Is(err, tracking.ErrInvalidPolicy):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "providers": c.service.Providers()})
+	default:
+		log.Error().Err(err).Str("symbol", ctx.Param("symbol")).Msg(msg)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/market"
+	"github.com/market-data/internal/domain/tracking"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackingController(t *testing.T) {
+	refreshedAt := time.Date(2025, time.June, 13, 14, 0, 0, 0, time.UTC)
+	repo := newTrackingRepository(
+		tracking.TrackedSymbol{Symbol: "AAPL", Intervals: []string{"1d", "5m"}, HistoryDays: 365, RefreshMinutes: 15,
+			Active: true, LastAttemptAt: &refreshedAt, LastError: tests.Ptr("interval 5m: provider unavailable"),
+			IntervalSuccessAt: map[string]time.Time{"1d": refreshedAt}},
+		tracking.TrackedSymbol{Symbol: "MSFT", Intervals: []string{"1d"}, HistoryDays: 30, RefreshMinutes: 60},
+	)
+	router := newRouter(api.NewTrackingController(newTrackingService(repo,
+		market.NewMarketService(newMarketRepository(), barProvider{}))))
+
+	t.Run("List tracked symbols", func(t *testing.T) {
+		list := decode[[]api.TrackedSymbol](t, serve(t, router, http.MethodGet, "/tracked-symbols", ""), http.StatusOK)
+		require.Len(t, list, 2)
+		assert.Equal(t, "AAPL", list[0].Symbol)
+		assert.Equal(t, "MSFT", list[1].Symbol)
+
+		active := decode[[]api.TrackedSymbol](t, serve(t, router, http.MethodGet, "/tracked-symbols?active=true", ""),
+			http.StatusOK)
+		require.Len(t, active, 1)
+		assert.Equal(t, "AAPL", active[0].Symbol)
+	})
+
+	t.Run("Get a tracked symbol", func(t *testing.T) {
+		tracked := decode[api.TrackedSymbol](t, serve(t, router, http.MethodGet, "/tracked-symbols/AAPL", ""),
+			http.StatusOK)
+		assert.Equal(t, []string{"1d", "5m"}, tracked.Intervals)
+		assert.Equal(t, 365, tracked.HistoryDays)
+		assert.Equal(t, 15, tracked.RefreshMinutes)
+		assert.True(t, tracked.Active)
+		assert.True(t, refreshedAt.Equal(*tracked.LastAttemptAt))
+		assert.Nil(t, tracked.LastSuccessAt, "an interval failed")
+		require.Len(t, tracked.IntervalSuccessAt, 1)
+		assert.True(t, refreshedAt.Equal(tracked.IntervalSuccessAt["1d"]))
+		assert.Equal(t, "interval 5m: provider unavailable", *tracked.LastError)
+	})
+
+	t.Run("Track a symbol", func(t *testing.T) {
+		tracked := decode[api.TrackedSymbol](t, serve(t, router, http.MethodPost, "/tracked-symbols",
+			`{"symbol": " nvda ", "intervals": ["1h", "1h"], "historyDays": 7}`), http.StatusCreated)
+		assert.Equal(t, "NVDA", tracked.Symbol)
+		assert.Equal(t, []string{"1h"}, tracked.Intervals)
+		assert.Equal(t, 7, tracked.HistoryDays)
+		assert.Equal(t, 60, tracked.RefreshMinutes, "omitted fields take the defaults")
+		assert.True(t, tracked.Active)
+		assert.Contains(t, repo.tracked, "NVDA")
+	})
+
+	t.Run("Update a tracked symbol", func(t *testing.T) {
+		tracked := decode[api.TrackedSymbol](t, serve(t, router, http.MethodPatch, "/tracked-symbols/MSFT",
+			`{"active": true, "refreshMinutes": 5}`), http.StatusOK)
+		assert.True(t, tracked.Active)
+		assert.Equal(t, 5, tracked.RefreshMinutes)
+		assert.Equal(t, []string{"1d"}, tracked.Intervals, "omitted fields are kept")
+		assert.True(t, repo.tracked["MSFT"].Active)
+	})
+
+	t.Run("Untrack a symbol", func(t *testing.T) {
+		w := serve(t, router, http.MethodDelete, "/tracked-symbols/NVDA", "")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.NotContains(t, repo.tracked, "NVDA")
+	})
+
+	t.Run("Track with an unknown provider", func(t *testing.T) {
+		response := decode[struct {
+			Providers []string `json:"providers"`
+		}](t, serve(t, router, http.MethodPost, "/tracked-symbols", `{"symbol": "ETHUSDT", "provider": "binance"}`),
+			http.StatusBadRequest)
+		assert.Equal(t, []string{yahoo.Provider}, response.Providers, "the known providers are listed")
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Get an untracked symbol", http.MethodGet, "/tracked-symbols/NVDA", "", http.StatusNotFound},
+		{"Update an untracked symbol", http.MethodPatch, "/tracked-symbols/NVDA", `{"active": false}`, http.StatusNotFound},
+		{"Untrack an untracked symbol", http.MethodDelete, "/tracked-symbols/NVDA", "", http.StatusNotFound},
+		{"Track a tracked symbol", http.MethodPost, "/tracked-symbols", `{"symbol": "AAPL"}`, http.StatusConflict},
+		{"Track without symbol", http.MethodPost, "/tracked-symbols", `{"intervals": ["1d"]}`, http.StatusBadRequest},
+		{"Track an unsupported interval", http.MethodPost, "/tracked-symbols", `{"symbol": "NVDA", "intervals": ["2m"]}`,
+			http.StatusBadRequest},
+		{"Track with a negative history", http.MethodPost, "/tracked-symbols", `{"symbol": "NVDA", "historyDays": -1}`,
+			http.StatusBadRequest},
+		{"Update with a zero refresh interval", http.MethodPatch, "/tracked-symbols/AAPL", `{"refreshMinutes": 0}`,
+			http.StatusBadRequest},
+		{"Track with an invalid body", http.MethodPost, "/tracked-symbols", `{"symbol": 1}`, http.StatusBadRequest},
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/rs/zerolog/log"
+)
+
+// maxUsageDays limits the number of days of a usage request
+const maxUsageDays = 366
+
+type UsageCounters struct {
+	Requests int64 `json:"requests"`
+	Retries  int64 `json:"retries"`
+	Failures int64 `json:"failures"`
+	Bytes    int64 `json:"bytes"`
+}
+
+type SymbolUsage struct {
+	Symbol string `json:"symbol"`
+	UsageCounters
+}
+
+type ProviderUsage struct {
+	Provider string `json:"provider"`
+	Day      string `json:"day"`
+	UsageCounters
+	DailyCap *int64        `json:"dailyCap"`
+	Paused   bool          `json:"paused"` // the cap is reached and scheduled fetches are paused, only set for today
+	Symbols  []SymbolUsage `json:"symbols,omitempty"`
+}
+
+type UsageReport struct {
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Usage []ProviderUsage `json:"usage"`
+}
+
+func buildUsageCounters(c usage.Counters) UsageCounters {
+	return UsageCounters{
+		Requests: c.Requests,
+		Retries:  c.Retries,
+		Failures: c.Failures,
+		Bytes:    c.Bytes,
+	}
+}
+
+// UsageController handles provider usage endpoints
+type UsageController struct {
+	meter *usage.Meter
+}
+
+// NewUsageController creates a new usage controller
+func NewUsageController(meter *usage.Meter) *UsageController {
+	return &UsageController{
+		meter: meter,
+	}
+}
+
+// RegisterRoutes registers the routes for the usage controller
+func (c *UsageController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/admin/usage", c.getUsage)
+}
+
+// getUsage reports the provider calls per UTC day (?from=&to=, today by default), optionally of a single
+// provider (?provider=) and broken down by symbol (?bySymbol=true)
+func (c *UsageController) getUsage(ctx *gin.Context) {
+	from, to, ok := parseTimeRange(ctx)
+	if !ok {
+		return
+	}
+	today := usage.Day(time.Now())
+	if to.IsZero() {
+		to = today
+	}
+	if from.IsZero() {
+		from = to
+	}
+	from, to = usage.Day(from), usage.Day(to)
+	if from.After(to) || to.Sub(from) > maxUsageDays*24*time.Hour {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid day range"})
+		return
+	}
+
+	entries, err := c.meter.GetUsage(ctx, from, to, ctx.Query("provider"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get provider usage")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving provider usage"})
+		return
+	}
+
+	bySymbol := ctx.Query("bySymbol") == "true"
+	response := UsageReport{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), Usage: []ProviderUsage{}}
+	// entries are ordered by day and provider, so the symbols of a provider day are adjacent
+	var totals usage.Counters
+	for _, u := range entries {
+		day := u.Day.Format(time.DateOnly)
+		last := len(response.Usage) - 1
+		if last < 0 || response.Usage[last].Provider != u.Provider || response.Usage[last].Day != day {
+			totals = usage.Counters{}
+			response.Usage = append(response.Usage, c.providerUsage(u.Provider, u.Day, today))
+			last++
+		}
+		totals.Add(u.Counters)
+		response.Usage[last].UsageCounters = buildUsageCounters(totals)
+		if bySymbol {
+			response.Usage[last].Symbols = append(response.Usage[last].Symbols, SymbolUsage{
+				Symbol:        u.Symbol,
+				UsageCounters: buildUsageCounters(u.Counters),
+			})
+		}
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *UsageController) providerUsage(provider string, day, today time.Time) ProviderUsage {
+	pu := ProviderUsage{Provider: provider, Day: day.Format(time.DateOnly)}
+	if limit := c.meter.Cap(provider); limit > 0 {
+		pu.DailyCap = &limit
+		pu.Paused = day.Equal(today) && c.meter.CheckQuota(provider) != nil
+	}
+	return pu
+}
@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/domain/usage"
+	"github.com/market-data/internal/interfaces/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageRepository keeps the usage flushed by the meter in memory
+type usageRepository struct {
+	entries []usage.Usage
+	// provider is the provider filter of the last read
+	provider string
+}
+
+func (r *usageRepository) AddUsage(_ context.Context, entries []usage.Usage) error {
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+// GetUsage returns the usage of the days ordered by day, provider and symbol like the database does
+func (r *usageRepository) GetUsage(_ context.Context, from, to time.Time, provider string) ([]usage.Usage, error) {
+	r.provider = provider
+	var entries []usage.Usage
+	for _, u := range r.entries {
+		if !u.Day.Before(from) && !u.Day.After(to) && (provider == "" || u.Provider == provider) {
+			entries = append(entries, u)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Symbol < b.Symbol
+	})
+	return entries, nil
+}
+
+func (r *usageRepository) GetDailyRequests(context.Context, time.Time) (map[string]int64, error) {
+	return nil, nil
+}
+
+func TestUsageController(t *testing.T) {
+	today := usage.Day(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+	repo := &usageRepository{entries: []usage.Usage{
+		{Provider: "yahoo", Day: yesterday, Symbol: "AAPL", Counters: usage.Counters{Requests: 5, Bytes: 500}},
+	}}
+	meter := usage.NewMeter(repo, map[string]int64{"yahoo": 3})
+	meter.Record("yahoo", "AAPL", usage.Counters{Requests: 2, Retries: 1, Failures: 1, Bytes: 100})
+	meter.Record("yahoo", "MSFT", usage.Counters{Requests: 1, Bytes: 50})
+	meter.Record("binance", "BTCUSDT", usage.Counters{Requests: 4, Bytes: 400})
+	router := newRouter(api.NewUsageController(meter))
+
+	t.Run("Usage of today", func(t *testing.T) {
+		report := decode[api.UsageReport](t, serve(t, router, http.MethodGet, "/admin/usage", ""), http.StatusOK)
+		assert.Equal(t, today.Format(time.DateOnly), report.From)
+		assert.Equal(t, today.Format(time.DateOnly), report.To)
+		require.Len(t, report.Usage, 2)
+
+		binance := report.Usage[0]
+		assert.Equal(t, "binance", binance.Provider)
+		assert.Equal(t, api.UsageCounters{Requests: 4, Bytes: 400}, binance.UsageCounters)
+		assert.Nil(t, binance.DailyCap, "unlimited")
+		assert.False(t, binance.Paused)
+		assert.Empty(t, binance.Symbols)
+
+		// the recorded counters are flushed and summed over the symbols, the cap of 3 requests is reached
+		yahoo := report.Usage[1]
+		assert.Equal(t, "yahoo", yahoo.Provider)
+		assert.Equal(t, api.UsageCounters{Requests: 3, Retries: 1, Failures: 1, Bytes: 150}, yahoo.UsageCounters)
+		assert.Equal(t, int64(3), *yahoo.DailyCap)
+		assert.True(t, yahoo.Paused)
+	})
+
+	t.Run("Usage of a provider by symbol", func(t *testing.T) {
+		report := decode[api.UsageReport](t, serve(t, router, http.MethodGet,
+			"/admin/usage?provider=yahoo&bySymbol=true&from="+yesterday.Format(time.DateOnly), ""), http.StatusOK)
+		assert.Equal(t, "yahoo", repo.provider)
+		require.Len(t, report.Usage, 2)
+
+		// days before today are never reported as paused
+		previous := report.Usage[0]
+		assert.Equal(t, yesterday.Format(time.DateOnly), previous.Day)
+		assert.False(t, previous.Paused)
+		assert.Equal(t, []api.SymbolUsage{
+			{Symbol: "AAPL", UsageCounters: api.UsageCounters{Requests: 5, Bytes: 500}},
+		}, previous.Symbols)
+
+		current := report.Usage[1]
+		assert.Equal(t, today.Format(time.DateOnly), current.Day)
+		assert.True(t, current.Paused)
+		assert.Equal(t, []api.SymbolUsage{
+			{Symbol: "AAPL", UsageCounters: api.UsageCounters{Requests: 2, Retries: 1, Failures: 1, Bytes: 100}},
+			{Symbol: "MSFT", UsageCounters: api.UsageCounters{Requests: 1, Bytes: 50}},
+		}, current.Symbols)
+	})
+
+	t.Run("Usage of days without calls", func(t *testing.T) {
+		w := serve(t, router, http.MethodGet, "/admin/usage?from=2025-06-01&to=2025-06-02", "")
+		assert.JSONEq(t, `{"from": "2025-06-01", "to": "2025-06-02", "usage": []}`, w.Body.String())
+	})
+
+	assertStatuses(t, router, []statusCase{
+		{"Usage with an invalid from", http.MethodGet, "/admin/usage?from=monday", "", http.StatusBadRequest},
+		{"Usage from after to", http.MethodGet, "/admin/usage?from=2025-06-02&to=2025-06-01", "", http.StatusBadRequest},
+		{"Usage of more than a year", http.MethodGet, "/admin/usage?from=2024-01-01&to=2025-06-01", "", http.StatusBadRequest},
+	})
+}
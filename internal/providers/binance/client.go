@@ -0,0 +1,338 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Exchange is the exchange name reported for symbols fetched from Binance.
+const Exchange = "BINANCE"
+
+// Provider is the name of the Binance provider.
+const Provider = "binance"
+
+// maxPageLimit is the largest number of klines Binance returns for a single request.
+const maxPageLimit = 1000
+
+// listingEpoch is used as the start of the "max" period, Binance has no data before it.
+var listingEpoch = time.Date(2017, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+var intervalConv = map[yahoo.IntervalAPI]string{
+	yahoo.Interval1m:  "1m",
+	yahoo.Interval5m:  "5m",
+	yahoo.Interval15m: "15m",
+	yahoo.Interval30m: "30m",
+	yahoo.Interval1h:  "1h",
+	yahoo.Interval1d:  "1d",
+	yahoo.Interval1wk: "1w",
+	yahoo.Interval1mo: "1M",
+}
+
+// Client is a Binance-style klines REST API client for assets that trade 24/7
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	retryCount    int
+	retryWaitTime time.Duration
+	pageLimit     int
+	calendar      calendar.Calendar
+	now           func() time.Time
+}
+
+// NewClient creates a new Binance klines client
+func NewClient(cfg *config.BinanceConfig) *Client {
+	pageLimit := cfg.PageLimit
+	if pageLimit <= 0 || pageLimit > maxPageLimit {
+		pageLimit = maxPageLimit
+	}
+	return &Client{
+		baseURL: cfg.BaseURL,
+		httpClient: &http.Client{
+			Timeout: cfg.GetRequestTimeout(),
+		},
+		retryCount:    cfg.RetryCount,
+		retryWaitTime: cfg.GetRetryWaitTime(),
+		pageLimit:     pageLimit,
+		calendar:      calendar.AlwaysOpen{},
+		now:           time.Now,
+	}
+}
+
+// Calendar returns the trading calendar of assets served by this provider.
+func (c *Client) Calendar() calendar.Calendar {
+	return c.calendar
+}
+
+// GetMarketData retrieves klines for a symbol covering the requested period, following pagination
+// until the whole range is downloaded.
+func (c *Client) GetMarketData(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI,
+) (*yahoo.MarketData, error) {
+	payloads, err := c.FetchRawMarketData(ctx, symbol, interval, period)
+	if err != nil {
+		return nil, err
+	}
+	return c.ParseMarketData(symbol, payloads)
+}
+
+// Name returns the provider name used to label archived responses.
+func (c *Client) Name() string {
+	return Provider
+}
+
+// FetchRawMarketData retrieves the unparsed klines pages covering the requested period, following
+// pagination until the whole range is downloaded.
+func (c *Client) FetchRawMarketData(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI,
+) ([][]byte, error) {
+	end := c.calendar.LastOpen(c.now().UTC())
+	start, err := c.periodStart(end, period)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetchKlinePages(ctx, symbol, interval, start.UnixMilli(), end.UnixMilli())
+}
+
+// GetMarketDataRange retrieves klines of a symbol opened in [start, end).
+func (c *Client) GetMarketDataRange(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	start, end time.Time,
+) (*yahoo.MarketData, error) {
+	payloads, err := c.FetchRawMarketDataRange(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return c.ParseMarketData(symbol, payloads)
+}
+
+// FetchRawMarketDataRange retrieves the unparsed klines pages of a symbol opened in [start, end).
+func (c *Client) FetchRawMarketDataRange(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	start, end time.Time,
+) ([][]byte, error) {
+	return c.fetchKlinePages(ctx, symbol, interval, start.UnixMilli(), end.UnixMilli()-1)
+}
+
+// fetchKlinePages downloads the klines opened between the inclusive bounds page by page.
+func (c *Client) fetchKlinePages(ctx context.Context, symbol string, interval yahoo.IntervalAPI,
+	startMs, endMs int64) ([][]byte, error) {
+	binanceInterval, ok := intervalConv[interval]
+	if !ok {
+		return nil, eris.Errorf("unsupported interval: %s", interval)
+	}
+	ctx = usage.WithSymbol(ctx, symbol)
+
+	pair := normalizeSymbol(symbol)
+	var pages [][]byte
+	klineCount := 0
+	for startMs <= endMs {
+		klines, body, err := c.getKlines(ctx, pair, binanceInterval, startMs, endMs)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, body)
+		klineCount += len(klines)
+
+		if len(klines) < c.pageLimit {
+			break
+		}
+
+		next := klines[len(klines)-1].OpenTime + 1
+		if next <= startMs {
+			return nil, eris.Errorf("pagination did not advance for symbol %s", pair)
+		}
+		startMs = next
+	}
+
+	log.Debug().
+		Str("symbol", pair).
+		Str("interval", binanceInterval).
+		Int("klines", klineCount).
+		Msg("Downloaded Binance klines")
+
+	return pages, nil
+}
+
+// ParseMarketData converts klines pages returned by FetchRawMarketData into market data. Crypto volumes are
+// fractional in the base asset, e.g. 0.8 BTC, so the volume of a bar is the quote asset volume (e.g. USDT
+// traded) rounded to whole units.
+func (c *Client) ParseMarketData(symbol string, payloads [][]byte) (*yahoo.MarketData, error) {
+	pair := normalizeSymbol(symbol)
+	marketData := &yahoo.MarketData{
+		Symbol:   pair,
+		Name:     pair,
+		Exchange: Exchange,
+	}
+
+	for _, payload := range payloads {
+		var klines []Kline
+		if err := json.Unmarshal(payload, &klines); err != nil {
+			return nil, eris.Wrap(err, "failed to unmarshal Binance klines response")
+		}
+		for _, k := range klines {
+			marketData.Prices = append(marketData.Prices, yahoo.StockPrice{
+				Time:     time.UnixMilli(k.OpenTime),
+				Open:     k.Open,
+				High:     k.High,
+				Low:      k.Low,
+				Close:    k.Close,
+				AdjClose: k.Close,
+				Volume:   k.Volume,
+			})
+		}
+	}
+	return marketData, nil
+}
+
+// periodStart converts a relative period into the start of the requested range. Day based
+// periods are counted in sessions of the calendar, which for a 24/7 asset equals calendar days.
+func (c *Client) periodStart(end time.Time, period yahoo.PeriodAPI) (time.Time, error) {
+	switch period {
+	case yahoo.Period1d:
+		return c.calendar.AddSessions(end, -1), nil
+	case yahoo.Period5d:
+		return c.calendar.AddSessions(end, -5), nil
+	case yahoo.Period1mo:
+		return end.AddDate(0, -1, 0), nil
+	case yahoo.Period3mo:
+		return end.AddDate(0, -3, 0), nil
+	case yahoo.Period6mo:
+		return end.AddDate(0, -6, 0), nil
+	case yahoo.Period1y:
+		return end.AddDate(-1, 0, 0), nil
+	case yahoo.Period2y:
+		return end.AddDate(-2, 0, 0), nil
+	case yahoo.Period5y:
+		return end.AddDate(-5, 0, 0), nil
+	case yahoo.Period10y:
+		return end.AddDate(-10, 0, 0), nil
+	case yahoo.PeriodYTD:
+		return time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	case yahoo.PeriodMax:
+		return listingEpoch, nil
+	default:
+		return time.Time{}, eris.Errorf("unsupported period: %s", period)
+	}
+}
+
+// getKlines downloads a single page of klines, retrying transient failures. The parsed page is returned
+// along with the response body.
+func (c *Client) getKlines(ctx context.Context, pair, interval string, startMs, endMs int64) ([]Kline, []byte, error) {
+	query := url.Values{}
+	query.Set("symbol", pair)
+	query.Set("interval", interval)
+	query.Set("startTime", strconv.FormatInt(startMs, 10))
+	query.Set("endTime", strconv.FormatInt(endMs, 10))
+	query.Set("limit", strconv.Itoa(c.pageLimit))
+	requestURL := c.baseURL + "?" + query.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retryCount; attempt++ {
+		if attempt > 0 {
+			log.Debug().
+				Str("symbol", pair).
+				Int("attempt", attempt).
+				Msg("Retrying Binance API request")
+
+			select {
+			case <-ctx.Done():
+				return nil, nil, eris.Wrap(ctx.Err(), "context canceled while waiting to retry")
+			case <-time.After(c.retryWaitTime):
+				// Continue with retry
+			}
+		}
+
+		body, status, err := c.get(usage.WithAttempt(ctx, attempt), requestURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if status == http.StatusOK {
+			var klines []Kline
+			if err := json.Unmarshal(body, &klines); err != nil {
+				return nil, nil, eris.Wrap(err, "failed to unmarshal Binance klines response")
+			}
+			return klines, body, nil
+		}
+
+		lastErr = eris.Errorf("unexpected status code: %d", status)
+		var apiErr Error
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Msg != "" {
+			lastErr = eris.Errorf("unexpected status code: %d: %s", status, apiErr.Msg)
+			if apiErr.Code == codeInvalidSymbol {
+				lastErr = eris.Wrapf(yahoo.ErrUnknownSymbol, "unexpected status code: %d: %s", status, apiErr.Msg)
+			}
+		}
+
+		if !retryable(status) {
+			return nil, nil, eris.Wrapf(lastErr, "failed to fetch klines for symbol %s", pair)
+		}
+	}
+
+	return nil, nil, eris.Wrapf(lastErr, "failed to fetch klines for symbol %s after %d attempts", pair, c.retryCount+1)
+}
+
+func (c *Client) get(ctx context.Context, requestURL string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, 0, eris.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("User-Agent", "MarketDataService/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, eris.Wrap(err, "request failed")
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to close response body")
+		}
+	}(resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, eris.Wrap(err, "failed to read response body")
+	}
+	return body, resp.StatusCode, nil
+}
+
+// retryable reports whether a response status is worth retrying. Binance answers 429 when
+// the rate limit is hit and 418 when the client is temporarily banned for ignoring it.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusTeapot || status >= http.StatusInternalServerError
+}
+
+// normalizeSymbol converts symbols such as "btc-usdt" or "BTC/USDT" into Binance pair notation.
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "/", "", "_", "").Replace(symbol))
+}
+
+// SetTransport replaces the HTTP transport of the client, e.g. with a recording one in tests
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
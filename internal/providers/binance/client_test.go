@@ -0,0 +1,125 @@
+package binance_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/binance"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// klineServer is a local stand-in for the Binance klines endpoint generating one kline per interval step.
+type klineServer struct {
+	mu       sync.Mutex
+	requests []map[string]string
+	step     time.Duration
+}
+
+func (s *klineServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	s.mu.Lock()
+	s.requests = append(s.requests, map[string]string{
+		"symbol":    q.Get("symbol"),
+		"interval":  q.Get("interval"),
+		"startTime": q.Get("startTime"),
+		"endTime":   q.Get("endTime"),
+	})
+	s.mu.Unlock()
+
+	if q.Get("symbol") != "BTCUSDT" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":-1121,"msg":"Invalid symbol."}`))
+		return
+	}
+
+	start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+	end, _ := strconv.ParseInt(q.Get("endTime"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	stepMs := s.step.Milliseconds()
+	first := (start + stepMs - 1) / stepMs * stepMs
+	var klines [][]any
+	for t := first; t <= end && len(klines) < limit; t += stepMs {
+		klines = append(klines, []any{
+			t, "100.5", "110.25", "90.75", "105.0", "0.8", t + stepMs - 1, "84.4", 42, "0.4", "42.2", "0",
+		})
+	}
+	_ = json.NewEncoder(w).Encode(klines)
+}
+
+func newClient(url string, pageLimit int) *binance.Client {
+	return binance.NewClient(&config.BinanceConfig{
+		BaseURL:        url,
+		RequestTimeout: 5,
+		RetryCount:     1,
+		PageLimit:      pageLimit,
+	})
+}
+
+func TestClient_GetMarketData_Paginates(t *testing.T) {
+	stub := &klineServer{step: time.Hour}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	client := newClient(server.URL, 50)
+	data, err := client.GetMarketData(context.TODO(), "btc-usdt", yahoo.Interval1h, yahoo.Period5d)
+	require.NoError(t, err)
+
+	assert.Equal(t, "BTCUSDT", data.Symbol)
+	assert.Equal(t, binance.Exchange, data.Exchange)
+	// 5 days of hourly klines, split into pages of 50
+	assert.InDelta(t, 120, len(data.Prices), 1)
+	assert.Len(t, stub.requests, 3)
+	for i := 1; i < len(data.Prices); i++ {
+		assert.Equal(t, time.Hour, data.Prices[i].Time.Sub(data.Prices[i-1].Time))
+	}
+
+	price := data.Prices[0]
+	assert.Equal(t, 100.5, price.Open)
+	assert.Equal(t, 110.25, price.High)
+	assert.Equal(t, 90.75, price.Low)
+	assert.Equal(t, 105.0, price.Close)
+	assert.Equal(t, 105.0, price.AdjClose)
+	// volumes are traded in fractions of the base asset
+	assert.Equal(t, 0.8, price.Volume)
+}
+
+func TestClient_GetMarketData_TreatsAssetAs24x7(t *testing.T) {
+	stub := &klineServer{step: 24 * time.Hour}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	client := newClient(server.URL, 1000)
+	_, err := client.GetMarketData(context.TODO(), "BTCUSDT", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+
+	require.Len(t, stub.requests, 1)
+	assert.Equal(t, "1d", stub.requests[0]["interval"])
+	start, _ := strconv.ParseInt(stub.requests[0]["startTime"], 10, 64)
+	end, _ := strconv.ParseInt(stub.requests[0]["endTime"], 10, 64)
+	// weekends are not skipped, five sessions are exactly five days
+	assert.Equal(t, 5*24*time.Hour, time.Duration(end-start)*time.Millisecond)
+	assert.True(t, client.Calendar().IsOpen(time.Date(2025, time.August, 9, 3, 0, 0, 0, time.UTC)))
+}
+
+func TestClient_GetMarketData_Errors(t *testing.T) {
+	server := httptest.NewServer(&klineServer{step: time.Hour})
+	defer server.Close()
+	client := newClient(server.URL, 1000)
+
+	_, err := client.GetMarketData(context.TODO(), "NOPE", yahoo.Interval1d, yahoo.Period1mo)
+	assert.ErrorContains(t, err, "Invalid symbol")
+	assert.ErrorIs(t, err, yahoo.ErrUnknownSymbol)
+
+	_, err = client.GetMarketData(context.TODO(), "BTCUSDT", yahoo.IntervalAPI("2m"), yahoo.Period1mo)
+	assert.ErrorContains(t, err, "unsupported interval")
+}
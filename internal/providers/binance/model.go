@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/rotisserie/eris"
+)
+
+// Kline represents a single candlestick returned by the Binance klines endpoint.
+//
+// Binance encodes a kline as a positional JSON array:
+// [openTime, open, high, low, close, volume, closeTime, quoteVolume, trades, takerBase, takerQuote, ignore]
+// where prices and volumes are decimal strings and times are milliseconds since epoch. Volume is traded in
+// the base asset (e.g. BTC), QuoteVolume in the quote asset (e.g. USDT).
+type Kline struct {
+	OpenTime    int64
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64
+	CloseTime   int64
+	QuoteVolume float64
+}
+
+// UnmarshalJSON decodes the positional array representation of a kline.
+func (k *Kline) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return eris.Wrap(err, "kline is not an array")
+	}
+	if len(raw) < 8 {
+		return eris.Errorf("kline has %d fields, expected at least 8", len(raw))
+	}
+
+	if err := json.Unmarshal(raw[0], &k.OpenTime); err != nil {
+		return eris.Wrap(err, "invalid kline open time")
+	}
+	if err := json.Unmarshal(raw[6], &k.CloseTime); err != nil {
+		return eris.Wrap(err, "invalid kline close time")
+	}
+
+	fields := map[int]*float64{1: &k.Open, 2: &k.High, 3: &k.Low, 4: &k.Close, 5: &k.Volume, 7: &k.QuoteVolume}
+	for i, field := range fields {
+		var s string
+		if err := json.Unmarshal(raw[i], &s); err != nil {
+			return eris.Wrapf(err, "invalid kline field %d", i)
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return eris.Wrapf(err, "invalid kline number %q", s)
+		}
+		*field = v
+	}
+	return nil
+}
+
+// codeInvalidSymbol is the Binance error code of requests for an unknown pair
+const codeInvalidSymbol = -1121
+
+// Error represents an error payload returned by the Binance API
+type Error struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
@@ -0,0 +1,388 @@
+package file
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Supported file formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Special date formats for numeric timestamps
+const (
+	DateFormatUnix   = "unix"
+	DateFormatUnixMs = "unix_ms"
+)
+
+// Rejection describes a row of an imported file that could not be converted into a price.
+type Rejection struct {
+	Row    int    // 1-based data row number, the CSV header is not counted
+	Reason string // why the row was rejected
+}
+
+// Client is a market data provider backed by CSV or JSON files on the local filesystem
+type Client struct {
+	directory    string
+	format       string
+	delimiter    rune
+	decimalComma bool
+	dateFormats  []string
+	location     *time.Location
+	exchange     string
+	columns      config.ColumnMapping
+	now          func() time.Time
+}
+
+// NewClient creates a new file provider
+func NewClient(cfg *config.FileProviderConfig) (*Client, error) {
+	format := strings.ToLower(cfg.Format)
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatJSON {
+		return nil, eris.Errorf("unsupported file format: %s", cfg.Format)
+	}
+
+	delimiter := ','
+	if cfg.Delimiter != "" {
+		runes := []rune(cfg.Delimiter)
+		if len(runes) != 1 {
+			return nil, eris.Errorf("delimiter must be a single character: %q", cfg.Delimiter)
+		}
+		delimiter = runes[0]
+	}
+
+	if cfg.DecimalSeparator != "" && cfg.DecimalSeparator != "." && cfg.DecimalSeparator != "," {
+		return nil, eris.Errorf("decimal separator must be \".\" or \",\": %q", cfg.DecimalSeparator)
+	}
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid timezone: %s", cfg.Timezone)
+		}
+		location = loc
+	}
+
+	dateFormats := cfg.DateFormats
+	if len(dateFormats) == 0 {
+		dateFormats = []string{"2006-01-02", time.RFC3339}
+	}
+
+	// filling missing prices from the close would store bars the file does not have
+	columns := cfg.Columns
+	if columns.Time == "" || columns.Open == "" || columns.High == "" || columns.Low == "" || columns.Close == "" {
+		return nil, eris.New("time, open, high, low and close columns must be mapped")
+	}
+
+	return &Client{
+		directory:    cfg.Directory,
+		format:       format,
+		delimiter:    delimiter,
+		decimalComma: cfg.DecimalSeparator == ",",
+		dateFormats:  dateFormats,
+		location:     location,
+		exchange:     cfg.Exchange,
+		columns:      cfg.Columns,
+		now:          time.Now,
+	}, nil
+}
+
+// GetMarketData reads the file <directory>/<SYMBOL>.<format> and returns prices within the requested period.
+// Files contain bars of a single granularity, so the interval is not used for resampling.
+func (c *Client) GetMarketData(
+	_ context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI,
+) (*yahoo.MarketData, error) {
+	path := filepath.Join(c.directory, fmt.Sprintf("%s.%s", strings.ToUpper(symbol), c.format))
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, eris.Wrapf(yahoo.ErrUnknownSymbol, "no price file: %s", path)
+	}
+	data, rejections, err := c.LoadFile(symbol, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(rejections) > 0 {
+		log.Warn().
+			Str("symbol", symbol).
+			Str("path", path).
+			Int("rejected", len(rejections)).
+			Msg("Rows rejected while reading price file")
+	}
+
+	start, ok := periodStart(c.now(), period)
+	if ok {
+		filtered := data.Prices[:0]
+		for _, price := range data.Prices {
+			if !price.Time.Before(start) {
+				filtered = append(filtered, price)
+			}
+		}
+		data.Prices = filtered
+	}
+
+	log.Debug().
+		Str("symbol", symbol).
+		Str("interval", string(interval)).
+		Str("period", string(period)).
+		Int("prices", len(data.Prices)).
+		Msg("Loaded prices from file")
+
+	return data, nil
+}
+
+// LoadFile parses a whole price file for the symbol. Rows which cannot be parsed are skipped and
+// reported as rejections, only unreadable files produce an error.
+func (c *Client) LoadFile(symbol, path string) (*yahoo.MarketData, []Rejection, error) {
+	f, err := os.Open(path) // nolint:gosec
+	if err != nil {
+		return nil, nil, eris.Wrapf(err, "cannot open price file: %s", path)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Error().Err(err).Str("path", path).Msg("failed to close price file")
+		}
+	}()
+
+	format := c.format
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext == FormatCSV || ext == FormatJSON {
+		format = ext
+	}
+
+	var records []map[string]string
+	switch format {
+	case FormatJSON:
+		records, err = readJSON(f)
+	default:
+		records, err = c.readCSV(f)
+	}
+	if err != nil {
+		return nil, nil, eris.Wrapf(err, "cannot read price file: %s", path)
+	}
+
+	data := &yahoo.MarketData{
+		Symbol:   strings.ToUpper(symbol),
+		Name:     strings.ToUpper(symbol),
+		Exchange: c.exchange,
+	}
+	var rejections []Rejection
+	seen := make(map[int64]bool, len(records))
+	for i, record := range records {
+		price, err := c.parseRecord(record)
+		if err != nil {
+			rejections = append(rejections, Rejection{Row: i + 1, Reason: err.Error()})
+			continue
+		}
+		if seen[price.Time.Unix()] {
+			rejections = append(rejections, Rejection{Row: i + 1, Reason: "duplicate time " + price.Time.Format(time.RFC3339)})
+			continue
+		}
+		seen[price.Time.Unix()] = true
+		data.Prices = append(data.Prices, *price)
+	}
+
+	sort.Slice(data.Prices, func(i, j int) bool {
+		return data.Prices[i].Time.Before(data.Prices[j].Time)
+	})
+
+	return data, rejections, nil
+}
+
+func (c *Client) readCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = c.delimiter
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, eris.Wrap(err, "cannot read header")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var records []map[string]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, eris.Wrap(err, "cannot read row")
+		}
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				record[name] = strings.TrimSpace(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	var rows []map[string]any
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, eris.Wrap(err, "expected a JSON array of objects")
+	}
+
+	records := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		record := make(map[string]string, len(row))
+		for k, v := range row {
+			switch value := v.(type) {
+			case nil:
+				record[k] = ""
+			case string:
+				record[k] = value
+			case float64:
+				record[k] = strconv.FormatFloat(value, 'f', -1, 64)
+			default:
+				record[k] = fmt.Sprint(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (c *Client) parseRecord(record map[string]string) (*yahoo.StockPrice, error) {
+	t, err := c.parseTime(record[c.columns.Time])
+	if err != nil {
+		return nil, err
+	}
+
+	price := &yahoo.StockPrice{Time: t}
+	required := []struct {
+		column string
+		target *float64
+	}{
+		{c.columns.Open, &price.Open},
+		{c.columns.High, &price.High},
+		{c.columns.Low, &price.Low},
+		{c.columns.Close, &price.Close},
+	}
+	for _, r := range required {
+		v, err := c.parsePrice(record, r.column)
+		if err != nil {
+			return nil, err
+		}
+		*r.target = v
+	}
+
+	// files without adjusted prices are taken as unadjusted, like the other providers without adjustments
+	price.AdjClose = price.Close
+	if _, ok := record[c.columns.AdjClose]; ok && c.columns.AdjClose != "" {
+		adjClose, err := c.parsePrice(record, c.columns.AdjClose)
+		if err != nil {
+			return nil, err
+		}
+		price.AdjClose = adjClose
+	}
+
+	if c.columns.Volume != "" && record[c.columns.Volume] != "" {
+		volume, err := strconv.ParseFloat(c.normalizeNumber(record[c.columns.Volume]), 64)
+		if err != nil || volume < 0 {
+			return nil, eris.Errorf("invalid volume %q", record[c.columns.Volume])
+		}
+		price.Volume = volume
+	}
+
+	return price, nil
+}
+
+func (c *Client) parsePrice(record map[string]string, column string) (float64, error) {
+	raw, ok := record[column]
+	if !ok || raw == "" {
+		return 0, eris.Errorf("missing value for column %q", column)
+	}
+	v, err := strconv.ParseFloat(c.normalizeNumber(raw), 64)
+	if err != nil {
+		return 0, eris.Errorf("invalid number %q in column %q", raw, column)
+	}
+	return v, nil
+}
+
+// normalizeNumber drops thousands separators and converts decimal commas to points.
+func (c *Client) normalizeNumber(raw string) string {
+	if c.decimalComma {
+		return strings.ReplaceAll(strings.ReplaceAll(raw, ".", ""), ",", ".")
+	}
+	return strings.ReplaceAll(raw, ",", "")
+}
+
+func (c *Client) parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, eris.New("missing time")
+	}
+	for _, layout := range c.dateFormats {
+		switch layout {
+		case DateFormatUnix, DateFormatUnixMs:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				continue
+			}
+			if layout == DateFormatUnixMs {
+				return time.UnixMilli(n), nil
+			}
+			return time.Unix(n, 0), nil
+		default:
+			if t, err := time.ParseInLocation(layout, raw, c.location); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, eris.Errorf("time %q does not match any configured date format", raw)
+}
+
+// periodStart returns the earliest time included in the period, ok is false for periods without a bound.
+func periodStart(now time.Time, period yahoo.PeriodAPI) (time.Time, bool) {
+	switch period {
+	case yahoo.Period1d:
+		return now.AddDate(0, 0, -1), true
+	case yahoo.Period5d:
+		return now.AddDate(0, 0, -5), true
+	case yahoo.Period1mo:
+		return now.AddDate(0, -1, 0), true
+	case yahoo.Period3mo:
+		return now.AddDate(0, -3, 0), true
+	case yahoo.Period6mo:
+		return now.AddDate(0, -6, 0), true
+	case yahoo.Period1y:
+		return now.AddDate(-1, 0, 0), true
+	case yahoo.Period2y:
+		return now.AddDate(-2, 0, 0), true
+	case yahoo.Period5y:
+		return now.AddDate(-5, 0, 0), true
+	case yahoo.Period10y:
+		return now.AddDate(-10, 0, 0), true
+	case yahoo.PeriodYTD:
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location()), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,106 @@
+package file_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/file"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_LoadFile_CSV(t *testing.T) {
+	client, err := file.NewClient(&config.FileProviderConfig{
+		Directory:        "fixtures/test",
+		Format:           "csv",
+		Delimiter:        ";",
+		DecimalSeparator: ",",
+		DateFormats:      []string{"02.01.2006"},
+		Timezone:         "Europe/Berlin",
+		Exchange:         "XETRA",
+		Columns: config.ColumnMapping{
+			Time:   "Datum",
+			Open:   "Eröffnung",
+			High:   "Hoch",
+			Low:    "Tief",
+			Close:  "Schluss",
+			Volume: "Umsatz",
+		},
+	})
+	require.NoError(t, err)
+
+	data, rejections, err := client.LoadFile("aapl", "fixtures/test/AAPL.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, "AAPL", data.Symbol)
+	assert.Equal(t, "XETRA", data.Exchange)
+	require.Len(t, data.Prices, 3)
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	first := data.Prices[0]
+	assert.True(t, first.Time.Equal(time.Date(2025, time.August, 6, 0, 0, 0, 0, berlin)))
+	assert.Equal(t, 205.63, first.Open)
+	assert.Equal(t, 215.38, first.High)
+	assert.Equal(t, 205.59, first.Low)
+	assert.Equal(t, 213.25, first.Close)
+	assert.Equal(t, 213.25, first.AdjClose, "unmapped adjusted close falls back to close")
+	assert.Equal(t, 108483100.0, first.Volume)
+
+	require.Len(t, rejections, 3)
+	assert.Equal(t, 4, rejections[0].Row)
+	assert.Contains(t, rejections[0].Reason, "Schluss")
+	assert.Equal(t, 5, rejections[1].Row)
+	assert.Contains(t, rejections[1].Reason, "date format")
+	assert.Equal(t, 6, rejections[2].Row)
+	assert.Contains(t, rejections[2].Reason, "duplicate")
+}
+
+func TestClient_GetMarketData_JSON(t *testing.T) {
+	client, err := file.NewClient(&config.FileProviderConfig{
+		Directory:   "fixtures/test",
+		Format:      "json",
+		DateFormats: []string{file.DateFormatUnix},
+		Columns: config.ColumnMapping{
+			Time:   "ts",
+			Open:   "o",
+			High:   "h",
+			Low:    "l",
+			Close:  "c",
+			Volume: "v",
+		},
+	})
+	require.NoError(t, err)
+
+	data, err := client.GetMarketData(context.TODO(), "MSFT", yahoo.Interval1d, yahoo.PeriodMax)
+	require.NoError(t, err)
+	require.Len(t, data.Prices, 2, "rows without open, high or low are rejected")
+
+	assert.True(t, data.Prices[0].Time.Before(data.Prices[1].Time), "prices are sorted by time")
+	assert.Equal(t, 526.2, data.Prices[0].Open)
+	assert.Equal(t, 17383400.0, data.Prices[0].Volume)
+
+	recent, err := client.GetMarketData(context.TODO(), "MSFT", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Len(t, recent.Prices, 0, "fixture prices are older than the requested period")
+
+	_, err = client.GetMarketData(context.TODO(), "NOPE", yahoo.Interval1d, yahoo.PeriodMax)
+	assert.ErrorIs(t, err, yahoo.ErrUnknownSymbol)
+}
+
+func TestNewClient_InvalidConfig(t *testing.T) {
+	ohlcColumns := config.ColumnMapping{Time: "t", Open: "o", High: "h", Low: "l", Close: "c"}
+	_, err := file.NewClient(&config.FileProviderConfig{Format: "xml", Columns: ohlcColumns})
+	assert.ErrorContains(t, err, "unsupported file format")
+
+	_, err = file.NewClient(&config.FileProviderConfig{Delimiter: "::", Columns: ohlcColumns})
+	assert.ErrorContains(t, err, "single character")
+
+	_, err = file.NewClient(&config.FileProviderConfig{})
+	assert.ErrorContains(t, err, "must be mapped")
+
+	_, err = file.NewClient(&config.FileProviderConfig{Columns: config.ColumnMapping{Time: "t", Close: "c"}})
+	assert.ErrorContains(t, err, "must be mapped", "open, high and low are required")
+}
@@ -0,0 +1,227 @@
+package fred
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/domain/usage"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// Source is the source name recorded for series fetched from FRED.
+const Source = "FRED"
+
+// dateLayout is the date format used by FRED for observation dates and range parameters.
+const dateLayout = "2006-01-02"
+
+// missingValue is how FRED reports an observation without value.
+const missingValue = "."
+
+// Client is a FRED-compatible economic time series API client
+type Client struct {
+	baseURL       string
+	apiKey        string
+	httpClient    *http.Client
+	retryCount    int
+	retryWaitTime time.Duration
+	pageLimit     int
+}
+
+// NewClient creates a new FRED client
+func NewClient(cfg *config.FredConfig) *Client {
+	pageLimit := cfg.PageLimit
+	if pageLimit <= 0 {
+		pageLimit = 100000
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		httpClient: &http.Client{
+			Timeout: cfg.GetRequestTimeout(),
+		},
+		retryCount:    cfg.RetryCount,
+		retryWaitTime: cfg.GetRetryWaitTime(),
+		pageLimit:     pageLimit,
+	}
+}
+
+// GetSeries retrieves series metadata and all observations since start. A zero start fetches the full history.
+func (c *Client) GetSeries(ctx context.Context, code string, start time.Time) (*SeriesData, error) {
+	ctx = usage.WithSymbol(ctx, code)
+	query := url.Values{}
+	query.Set("series_id", code)
+
+	var seriesResp SeriesResponse
+	if err := c.get(ctx, "/series", query, &seriesResp); err != nil {
+		return nil, eris.Wrapf(err, "failed to fetch series %s", code)
+	}
+	if len(seriesResp.Seriess) == 0 {
+		return nil, eris.Wrapf(ErrSeriesNotFound, "series %s", code)
+	}
+	info := seriesResp.Seriess[0]
+
+	data := &SeriesData{
+		Source:    Source,
+		Code:      info.ID,
+		Title:     info.Title,
+		Frequency: info.FrequencyShort,
+		Units:     info.UnitsShort,
+	}
+
+	if !start.IsZero() {
+		query.Set("observation_start", start.Format(dateLayout))
+	}
+	query.Set("limit", strconv.Itoa(c.pageLimit))
+	query.Set("sort_order", "asc")
+
+	for offset := 0; ; {
+		query.Set("offset", strconv.Itoa(offset))
+
+		var obsResp ObservationsResponse
+		if err := c.get(ctx, "/series/observations", query, &obsResp); err != nil {
+			return nil, eris.Wrapf(err, "failed to fetch observations of series %s", code)
+		}
+
+		for _, o := range obsResp.Observations {
+			point, err := transform(o)
+			if err != nil {
+				return nil, eris.Wrapf(err, "invalid observation of series %s", code)
+			}
+			data.Points = append(data.Points, point)
+		}
+
+		offset += len(obsResp.Observations)
+		if len(obsResp.Observations) == 0 || offset >= obsResp.Count {
+			break
+		}
+	}
+
+	return data, nil
+}
+
+func transform(o Observation) (SeriesPoint, error) {
+	t, err := time.ParseInLocation(dateLayout, o.Date, time.UTC)
+	if err != nil {
+		return SeriesPoint{}, eris.Wrapf(err, "invalid date %q", o.Date)
+	}
+	point := SeriesPoint{Time: t}
+	if o.Value == missingValue || o.Value == "" {
+		return point, nil
+	}
+	v, err := strconv.ParseFloat(o.Value, 64)
+	if err != nil {
+		return SeriesPoint{}, eris.Wrapf(err, "invalid value %q", o.Value)
+	}
+	point.Value = &v
+	return point, nil
+}
+
+// get calls an endpoint of the API and decodes the JSON response into target, retrying transient failures.
+func (c *Client) get(ctx context.Context, path string, query url.Values, target any) error {
+	params := url.Values{}
+	for k, v := range query {
+		params[k] = v
+	}
+	params.Set("api_key", c.apiKey)
+	params.Set("file_type", "json")
+	requestURL := c.baseURL + path + "?" + params.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retryCount; attempt++ {
+		if attempt > 0 {
+			log.Debug().
+				Str("path", path).
+				Int("attempt", attempt).
+				Msg("Retrying FRED API request")
+
+			select {
+			case <-ctx.Done():
+				return eris.Wrap(ctx.Err(), "context canceled while waiting to retry")
+			case <-time.After(c.retryWaitTime):
+				// Continue with retry
+			}
+		}
+
+		body, status, err := c.do(usage.WithAttempt(ctx, attempt), requestURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if status == http.StatusOK {
+			if err := json.Unmarshal(body, target); err != nil {
+				return eris.Wrap(err, "failed to unmarshal FRED response")
+			}
+			return nil
+		}
+
+		lastErr = eris.Errorf("unexpected status code: %d", status)
+		var apiErr ErrorResponse
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.ErrorMessage != "" {
+			lastErr = eris.Errorf("unexpected status code: %d: %s", status, apiErr.ErrorMessage)
+		}
+
+		if status == http.StatusBadRequest && strings.Contains(apiErr.ErrorMessage, "series does not exist") {
+			return eris.Wrap(ErrSeriesNotFound, apiErr.ErrorMessage)
+		}
+		if status != http.StatusTooManyRequests && status < http.StatusInternalServerError {
+			return lastErr
+		}
+	}
+
+	return eris.Wrapf(lastErr, "request failed after %d attempts", c.retryCount+1)
+}
+
+func (c *Client) do(ctx context.Context, requestURL string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, 0, eris.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("User-Agent", "MarketDataService/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// the error quotes the request URL, which carries the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactQuery(urlErr.URL)
+		}
+		return nil, 0, eris.Wrap(err, "request failed")
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to close response body")
+		}
+	}(resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, eris.Wrap(err, "failed to read response body")
+	}
+	return body, resp.StatusCode, nil
+}
+
+// redactQuery strips the query of a URL
+func redactQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<unparsable url>"
+	}
+	u.RawQuery = ""
+	return u.String()
+}
+
+// SetTransport replaces the HTTP transport of the client, e.g. with a recording one in tests
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
@@ -0,0 +1,115 @@
+package fred_test
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/fred"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	//go:embed fixtures/test/series.json
+	seriesData []byte
+	//go:embed fixtures/test/observations.json
+	observationsData []byte
+)
+
+// newFixtureServer serves the recorded FRED responses, paginating observations by the limit/offset parameters.
+func newFixtureServer(t *testing.T, requests *[]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/series", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RequestURI())
+		if r.URL.Query().Get("api_key") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_code":400,"error_message":"Bad Request. The value for variable api_key is not registered."}`))
+			return
+		}
+		if r.URL.Query().Get("series_id") != "UNRATE" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_code":400,"error_message":"Bad Request. The series does not exist."}`))
+			return
+		}
+		_, _ = w.Write(seriesData)
+	})
+	mux.HandleFunc("/series/observations", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RequestURI())
+		var resp fred.ObservationsResponse
+		require.NoError(t, json.Unmarshal(observationsData, &resp))
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(resp.Observations))
+		resp.Observations = resp.Observations[offset:end]
+		resp.Offset = offset
+		resp.Limit = limit
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_GetSeries(t *testing.T) {
+	var requests []string
+	server := newFixtureServer(t, &requests)
+	defer server.Close()
+
+	client := fred.NewClient(&config.FredConfig{
+		BaseURL:        server.URL,
+		APIKey:         "secret",
+		RequestTimeout: 5,
+		PageLimit:      2,
+	})
+
+	data, err := client.GetSeries(context.TODO(), "UNRATE", time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, fred.Source, data.Source)
+	assert.Equal(t, "UNRATE", data.Code)
+	assert.Equal(t, "Unemployment Rate", data.Title)
+	assert.Equal(t, "M", data.Frequency)
+	assert.Equal(t, "%", data.Units)
+
+	require.Len(t, data.Points, 5)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), data.Points[0].Time)
+	assert.Equal(t, 4.2, *data.Points[0].Value)
+	assert.Nil(t, data.Points[3].Value, "missing observations are reported as nil")
+
+	// one series request and three pages of observations
+	require.Len(t, requests, 4)
+	assert.Contains(t, requests[1], "observation_start=2025-03-01")
+	assert.Contains(t, requests[1], "file_type=json")
+}
+
+func TestClient_GetSeries_Errors(t *testing.T) {
+	var requests []string
+	server := newFixtureServer(t, &requests)
+	defer server.Close()
+
+	client := fred.NewClient(&config.FredConfig{BaseURL: server.URL, APIKey: "secret", RequestTimeout: 5})
+	_, err := client.GetSeries(context.TODO(), "NOPE", time.Time{})
+	assert.ErrorIs(t, err, fred.ErrSeriesNotFound)
+	assert.ErrorContains(t, err, "series does not exist")
+
+	client = fred.NewClient(&config.FredConfig{BaseURL: server.URL, APIKey: "wrong", RequestTimeout: 5})
+	_, err = client.GetSeries(context.TODO(), "UNRATE", time.Time{})
+	assert.ErrorContains(t, err, "api_key")
+}
+
+func TestClient_GetSeries_RedactsAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := fred.NewClient(&config.FredConfig{BaseURL: server.URL, APIKey: "secret", RequestTimeout: 5})
+	_, err := client.GetSeries(context.TODO(), "UNRATE", time.Time{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, server.URL+"/series")
+	assert.NotContains(t, err.Error(), "secret")
+}
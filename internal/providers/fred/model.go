@@ -0,0 +1,58 @@
+package fred
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSeriesNotFound is reported for series the API does not know
+var ErrSeriesNotFound = errors.New("series not found")
+
+// SeriesResponse represents the response of the FRED series endpoint
+type SeriesResponse struct {
+	Seriess []SeriesInfo `json:"seriess"`
+}
+
+// SeriesInfo represents metadata of a series in the FRED response
+type SeriesInfo struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	FrequencyShort string `json:"frequency_short"`
+	UnitsShort     string `json:"units_short"`
+}
+
+// ObservationsResponse represents the response of the FRED series observations endpoint
+type ObservationsResponse struct {
+	Count        int           `json:"count"`
+	Offset       int           `json:"offset"`
+	Limit        int           `json:"limit"`
+	Observations []Observation `json:"observations"`
+}
+
+// Observation represents a single observation in the FRED response. Missing values are reported as ".".
+type Observation struct {
+	Date  string `json:"date"`
+	Value string `json:"value"`
+}
+
+// ErrorResponse represents an error returned by the FRED API
+type ErrorResponse struct {
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// SeriesPoint is a single observation of a series
+type SeriesPoint struct {
+	Time  time.Time
+	Value *float64
+}
+
+// SeriesData is a series with its observations, transformed from the FRED response
+type SeriesData struct {
+	Source    string
+	Code      string
+	Title     string
+	Frequency string
+	Units     string
+	Points    []SeriesPoint
+}
@@ -0,0 +1,211 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRestartWaitTime caps the exponential backoff between restarts of a failing plugin.
+const maxRestartWaitTime = time.Minute
+
+// Client is a data provider served by an external process speaking the line-delimited JSON protocol over
+// stdin/stdout. The process is health-checked periodically and restarted, with exponential backoff, when it
+// exits or stops answering.
+type Client struct {
+	cfg config.PluginConfig
+
+	mu          sync.Mutex
+	proc        *process
+	restarts    int
+	restartWait time.Duration
+	nextStart   time.Time
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewClient creates a new plugin client, the process is spawned by Start
+func NewClient(cfg *config.PluginConfig) *Client {
+	return &Client{
+		cfg:         *cfg,
+		restartWait: cfg.GetRestartWaitTime(),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Name returns the configured plugin name.
+func (c *Client) Name() string {
+	return c.cfg.Name
+}
+
+// Start spawns the plugin process, checks that it answers a ping and starts the health checks.
+func (c *Client) Start(ctx context.Context) error {
+	c.mu.Lock()
+	proc, err := c.spawn(ctx)
+	c.proc = proc
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	c.wg.Add(1)
+	go c.healthLoop()
+	return nil
+}
+
+// Stop ends the health checks and the plugin process.
+func (c *Client) Stop() {
+	close(c.stopCh)
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proc != nil {
+		c.proc.stop()
+		c.proc = nil
+	}
+}
+
+// Restarts returns how many times a restart of the plugin process has been attempted.
+func (c *Client) Restarts() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.restarts
+}
+
+// GetMarketData requests market data of a symbol from the plugin.
+func (c *Client) GetMarketData(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI,
+) (*yahoo.MarketData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.GetRequestTimeout())
+	defer cancel()
+
+	proc, err := c.running(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result MarketDataResult
+	params := MarketDataParams{Symbol: symbol, Interval: string(interval), Period: string(period)}
+	if err := proc.call(ctx, MethodGetMarketData, params, &result); err != nil {
+		return nil, eris.Wrapf(err, "plugin %s failed to get market data of %s", c.cfg.Name, symbol)
+	}
+	if len(result.Prices) == 0 {
+		return nil, eris.Errorf("no data returned for symbol: %s", symbol)
+	}
+	return result.toMarketData(), nil
+}
+
+// running returns the plugin process, restarting it first when it has exited and the backoff has passed.
+func (c *Client) running(ctx context.Context) (*process, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proc != nil && c.proc.alive() {
+		return c.proc, nil
+	}
+	if err := c.restart(ctx); err != nil {
+		return nil, err
+	}
+	return c.proc, nil
+}
+
+// restart replaces the current process by a new one. It must be called with the mutex held.
+func (c *Client) restart(ctx context.Context) error {
+	if wait := time.Until(c.nextStart); wait > 0 {
+		return eris.Errorf("plugin %s is not running, next restart in %s", c.cfg.Name, wait.Round(time.Millisecond))
+	}
+	if c.proc != nil {
+		c.proc.kill()
+		c.proc = nil
+	}
+
+	c.restarts++
+	log.Warn().Str("plugin", c.cfg.Name).Int("restarts", c.restarts).Msg("Restarting plugin")
+	proc, err := c.spawn(ctx)
+	if err != nil {
+		c.nextStart = time.Now().Add(c.restartWait)
+		c.restartWait = min(2*c.restartWait, maxRestartWaitTime)
+		return err
+	}
+	c.proc = proc
+	c.restartWait = c.cfg.GetRestartWaitTime()
+	return nil
+}
+
+// spawn starts a process and waits for its first ping, killing it when it does not answer.
+func (c *Client) spawn(ctx context.Context) (*process, error) {
+	proc, err := startProcess(&c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ping(ctx, proc); err != nil {
+		proc.kill()
+		return nil, err
+	}
+	log.Info().Str("plugin", c.cfg.Name).Int("pid", proc.cmd.Process.Pid).Msg("Plugin started")
+	return proc, nil
+}
+
+func (c *Client) ping(ctx context.Context, proc *process) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.GetRequestTimeout())
+	defer cancel()
+
+	var result PingResult
+	if err := proc.call(ctx, MethodPing, nil, &result); err != nil {
+		return eris.Wrapf(err, "plugin %s failed the health check", c.cfg.Name)
+	}
+	if result.ProtocolVersion != ProtocolVersion {
+		return eris.Errorf("plugin %s speaks protocol version %d, expected %d",
+			c.cfg.Name, result.ProtocolVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// healthLoop pings the process every health interval and restarts it when it has exited or fails the ping.
+func (c *Client) healthLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.cfg.GetHealthInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.checkHealth()
+		}
+	}
+}
+
+func (c *Client) checkHealth() {
+	c.mu.Lock()
+	proc := c.proc
+	c.mu.Unlock()
+
+	if proc != nil && proc.alive() {
+		err := c.ping(context.Background(), proc)
+		if err == nil {
+			return
+		}
+		log.Error().Err(err).Str("plugin", c.cfg.Name).Msg("Plugin health check failed")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.proc != proc {
+		// restarted by a request in the meantime
+		return
+	}
+	if err := c.restart(context.Background()); err != nil {
+		log.Error().Err(err).Str("plugin", c.cfg.Name).Msg("Failed to restart plugin")
+	}
+}
@@ -0,0 +1,199 @@
+package plugin_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/plugin"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess is not a real test, it is the plugin process spawned by the other tests running the test
+// binary again. It answers requests for any symbol with one bar per day, fails for NOPE, exits for CRASH,
+// stops answering and reading requests for HANG and answers twice for DUP.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     uint64                  `json:"id"`
+			Method string                  `json:"method"`
+			Params plugin.MarketDataParams `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid request:", err)
+			continue
+		}
+
+		resp := map[string]any{"id": req.ID}
+		switch {
+		case req.Method == plugin.MethodPing:
+			resp["result"] = plugin.PingResult{Name: "helper", ProtocolVersion: plugin.ProtocolVersion}
+		case req.Method != plugin.MethodGetMarketData:
+			resp["error"] = plugin.Error{Code: plugin.CodeInvalidRequest, Message: "unknown method " + req.Method}
+		case req.Params.Symbol == "NOPE":
+			resp["error"] = plugin.Error{Code: plugin.CodeNotFound, Message: "unknown symbol NOPE"}
+		case req.Params.Symbol == "CRASH":
+			os.Exit(3)
+		case req.Params.Symbol == "HANG":
+			select {}
+		default:
+			start := time.Date(2025, time.June, 9, 13, 30, 0, 0, time.UTC)
+			result := plugin.MarketDataResult{Symbol: req.Params.Symbol, Name: "Helper " + req.Params.Symbol, Exchange: "HELPER"}
+			for i := range 5 {
+				price := 100 + float64(i)
+				result.Prices = append(result.Prices, plugin.Price{
+					Time: start.AddDate(0, 0, i), Open: price, High: price + 1, Low: price - 1, Close: price, Volume: 1000,
+				})
+			}
+			result.Quote = &plugin.Quote{Price: 104, PreviousClose: 103, Currency: "EUR", Time: start.AddDate(0, 0, 4)}
+			resp["result"] = result
+		}
+		_ = out.Encode(resp)
+		if req.Params.Symbol == "DUP" {
+			_ = out.Encode(resp)
+		}
+	}
+}
+
+func helperConfig() *config.PluginConfig {
+	return &config.PluginConfig{
+		Name:            "helper",
+		Command:         os.Args[0],
+		Args:            []string{"-test.run=^TestHelperProcess$"},
+		Env:             []string{"GO_WANT_HELPER_PROCESS=1"},
+		RequestTimeout:  1,
+		HealthInterval:  3600,
+		RestartWaitTime: 10,
+	}
+}
+
+func startClient(t *testing.T, cfg *config.PluginConfig) *plugin.Client {
+	client := plugin.NewClient(cfg)
+	require.NoError(t, client.Start(context.TODO()))
+	t.Cleanup(client.Stop)
+	return client
+}
+
+func TestClient_GetMarketData(t *testing.T) {
+	client := startClient(t, helperConfig())
+
+	data, err := client.GetMarketData(context.TODO(), "ACME", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", data.Symbol)
+	assert.Equal(t, "Helper ACME", data.Name)
+	assert.Equal(t, "HELPER", data.Exchange)
+	require.Len(t, data.Prices, 5)
+	assert.Equal(t, time.Date(2025, time.June, 9, 13, 30, 0, 0, time.UTC), data.Prices[0].Time.UTC())
+	assert.Equal(t, 104.0, data.Prices[4].Close)
+	// the adjusted close defaults to the close
+	assert.Equal(t, 104.0, data.Prices[4].AdjClose)
+	require.NotNil(t, data.Quote)
+	assert.Equal(t, "EUR", data.Quote.Currency)
+	assert.Equal(t, 103.0, data.Quote.PreviousClose)
+
+	_, err = client.GetMarketData(context.TODO(), "NOPE", yahoo.Interval1d, yahoo.Period5d)
+	var pluginErr *plugin.Error
+	require.True(t, errors.As(err, &pluginErr))
+	assert.Equal(t, plugin.CodeNotFound, pluginErr.Code)
+	assert.ErrorIs(t, err, yahoo.ErrUnknownSymbol)
+
+	// requests are multiplexed over the same process
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			symbol := fmt.Sprintf("SYM%d", i)
+			data, err := client.GetMarketData(context.TODO(), symbol, yahoo.Interval1d, yahoo.Period5d)
+			if assert.NoError(t, err) {
+				assert.Equal(t, symbol, data.Symbol)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, client.Restarts())
+}
+
+func TestClient_RestartAfterExit(t *testing.T) {
+	client := startClient(t, helperConfig())
+
+	_, err := client.GetMarketData(context.TODO(), "CRASH", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorIs(t, err, plugin.ErrProcessExited)
+
+	// the next request spawns a new process
+	data, err := client.GetMarketData(context.TODO(), "ACME", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", data.Symbol)
+	assert.Equal(t, 1, client.Restarts())
+}
+
+func TestClient_HealthCheckRestartsHungPlugin(t *testing.T) {
+	cfg := helperConfig()
+	cfg.HealthInterval = 1
+	client := startClient(t, cfg)
+
+	_, err := client.GetMarketData(context.TODO(), "HANG", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the hung process fails the next health check and is replaced
+	require.Eventually(t, func() bool { return client.Restarts() == 1 }, 5*time.Second, 50*time.Millisecond)
+	data, err := client.GetMarketData(context.TODO(), "ACME", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", data.Symbol)
+}
+
+func TestClient_KillsPluginNotReadingRequests(t *testing.T) {
+	client := startClient(t, helperConfig())
+
+	_, err := client.GetMarketData(context.TODO(), "HANG", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// a request larger than the pipe buffer blocks in the write until it times out
+	_, err = client.GetMarketData(context.TODO(), strings.Repeat("X", 1<<20), yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the process was killed, the next request spawns a new one without waiting for a health check
+	require.Eventually(t, func() bool {
+		data, err := client.GetMarketData(context.TODO(), "ACME", yahoo.Interval1d, yahoo.Period5d)
+		return err == nil && data.Symbol == "ACME"
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, 1, client.Restarts())
+}
+
+func TestClient_DuplicateResponse(t *testing.T) {
+	client := startClient(t, helperConfig())
+
+	for range 10 {
+		data, err := client.GetMarketData(context.TODO(), "DUP", yahoo.Interval1d, yahoo.Period5d)
+		require.NoError(t, err)
+		assert.Equal(t, "DUP", data.Symbol)
+	}
+	data, err := client.GetMarketData(context.TODO(), "ACME", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", data.Symbol)
+	assert.Equal(t, 0, client.Restarts())
+}
+
+func TestClient_StartFails(t *testing.T) {
+	cfg := helperConfig()
+	cfg.Command = "/nonexistent/plugin"
+	err := plugin.NewClient(cfg).Start(context.TODO())
+	assert.ErrorContains(t, err, "failed to start plugin helper")
+}
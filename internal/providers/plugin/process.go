@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+// maxLineSize bounds a single response line, large enough for years of minute bars.
+const maxLineSize = 64 << 20
+
+// stopTimeout is how long a process may take to exit after its stdin is closed before it is killed.
+const stopTimeout = 5 * time.Second
+
+// ErrProcessExited is returned for requests pending or sent when the plugin process exits
+var ErrProcessExited = errors.New("plugin process exited")
+
+// process is a running plugin process. Requests are multiplexed over its stdin by id, so many may be in flight.
+type process struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	nextID  atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[uint64]chan *Response
+	exited  bool
+
+	done chan struct{} // closed when the process has exited
+}
+
+func startProcess(cfg *config.PluginConfig) (*process, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Dir = cfg.Dir
+	cmd.Env = append(os.Environ(), cfg.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to open stdin")
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to open stdout")
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to open stderr")
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, eris.Wrapf(err, "failed to start plugin %s", cfg.Name)
+	}
+
+	p := &process{
+		name:    cfg.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan *Response),
+		done:    make(chan struct{}),
+	}
+	go p.logStderr(stderr)
+	go p.readResponses(stdout)
+	return p, nil
+}
+
+// call sends a request and decodes the result of its response into result.
+func (p *process) call(ctx context.Context, method string, params, result any) error {
+	id := p.nextID.Add(1)
+	respCh := make(chan *Response, 1)
+
+	p.mu.Lock()
+	if p.exited {
+		p.mu.Unlock()
+		return ErrProcessExited
+	}
+	p.pending[id] = respCh
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	line, err := json.Marshal(Request{ID: id, Method: method, Params: params})
+	if err != nil {
+		return eris.Wrap(err, "failed to encode request")
+	}
+	if err := p.write(ctx, append(line, '\n')); err != nil {
+		return eris.Wrapf(err, "failed to send %s", method)
+	}
+
+	select {
+	case <-ctx.Done():
+		return eris.Wrapf(ctx.Err(), "no response to %s", method)
+	case resp, ok := <-respCh:
+		if !ok {
+			return ErrProcessExited
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return eris.Wrap(json.Unmarshal(resp.Result, result), "failed to decode result")
+	}
+}
+
+// write writes a request line to the stdin of the process. A process which stops reading its stdin blocks
+// the write, and every write after it, so the process is killed when ctx ends first and gets restarted.
+func (p *process) write(ctx context.Context, line []byte) error {
+	written := make(chan error, 1)
+	go func() {
+		p.writeMu.Lock()
+		defer p.writeMu.Unlock()
+		_, err := p.stdin.Write(line)
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		return err
+	case <-ctx.Done():
+		log.Warn().Str("plugin", p.name).Msg("Plugin does not read its requests, killing it")
+		_ = p.cmd.Process.Kill()
+		return ctx.Err()
+	}
+}
+
+// readResponses dispatches response lines to the pending requests until stdout is closed.
+func (p *process) readResponses(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			log.Warn().Err(err).Str("plugin", p.name).Msg("Ignoring invalid plugin output line")
+			continue
+		}
+		p.mu.Lock()
+		respCh, ok := p.pending[resp.ID]
+		p.mu.Unlock()
+		if !ok {
+			// the request has already timed out
+			continue
+		}
+		select {
+		case respCh <- &resp:
+		default:
+			log.Warn().Uint64("id", resp.ID).Str("plugin", p.name).Msg("Ignoring duplicate plugin response")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Str("plugin", p.name).Msg("Failed to read plugin output")
+	}
+
+	p.mu.Lock()
+	p.exited = true
+	for id, respCh := range p.pending {
+		close(respCh)
+		delete(p.pending, id)
+	}
+	p.mu.Unlock()
+
+	// make sure a process which closed its stdout does not linger
+	_ = p.cmd.Process.Kill()
+	err := p.cmd.Wait()
+	log.Warn().Err(err).Str("plugin", p.name).Msg("Plugin process exited")
+	close(p.done)
+}
+
+func (p *process) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		log.Info().Str("plugin", p.name).Msg(scanner.Text())
+	}
+}
+
+// alive reports whether the process still serves requests, which ends as soon as its stdout is closed.
+func (p *process) alive() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.exited
+}
+
+// stop closes the stdin of the process, which asks it to exit, and kills it when it does not exit in time.
+func (p *process) stop() {
+	_ = p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		p.kill()
+	}
+}
+
+// kill terminates the process immediately and waits for it to exit.
+func (p *process) kill() {
+	_ = p.cmd.Process.Kill()
+	<-p.done
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// ProtocolVersion is the version of the stdio protocol spoken by the service, see docs/plugin-protocol.md.
+const ProtocolVersion = 1
+
+// Protocol methods
+const (
+	MethodPing          = "ping"
+	MethodGetMarketData = "get_market_data"
+)
+
+// Error codes reported by plugins
+const (
+	CodeNotFound       = "not_found"
+	CodeInvalidRequest = "invalid_request"
+	CodeInternal       = "internal"
+)
+
+// Request is a single line written to the plugin stdin
+type Request struct {
+	ID     uint64 `json:"id"`
+	Method string `json:"method"`
+	Params any    `json:"params,omitempty"`
+}
+
+// Response is a single line read from the plugin stdout, answering the request with the same id
+type Response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error is a failure reported by a plugin
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is reports not_found errors as unknown symbols
+func (e *Error) Is(target error) bool {
+	return target == yahoo.ErrUnknownSymbol && e.Code == CodeNotFound
+}
+
+// PingResult is the result of the ping method
+type PingResult struct {
+	Name            string `json:"name"`
+	ProtocolVersion int    `json:"protocol_version"`
+}
+
+// MarketDataParams are the parameters of the get_market_data method
+type MarketDataParams struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	Period   string `json:"period"`
+}
+
+// MarketDataResult is the result of the get_market_data method
+type MarketDataResult struct {
+	Symbol   string  `json:"symbol"`
+	Name     string  `json:"name"`
+	Exchange string  `json:"exchange"`
+	Prices   []Price `json:"prices"`
+	Quote    *Quote  `json:"quote,omitempty"`
+}
+
+// Price is a single bar of a market data result
+type Price struct {
+	Time     time.Time `json:"time"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	AdjClose *float64  `json:"adj_close,omitempty"` // defaults to the close
+	Volume   float64   `json:"volume"`
+}
+
+// Quote is the latest quote of a market data result
+type Quote struct {
+	Price         float64   `json:"price"`
+	PreviousClose float64   `json:"previous_close,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// toMarketData converts a result into the market data shared by all providers.
+func (r *MarketDataResult) toMarketData() *yahoo.MarketData {
+	data := &yahoo.MarketData{
+		Symbol:   r.Symbol,
+		Name:     r.Name,
+		Exchange: r.Exchange,
+		Prices:   make([]yahoo.StockPrice, 0, len(r.Prices)),
+	}
+	for _, p := range r.Prices {
+		adjClose := p.Close
+		if p.AdjClose != nil {
+			adjClose = *p.AdjClose
+		}
+		data.Prices = append(data.Prices, yahoo.StockPrice{
+			Time:     p.Time,
+			Open:     p.Open,
+			High:     p.High,
+			Low:      p.Low,
+			Close:    p.Close,
+			AdjClose: adjClose,
+			Volume:   p.Volume,
+		})
+	}
+	if r.Quote != nil {
+		data.Quote = &yahoo.MarketQuote{
+			Price:         r.Quote.Price,
+			PreviousClose: r.Quote.PreviousClose,
+			Currency:      r.Quote.Currency,
+			Time:          r.Quote.Time,
+		}
+	}
+	return data
+}
@@ -0,0 +1,367 @@
+package synthetic
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/market-data/internal/calendar"
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/rotisserie/eris"
+)
+
+// Exchange is the exchange name reported for synthetic symbols.
+const Exchange = "SYNTHETIC"
+
+// tradingDaysPerYear converts the annualized drift and volatility into daily steps.
+const tradingDaysPerYear = 252
+
+// Split is a stock split of a synthetic symbol. Prices before the split date are reported unadjusted,
+// i.e. Ratio times higher than the adjusted close.
+type Split struct {
+	Date  time.Time
+	Ratio float64
+}
+
+// Client is a synthetic market data provider generating reproducible prices by geometric Brownian motion.
+// Every symbol follows its own path derived from the seed and the symbol, which starts at the start date,
+// so overlapping requests always return identical bars.
+type Client struct {
+	seed           int64
+	startPrice     float64
+	drift          float64
+	volatility     float64
+	gapProbability float64
+	start          time.Time
+	end            time.Time // zero means now
+	splits         map[string][]Split
+	calendar       *calendar.Exchange
+	now            func() time.Time
+}
+
+// NewClient creates a new synthetic provider
+func NewClient(cfg *config.SyntheticConfig) (*Client, error) {
+	c := &Client{
+		seed:           cfg.Seed,
+		startPrice:     cfg.StartPrice,
+		drift:          cfg.Drift,
+		volatility:     cfg.Volatility,
+		gapProbability: cfg.GapProbability,
+		splits:         make(map[string][]Split),
+		calendar:       calendar.NYSE(),
+		now:            time.Now,
+	}
+	if c.startPrice <= 0 {
+		return nil, eris.New("start_price must be positive")
+	}
+	if c.volatility < 0 || c.gapProbability < 0 || c.gapProbability >= 1 {
+		return nil, eris.New("volatility must not be negative and gap_probability must be in [0, 1)")
+	}
+
+	var err error
+	if c.start, err = time.ParseInLocation(time.DateOnly, cfg.StartDate, c.calendar.Location); err != nil {
+		return nil, eris.Wrap(err, "invalid start_date")
+	}
+	if cfg.EndDate != "" {
+		end, err := time.ParseInLocation(time.DateOnly, cfg.EndDate, c.calendar.Location)
+		if err != nil {
+			return nil, eris.Wrap(err, "invalid end_date")
+		}
+		// the whole end day is generated
+		c.end = end.Add(24*time.Hour - time.Nanosecond)
+	}
+	for _, split := range cfg.Splits {
+		date, err := time.ParseInLocation(time.DateOnly, split.Date, c.calendar.Location)
+		if err != nil {
+			return nil, eris.Wrapf(err, "invalid split date of symbol %s", split.Symbol)
+		}
+		if split.Ratio <= 0 {
+			return nil, eris.Errorf("invalid split ratio of symbol %s", split.Symbol)
+		}
+		symbol := strings.ToUpper(split.Symbol)
+		c.splits[symbol] = append(c.splits[symbol], Split{Date: date, Ratio: split.Ratio})
+	}
+	return c, nil
+}
+
+// Calendar returns the trading calendar of synthetic symbols.
+func (c *Client) Calendar() calendar.Calendar {
+	return c.calendar
+}
+
+// GetMarketData generates the bars of a symbol for the requested interval and period.
+func (c *Client) GetMarketData(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	period yahoo.PeriodAPI,
+) (*yahoo.MarketData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, eris.Wrap(err, "context canceled")
+	}
+
+	end := c.end
+	if end.IsZero() {
+		end = c.now()
+	}
+	end = c.calendar.LastOpen(end)
+	start, err := c.periodStart(end, period)
+	if err != nil {
+		return nil, err
+	}
+	return c.generate(symbol, interval, start, end, true)
+}
+
+// GetMarketDataRange generates the bars of a symbol starting in [start, end) for the requested interval.
+func (c *Client) GetMarketDataRange(
+	ctx context.Context,
+	symbol string,
+	interval yahoo.IntervalAPI,
+	start, end time.Time,
+) (*yahoo.MarketData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, eris.Wrap(err, "context canceled")
+	}
+	if !c.end.IsZero() && end.After(c.end) {
+		end = c.end
+	}
+	return c.generate(symbol, interval, start, end.Add(-time.Nanosecond), false)
+}
+
+// generate builds the market data of the bars starting in [start, end], with the quote of the last session
+// up to end.
+func (c *Client) generate(symbol string, interval yahoo.IntervalAPI, start, end time.Time, quote bool) (*yahoo.MarketData, error) {
+	symbol = strings.ToUpper(symbol)
+	days := c.dailyPath(symbol, end)
+	var prices []yahoo.StockPrice
+	switch interval {
+	case yahoo.Interval1d:
+		prices = days
+	case yahoo.Interval1wk, yahoo.Interval1mo:
+		prices = aggregate(days, interval, c.calendar.Location)
+	case yahoo.Interval1m, yahoo.Interval5m, yahoo.Interval15m, yahoo.Interval30m, yahoo.Interval1h:
+		prices = c.intraday(symbol, days, start, end, interval)
+	default:
+		return nil, eris.Errorf("unsupported interval: %s", interval)
+	}
+
+	data := &yahoo.MarketData{
+		Symbol:   symbol,
+		Name:     symbol + " (synthetic)",
+		Exchange: Exchange,
+	}
+	for _, p := range prices {
+		if !p.Time.Before(start) && !p.Time.After(end) {
+			data.Prices = append(data.Prices, p)
+		}
+	}
+	if n := len(days); quote && n > 0 {
+		last := days[n-1]
+		data.Quote = &yahoo.MarketQuote{
+			Price:    last.Close,
+			Currency: "USD",
+			Time:     c.sessionClose(last.Time),
+		}
+		if n > 1 {
+			data.Quote.PreviousClose = days[n-2].Close
+		}
+	}
+	return data, nil
+}
+
+// dailyPath generates the daily bars of a symbol from the start date up to the last session opened before end.
+// Skipped sessions (gaps) still move the price, as if the data was lost rather than the market closed.
+func (c *Client) dailyPath(symbol string, end time.Time) []yahoo.StockPrice {
+	rng := rand.New(rand.NewSource(c.symbolSeed(symbol, time.Time{})))
+	dt := 1.0 / tradingDaysPerYear
+	driftStep := (c.drift - c.volatility*c.volatility/2) * dt
+	volStep := c.volatility * math.Sqrt(dt)
+
+	// every symbol starts at its own level around the configured start price
+	value := c.startPrice * math.Exp(0.5*rng.NormFloat64())
+	var prices []yahoo.StockPrice
+	for day := c.start; ; day = day.AddDate(0, 0, 1) {
+		open := c.sessionOpen(day)
+		if open.After(end) {
+			break
+		}
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+
+		previous := value
+		value *= math.Exp(driftStep + volStep*rng.NormFloat64())
+		openValue := previous * math.Exp(volStep/4*rng.NormFloat64())
+		high := math.Max(openValue, value) * (1 + math.Abs(volStep/2*rng.NormFloat64()))
+		low := math.Min(openValue, value) * (1 - math.Abs(volStep/2*rng.NormFloat64()))
+		volume := 1_000_000 * math.Exp(0.3*rng.NormFloat64())
+		gap := rng.Float64() < c.gapProbability
+		if gap {
+			continue
+		}
+
+		ratio := c.splitRatio(symbol, day)
+		prices = append(prices, yahoo.StockPrice{
+			Time:     open,
+			Open:     round(openValue * ratio),
+			High:     round(high * ratio),
+			Low:      round(low * ratio),
+			Close:    round(value * ratio),
+			AdjClose: round(value),
+			Volume:   math.Trunc(volume / ratio),
+		})
+	}
+	return prices
+}
+
+// intraday generates bars within the sessions of the daily bars in [start, end]. Each session follows its own
+// path from the daily open which is bent to finish at the daily close.
+func (c *Client) intraday(symbol string, days []yahoo.StockPrice, start, end time.Time, interval yahoo.IntervalAPI) []yahoo.StockPrice {
+	step := intervalSteps[interval]
+	stepsPerSession := int((c.calendar.Close - c.calendar.Open) / step)
+	volStep := c.volatility * math.Sqrt(1.0/tradingDaysPerYear/float64(stepsPerSession))
+
+	var prices []yahoo.StockPrice
+	for _, day := range days {
+		sessionClose := c.sessionClose(day.Time)
+		if sessionClose.Before(start) {
+			continue
+		}
+
+		rng := rand.New(rand.NewSource(c.symbolSeed(symbol, day.Time)))
+		path := make([]float64, stepsPerSession+1)
+		path[0] = day.Open
+		for i := 1; i <= stepsPerSession; i++ {
+			path[i] = path[i-1] * math.Exp(volStep*rng.NormFloat64())
+		}
+		correction := math.Log(day.Close / path[stepsPerSession])
+
+		volume := math.Trunc(day.Volume / float64(stepsPerSession))
+		for i := 1; i <= stepsPerSession; i++ {
+			barStart := day.Time.Add(time.Duration(i-1) * step)
+			if barStart.After(end) {
+				break
+			}
+			open := path[i-1] * math.Exp(correction*float64(i-1)/float64(stepsPerSession))
+			closeValue := path[i] * math.Exp(correction*float64(i)/float64(stepsPerSession))
+			wiggle := 1 + math.Abs(volStep/2*rng.NormFloat64())
+			prices = append(prices, yahoo.StockPrice{
+				Time:     barStart,
+				Open:     round(open),
+				High:     round(math.Max(open, closeValue) * wiggle),
+				Low:      round(math.Min(open, closeValue) / wiggle),
+				Close:    round(closeValue),
+				AdjClose: round(closeValue * day.AdjClose / day.Close),
+				Volume:   volume,
+			})
+		}
+	}
+	return prices
+}
+
+var intervalSteps = map[yahoo.IntervalAPI]time.Duration{
+	yahoo.Interval1m:  time.Minute,
+	yahoo.Interval5m:  5 * time.Minute,
+	yahoo.Interval15m: 15 * time.Minute,
+	yahoo.Interval30m: 30 * time.Minute,
+	yahoo.Interval1h:  time.Hour,
+}
+
+// aggregate combines daily bars into weekly (starting Monday) or monthly bars stamped with their first session.
+func aggregate(days []yahoo.StockPrice, interval yahoo.IntervalAPI, loc *time.Location) []yahoo.StockPrice {
+	bucket := func(t time.Time) time.Time {
+		local := t.In(loc)
+		if interval == yahoo.Interval1mo {
+			return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		offset := (int(local.Weekday()) + 6) % 7
+		return time.Date(local.Year(), local.Month(), local.Day()-offset, 0, 0, 0, 0, loc)
+	}
+
+	var bars []yahoo.StockPrice
+	var current time.Time
+	for _, day := range days {
+		b := bucket(day.Time)
+		if len(bars) == 0 || !b.Equal(current) {
+			current = b
+			bars = append(bars, day)
+			continue
+		}
+		bar := &bars[len(bars)-1]
+		bar.High = math.Max(bar.High, day.High)
+		bar.Low = math.Min(bar.Low, day.Low)
+		bar.Close = day.Close
+		bar.AdjClose = day.AdjClose
+		bar.Volume += day.Volume
+	}
+	return bars
+}
+
+// periodStart converts a relative period into the start of the requested range.
+func (c *Client) periodStart(end time.Time, period yahoo.PeriodAPI) (time.Time, error) {
+	switch period {
+	case yahoo.Period1d:
+		return c.calendar.AddSessions(end, -1), nil
+	case yahoo.Period5d:
+		return c.calendar.AddSessions(end, -5), nil
+	case yahoo.Period1mo:
+		return end.AddDate(0, -1, 0), nil
+	case yahoo.Period3mo:
+		return end.AddDate(0, -3, 0), nil
+	case yahoo.Period6mo:
+		return end.AddDate(0, -6, 0), nil
+	case yahoo.Period1y:
+		return end.AddDate(-1, 0, 0), nil
+	case yahoo.Period2y:
+		return end.AddDate(-2, 0, 0), nil
+	case yahoo.Period5y:
+		return end.AddDate(-5, 0, 0), nil
+	case yahoo.Period10y:
+		return end.AddDate(-10, 0, 0), nil
+	case yahoo.PeriodYTD:
+		local := end.In(c.calendar.Location)
+		return time.Date(local.Year(), time.January, 1, 0, 0, 0, 0, c.calendar.Location), nil
+	case yahoo.PeriodMax:
+		return c.start, nil
+	default:
+		return time.Time{}, eris.Errorf("unsupported period: %s", period)
+	}
+}
+
+// splitRatio returns the product of the ratios of the splits of a symbol after day.
+func (c *Client) splitRatio(symbol string, day time.Time) float64 {
+	ratio := 1.0
+	for _, split := range c.splits[symbol] {
+		if day.Before(split.Date) {
+			ratio *= split.Ratio
+		}
+	}
+	return ratio
+}
+
+// symbolSeed derives the random source of a symbol, or of a single session of it when day is set.
+func (c *Client) symbolSeed(symbol string, day time.Time) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(symbol))
+	if !day.IsZero() {
+		_, _ = h.Write([]byte(day.Format(time.DateOnly)))
+	}
+	return c.seed ^ int64(h.Sum64())
+}
+
+func (c *Client) sessionOpen(day time.Time) time.Time {
+	local := day.In(c.calendar.Location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.calendar.Location).Add(c.calendar.Open)
+}
+
+func (c *Client) sessionClose(open time.Time) time.Time {
+	return open.Add(c.calendar.Close - c.calendar.Open)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,173 @@
+package synthetic_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/synthetic"
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() *config.SyntheticConfig {
+	return &config.SyntheticConfig{
+		Seed:       7,
+		StartPrice: 100,
+		Drift:      0.05,
+		Volatility: 0.3,
+		StartDate:  "2024-01-02",
+		EndDate:    "2025-06-13",
+	}
+}
+
+func newClient(t *testing.T, cfg *config.SyntheticConfig) *synthetic.Client {
+	client, err := synthetic.NewClient(cfg)
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_GetMarketData_Deterministic(t *testing.T) {
+	ctx := context.TODO()
+	first, err := newClient(t, testConfig()).GetMarketData(ctx, "DEMO", yahoo.Interval1d, yahoo.Period1y)
+	require.NoError(t, err)
+	second, err := newClient(t, testConfig()).GetMarketData(ctx, "demo", yahoo.Interval1d, yahoo.Period1y)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	assert.Equal(t, "DEMO", first.Symbol)
+	assert.Equal(t, synthetic.Exchange, first.Exchange)
+	// one year of weekday sessions
+	assert.InDelta(t, 261, len(first.Prices), 2)
+	for i, p := range first.Prices {
+		assert.LessOrEqual(t, p.Low, math.Min(p.Open, p.Close))
+		assert.GreaterOrEqual(t, p.High, math.Max(p.Open, p.Close))
+		assert.Positive(t, p.Volume)
+		if i > 0 {
+			assert.True(t, p.Time.After(first.Prices[i-1].Time))
+		}
+	}
+
+	require.NotNil(t, first.Quote)
+	last := first.Prices[len(first.Prices)-1]
+	assert.Equal(t, last.Close, first.Quote.Price)
+	assert.Equal(t, first.Prices[len(first.Prices)-2].Close, first.Quote.PreviousClose)
+	assert.Equal(t, time.Date(2025, time.June, 13, 13, 30, 0, 0, time.UTC), last.Time.UTC())
+
+	other, err := newClient(t, testConfig()).GetMarketData(ctx, "ACME", yahoo.Interval1d, yahoo.Period1y)
+	require.NoError(t, err)
+	assert.NotEqual(t, last.Close, other.Prices[len(other.Prices)-1].Close)
+
+	cfg := testConfig()
+	cfg.Seed = 8
+	reseeded, err := newClient(t, cfg).GetMarketData(ctx, "DEMO", yahoo.Interval1d, yahoo.Period1y)
+	require.NoError(t, err)
+	assert.NotEqual(t, last.Close, reseeded.Prices[len(reseeded.Prices)-1].Close)
+}
+
+func TestClient_GetMarketData_PeriodsOverlap(t *testing.T) {
+	client := newClient(t, testConfig())
+	long, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.Period1y)
+	require.NoError(t, err)
+	short, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+
+	require.Len(t, short.Prices, 5)
+	assert.Equal(t, long.Prices[len(long.Prices)-5:], short.Prices)
+}
+
+func TestClient_GetMarketData_Gaps(t *testing.T) {
+	client := newClient(t, testConfig())
+	full, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.PeriodMax)
+	require.NoError(t, err)
+
+	cfg := testConfig()
+	cfg.GapProbability = 0.2
+	gapped, err := newClient(t, cfg).GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.PeriodMax)
+	require.NoError(t, err)
+
+	assert.Less(t, len(gapped.Prices), len(full.Prices)*9/10)
+	assert.Greater(t, len(gapped.Prices), len(full.Prices)*7/10)
+
+	// the remaining sessions keep the prices of the path without gaps
+	byTime := make(map[int64]yahoo.StockPrice)
+	for _, p := range full.Prices {
+		byTime[p.Time.Unix()] = p
+	}
+	for _, p := range gapped.Prices {
+		assert.Equal(t, byTime[p.Time.Unix()], p)
+	}
+}
+
+func TestClient_GetMarketData_Splits(t *testing.T) {
+	cfg := testConfig()
+	cfg.Splits = []config.SplitConfig{{Symbol: "demo", Date: "2025-01-02", Ratio: 4}}
+	split, err := newClient(t, cfg).GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.PeriodMax)
+	require.NoError(t, err)
+	plain, err := newClient(t, testConfig()).GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.PeriodMax)
+	require.NoError(t, err)
+	require.Len(t, split.Prices, len(plain.Prices))
+
+	splitDate := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+	for i, p := range split.Prices {
+		assert.Equal(t, plain.Prices[i].AdjClose, p.AdjClose)
+		if p.Time.Before(splitDate) {
+			assert.InDelta(t, plain.Prices[i].Close*4, p.Close, 0.03)
+			assert.Equal(t, math.Trunc(plain.Prices[i].Volume/4), p.Volume)
+		} else {
+			assert.Equal(t, plain.Prices[i], p)
+		}
+	}
+}
+
+func TestClient_GetMarketData_Intraday(t *testing.T) {
+	client := newClient(t, testConfig())
+	daily, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	intraday, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval5m, yahoo.Period1d)
+	require.NoError(t, err)
+
+	// a 6.5 hour session of 5 minute bars
+	require.Len(t, intraday.Prices, 78)
+	last := daily.Prices[len(daily.Prices)-1]
+	assert.Equal(t, last.Time, intraday.Prices[0].Time)
+	assert.Equal(t, last.Open, intraday.Prices[0].Open)
+	assert.Equal(t, last.Close, intraday.Prices[77].Close)
+}
+
+func TestClient_GetMarketData_Weekly(t *testing.T) {
+	client := newClient(t, testConfig())
+	daily, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1d, yahoo.Period1mo)
+	require.NoError(t, err)
+	weekly, err := client.GetMarketData(context.TODO(), "DEMO", yahoo.Interval1wk, yahoo.Period1mo)
+	require.NoError(t, err)
+
+	// the last week of the range runs from Monday 2025-06-09 to Friday 2025-06-13
+	week := weekly.Prices[len(weekly.Prices)-1]
+	days := daily.Prices[len(daily.Prices)-5:]
+	assert.Equal(t, days[0].Time, week.Time)
+	assert.Equal(t, days[0].Open, week.Open)
+	assert.Equal(t, days[4].Close, week.Close)
+	volume := 0.0
+	for _, d := range days {
+		volume += d.Volume
+		assert.LessOrEqual(t, week.Low, d.Low)
+		assert.GreaterOrEqual(t, week.High, d.High)
+	}
+	assert.Equal(t, volume, week.Volume)
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	cfg := testConfig()
+	cfg.StartDate = "yesterday"
+	_, err := synthetic.NewClient(cfg)
+	assert.ErrorContains(t, err, "invalid start_date")
+
+	cfg = testConfig()
+	cfg.Splits = []config.SplitConfig{{Symbol: "DEMO", Date: "2025-01-02"}}
+	_, err = synthetic.NewClient(cfg)
+	assert.ErrorContains(t, err, "invalid split ratio")
+}
@@ -6,16 +6,25 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/domain/usage"
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog/log"
 )
 
+// Provider is the name of the Yahoo Finance provider.
+const Provider = "yahoo"
+
 // Client is a Yahoo Finance API client
 type Client struct {
 	baseURL        string
+	optionsBaseURL string
+	sparkBaseURL   string
+	batchSize      int
 	httpClient     *http.Client
 	retryCount     int
 	retryWaitTime  time.Duration
@@ -29,8 +38,184 @@ func (c *Client) GetMarketData(
 	interval IntervalAPI,
 	period PeriodAPI,
 ) (*MarketData, error) {
+	payloads, err := c.FetchRawMarketData(ctx, symbol, interval, period)
+	if err != nil {
+		return nil, err
+	}
+	return c.ParseMarketData(symbol, payloads)
+}
+
+// Name returns the provider name used to label archived responses.
+func (c *Client) Name() string {
+	return Provider
+}
+
+// FetchRawMarketData retrieves the unparsed chart response of a symbol.
+func (c *Client) FetchRawMarketData(
+	ctx context.Context,
+	symbol string,
+	interval IntervalAPI,
+	period PeriodAPI,
+) ([][]byte, error) {
 	url := fmt.Sprintf("%s%s?interval=%s&range=%s", c.baseURL, symbol, interval, period)
 
+	body, err := c.fetch(usage.WithSymbol(ctx, symbol), url, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to fetch market data for symbol %s", symbol)
+	}
+	return [][]byte{body}, nil
+}
+
+// GetMarketDataRange retrieves market data of a symbol for bars starting in [start, end).
+func (c *Client) GetMarketDataRange(
+	ctx context.Context,
+	symbol string,
+	interval IntervalAPI,
+	start, end time.Time,
+) (*MarketData, error) {
+	payloads, err := c.FetchRawMarketDataRange(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return c.ParseMarketData(symbol, payloads)
+}
+
+// FetchRawMarketDataRange retrieves the unparsed chart response of a symbol for bars starting in [start, end).
+func (c *Client) FetchRawMarketDataRange(
+	ctx context.Context,
+	symbol string,
+	interval IntervalAPI,
+	start, end time.Time,
+) ([][]byte, error) {
+	url := fmt.Sprintf("%s%s?interval=%s&period1=%d&period2=%d", c.baseURL, symbol, interval, start.Unix(), end.Unix())
+
+	body, err := c.fetch(usage.WithSymbol(ctx, symbol), url, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to fetch market data for symbol %s", symbol)
+	}
+	return [][]byte{body}, nil
+}
+
+// HistoryLimits returns how far back Yahoo Finance serves intraday bars and how much of them a single request
+// may cover. Daily and longer bars are available for the whole history.
+func (c *Client) HistoryLimits() HistoryLimits {
+	return HistoryLimits{
+		Interval1m:  {Lookback: 30 * 24 * time.Hour, MaxRange: 7 * 24 * time.Hour},
+		Interval5m:  {Lookback: 60 * 24 * time.Hour, MaxRange: 60 * 24 * time.Hour},
+		Interval15m: {Lookback: 60 * 24 * time.Hour, MaxRange: 60 * 24 * time.Hour},
+		Interval30m: {Lookback: 60 * 24 * time.Hour, MaxRange: 60 * 24 * time.Hour},
+		Interval1h:  {Lookback: 730 * 24 * time.Hour, MaxRange: 730 * 24 * time.Hour},
+	}
+}
+
+// ParseMarketData converts chart responses returned by FetchRawMarketData into market data.
+func (c *Client) ParseMarketData(symbol string, payloads [][]byte) (*MarketData, error) {
+	if len(payloads) != 1 {
+		return nil, eris.Errorf("expected one chart response for symbol %s, got %d", symbol, len(payloads))
+	}
+
+	var yahooResp YahooFinanceResponse
+	if err := json.Unmarshal(payloads[0], &yahooResp); err != nil {
+		return nil, eris.Wrap(err, "failed to unmarshal Yahoo Finance response")
+	}
+
+	return c.transform(yahooResp)
+}
+
+// GetOptionChain retrieves the option chain of a symbol for a single expiration date. A zero expiry
+// selects the nearest expiration, the returned chain lists all available expiration dates.
+func (c *Client) GetOptionChain(ctx context.Context, symbol string, expiry time.Time) (*OptionChain, error) {
+	url := fmt.Sprintf("%s%s", c.optionsBaseURL, symbol)
+	if !expiry.IsZero() {
+		url = fmt.Sprintf("%s?date=%d", url, expiry.Unix())
+	}
+
+	body, err := c.fetch(usage.WithSymbol(ctx, symbol), url, symbol)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to fetch option chain for symbol %s", symbol)
+	}
+
+	var optionsResp OptionsResponse
+	if err := json.Unmarshal(body, &optionsResp); err != nil {
+		return nil, eris.Wrap(err, "failed to unmarshal Yahoo Finance options response")
+	}
+
+	return c.transformOptions(optionsResp)
+}
+
+// GetQuotesBatch retrieves the latest quotes of many symbols through the spark API, requesting at most
+// batchSize symbols at once. Every requested symbol has a result, the Data of successful ones holds the
+// symbol metadata and Quote without prices. A failing request fails all symbols of its batch only. Spark
+// charts carry close prices only, bars are fetched per symbol through the chart API.
+func (c *Client) GetQuotesBatch(ctx context.Context, symbols []string) []BatchResult {
+	results := make([]BatchResult, 0, len(symbols))
+	for start := 0; start < len(symbols); start += c.batchSize {
+		batch := symbols[start:min(start+c.batchSize, len(symbols))]
+		results = append(results, c.getSparkBatch(ctx, batch)...)
+	}
+	return results
+}
+
+func (c *Client) getSparkBatch(ctx context.Context, symbols []string) []BatchResult {
+	query := url.Values{}
+	query.Set("symbols", strings.Join(symbols, ","))
+	query.Set("range", string(Period1d))
+	query.Set("interval", string(Interval1d))
+	requestURL := fmt.Sprintf("%s?%s", c.sparkBaseURL, query.Encode())
+
+	results := make([]BatchResult, len(symbols))
+	for i, symbol := range symbols {
+		results[i].Symbol = symbol
+	}
+	fail := func(err error) []BatchResult {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	body, err := c.fetch(ctx, requestURL, strings.Join(symbols, ","))
+	if err != nil {
+		return fail(eris.Wrapf(err, "failed to fetch quotes for %d symbols", len(symbols)))
+	}
+
+	var sparkResp SparkResponse
+	if err := json.Unmarshal(body, &sparkResp); err != nil {
+		return fail(eris.Wrap(err, "failed to unmarshal Yahoo Finance spark response"))
+	}
+	if sparkResp.Spark.Error != nil {
+		return fail(eris.Errorf("spark error: %s", sparkResp.Spark.Error.Description))
+	}
+
+	bySymbol := make(map[string]Result, len(sparkResp.Spark.Result))
+	for _, r := range sparkResp.Spark.Result {
+		if len(r.Response) > 0 {
+			bySymbol[r.Symbol] = r.Response[0]
+		}
+	}
+	for i := range results {
+		result, ok := bySymbol[results[i].Symbol]
+		if !ok {
+			results[i].Err = eris.Errorf("no data returned for symbol %s", results[i].Symbol)
+			continue
+		}
+		quote := transformQuote(result.Meta)
+		if quote == nil {
+			results[i].Err = eris.Errorf("no quote returned for symbol %s", results[i].Symbol)
+			continue
+		}
+		results[i].Data = &MarketData{
+			Symbol:   result.Meta.Symbol,
+			Name:     result.Meta.Name,
+			Exchange: result.Meta.ExchangeName,
+			Quote:    quote,
+		}
+	}
+	return results
+}
+
+// fetch performs a GET request with retries and returns the body of the first successful response.
+func (c *Client) fetch(ctx context.Context, url string, symbol string) ([]byte, error) {
 	var resp *http.Response
 	var err error
 
@@ -50,7 +235,7 @@ func (c *Client) GetMarketData(
 			}
 		}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		req, err := http.NewRequestWithContext(usage.WithAttempt(ctx, attempt), http.MethodGet, url, nil)
 		if err != nil {
 			return nil, eris.Wrap(err, "failed to create request")
 		}
@@ -62,6 +247,13 @@ func (c *Client) GetMarketData(
 		if err == nil && resp.StatusCode == http.StatusOK {
 			break
 		}
+		if err == nil && resp.StatusCode == http.StatusNotFound {
+			// the chart endpoint answers unknown symbols with 404, asking again does not help
+			if err := resp.Body.Close(); err != nil {
+				return nil, eris.Wrap(err, "cannot close response body")
+			}
+			return nil, eris.Wrapf(ErrUnknownSymbol, "unexpected status code: %d", resp.StatusCode)
+		}
 
 		if resp != nil {
 			err := resp.Body.Close()
@@ -75,7 +267,7 @@ func (c *Client) GetMarketData(
 		}
 
 		if attempt == c.retryCount {
-			return nil, eris.Wrapf(err, "failed to fetch market data for symbol %s after %d attempts", symbol, c.retryCount+1)
+			return nil, eris.Wrapf(err, "request failed after %d attempts", c.retryCount+1)
 		}
 	}
 
@@ -90,13 +282,7 @@ func (c *Client) GetMarketData(
 	if err != nil {
 		return nil, eris.Wrap(err, "failed to read response body")
 	}
-
-	var yahooResp YahooFinanceResponse
-	if err := json.Unmarshal(body, &yahooResp); err != nil {
-		return nil, eris.Wrap(err, "failed to unmarshal Yahoo Finance response")
-	}
-
-	return c.transform(yahooResp)
+	return body, nil
 }
 
 func (c *Client) transform(resp YahooFinanceResponse) (*MarketData, error) {
@@ -110,24 +296,128 @@ func (c *Client) transform(resp YahooFinanceResponse) (*MarketData, error) {
 		Name:     result.Meta.Name,
 		Exchange: result.Meta.ExchangeName,
 	}
+	if len(result.Timestamp) > 0 && len(result.Indicators.Quote) == 0 {
+		return nil, eris.New("no quote indicators found")
+	}
 	for i, ts := range result.Timestamp {
-		marketData.Prices = append(marketData.Prices, StockPrice{
+		quote := result.Indicators.Quote[0]
+		open, high, low, closePrice := valueAt(quote.Open, i), valueAt(quote.High, i), valueAt(quote.Low, i),
+			valueAt(quote.Close, i)
+		// Yahoo reports bars without trades (e.g. halted sessions) as nulls
+		if open == nil || high == nil || low == nil || closePrice == nil {
+			continue
+		}
+
+		price := StockPrice{
 			Time:     time.Unix(ts, 0),
-			Open:     result.Indicators.Quote[0].Open[i],
-			High:     result.Indicators.Quote[0].High[i],
-			Low:      result.Indicators.Quote[0].Low[i],
-			Close:    result.Indicators.Quote[0].Close[i],
-			AdjClose: result.Indicators.Adjclose[0].Adjclose[i],
-			Volume:   int(result.Indicators.Quote[0].Volume[i]),
-		})
+			Open:     *open,
+			High:     *high,
+			Low:      *low,
+			Close:    *closePrice,
+			AdjClose: *closePrice, // intraday charts carry no adjusted close
+		}
+		if len(result.Indicators.Adjclose) > 0 {
+			if adjClose := valueAt(result.Indicators.Adjclose[0].Adjclose, i); adjClose != nil {
+				price.AdjClose = *adjClose
+			}
+		}
+		if volume := valueAt(quote.Volume, i); volume != nil {
+			price.Volume = *volume
+		}
+		marketData.Prices = append(marketData.Prices, price)
 	}
+	marketData.Quote = transformQuote(result.Meta)
 	return marketData, nil
 }
 
+// valueAt returns the i-th value of an indicator series, nil when it is null or missing.
+func valueAt(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+// transformQuote extracts the regular market quote from the chart metadata. The previous close is the close
+// of the session before the regular market time. The chart previous close is not used, it is the close before
+// the requested range, which only matches for single-session daily charts.
+func transformQuote(meta Meta) *MarketQuote {
+	if meta.RegularMarketPrice <= 0 || meta.RegularMarketTime == 0 {
+		return nil
+	}
+
+	quote := &MarketQuote{
+		Price:         meta.RegularMarketPrice,
+		PreviousClose: meta.PreviousClose,
+		Currency:      meta.Currency,
+		Time:          time.Unix(meta.RegularMarketTime, 0),
+	}
+	if quote.PreviousClose == 0 {
+		quote.PreviousClose = meta.RegularMarketPreviousClose
+	}
+	return quote
+}
+
+func (c *Client) transformOptions(resp OptionsResponse) (*OptionChain, error) {
+	if resp.OptionChain.Error != nil {
+		return nil, eris.Errorf("option chain error: %s", resp.OptionChain.Error.Description)
+	}
+	results := resp.OptionChain.Result
+	if len(results) == 0 {
+		return nil, eris.New("no results found")
+	}
+	result := results[0]
+
+	name := result.Quote.LongName
+	if name == "" {
+		name = result.Quote.ShortName
+	}
+	chain := &OptionChain{
+		Symbol:          result.UnderlyingSymbol,
+		Name:            name,
+		Exchange:        result.Quote.FullExchangeName,
+		UnderlyingPrice: result.Quote.RegularMarketPrice,
+	}
+	for _, ts := range result.ExpirationDates {
+		chain.ExpirationDates = append(chain.ExpirationDates, time.Unix(ts, 0).UTC())
+	}
+
+	for _, set := range result.Options {
+		for _, contracts := range []struct {
+			optionType OptionType
+			quotes     []OptionContract
+		}{{OptionCall, set.Calls}, {OptionPut, set.Puts}} {
+			for _, q := range contracts.quotes {
+				expiration := q.Expiration
+				if expiration == 0 {
+					expiration = set.ExpirationDate
+				}
+				chain.Contracts = append(chain.Contracts, OptionQuoteData{
+					ContractSymbol:    q.ContractSymbol,
+					Type:              contracts.optionType,
+					Expiry:            time.Unix(expiration, 0).UTC(),
+					Strike:            q.Strike,
+					Bid:               q.Bid,
+					Ask:               q.Ask,
+					LastPrice:         q.LastPrice,
+					Volume:            q.Volume,
+					OpenInterest:      q.OpenInterest,
+					ImpliedVolatility: q.ImpliedVolatility,
+					LastTradeTime:     time.Unix(q.LastTradeDate, 0),
+				})
+			}
+		}
+	}
+	return chain, nil
+}
+
 // NewClient creates a new Yahoo Finance client
 func NewClient(cfg *config.YahooFinanceConfig) *Client {
 	return &Client{
-		baseURL: cfg.BaseURL,
+		baseURL:        cfg.BaseURL,
+		optionsBaseURL: cfg.OptionsBaseURL,
+		sparkBaseURL:   cfg.SparkBaseURL,
+		batchSize:      max(cfg.BatchSize, 1),
 		httpClient: &http.Client{
 			Timeout: cfg.GetRequestTimeout(),
 		},
@@ -136,3 +426,8 @@ func NewClient(cfg *config.YahooFinanceConfig) *Client {
 		defaultSymbols: cfg.DefaultSymbols,
 	}
 }
+
+// SetTransport replaces the HTTP transport of the client, e.g. with a recording one in tests
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
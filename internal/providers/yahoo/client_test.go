@@ -2,28 +2,202 @@ package yahoo_test
 
 import (
 	"context"
+	_ "embed"
+	"encoding/json"
 	"github.com/market-data/internal/config"
 	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests/cassette"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
-	"os"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
-func TestYahoo(t *testing.T) {
-	// manual test
-	if os.Getenv("MANUAL_TEST") == "" {
-		t.Skip("Skipping this test when running from CLI")
-	}
+var (
+	//go:embed fixtures/test/options-aapl.json
+	optionsData []byte
+	//go:embed fixtures/test/chart-aapl.json
+	chartData []byte
+	//go:embed fixtures/test/spark.json
+	sparkData []byte
+)
 
+// TestClient_GetMarketData replays a recorded Yahoo Finance session. Run with CASSETTE_RECORD=1 to record
+// it again against the real API.
+func TestClient_GetMarketData(t *testing.T) {
 	client := yahoo.NewClient(&config.YahooFinanceConfig{
 		BaseURL:        "https://query1.finance.yahoo.com/v8/finance/chart/",
 		RequestTimeout: 10,
-		RetryCount:     3,
 	})
+	client.SetTransport(cassette.NewForTest(t, "chart-aapl-5d"))
+
+	data, err := client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	log.Info().Interface("data", data).Msg("Market data")
+
+	assert.Equal(t, "AAPL", data.Symbol)
+	assert.Equal(t, "Apple Inc.", data.Name)
+	assert.Equal(t, "NMS", data.Exchange)
+	require.Len(t, data.Prices, 5)
+	for i := 1; i < len(data.Prices); i++ {
+		assert.True(t, data.Prices[i].Time.After(data.Prices[i-1].Time), "prices are ordered by time")
+	}
+	require.NotNil(t, data.Quote)
+	assert.Equal(t, "USD", data.Quote.Currency)
+
+	_, err = client.GetMarketData(context.TODO(), "NOPE123", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorContains(t, err, "unexpected status code: 404")
+}
+
+func TestClient_GetMarketData_Quote(t *testing.T) {
+	tests := []struct {
+		name          string
+		meta          string // added to the chart metadata
+		previousClose float64
+	}{
+		// chartPreviousClose is the close before the whole range, not the one of the previous session
+		{"Without previous close", "", 0},
+		{"Previous close", `"previousClose": 198.42,`, 198.42},
+		{"Regular market previous close", `"regularMarketPreviousClose": 198.42,`, 198.42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.Replace(string(chartData), `"priceHint"`, tt.meta+`"priceHint"`, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client := yahoo.NewClient(&config.YahooFinanceConfig{
+				BaseURL:        server.URL + "/v8/finance/chart/",
+				RequestTimeout: 5,
+			})
+
+			data, err := client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval1d, "3d")
+			require.NoError(t, err)
+			require.Len(t, data.Prices, 3)
+
+			require.NotNil(t, data.Quote)
+			assert.Equal(t, 195.64, data.Quote.Price)
+			assert.Equal(t, "USD", data.Quote.Currency)
+			assert.Equal(t, time.Unix(1750190401, 0), data.Quote.Time)
+			assert.Equal(t, tt.previousClose, data.Quote.PreviousClose)
+		})
+	}
+}
+
+func TestClient_GetMarketDataRange(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		_, _ = w.Write(chartData)
+	}))
+	defer server.Close()
+
+	client := yahoo.NewClient(&config.YahooFinanceConfig{
+		BaseURL:        server.URL + "/v8/finance/chart/",
+		RequestTimeout: 5,
+	})
+
+	start := time.Date(2025, time.June, 16, 0, 0, 0, 0, time.UTC)
+	data, err := client.GetMarketDataRange(context.TODO(), "AAPL", yahoo.Interval1d, start, start.AddDate(0, 0, 3))
+	require.NoError(t, err)
+	assert.Equal(t, "/v8/finance/chart/AAPL?interval=1d&period1=1750032000&period2=1750291200", requested)
+	assert.Len(t, data.Prices, 3)
+
+	limits := client.HistoryLimits()
+	assert.Equal(t, 7*24*time.Hour, limits[yahoo.Interval1m].MaxRange)
+	assert.Zero(t, limits[yahoo.Interval1d].Lookback, "daily bars are unlimited")
+}
+
+func TestClient_GetQuotesBatch(t *testing.T) {
+	var requested [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbols := strings.Split(r.URL.Query().Get("symbols"), ",")
+		requested = append(requested, symbols)
+		if symbols[0] == "FAIL" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// answer with the recorded charts of the requested symbols only
+		var resp yahoo.SparkResponse
+		require.NoError(t, json.Unmarshal(sparkData, &resp))
+		var results []yahoo.SparkResult
+		for _, result := range resp.Spark.Result {
+			for _, symbol := range symbols {
+				if result.Symbol == symbol {
+					results = append(results, result)
+				}
+			}
+		}
+		resp.Spark.Result = results
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := yahoo.NewClient(&config.YahooFinanceConfig{
+		SparkBaseURL:   server.URL + "/v8/finance/spark",
+		BatchSize:      2,
+		RequestTimeout: 5,
+	})
+
+	results := client.GetQuotesBatch(context.TODO(), []string{"AAPL", "MSFT", "GOOG", "NOPE", "FAIL"})
+	assert.Equal(t, [][]string{{"AAPL", "MSFT"}, {"GOOG", "NOPE"}, {"FAIL"}}, requested)
+
+	require.Len(t, results, 5)
+	for i, symbol := range []string{"AAPL", "MSFT", "GOOG"} {
+		assert.Equal(t, symbol, results[i].Symbol)
+		require.NoError(t, results[i].Err)
+		assert.Equal(t, symbol, results[i].Data.Symbol)
+		assert.Empty(t, results[i].Data.Prices)
+	}
+	assert.Equal(t, 195.64, results[0].Data.Quote.Price)
+	assert.Equal(t, 198.42, results[0].Data.Quote.PreviousClose)
+	assert.Equal(t, time.Unix(1750190401, 0), results[0].Data.Quote.Time)
+
+	assert.Equal(t, "NOPE", results[3].Symbol)
+	assert.ErrorContains(t, results[3].Err, "no data returned")
+	assert.Equal(t, "FAIL", results[4].Symbol)
+	assert.ErrorContains(t, results[4].Err, "unexpected status code: 500")
+}
+
+func TestClient_GetOptionChain(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		_, _ = w.Write(optionsData)
+	}))
+	defer server.Close()
+
+	client := yahoo.NewClient(&config.YahooFinanceConfig{
+		OptionsBaseURL: server.URL + "/v7/finance/options/",
+		RequestTimeout: 5,
+	})
+
+	expiry := time.Date(2025, time.August, 15, 0, 0, 0, 0, time.UTC)
+	chain, err := client.GetOptionChain(context.TODO(), "AAPL", expiry)
+	require.NoError(t, err)
+	assert.Equal(t, "/v7/finance/options/AAPL?date=1755216000", requested)
+
+	assert.Equal(t, "AAPL", chain.Symbol)
+	assert.Equal(t, "Apple Inc.", chain.Name)
+	assert.Equal(t, 229.35, chain.UnderlyingPrice)
+	assert.Equal(t, []time.Time{expiry, expiry.AddDate(0, 0, 7)}, chain.ExpirationDates)
 
-	data, err := client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval1d, yahoo.Period1mo)
-	assert.NoError(t, err)
-	assert.NotNil(t, data)
-	log.Info().Interface("data", data).Msg("Data")
+	require.Len(t, chain.Contracts, 3)
+	put := chain.Contracts[2]
+	assert.Equal(t, "AAPL250815P00225000", put.ContractSymbol)
+	assert.Equal(t, yahoo.OptionPut, put.Type)
+	assert.Equal(t, expiry, put.Expiry)
+	assert.Equal(t, 225.0, put.Strike)
+	assert.Equal(t, 1.08, put.Bid)
+	assert.Equal(t, 1.12, put.Ask)
+	assert.Equal(t, int64(33150), put.OpenInterest)
+	assert.Equal(t, 0.2651, put.ImpliedVolatility)
+	assert.Equal(t, yahoo.OptionCall, chain.Contracts[0].Type)
 }
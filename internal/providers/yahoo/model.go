@@ -1,23 +1,41 @@
 package yahoo
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownSymbol is reported by data providers for symbols they do not know, e.g. a mistyped or delisted
+// ticker. Unlike other failures it is not worth retrying.
+var ErrUnknownSymbol = errors.New("unknown symbol")
 
 // IntervalAPI represents a set of predefined time intervals used for specifying durations in API requests.
 type IntervalAPI string
 
 // Interval1m represents a 1-minute interval for the IntervalAPI type.
 // Interval5m represents a 5-minute interval for the IntervalAPI type.
+// Interval15m represents a 15-minute interval for the IntervalAPI type.
+// Interval30m represents a 30-minute interval for the IntervalAPI type.
+// Interval1h represents a 1-hour interval for the IntervalAPI type.
 // Interval1d represents a 1-day interval for the IntervalAPI type.
 // Interval1wk represents a 1-week interval for the IntervalAPI type.
 // Interval1mo represents a 1-month interval for the IntervalAPI type.
 const (
 	Interval1m  IntervalAPI = "1m"  // one minute
 	Interval5m  IntervalAPI = "5m"  // 5 minutes
+	Interval15m IntervalAPI = "15m" // 15 minutes
+	Interval30m IntervalAPI = "30m" // 30 minutes
+	Interval1h  IntervalAPI = "1h"  // one hour
 	Interval1d  IntervalAPI = "1d"  // one day
 	Interval1wk IntervalAPI = "1wk" // one week
 	Interval1mo IntervalAPI = "1mo" // one month
 )
 
+// Intervals are the supported bar intervals, finest first
+var Intervals = []IntervalAPI{
+	Interval1m, Interval5m, Interval15m, Interval30m, Interval1h, Interval1d, Interval1wk, Interval1mo,
+}
+
 // PeriodAPI represents a string type used to define specific periods in financial or date-related contexts.
 type PeriodAPI string
 
@@ -72,23 +90,25 @@ type Result struct {
 
 // Meta represents metadata in the Yahoo Finance response
 type Meta struct {
-	Currency             string        `json:"currency"`
-	Symbol               string        `json:"symbol"`
-	Name                 string        `json:"longName"`
-	ExchangeName         string        `json:"exchangeName"`
-	InstrumentType       string        `json:"instrumentType"`
-	FirstTradeDate       int64         `json:"firstTradeDate"`
-	RegularMarketTime    int64         `json:"regularMarketTime"`
-	GMTOffset            int           `json:"gmtoffset"`
-	Timezone             string        `json:"timezone"`
-	ExchangeTimezoneName string        `json:"exchangeTimezoneName"`
-	RegularMarketPrice   float64       `json:"regularMarketPrice"`
-	ChartPreviousClose   float64       `json:"chartPreviousClose"`
-	PriceHint            int           `json:"priceHint"`
-	CurrentTradingPeriod TradingPeriod `json:"currentTradingPeriod"`
-	DataGranularity      string        `json:"dataGranularity"`
-	Range                string        `json:"range"`
-	ValidRanges          []string      `json:"validRanges"`
+	Currency                   string        `json:"currency"`
+	Symbol                     string        `json:"symbol"`
+	Name                       string        `json:"longName"`
+	ExchangeName               string        `json:"exchangeName"`
+	InstrumentType             string        `json:"instrumentType"`
+	FirstTradeDate             int64         `json:"firstTradeDate"`
+	RegularMarketTime          int64         `json:"regularMarketTime"`
+	GMTOffset                  int           `json:"gmtoffset"`
+	Timezone                   string        `json:"timezone"`
+	ExchangeTimezoneName       string        `json:"exchangeTimezoneName"`
+	RegularMarketPrice         float64       `json:"regularMarketPrice"`
+	ChartPreviousClose         float64       `json:"chartPreviousClose"`
+	PreviousClose              float64       `json:"previousClose"`
+	RegularMarketPreviousClose float64       `json:"regularMarketPreviousClose"`
+	PriceHint                  int           `json:"priceHint"`
+	CurrentTradingPeriod       TradingPeriod `json:"currentTradingPeriod"`
+	DataGranularity            string        `json:"dataGranularity"`
+	Range                      string        `json:"range"`
+	ValidRanges                []string      `json:"validRanges"`
 }
 
 // TradingPeriod represents a trading period in the Yahoo Finance response
@@ -112,18 +132,18 @@ type Indicators struct {
 	Adjclose []Adjclose `json:"adjclose,omitempty"`
 }
 
-// Quote represents quote data in the Yahoo Finance response
+// Quote represents quote data in the Yahoo Finance response, null values are kept as nil
 type Quote struct {
-	High   []float64 `json:"high"`
-	Open   []float64 `json:"open"`
-	Low    []float64 `json:"low"`
-	Close  []float64 `json:"close"`
-	Volume []float64 `json:"volume"`
+	High   []*float64 `json:"high"`
+	Open   []*float64 `json:"open"`
+	Low    []*float64 `json:"low"`
+	Close  []*float64 `json:"close"`
+	Volume []*float64 `json:"volume"`
 }
 
 // Adjclose represents adjusted close data in the Yahoo Finance response
 type Adjclose struct {
-	Adjclose []float64 `json:"adjclose"`
+	Adjclose []*float64 `json:"adjclose"`
 }
 
 type StockPrice struct {
@@ -133,12 +153,146 @@ type StockPrice struct {
 	Low      float64
 	Close    float64
 	AdjClose float64
-	Volume   int
+	Volume   float64
+}
+
+// IntervalLimit describes how far back and how much history of an interval a provider serves
+type IntervalLimit struct {
+	Lookback time.Duration // age of the oldest available bar, zero when unlimited
+	MaxRange time.Duration // longest range of a single request, zero when unlimited
 }
 
+// HistoryLimits are the interval limits of a provider, intervals without an entry are unlimited
+type HistoryLimits map[IntervalAPI]IntervalLimit
+
 type MarketData struct {
 	Symbol   string
 	Name     string
 	Exchange string
+	Interval IntervalAPI // bar interval of the prices, stored as daily bars when empty
 	Prices   []StockPrice
+	Quote    *MarketQuote // latest regular market quote, nil when the provider does not report one
+}
+
+// MarketQuote represents the regular market quote reported alongside the chart data
+type MarketQuote struct {
+	Price         float64
+	PreviousClose float64 // close of the previous session, zero when unknown
+	Currency      string
+	Time          time.Time
+}
+
+// SparkResponse represents the response from the Yahoo Finance multi-symbol spark API
+type SparkResponse struct {
+	Spark SparkBody `json:"spark"`
+}
+
+// SparkBody represents the spark data in the Yahoo Finance spark response
+type SparkBody struct {
+	Result []SparkResult `json:"result"`
+	Error  *Error        `json:"error"`
+}
+
+// SparkResult represents the chart of a single symbol in the Yahoo Finance spark response. Spark charts
+// carry close prices only.
+type SparkResult struct {
+	Symbol   string   `json:"symbol"`
+	Response []Result `json:"response"`
+}
+
+// BatchResult represents the outcome of a single symbol of a batch request
+type BatchResult struct {
+	Symbol string
+	Data   *MarketData
+	Err    error
+}
+
+// OptionType represents the type of an option contract.
+type OptionType string
+
+// OptionCall represents a call option.
+// OptionPut represents a put option.
+const (
+	OptionCall OptionType = "call"
+	OptionPut  OptionType = "put"
+)
+
+// OptionsResponse represents the response from the Yahoo Finance options API
+type OptionsResponse struct {
+	OptionChain OptionChainResponse `json:"optionChain"`
+}
+
+// OptionChainResponse represents the option chain data in the Yahoo Finance options response
+type OptionChainResponse struct {
+	Result []OptionResult `json:"result"`
+	Error  *Error         `json:"error"`
+}
+
+// OptionResult represents a result in the Yahoo Finance options response
+type OptionResult struct {
+	UnderlyingSymbol string      `json:"underlyingSymbol"`
+	ExpirationDates  []int64     `json:"expirationDates"`
+	Strikes          []float64   `json:"strikes"`
+	Quote            OptionQuote `json:"quote"`
+	Options          []OptionSet `json:"options"`
+}
+
+// OptionQuote represents the quote of the underlying in the Yahoo Finance options response
+type OptionQuote struct {
+	Symbol             string  `json:"symbol"`
+	LongName           string  `json:"longName"`
+	ShortName          string  `json:"shortName"`
+	Exchange           string  `json:"exchange"`
+	FullExchangeName   string  `json:"fullExchangeName"`
+	RegularMarketPrice float64 `json:"regularMarketPrice"`
+	RegularMarketTime  int64   `json:"regularMarketTime"`
+}
+
+// OptionSet represents calls and puts of a single expiration in the Yahoo Finance options response
+type OptionSet struct {
+	ExpirationDate int64            `json:"expirationDate"`
+	Calls          []OptionContract `json:"calls"`
+	Puts           []OptionContract `json:"puts"`
+}
+
+// OptionContract represents an option contract quote in the Yahoo Finance options response
+type OptionContract struct {
+	ContractSymbol    string  `json:"contractSymbol"`
+	Strike            float64 `json:"strike"`
+	Currency          string  `json:"currency"`
+	LastPrice         float64 `json:"lastPrice"`
+	Change            float64 `json:"change"`
+	PercentChange     float64 `json:"percentChange"`
+	Volume            int64   `json:"volume"`
+	OpenInterest      int64   `json:"openInterest"`
+	Bid               float64 `json:"bid"`
+	Ask               float64 `json:"ask"`
+	ContractSize      string  `json:"contractSize"`
+	Expiration        int64   `json:"expiration"`
+	LastTradeDate     int64   `json:"lastTradeDate"`
+	ImpliedVolatility float64 `json:"impliedVolatility"`
+	InTheMoney        bool    `json:"inTheMoney"`
+}
+
+type OptionQuoteData struct {
+	ContractSymbol    string
+	Type              OptionType
+	Expiry            time.Time
+	Strike            float64
+	Bid               float64
+	Ask               float64
+	LastPrice         float64
+	Volume            int64
+	OpenInterest      int64
+	ImpliedVolatility float64
+	LastTradeTime     time.Time
+}
+
+type OptionChain struct {
+	Symbol          string
+	Name            string
+	Exchange        string
+	UnderlyingPrice float64
+	ExpirationDates []time.Time
+	Contracts       []OptionQuoteData
 }
@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job is a task executed periodically by the scheduler
+type Job struct {
+	Name     string                          // name used in logs
+	Interval time.Duration                   // time between two runs
+	Timeout  time.Duration                   // maximum duration of a single run, zero means no limit
+	Run      func(ctx context.Context) error // the work to execute
+}
+
+// Leadership tells whether this replica leads the others, only the leader runs scheduled jobs
+type Leadership interface {
+	IsLeader() bool
+}
+
+// Scheduler runs registered jobs periodically until stopped
+type Scheduler struct {
+	jobs       []Job
+	leadership Leadership // nil when every run is executed
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// New creates a new scheduler without jobs
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job, jobs added after Start are not executed
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// SetLeadership restricts the runs to the times this replica leads, so that several replicas do not execute the
+// same jobs. Runs falling into times without leadership are skipped.
+func (s *Scheduler) SetLeadership(leadership Leadership) {
+	s.leadership = leadership
+}
+
+// Start runs every job immediately and then at its interval, each job in its own goroutine
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			log.Warn().Str("job", job.Name).Msg("Job has no interval, not scheduled")
+			continue
+		}
+
+		log.Info().
+			Str("job", job.Name).
+			Dur("interval", job.Interval).
+			Msg("Scheduling job")
+
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	log.Info().Msg("Scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, job)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	if s.leadership != nil && !s.leadership.IsLeader() {
+		log.Debug().Str("job", job.Name).Msg("Not the leader, scheduled job skipped")
+		return
+	}
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		log.Error().Err(err).Str("job", job.Name).Msg("Scheduled job failed")
+		return
+	}
+	log.Debug().
+		Str("job", job.Name).
+		Dur("duration", time.Since(start)).
+		Msg("Scheduled job finished")
+}
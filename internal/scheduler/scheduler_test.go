@@ -0,0 +1,110 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	var runs, failures atomic.Int32
+
+	s := scheduler.New()
+	s.Add(scheduler.Job{
+		Name:     "count",
+		Interval: 10 * time.Millisecond,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	s.Add(scheduler.Job{
+		Name:     "fail",
+		Interval: 10 * time.Millisecond,
+		Run: func(context.Context) error {
+			failures.Add(1)
+			return errors.New("boom")
+		},
+	})
+	s.Add(scheduler.Job{
+		Name: "never",
+		Run: func(context.Context) error {
+			t.Error("job without interval must not run")
+			return nil
+		},
+	})
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 3 && failures.Load() >= 3
+	}, time.Second, 5*time.Millisecond, "failing jobs keep being scheduled")
+	s.Stop()
+
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load(), "no runs after stop")
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	done := make(chan error, 1)
+
+	s := scheduler.New()
+	s.Add(scheduler.Job{
+		Name:     "slow",
+		Interval: time.Hour,
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			done <- ctx.Err()
+			return ctx.Err()
+		},
+	})
+	s.Start(context.Background())
+	defer s.Stop()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("job was not canceled after its timeout")
+	}
+}
+
+type leadership struct {
+	leader atomic.Bool
+}
+
+func (l *leadership) IsLeader() bool {
+	return l.leader.Load()
+}
+
+func TestScheduler_Leadership(t *testing.T) {
+	var runs atomic.Int32
+	lead := &leadership{}
+
+	s := scheduler.New()
+	s.SetLeadership(lead)
+	s.Add(scheduler.Job{
+		Name:     "count",
+		Interval: 10 * time.Millisecond,
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+
+	s.Start(context.Background())
+	defer s.Stop()
+	time.Sleep(30 * time.Millisecond)
+	assert.Zero(t, runs.Load(), "followers do not run jobs")
+
+	lead.leader.Store(true)
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 2
+	}, time.Second, 5*time.Millisecond, "jobs run once leading")
+}
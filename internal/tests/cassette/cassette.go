@@ -0,0 +1,299 @@
+// Package cassette provides an HTTP transport which records provider responses to fixture files once and
+// replays them deterministically afterwards, so provider tests run without the network.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+// RecordEnv is the environment variable switching NewForTest into recording mode when set to a non-empty value.
+const RecordEnv = "CASSETTE_RECORD"
+
+// scrubbedValue replaces secrets in recorded requests and responses.
+const scrubbedValue = "REDACTED"
+
+// Mode selects whether a Recorder talks to the real server or serves recorded interactions.
+type Mode int
+
+// ModeReplay serves recorded interactions and fails requests without a match.
+// ModeRecord forwards requests to the real server and records every interaction.
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+)
+
+// Default secrets removed from recorded interactions
+var (
+	DefaultScrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-MBX-APIKEY"}
+	DefaultScrubParams  = []string{"api_key", "apikey", "token", "crumb"}
+)
+
+// Cassette is the content of a fixture file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request together with its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of an HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+}
+
+// Response is the recorded part of an HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Matcher reports whether an incoming request, already scrubbed, matches a recorded one.
+type Matcher func(r *http.Request, recorded Request) bool
+
+// MatchMethodAndURL matches requests with the same method and URL, ignoring the order of query parameters.
+func MatchMethodAndURL(r *http.Request, recorded Request) bool {
+	if r.Method != recorded.Method {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return r.URL.Scheme == recordedURL.Scheme &&
+		r.URL.Host == recordedURL.Host &&
+		r.URL.Path == recordedURL.Path &&
+		r.URL.Query().Encode() == recordedURL.Query().Encode()
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithMode sets the recording mode, ModeReplay by default.
+func WithMode(mode Mode) Option {
+	return func(r *Recorder) { r.mode = mode }
+}
+
+// WithMatcher replaces MatchMethodAndURL as the request matcher.
+func WithMatcher(matcher Matcher) Option {
+	return func(r *Recorder) { r.matcher = matcher }
+}
+
+// WithScrubHeaders adds headers whose values are redacted in the cassette.
+func WithScrubHeaders(headers ...string) Option {
+	return func(r *Recorder) { r.scrubHeaders = append(r.scrubHeaders, headers...) }
+}
+
+// WithScrubParams adds query parameters whose values are redacted in the cassette.
+func WithScrubParams(params ...string) Option {
+	return func(r *Recorder) { r.scrubParams = append(r.scrubParams, params...) }
+}
+
+// WithTransport sets the transport used to reach the real server when recording, http.DefaultTransport by default.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) { r.transport = transport }
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a cassette file. In replay mode every
+// recorded interaction is served at most once, in recording order among equally matching ones.
+type Recorder struct {
+	path         string
+	mode         Mode
+	matcher      Matcher
+	scrubHeaders []string
+	scrubParams  []string
+	transport    http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a recorder for the cassette file at path. In replay mode the file must exist.
+func New(path string, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:         path,
+		matcher:      MatchMethodAndURL,
+		scrubHeaders: append([]string{}, DefaultScrubHeaders...),
+		scrubParams:  append([]string{}, DefaultScrubParams...),
+		transport:    http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeReplay {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to read cassette %s, record it with %s=1", path, RecordEnv)
+		}
+		if err := json.Unmarshal(content, &r.cassette); err != nil {
+			return nil, eris.Wrapf(err, "failed to parse cassette %s", path)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// NewForTest creates a recorder for fixtures/cassettes/<name>.json of the test package. It records when
+// CASSETTE_RECORD is set and replays otherwise; a recorded cassette is saved when the test finishes.
+func NewForTest(t *testing.T, name string, opts ...Option) *Recorder {
+	t.Helper()
+
+	mode := ModeReplay
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	opts = append([]Option{WithMode(mode)}, opts...)
+
+	r, err := New(filepath.Join("fixtures", "cassettes", name+".json"), opts...)
+	if err != nil {
+		t.Fatalf("cannot load cassette: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Errorf("cannot save cassette: %v", err)
+		}
+	})
+	return r
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+// Stop saves the cassette when recording. Replaying recorders have nothing to save.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return eris.Wrap(err, "failed to marshal cassette")
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return eris.Wrap(err, "failed to create cassette directory")
+	}
+	if err := os.WriteFile(r.path, append(content, '\n'), 0o644); err != nil {
+		return eris.Wrapf(err, "failed to write cassette %s", r.path)
+	}
+	return nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to read response body")
+	}
+
+	headers := r.scrubbedHeaders(resp.Header)
+	// the body is stored decoded, its original length and encoding no longer apply
+	headers.Del("Content-Length")
+	headers.Del("Content-Encoding")
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     r.scrubbedURL(req.URL).String(),
+			Headers: r.scrubbedHeaders(req.Header),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    headers,
+			Body:       string(body),
+		},
+	})
+	r.mu.Unlock()
+
+	return newResponse(req, resp.StatusCode, resp.Header, body), nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	scrubbed := req.Clone(req.Context())
+	scrubbed.URL = r.scrubbedURL(req.URL)
+	scrubbed.Header = r.scrubbedHeaders(req.Header)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matcher(scrubbed, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		resp := interaction.Response
+		return newResponse(req, resp.StatusCode, resp.Headers, []byte(resp.Body)), nil
+	}
+	return nil, fmt.Errorf("cassette %s has no unused interaction for %s %s", r.path, req.Method, scrubbed.URL)
+}
+
+func (r *Recorder) scrubbedURL(u *url.URL) *url.URL {
+	scrubbed := *u
+	query := scrubbed.Query()
+	for _, param := range r.scrubParams {
+		if query.Has(param) {
+			query.Set(param, scrubbedValue)
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return &scrubbed
+}
+
+func (r *Recorder) scrubbedHeaders(headers http.Header) http.Header {
+	scrubbed := headers.Clone()
+	if scrubbed == nil {
+		scrubbed = http.Header{}
+	}
+	for _, header := range r.scrubHeaders {
+		if scrubbed.Get(header) != "" {
+			scrubbed.Set(header, scrubbedValue)
+		}
+	}
+	return scrubbed
+}
+
+func newResponse(req *http.Request, statusCode int, headers http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, strings.TrimSpace(http.StatusText(statusCode))),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,74 @@
+package cassette_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/market-data/internal/tests/cassette"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		if r.URL.Query().Get("series_id") == "NOPE" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_, _ = w.Write([]byte(`{"series_id":"` + r.URL.Query().Get("series_id") + `"}`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "fred.json")
+	recorder, err := cassette.New(path, cassette.WithMode(cassette.ModeRecord))
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder}
+
+	status, body := get(t, client, server.URL+"/series?series_id=UNRATE&api_key=secret")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"series_id":"UNRATE"}`, body)
+	status, _ = get(t, client, server.URL+"/series?series_id=NOPE&api_key=secret")
+	assert.Equal(t, http.StatusBadRequest, status)
+	require.NoError(t, recorder.Stop())
+	server.Close()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret", "secrets are scrubbed from the cassette")
+
+	// replay works without the server, query parameter order and secret values do not matter
+	recorder, err = cassette.New(path)
+	require.NoError(t, err)
+	client = &http.Client{Transport: recorder}
+
+	status, _ = get(t, client, server.URL+"/series?api_key=other&series_id=NOPE")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, body = get(t, client, server.URL+"/series?series_id=UNRATE&api_key=other")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"series_id":"UNRATE"}`, body)
+
+	// every interaction is replayed once
+	_, err = client.Get(server.URL + "/series?series_id=UNRATE&api_key=other")
+	assert.ErrorContains(t, err, "no unused interaction")
+}
+
+func TestNew_MissingCassette(t *testing.T) {
+	_, err := cassette.New(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, cassette.RecordEnv)
+}
@@ -0,0 +1,357 @@
+// Package fakeyahoo provides an httptest server emulating the Yahoo Finance chart endpoint with synthetic,
+// deterministic bars, so services, schedulers and controllers can be tested end-to-end without the network.
+package fakeyahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/config"
+	"github.com/market-data/internal/providers/yahoo"
+)
+
+// chartPath is the path prefix of the emulated chart endpoint, the symbol follows it.
+const chartPath = "/v8/finance/chart/"
+
+// DefaultEnd is the time of the last bar served unless changed with SetEnd, a Friday after the close.
+var DefaultEnd = time.Date(2025, time.June, 13, 21, 0, 0, 0, time.UTC)
+
+// regular session of the synthetic exchange in UTC
+const (
+	sessionOpen  = 13*time.Hour + 30*time.Minute
+	sessionClose = 20 * time.Hour
+)
+
+// Symbol configures the chart served for a symbol.
+type Symbol struct {
+	Symbol     string
+	Name       string
+	Exchange   string        // defaults to NMS
+	Currency   string        // defaults to USD
+	Price      float64       // close before the first bar, the bars oscillate around it; defaults to 100
+	NullBars   []int         // indexes of bars served with null prices
+	Status     int           // answers every request with this status when set, e.g. http.StatusNotFound
+	RateLimit  int           // answers this many requests with 429 before serving the chart
+	RetryAfter int           // seconds announced in the Retry-After header of rate limited responses
+	Delay      time.Duration // waits before answering, e.g. to trigger client timeouts
+}
+
+// Server is a fake Yahoo Finance chart API.
+type Server struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	end      time.Time
+	symbols  map[string]*Symbol
+	requests map[string]int
+}
+
+// New starts a fake server serving the given symbols, it is closed when the test finishes.
+func New(t testing.TB, symbols ...Symbol) *Server {
+	s := &Server{
+		end:      DefaultEnd,
+		symbols:  make(map[string]*Symbol),
+		requests: make(map[string]int),
+	}
+	for _, symbol := range symbols {
+		s.SetSymbol(symbol)
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// ChartURL returns the base URL of the chart endpoint, the equivalent of yahoo_finance.base_url.
+func (s *Server) ChartURL() string {
+	return s.server.URL + chartPath
+}
+
+// Config returns a Yahoo Finance configuration pointing at the server, with fast retries.
+func (s *Server) Config() *config.YahooFinanceConfig {
+	return &config.YahooFinanceConfig{
+		BaseURL:        s.ChartURL(),
+		RequestTimeout: 5,
+		RetryCount:     3,
+		RetryWaitTime:  1,
+	}
+}
+
+// SetSymbol adds or replaces a served symbol.
+func (s *Server) SetSymbol(symbol Symbol) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symbols[symbol.Symbol] = &symbol
+}
+
+// SetEnd moves the time of the last served bar, e.g. to emulate the passing of days between fetches.
+func (s *Server) SetEnd(end time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.end = end
+}
+
+// Requests returns the number of chart requests received for a symbol.
+func (s *Server) Requests(symbol string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[symbol]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, chartPath) {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, chartPath)
+
+	s.mu.Lock()
+	s.requests[name]++
+	symbol, ok := s.symbols[name]
+	var cfg Symbol
+	rateLimited := false
+	if ok {
+		cfg = *symbol
+		if symbol.RateLimit > 0 {
+			symbol.RateLimit--
+			rateLimited = true
+		}
+	}
+	end := s.end
+	s.mu.Unlock()
+
+	if cfg.Delay > 0 {
+		select {
+		case <-time.After(cfg.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, "Not Found", "No data found, symbol may be delisted")
+	case rateLimited:
+		if cfg.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(cfg.RetryAfter))
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("Too Many Requests"))
+	case cfg.Status != 0 && cfg.Status != http.StatusOK:
+		writeError(w, cfg.Status, http.StatusText(cfg.Status), "Configured failure")
+	default:
+		resp, err := chart(&cfg, r.URL.Query(), end)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "Unprocessable Entity", err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(yahoo.YahooFinanceResponse{
+		Chart: yahoo.ChartResponse{Error: &yahoo.Error{Code: code, Description: description}},
+	})
+}
+
+// chart builds the response of a symbol with bars covering the requested range (period1 to period2, exclusive)
+// or period (range) up to end.
+func chart(symbol *Symbol, query url.Values, end time.Time) (*yahoo.YahooFinanceResponse, error) {
+	interval := yahoo.IntervalAPI(query.Get("interval"))
+	period := yahoo.PeriodAPI(query.Get("range"))
+	var start time.Time
+	if query.Has("period1") {
+		period1, err1 := strconv.ParseInt(query.Get("period1"), 10, 64)
+		period2, err2 := strconv.ParseInt(query.Get("period2"), 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid input - period1=%s period2=%s", query.Get("period1"), query.Get("period2"))
+		}
+		start = time.Unix(period1, 0).UTC()
+		if rangeEnd := time.Unix(period2, 0).UTC().Add(-time.Nanosecond); rangeEnd.Before(end) {
+			end = rangeEnd
+		}
+	} else {
+		var err error
+		if start, err = periodStart(period, end); err != nil {
+			return nil, err
+		}
+	}
+
+	times, err := barTimes(interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	base := symbol.Price
+	if base == 0 {
+		base = 100
+	}
+	quote := yahoo.Quote{}
+	adjClose := yahoo.Adjclose{}
+	timestamps := make([]int64, 0, len(times))
+	previous := base
+	for i, t := range times {
+		timestamps = append(timestamps, t.Unix())
+		if slices.Contains(symbol.NullBars, i) {
+			quote.Open = append(quote.Open, nil)
+			quote.High = append(quote.High, nil)
+			quote.Low = append(quote.Low, nil)
+			quote.Close = append(quote.Close, nil)
+			quote.Volume = append(quote.Volume, nil)
+			adjClose.Adjclose = append(adjClose.Adjclose, nil)
+			continue
+		}
+
+		closePrice := round(base * (1 + 0.02*math.Sin(float64(i+1)/3)))
+		open := previous
+		high := round(math.Max(open, closePrice) * 1.005)
+		low := round(math.Min(open, closePrice) * 0.995)
+		volume := float64(1_000_000 + 1_000*i)
+		quote.Open = append(quote.Open, &open)
+		quote.High = append(quote.High, &high)
+		quote.Low = append(quote.Low, &low)
+		quote.Close = append(quote.Close, &closePrice)
+		quote.Volume = append(quote.Volume, &volume)
+		adjClose.Adjclose = append(adjClose.Adjclose, &closePrice)
+		previous = closePrice
+	}
+
+	result := yahoo.Result{
+		Meta: yahoo.Meta{
+			Currency:             valueOr(symbol.Currency, "USD"),
+			Symbol:               symbol.Symbol,
+			Name:                 symbol.Name,
+			ExchangeName:         valueOr(symbol.Exchange, "NMS"),
+			InstrumentType:       "EQUITY",
+			RegularMarketTime:    end.Unix(),
+			Timezone:             "UTC",
+			ExchangeTimezoneName: "UTC",
+			RegularMarketPrice:   previous,
+			ChartPreviousClose:   base,
+			DataGranularity:      string(interval),
+			Range:                string(period),
+		},
+		Timestamp:  timestamps,
+		Indicators: yahoo.Indicators{Quote: []yahoo.Quote{quote}},
+	}
+	// like Yahoo, only daily and longer charts carry adjusted closes
+	if step, _ := barStep(interval); step >= 24*time.Hour {
+		result.Indicators.Adjclose = []yahoo.Adjclose{adjClose}
+	}
+	return &yahoo.YahooFinanceResponse{Chart: yahoo.ChartResponse{Result: []yahoo.Result{result}}}, nil
+}
+
+// barTimes returns the start times of the bars in [start, end], oldest first. Bars of daily and shorter
+// intervals are placed in the regular session of weekdays.
+func barTimes(interval yahoo.IntervalAPI, start, end time.Time) ([]time.Time, error) {
+	step, err := barStep(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var times []time.Time
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case step >= 7*24*time.Hour:
+		for t := day.Add(sessionOpen); !t.After(end); {
+			if !t.Before(start) {
+				times = append(times, t)
+			}
+			if interval == yahoo.Interval1mo {
+				t = t.AddDate(0, 1, 0)
+			} else {
+				t = t.AddDate(0, 0, 7)
+			}
+		}
+	default:
+		for ; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+				continue
+			}
+			sessionEnd := day.Add(sessionClose)
+			if step == 24*time.Hour {
+				sessionEnd = day.Add(sessionOpen + time.Nanosecond)
+			}
+			for t := day.Add(sessionOpen); t.Before(sessionEnd) && !t.After(end); t = t.Add(step) {
+				if !t.Before(start) {
+					times = append(times, t)
+				}
+			}
+		}
+	}
+	return times, nil
+}
+
+func barStep(interval yahoo.IntervalAPI) (time.Duration, error) {
+	switch interval {
+	case yahoo.Interval1m:
+		return time.Minute, nil
+	case yahoo.Interval5m:
+		return 5 * time.Minute, nil
+	case yahoo.Interval15m:
+		return 15 * time.Minute, nil
+	case yahoo.Interval30m:
+		return 30 * time.Minute, nil
+	case yahoo.Interval1h:
+		return time.Hour, nil
+	case yahoo.Interval1d:
+		return 24 * time.Hour, nil
+	case yahoo.Interval1wk:
+		return 7 * 24 * time.Hour, nil
+	case yahoo.Interval1mo:
+		return 30 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("invalid input - interval=%s is not supported", interval)
+	}
+}
+
+func periodStart(period yahoo.PeriodAPI, end time.Time) (time.Time, error) {
+	switch period {
+	case yahoo.Period1d:
+		return end.AddDate(0, 0, -1), nil
+	case yahoo.Period5d:
+		return end.AddDate(0, 0, -7), nil
+	case yahoo.Period1mo:
+		return end.AddDate(0, -1, 0), nil
+	case yahoo.Period3mo:
+		return end.AddDate(0, -3, 0), nil
+	case yahoo.Period6mo:
+		return end.AddDate(0, -6, 0), nil
+	case yahoo.Period1y:
+		return end.AddDate(-1, 0, 0), nil
+	case yahoo.Period2y:
+		return end.AddDate(-2, 0, 0), nil
+	case yahoo.Period5y:
+		return end.AddDate(-5, 0, 0), nil
+	case yahoo.Period10y, yahoo.PeriodMax:
+		return end.AddDate(-10, 0, 0), nil
+	case yahoo.PeriodYTD:
+		return time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid input - range=%s is not supported", period)
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
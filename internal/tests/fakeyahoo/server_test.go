@@ -0,0 +1,69 @@
+package fakeyahoo_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/market-data/internal/providers/yahoo"
+	"github.com/market-data/internal/tests/fakeyahoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Chart(t *testing.T) {
+	server := fakeyahoo.New(t, fakeyahoo.Symbol{Symbol: "AAPL", Name: "Apple Inc.", Price: 200, NullBars: []int{2}})
+	client := yahoo.NewClient(server.Config())
+
+	data, err := client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", data.Symbol)
+	assert.Equal(t, "Apple Inc.", data.Name)
+	require.Len(t, data.Prices, 4, "five sessions, one of them null")
+	assert.Equal(t, time.Date(2025, time.June, 9, 13, 30, 0, 0, time.UTC), data.Prices[0].Time.UTC())
+	assert.Equal(t, time.Date(2025, time.June, 13, 13, 30, 0, 0, time.UTC), data.Prices[3].Time.UTC())
+	assert.Equal(t, 200.0, data.Prices[0].Open)
+	require.NotNil(t, data.Quote)
+	assert.Equal(t, data.Prices[3].Close, data.Quote.Price)
+
+	intraday, err := client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval5m, yahoo.Period1d)
+	require.NoError(t, err)
+	assert.Len(t, intraday.Prices, 77, "one regular session of 78 five minute bars, one of them null")
+	assert.Equal(t, intraday.Prices[0].Close, intraday.Prices[0].AdjClose)
+
+	server.SetEnd(fakeyahoo.DefaultEnd.AddDate(0, 0, 3))
+	data, err = client.GetMarketData(context.TODO(), "AAPL", yahoo.Interval1d, yahoo.Period1d)
+	require.NoError(t, err)
+	require.Len(t, data.Prices, 1)
+	assert.Equal(t, time.Date(2025, time.June, 16, 13, 30, 0, 0, time.UTC), data.Prices[0].Time.UTC())
+	assert.Equal(t, 3, server.Requests("AAPL"))
+}
+
+func TestServer_Failures(t *testing.T) {
+	server := fakeyahoo.New(t,
+		fakeyahoo.Symbol{Symbol: "BUSY", RateLimit: 2, RetryAfter: 1},
+		fakeyahoo.Symbol{Symbol: "DOWN", Status: http.StatusInternalServerError},
+		fakeyahoo.Symbol{Symbol: "SLOW", Delay: 2 * time.Second},
+	)
+	client := yahoo.NewClient(server.Config())
+
+	_, err := client.GetMarketData(context.TODO(), "BUSY", yahoo.Interval1d, yahoo.Period5d)
+	require.NoError(t, err, "rate limited requests are retried")
+	assert.Equal(t, 3, server.Requests("BUSY"))
+
+	_, err = client.GetMarketData(context.TODO(), "NOPE", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorContains(t, err, "unexpected status code: 404")
+	assert.ErrorIs(t, err, yahoo.ErrUnknownSymbol)
+	assert.Equal(t, 1, server.Requests("NOPE"), "unknown symbols are not retried")
+
+	_, err = client.GetMarketData(context.TODO(), "DOWN", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorContains(t, err, "unexpected status code: 500")
+	assert.Equal(t, 4, server.Requests("DOWN"))
+
+	cfg := server.Config()
+	cfg.RequestTimeout = 1
+	cfg.RetryCount = 0
+	_, err = yahoo.NewClient(cfg).GetMarketData(context.TODO(), "SLOW", yahoo.Interval1d, yahoo.Period5d)
+	assert.ErrorContains(t, err, "Client.Timeout")
+}